@@ -570,6 +570,66 @@ func (x *BazelFlagDb) GetCommands() []string {
 	return nil
 }
 
+type BazelFlagDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flag          *BazelFlag             `protobuf:"bytes,1,opt,name=flag,proto3" json:"flag,omitempty"`
+	BazelVersion  []string               `protobuf:"bytes,2,rep,name=bazel_version,json=bazelVersion,proto3" json:"bazel_version,omitempty"`
+	Command       []string               `protobuf:"bytes,3,rep,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BazelFlagDetail) Reset() {
+	*x = BazelFlagDetail{}
+	mi := &file_build_stack_bazel_help_v1_help_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BazelFlagDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BazelFlagDetail) ProtoMessage() {}
+
+func (x *BazelFlagDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_help_v1_help_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BazelFlagDetail.ProtoReflect.Descriptor instead.
+func (*BazelFlagDetail) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_help_v1_help_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BazelFlagDetail) GetFlag() *BazelFlag {
+	if x != nil {
+		return x.Flag
+	}
+	return nil
+}
+
+func (x *BazelFlagDetail) GetBazelVersion() []string {
+	if x != nil {
+		return x.BazelVersion
+	}
+	return nil
+}
+
+func (x *BazelFlagDetail) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
 var File_build_stack_bazel_help_v1_help_proto protoreflect.FileDescriptor
 
 const file_build_stack_bazel_help_v1_help_proto_rawDesc = "" +
@@ -616,7 +676,11 @@ const file_build_stack_bazel_help_v1_help_proto_rawDesc = "" +
 	"\vBazelFlagDb\x12%\n" +
 	"\x0ebazel_versions\x18\x01 \x03(\tR\rbazelVersions\x128\n" +
 	"\x04flag\x18\x02 \x03(\v2$.build.stack.bazel.help.v1.BazelFlagR\x04flag\x12\x1a\n" +
-	"\bcommands\x18\x03 \x03(\tR\bcommands*7\n" +
+	"\bcommands\x18\x03 \x03(\tR\bcommands\"\x8a\x01\n" +
+	"\x0fBazelFlagDetail\x128\n" +
+	"\x04flag\x18\x01 \x01(\v2$.build.stack.bazel.help.v1.BazelFlagR\x04flag\x12#\n" +
+	"\rbazel_version\x18\x02 \x03(\tR\fbazelVersion\x12\x18\n" +
+	"\acommand\x18\x03 \x03(\tR\acommand*7\n" +
 	"\x12BazelHelpParseMode\x12\t\n" +
 	"\x05USAGE\x10\x00\x12\f\n" +
 	"\bCATEGORY\x10\x01\x12\b\n" +
@@ -635,7 +699,7 @@ func file_build_stack_bazel_help_v1_help_proto_rawDescGZIP() []byte {
 }
 
 var file_build_stack_bazel_help_v1_help_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_build_stack_bazel_help_v1_help_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_build_stack_bazel_help_v1_help_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_build_stack_bazel_help_v1_help_proto_goTypes = []any{
 	(BazelHelpParseMode)(0),   // 0: build.stack.bazel.help.v1.BazelHelpParseMode
 	(*BazelOption)(nil),       // 1: build.stack.bazel.help.v1.BazelOption
@@ -645,6 +709,7 @@ var file_build_stack_bazel_help_v1_help_proto_goTypes = []any{
 	(*BazelHelpRegistry)(nil), // 5: build.stack.bazel.help.v1.BazelHelpRegistry
 	(*BazelFlag)(nil),         // 6: build.stack.bazel.help.v1.BazelFlag
 	(*BazelFlagDb)(nil),       // 7: build.stack.bazel.help.v1.BazelFlagDb
+	(*BazelFlagDetail)(nil),   // 8: build.stack.bazel.help.v1.BazelFlagDetail
 }
 var file_build_stack_bazel_help_v1_help_proto_depIdxs = []int32{
 	1, // 0: build.stack.bazel.help.v1.BazelHelpCategory.option:type_name -> build.stack.bazel.help.v1.BazelOption
@@ -652,11 +717,12 @@ var file_build_stack_bazel_help_v1_help_proto_depIdxs = []int32{
 	3, // 2: build.stack.bazel.help.v1.BazelHelpVersion.command:type_name -> build.stack.bazel.help.v1.BazelHelpCommand
 	4, // 3: build.stack.bazel.help.v1.BazelHelpRegistry.version:type_name -> build.stack.bazel.help.v1.BazelHelpVersion
 	6, // 4: build.stack.bazel.help.v1.BazelFlagDb.flag:type_name -> build.stack.bazel.help.v1.BazelFlag
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	6, // 5: build.stack.bazel.help.v1.BazelFlagDetail.flag:type_name -> build.stack.bazel.help.v1.BazelFlag
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_build_stack_bazel_help_v1_help_proto_init() }
@@ -670,7 +736,7 @@ func file_build_stack_bazel_help_v1_help_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_build_stack_bazel_help_v1_help_proto_rawDesc), len(file_build_stack_bazel_help_v1_help_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   7,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -170,6 +170,7 @@ type RegistryManifest struct {
 	CommitDate    string                 `protobuf:"bytes,2,opt,name=commit_date,json=commitDate,proto3" json:"commit_date,omitempty"`
 	Branch        string                 `protobuf:"bytes,3,opt,name=branch,proto3" json:"branch,omitempty"`
 	AssetHashes   map[string]string      `protobuf:"bytes,4,rep,name=asset_hashes,json=assetHashes,proto3" json:"asset_hashes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Deltas        []*ReleaseDeltaRef     `protobuf:"bytes,5,rep,name=deltas,proto3" json:"deltas,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -232,6 +233,193 @@ func (x *RegistryManifest) GetAssetHashes() map[string]string {
 	return nil
 }
 
+func (x *RegistryManifest) GetDeltas() []*ReleaseDeltaRef {
+	if x != nil {
+		return x.Deltas
+	}
+	return nil
+}
+
+type ReleaseDeltaRef struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	FromCommitSha      string                 `protobuf:"bytes,1,opt,name=from_commit_sha,json=fromCommitSha,proto3" json:"from_commit_sha,omitempty"`
+	FromSymbolsAsset   string                 `protobuf:"bytes,2,opt,name=from_symbols_asset,json=fromSymbolsAsset,proto3" json:"from_symbols_asset,omitempty"`
+	RegistryDeltaAsset string                 `protobuf:"bytes,3,opt,name=registry_delta_asset,json=registryDeltaAsset,proto3" json:"registry_delta_asset,omitempty"`
+	SymbolsDeltaAsset  string                 `protobuf:"bytes,4,opt,name=symbols_delta_asset,json=symbolsDeltaAsset,proto3" json:"symbols_delta_asset,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ReleaseDeltaRef) Reset() {
+	*x = ReleaseDeltaRef{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseDeltaRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseDeltaRef) ProtoMessage() {}
+
+func (x *ReleaseDeltaRef) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseDeltaRef.ProtoReflect.Descriptor instead.
+func (*ReleaseDeltaRef) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ReleaseDeltaRef) GetFromCommitSha() string {
+	if x != nil {
+		return x.FromCommitSha
+	}
+	return ""
+}
+
+func (x *ReleaseDeltaRef) GetFromSymbolsAsset() string {
+	if x != nil {
+		return x.FromSymbolsAsset
+	}
+	return ""
+}
+
+func (x *ReleaseDeltaRef) GetRegistryDeltaAsset() string {
+	if x != nil {
+		return x.RegistryDeltaAsset
+	}
+	return ""
+}
+
+func (x *ReleaseDeltaRef) GetSymbolsDeltaAsset() string {
+	if x != nil {
+		return x.SymbolsDeltaAsset
+	}
+	return ""
+}
+
+type RegistryDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Header        *Registry              `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Upsert        []*Module              `protobuf:"bytes,2,rep,name=upsert,proto3" json:"upsert,omitempty"`
+	ModuleName    []string               `protobuf:"bytes,3,rep,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryDelta) Reset() {
+	*x = RegistryDelta{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryDelta) ProtoMessage() {}
+
+func (x *RegistryDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryDelta.ProtoReflect.Descriptor instead.
+func (*RegistryDelta) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RegistryDelta) GetHeader() *Registry {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *RegistryDelta) GetUpsert() []*Module {
+	if x != nil {
+		return x.Upsert
+	}
+	return nil
+}
+
+func (x *RegistryDelta) GetModuleName() []string {
+	if x != nil {
+		return x.ModuleName
+	}
+	return nil
+}
+
+type SymbolsDelta struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Upsert        []*v1.ModuleVersionSymbols `protobuf:"bytes,1,rep,name=upsert,proto3" json:"upsert,omitempty"`
+	ModuleVersion []string                   `protobuf:"bytes,2,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymbolsDelta) Reset() {
+	*x = SymbolsDelta{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymbolsDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolsDelta) ProtoMessage() {}
+
+func (x *SymbolsDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolsDelta.ProtoReflect.Descriptor instead.
+func (*SymbolsDelta) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SymbolsDelta) GetUpsert() []*v1.ModuleVersionSymbols {
+	if x != nil {
+		return x.Upsert
+	}
+	return nil
+}
+
+func (x *SymbolsDelta) GetModuleVersion() []string {
+	if x != nil {
+		return x.ModuleVersion
+	}
+	return nil
+}
+
 type Module struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -244,7 +432,7 @@ type Module struct {
 
 func (x *Module) Reset() {
 	*x = Module{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[2]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -256,7 +444,7 @@ func (x *Module) String() string {
 func (*Module) ProtoMessage() {}
 
 func (x *Module) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[2]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -269,7 +457,7 @@ func (x *Module) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Module.ProtoReflect.Descriptor instead.
 func (*Module) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{2}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Module) GetName() string {
@@ -313,7 +501,7 @@ type Maintainer struct {
 
 func (x *Maintainer) Reset() {
 	*x = Maintainer{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[3]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -325,7 +513,7 @@ func (x *Maintainer) String() string {
 func (*Maintainer) ProtoMessage() {}
 
 func (x *Maintainer) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[3]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -338,7 +526,7 @@ func (x *Maintainer) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Maintainer.ProtoReflect.Descriptor instead.
 func (*Maintainer) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{3}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Maintainer) GetEmail() string {
@@ -376,6 +564,234 @@ func (x *Maintainer) GetGithubUserId() int32 {
 	return 0
 }
 
+type MaintainerIndex struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Maintainers            []*MaintainerPortfolio `protobuf:"bytes,1,rep,name=maintainers,proto3" json:"maintainers,omitempty"`
+	UnmaintainedModules    []string               `protobuf:"bytes,2,rep,name=unmaintained_modules,json=unmaintainedModules,proto3" json:"unmaintained_modules,omitempty"`
+	DoNotNotifyOnlyModules []string               `protobuf:"bytes,3,rep,name=do_not_notify_only_modules,json=doNotNotifyOnlyModules,proto3" json:"do_not_notify_only_modules,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *MaintainerIndex) Reset() {
+	*x = MaintainerIndex{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaintainerIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaintainerIndex) ProtoMessage() {}
+
+func (x *MaintainerIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaintainerIndex.ProtoReflect.Descriptor instead.
+func (*MaintainerIndex) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MaintainerIndex) GetMaintainers() []*MaintainerPortfolio {
+	if x != nil {
+		return x.Maintainers
+	}
+	return nil
+}
+
+func (x *MaintainerIndex) GetUnmaintainedModules() []string {
+	if x != nil {
+		return x.UnmaintainedModules
+	}
+	return nil
+}
+
+func (x *MaintainerIndex) GetDoNotNotifyOnlyModules() []string {
+	if x != nil {
+		return x.DoNotNotifyOnlyModules
+	}
+	return nil
+}
+
+type MaintainerPortfolio struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Key                  string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Maintainer           *Maintainer            `protobuf:"bytes,2,opt,name=maintainer,proto3" json:"maintainer,omitempty"`
+	Modules              []*MaintainerModule    `protobuf:"bytes,3,rep,name=modules,proto3" json:"modules,omitempty"`
+	ReleaseCount         int32                  `protobuf:"varint,4,opt,name=release_count,json=releaseCount,proto3" json:"release_count,omitempty"`
+	AuthoredReleaseCount int32                  `protobuf:"varint,5,opt,name=authored_release_count,json=authoredReleaseCount,proto3" json:"authored_release_count,omitempty"`
+	LastActivity         string                 `protobuf:"bytes,6,opt,name=last_activity,json=lastActivity,proto3" json:"last_activity,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *MaintainerPortfolio) Reset() {
+	*x = MaintainerPortfolio{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaintainerPortfolio) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaintainerPortfolio) ProtoMessage() {}
+
+func (x *MaintainerPortfolio) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaintainerPortfolio.ProtoReflect.Descriptor instead.
+func (*MaintainerPortfolio) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MaintainerPortfolio) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MaintainerPortfolio) GetMaintainer() *Maintainer {
+	if x != nil {
+		return x.Maintainer
+	}
+	return nil
+}
+
+func (x *MaintainerPortfolio) GetModules() []*MaintainerModule {
+	if x != nil {
+		return x.Modules
+	}
+	return nil
+}
+
+func (x *MaintainerPortfolio) GetReleaseCount() int32 {
+	if x != nil {
+		return x.ReleaseCount
+	}
+	return 0
+}
+
+func (x *MaintainerPortfolio) GetAuthoredReleaseCount() int32 {
+	if x != nil {
+		return x.AuthoredReleaseCount
+	}
+	return 0
+}
+
+func (x *MaintainerPortfolio) GetLastActivity() string {
+	if x != nil {
+		return x.LastActivity
+	}
+	return ""
+}
+
+type MaintainerModule struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Name                 string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ReleaseCount         int32                  `protobuf:"varint,2,opt,name=release_count,json=releaseCount,proto3" json:"release_count,omitempty"`
+	AuthoredReleaseCount int32                  `protobuf:"varint,3,opt,name=authored_release_count,json=authoredReleaseCount,proto3" json:"authored_release_count,omitempty"`
+	LatestVersion        string                 `protobuf:"bytes,4,opt,name=latest_version,json=latestVersion,proto3" json:"latest_version,omitempty"`
+	LastActivity         string                 `protobuf:"bytes,5,opt,name=last_activity,json=lastActivity,proto3" json:"last_activity,omitempty"`
+	DoNotNotify          bool                   `protobuf:"varint,6,opt,name=do_not_notify,json=doNotNotify,proto3" json:"do_not_notify,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *MaintainerModule) Reset() {
+	*x = MaintainerModule{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaintainerModule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaintainerModule) ProtoMessage() {}
+
+func (x *MaintainerModule) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaintainerModule.ProtoReflect.Descriptor instead.
+func (*MaintainerModule) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MaintainerModule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MaintainerModule) GetReleaseCount() int32 {
+	if x != nil {
+		return x.ReleaseCount
+	}
+	return 0
+}
+
+func (x *MaintainerModule) GetAuthoredReleaseCount() int32 {
+	if x != nil {
+		return x.AuthoredReleaseCount
+	}
+	return 0
+}
+
+func (x *MaintainerModule) GetLatestVersion() string {
+	if x != nil {
+		return x.LatestVersion
+	}
+	return ""
+}
+
+func (x *MaintainerModule) GetLastActivity() string {
+	if x != nil {
+		return x.LastActivity
+	}
+	return ""
+}
+
+func (x *MaintainerModule) GetDoNotNotify() bool {
+	if x != nil {
+		return x.DoNotNotify
+	}
+	return false
+}
+
 type ModuleMetadata struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	Homepage       string                 `protobuf:"bytes,1,opt,name=homepage,proto3" json:"homepage,omitempty"`
@@ -391,7 +807,7 @@ type ModuleMetadata struct {
 
 func (x *ModuleMetadata) Reset() {
 	*x = ModuleMetadata{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[4]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -403,7 +819,7 @@ func (x *ModuleMetadata) String() string {
 func (*ModuleMetadata) ProtoMessage() {}
 
 func (x *ModuleMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[4]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -416,7 +832,7 @@ func (x *ModuleMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleMetadata.ProtoReflect.Descriptor instead.
 func (*ModuleMetadata) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{4}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ModuleMetadata) GetHomepage() string {
@@ -484,7 +900,7 @@ type RepositoryMetadata struct {
 
 func (x *RepositoryMetadata) Reset() {
 	*x = RepositoryMetadata{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[5]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -496,7 +912,7 @@ func (x *RepositoryMetadata) String() string {
 func (*RepositoryMetadata) ProtoMessage() {}
 
 func (x *RepositoryMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[5]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -509,7 +925,7 @@ func (x *RepositoryMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RepositoryMetadata.ProtoReflect.Descriptor instead.
 func (*RepositoryMetadata) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{5}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *RepositoryMetadata) GetType() RepositoryType {
@@ -577,7 +993,7 @@ type RepositoryMetadataSet struct {
 
 func (x *RepositoryMetadataSet) Reset() {
 	*x = RepositoryMetadataSet{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[6]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -589,7 +1005,7 @@ func (x *RepositoryMetadataSet) String() string {
 func (*RepositoryMetadataSet) ProtoMessage() {}
 
 func (x *RepositoryMetadataSet) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[6]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -602,7 +1018,7 @@ func (x *RepositoryMetadataSet) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RepositoryMetadataSet.ProtoReflect.Descriptor instead.
 func (*RepositoryMetadataSet) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{6}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *RepositoryMetadataSet) GetRepositoryMetadata() []*RepositoryMetadata {
@@ -622,7 +1038,7 @@ type BazelRepositoryMetadata struct {
 
 func (x *BazelRepositoryMetadata) Reset() {
 	*x = BazelRepositoryMetadata{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[7]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -634,7 +1050,7 @@ func (x *BazelRepositoryMetadata) String() string {
 func (*BazelRepositoryMetadata) ProtoMessage() {}
 
 func (x *BazelRepositoryMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[7]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -647,7 +1063,7 @@ func (x *BazelRepositoryMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BazelRepositoryMetadata.ProtoReflect.Descriptor instead.
 func (*BazelRepositoryMetadata) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{7}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *BazelRepositoryMetadata) GetRepositoryMetadata() *RepositoryMetadata {
@@ -675,7 +1091,7 @@ type BazelRelease struct {
 
 func (x *BazelRelease) Reset() {
 	*x = BazelRelease{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[8]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -687,7 +1103,7 @@ func (x *BazelRelease) String() string {
 func (*BazelRelease) ProtoMessage() {}
 
 func (x *BazelRelease) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[8]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -700,7 +1116,7 @@ func (x *BazelRelease) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BazelRelease.ProtoReflect.Descriptor instead.
 func (*BazelRelease) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{8}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *BazelRelease) GetVersion() string {
@@ -733,7 +1149,7 @@ type BazelReleaseSet struct {
 
 func (x *BazelReleaseSet) Reset() {
 	*x = BazelReleaseSet{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[9]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -745,7 +1161,7 @@ func (x *BazelReleaseSet) String() string {
 func (*BazelReleaseSet) ProtoMessage() {}
 
 func (x *BazelReleaseSet) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[9]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -758,7 +1174,7 @@ func (x *BazelReleaseSet) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BazelReleaseSet.ProtoReflect.Descriptor instead.
 func (*BazelReleaseSet) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{9}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *BazelReleaseSet) GetRelease() []*BazelRelease {
@@ -769,17 +1185,21 @@ func (x *BazelReleaseSet) GetRelease() []*BazelRelease {
 }
 
 type ResourceStatus struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	Code          int32                  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Url               string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Code              int32                  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message           string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Integrity         string                 `protobuf:"bytes,4,opt,name=integrity,proto3" json:"integrity,omitempty"`
+	ActualIntegrity   string                 `protobuf:"bytes,5,opt,name=actual_integrity,json=actualIntegrity,proto3" json:"actual_integrity,omitempty"`
+	IntegrityMismatch bool                   `protobuf:"varint,6,opt,name=integrity_mismatch,json=integrityMismatch,proto3" json:"integrity_mismatch,omitempty"`
+	DownloadFailed    bool                   `protobuf:"varint,7,opt,name=download_failed,json=downloadFailed,proto3" json:"download_failed,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *ResourceStatus) Reset() {
 	*x = ResourceStatus{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[10]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -791,7 +1211,7 @@ func (x *ResourceStatus) String() string {
 func (*ResourceStatus) ProtoMessage() {}
 
 func (x *ResourceStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[10]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -804,7 +1224,7 @@ func (x *ResourceStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceStatus.ProtoReflect.Descriptor instead.
 func (*ResourceStatus) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{10}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ResourceStatus) GetUrl() string {
@@ -828,6 +1248,34 @@ func (x *ResourceStatus) GetMessage() string {
 	return ""
 }
 
+func (x *ResourceStatus) GetIntegrity() string {
+	if x != nil {
+		return x.Integrity
+	}
+	return ""
+}
+
+func (x *ResourceStatus) GetActualIntegrity() string {
+	if x != nil {
+		return x.ActualIntegrity
+	}
+	return ""
+}
+
+func (x *ResourceStatus) GetIntegrityMismatch() bool {
+	if x != nil {
+		return x.IntegrityMismatch
+	}
+	return false
+}
+
+func (x *ResourceStatus) GetDownloadFailed() bool {
+	if x != nil {
+		return x.DownloadFailed
+	}
+	return false
+}
+
 type ResourceStatusSet struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        []*ResourceStatus      `protobuf:"bytes,1,rep,name=status,proto3" json:"status,omitempty"`
@@ -837,7 +1285,7 @@ type ResourceStatusSet struct {
 
 func (x *ResourceStatusSet) Reset() {
 	*x = ResourceStatusSet{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[11]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -849,7 +1297,7 @@ func (x *ResourceStatusSet) String() string {
 func (*ResourceStatusSet) ProtoMessage() {}
 
 func (x *ResourceStatusSet) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[11]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -862,7 +1310,7 @@ func (x *ResourceStatusSet) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceStatusSet.ProtoReflect.Descriptor instead.
 func (*ResourceStatusSet) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{11}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *ResourceStatusSet) GetStatus() []*ResourceStatus {
@@ -873,31 +1321,34 @@ func (x *ResourceStatusSet) GetStatus() []*ResourceStatus {
 }
 
 type ModuleSource struct {
-	state         protoimpl.MessageState    `protogen:"open.v1"`
-	Url           string                    `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	Integrity     string                    `protobuf:"bytes,2,opt,name=integrity,proto3" json:"integrity,omitempty"`
-	StripPrefix   string                    `protobuf:"bytes,3,opt,name=strip_prefix,json=stripPrefix,proto3" json:"strip_prefix,omitempty"`
-	PatchStrip    int32                     `protobuf:"varint,4,opt,name=patch_strip,json=patchStrip,proto3" json:"patch_strip,omitempty"`
-	Patches       map[string]string         `protobuf:"bytes,5,rep,name=patches,proto3" json:"patches,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Overlay       map[string]string         `protobuf:"bytes,6,rep,name=overlay,proto3" json:"overlay,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	DocsUrl       string                    `protobuf:"bytes,7,opt,name=docs_url,json=docsUrl,proto3" json:"docs_url,omitempty"`
-	MirrorUrls    []string                  `protobuf:"bytes,8,rep,name=mirror_urls,json=mirrorUrls,proto3" json:"mirror_urls,omitempty"`
-	ArchiveType   string                    `protobuf:"bytes,9,opt,name=archive_type,json=archiveType,proto3" json:"archive_type,omitempty"`
-	Type          string                    `protobuf:"bytes,10,opt,name=type,proto3" json:"type,omitempty"`
-	Remote        string                    `protobuf:"bytes,11,opt,name=remote,proto3" json:"remote,omitempty"`
-	Commit        string                    `protobuf:"bytes,12,opt,name=commit,proto3" json:"commit,omitempty"`
-	Documentation *v1.ModuleVersionSymbols  `protobuf:"bytes,13,opt,name=documentation,proto3" json:"documentation,omitempty"`
-	DocsUrlStatus *ResourceStatus           `protobuf:"bytes,14,opt,name=docs_url_status,json=docsUrlStatus,proto3" json:"docs_url_status,omitempty"`
-	UrlStatus     *ResourceStatus           `protobuf:"bytes,15,opt,name=url_status,json=urlStatus,proto3" json:"url_status,omitempty"`
-	CommitSha     string                    `protobuf:"bytes,16,opt,name=commit_sha,json=commitSha,proto3" json:"commit_sha,omitempty"`
-	Packages      *v1.ModuleVersionPackages `protobuf:"bytes,17,opt,name=packages,proto3" json:"packages,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState    `protogen:"open.v1"`
+	Url             string                    `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Integrity       string                    `protobuf:"bytes,2,opt,name=integrity,proto3" json:"integrity,omitempty"`
+	StripPrefix     string                    `protobuf:"bytes,3,opt,name=strip_prefix,json=stripPrefix,proto3" json:"strip_prefix,omitempty"`
+	PatchStrip      int32                     `protobuf:"varint,4,opt,name=patch_strip,json=patchStrip,proto3" json:"patch_strip,omitempty"`
+	Patches         map[string]string         `protobuf:"bytes,5,rep,name=patches,proto3" json:"patches,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Overlay         map[string]string         `protobuf:"bytes,6,rep,name=overlay,proto3" json:"overlay,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	DocsUrl         string                    `protobuf:"bytes,7,opt,name=docs_url,json=docsUrl,proto3" json:"docs_url,omitempty"`
+	MirrorUrls      []string                  `protobuf:"bytes,8,rep,name=mirror_urls,json=mirrorUrls,proto3" json:"mirror_urls,omitempty"`
+	ArchiveType     string                    `protobuf:"bytes,9,opt,name=archive_type,json=archiveType,proto3" json:"archive_type,omitempty"`
+	Type            string                    `protobuf:"bytes,10,opt,name=type,proto3" json:"type,omitempty"`
+	Remote          string                    `protobuf:"bytes,11,opt,name=remote,proto3" json:"remote,omitempty"`
+	Commit          string                    `protobuf:"bytes,12,opt,name=commit,proto3" json:"commit,omitempty"`
+	Documentation   *v1.ModuleVersionSymbols  `protobuf:"bytes,13,opt,name=documentation,proto3" json:"documentation,omitempty"`
+	DocsUrlStatus   *ResourceStatus           `protobuf:"bytes,14,opt,name=docs_url_status,json=docsUrlStatus,proto3" json:"docs_url_status,omitempty"`
+	UrlStatus       *ResourceStatus           `protobuf:"bytes,15,opt,name=url_status,json=urlStatus,proto3" json:"url_status,omitempty"`
+	CommitSha       string                    `protobuf:"bytes,16,opt,name=commit_sha,json=commitSha,proto3" json:"commit_sha,omitempty"`
+	Packages        *v1.ModuleVersionPackages `protobuf:"bytes,17,opt,name=packages,proto3" json:"packages,omitempty"`
+	IntegrityStatus []*ResourceStatus         `protobuf:"bytes,18,rep,name=integrity_status,json=integrityStatus,proto3" json:"integrity_status,omitempty"`
+	PatchStats      []*PatchStats             `protobuf:"bytes,19,rep,name=patch_stats,json=patchStats,proto3" json:"patch_stats,omitempty"`
+	OverlayStats    *OverlayStats             `protobuf:"bytes,20,opt,name=overlay_stats,json=overlayStats,proto3" json:"overlay_stats,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ModuleSource) Reset() {
 	*x = ModuleSource{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[12]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -909,7 +1360,7 @@ func (x *ModuleSource) String() string {
 func (*ModuleSource) ProtoMessage() {}
 
 func (x *ModuleSource) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[12]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -922,7 +1373,7 @@ func (x *ModuleSource) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleSource.ProtoReflect.Descriptor instead.
 func (*ModuleSource) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{12}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *ModuleSource) GetUrl() string {
@@ -969,77 +1420,218 @@ func (x *ModuleSource) GetOverlay() map[string]string {
 
 func (x *ModuleSource) GetDocsUrl() string {
 	if x != nil {
-		return x.DocsUrl
+		return x.DocsUrl
+	}
+	return ""
+}
+
+func (x *ModuleSource) GetMirrorUrls() []string {
+	if x != nil {
+		return x.MirrorUrls
+	}
+	return nil
+}
+
+func (x *ModuleSource) GetArchiveType() string {
+	if x != nil {
+		return x.ArchiveType
+	}
+	return ""
+}
+
+func (x *ModuleSource) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ModuleSource) GetRemote() string {
+	if x != nil {
+		return x.Remote
+	}
+	return ""
+}
+
+func (x *ModuleSource) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *ModuleSource) GetDocumentation() *v1.ModuleVersionSymbols {
+	if x != nil {
+		return x.Documentation
+	}
+	return nil
+}
+
+func (x *ModuleSource) GetDocsUrlStatus() *ResourceStatus {
+	if x != nil {
+		return x.DocsUrlStatus
+	}
+	return nil
+}
+
+func (x *ModuleSource) GetUrlStatus() *ResourceStatus {
+	if x != nil {
+		return x.UrlStatus
+	}
+	return nil
+}
+
+func (x *ModuleSource) GetCommitSha() string {
+	if x != nil {
+		return x.CommitSha
+	}
+	return ""
+}
+
+func (x *ModuleSource) GetPackages() *v1.ModuleVersionPackages {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+func (x *ModuleSource) GetIntegrityStatus() []*ResourceStatus {
+	if x != nil {
+		return x.IntegrityStatus
+	}
+	return nil
+}
+
+func (x *ModuleSource) GetPatchStats() []*PatchStats {
+	if x != nil {
+		return x.PatchStats
+	}
+	return nil
+}
+
+func (x *ModuleSource) GetOverlayStats() *OverlayStats {
+	if x != nil {
+		return x.OverlayStats
+	}
+	return nil
+}
+
+type PatchStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Files         []string               `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	LinesAdded    int32                  `protobuf:"varint,3,opt,name=lines_added,json=linesAdded,proto3" json:"lines_added,omitempty"`
+	LinesRemoved  int32                  `protobuf:"varint,4,opt,name=lines_removed,json=linesRemoved,proto3" json:"lines_removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatchStats) Reset() {
+	*x = PatchStats{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchStats) ProtoMessage() {}
+
+func (x *PatchStats) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchStats.ProtoReflect.Descriptor instead.
+func (*PatchStats) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PatchStats) GetFilename() string {
+	if x != nil {
+		return x.Filename
 	}
 	return ""
 }
 
-func (x *ModuleSource) GetMirrorUrls() []string {
+func (x *PatchStats) GetFiles() []string {
 	if x != nil {
-		return x.MirrorUrls
+		return x.Files
 	}
 	return nil
 }
 
-func (x *ModuleSource) GetArchiveType() string {
+func (x *PatchStats) GetLinesAdded() int32 {
 	if x != nil {
-		return x.ArchiveType
+		return x.LinesAdded
 	}
-	return ""
+	return 0
 }
 
-func (x *ModuleSource) GetType() string {
+func (x *PatchStats) GetLinesRemoved() int32 {
 	if x != nil {
-		return x.Type
+		return x.LinesRemoved
 	}
-	return ""
+	return 0
 }
 
-func (x *ModuleSource) GetRemote() string {
-	if x != nil {
-		return x.Remote
-	}
-	return ""
+type OverlayStats struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ReplacesModuleBazel bool                   `protobuf:"varint,1,opt,name=replaces_module_bazel,json=replacesModuleBazel,proto3" json:"replaces_module_bazel,omitempty"`
+	BuildFiles          []string               `protobuf:"bytes,2,rep,name=build_files,json=buildFiles,proto3" json:"build_files,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
-func (x *ModuleSource) GetCommit() string {
-	if x != nil {
-		return x.Commit
-	}
-	return ""
+func (x *OverlayStats) Reset() {
+	*x = OverlayStats{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ModuleSource) GetDocumentation() *v1.ModuleVersionSymbols {
-	if x != nil {
-		return x.Documentation
-	}
-	return nil
+func (x *OverlayStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *ModuleSource) GetDocsUrlStatus() *ResourceStatus {
+func (*OverlayStats) ProtoMessage() {}
+
+func (x *OverlayStats) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[20]
 	if x != nil {
-		return x.DocsUrlStatus
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *ModuleSource) GetUrlStatus() *ResourceStatus {
-	if x != nil {
-		return x.UrlStatus
-	}
-	return nil
+// Deprecated: Use OverlayStats.ProtoReflect.Descriptor instead.
+func (*OverlayStats) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *ModuleSource) GetCommitSha() string {
+func (x *OverlayStats) GetReplacesModuleBazel() bool {
 	if x != nil {
-		return x.CommitSha
+		return x.ReplacesModuleBazel
 	}
-	return ""
+	return false
 }
 
-func (x *ModuleSource) GetPackages() *v1.ModuleVersionPackages {
+func (x *OverlayStats) GetBuildFiles() []string {
 	if x != nil {
-		return x.Packages
+		return x.BuildFiles
 	}
 	return nil
 }
@@ -1054,7 +1646,7 @@ type Attestations struct {
 
 func (x *Attestations) Reset() {
 	*x = Attestations{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[13]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1066,7 +1658,7 @@ func (x *Attestations) String() string {
 func (*Attestations) ProtoMessage() {}
 
 func (x *Attestations) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[13]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1079,7 +1671,7 @@ func (x *Attestations) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Attestations.ProtoReflect.Descriptor instead.
 func (*Attestations) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{13}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *Attestations) GetMediaType() string {
@@ -1118,7 +1710,7 @@ type ModuleVersion struct {
 
 func (x *ModuleVersion) Reset() {
 	*x = ModuleVersion{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[14]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1130,7 +1722,7 @@ func (x *ModuleVersion) String() string {
 func (*ModuleVersion) ProtoMessage() {}
 
 func (x *ModuleVersion) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[14]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1143,7 +1735,7 @@ func (x *ModuleVersion) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleVersion.ProtoReflect.Descriptor instead.
 func (*ModuleVersion) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{14}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *ModuleVersion) GetName() string {
@@ -1258,7 +1850,7 @@ type ModuleCommit struct {
 
 func (x *ModuleCommit) Reset() {
 	*x = ModuleCommit{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[15]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1270,7 +1862,7 @@ func (x *ModuleCommit) String() string {
 func (*ModuleCommit) ProtoMessage() {}
 
 func (x *ModuleCommit) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[15]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1283,7 +1875,7 @@ func (x *ModuleCommit) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleCommit.ProtoReflect.Descriptor instead.
 func (*ModuleCommit) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{15}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *ModuleCommit) GetSha1() string {
@@ -1340,7 +1932,7 @@ type PRAuthor struct {
 
 func (x *PRAuthor) Reset() {
 	*x = PRAuthor{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[16]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1352,7 +1944,7 @@ func (x *PRAuthor) String() string {
 func (*PRAuthor) ProtoMessage() {}
 
 func (x *PRAuthor) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[16]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1365,7 +1957,7 @@ func (x *PRAuthor) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PRAuthor.ProtoReflect.Descriptor instead.
 func (*PRAuthor) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{16}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *PRAuthor) GetPullRequest() int32 {
@@ -1405,7 +1997,7 @@ type PRAuthorSet struct {
 
 func (x *PRAuthorSet) Reset() {
 	*x = PRAuthorSet{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[17]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1417,7 +2009,7 @@ func (x *PRAuthorSet) String() string {
 func (*PRAuthorSet) ProtoMessage() {}
 
 func (x *PRAuthorSet) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[17]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1430,7 +2022,7 @@ func (x *PRAuthorSet) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PRAuthorSet.ProtoReflect.Descriptor instead.
 func (*PRAuthorSet) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{17}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *PRAuthorSet) GetAuthors() []*PRAuthor {
@@ -1458,7 +2050,7 @@ type ModuleDependencyOverride struct {
 
 func (x *ModuleDependencyOverride) Reset() {
 	*x = ModuleDependencyOverride{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[18]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1470,7 +2062,7 @@ func (x *ModuleDependencyOverride) String() string {
 func (*ModuleDependencyOverride) ProtoMessage() {}
 
 func (x *ModuleDependencyOverride) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[18]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1483,7 +2075,7 @@ func (x *ModuleDependencyOverride) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleDependencyOverride.ProtoReflect.Descriptor instead.
 func (*ModuleDependencyOverride) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{18}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ModuleDependencyOverride) GetModuleName() string {
@@ -1595,13 +2187,14 @@ type ModuleDependency struct {
 	MaxCompatibilityLevel int32                     `protobuf:"varint,5,opt,name=max_compatibility_level,json=maxCompatibilityLevel,proto3" json:"max_compatibility_level,omitempty"`
 	Override              *ModuleDependencyOverride `protobuf:"bytes,6,opt,name=override,proto3" json:"override,omitempty"`
 	Unresolved            bool                      `protobuf:"varint,7,opt,name=unresolved,proto3" json:"unresolved,omitempty"`
+	SelectedVersion       string                    `protobuf:"bytes,8,opt,name=selected_version,json=selectedVersion,proto3" json:"selected_version,omitempty"`
 	unknownFields         protoimpl.UnknownFields
 	sizeCache             protoimpl.SizeCache
 }
 
 func (x *ModuleDependency) Reset() {
 	*x = ModuleDependency{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[19]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1613,7 +2206,7 @@ func (x *ModuleDependency) String() string {
 func (*ModuleDependency) ProtoMessage() {}
 
 func (x *ModuleDependency) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[19]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1626,7 +2219,7 @@ func (x *ModuleDependency) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleDependency.ProtoReflect.Descriptor instead.
 func (*ModuleDependency) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{19}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ModuleDependency) GetName() string {
@@ -1678,6 +2271,13 @@ func (x *ModuleDependency) GetUnresolved() bool {
 	return false
 }
 
+func (x *ModuleDependency) GetSelectedVersion() string {
+	if x != nil {
+		return x.SelectedVersion
+	}
+	return ""
+}
+
 type GitOverride struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Commit        string                 `protobuf:"bytes,1,opt,name=commit,proto3" json:"commit,omitempty"`
@@ -1692,7 +2292,7 @@ type GitOverride struct {
 
 func (x *GitOverride) Reset() {
 	*x = GitOverride{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[20]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1704,7 +2304,7 @@ func (x *GitOverride) String() string {
 func (*GitOverride) ProtoMessage() {}
 
 func (x *GitOverride) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[20]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1717,7 +2317,7 @@ func (x *GitOverride) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GitOverride.ProtoReflect.Descriptor instead.
 func (*GitOverride) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{20}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *GitOverride) GetCommit() string {
@@ -1755,6 +2355,13 @@ func (x *GitOverride) GetBranch() string {
 	return ""
 }
 
+func (x *GitOverride) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
 type ArchiveOverride struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Integrity     string                 `protobuf:"bytes,1,opt,name=integrity,proto3" json:"integrity,omitempty"`
@@ -1770,7 +2377,7 @@ type ArchiveOverride struct {
 
 func (x *ArchiveOverride) Reset() {
 	*x = ArchiveOverride{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[21]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1782,7 +2389,7 @@ func (x *ArchiveOverride) String() string {
 func (*ArchiveOverride) ProtoMessage() {}
 
 func (x *ArchiveOverride) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[21]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1795,7 +2402,7 @@ func (x *ArchiveOverride) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ArchiveOverride.ProtoReflect.Descriptor instead.
 func (*ArchiveOverride) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{21}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *ArchiveOverride) GetIntegrity() string {
@@ -1859,7 +2466,7 @@ type SingleVersionOverride struct {
 
 func (x *SingleVersionOverride) Reset() {
 	*x = SingleVersionOverride{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[22]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1871,7 +2478,7 @@ func (x *SingleVersionOverride) String() string {
 func (*SingleVersionOverride) ProtoMessage() {}
 
 func (x *SingleVersionOverride) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[22]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1884,7 +2491,7 @@ func (x *SingleVersionOverride) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SingleVersionOverride.ProtoReflect.Descriptor instead.
 func (*SingleVersionOverride) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{22}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *SingleVersionOverride) GetPatchStrip() int32 {
@@ -1924,7 +2531,7 @@ type LocalPathOverride struct {
 
 func (x *LocalPathOverride) Reset() {
 	*x = LocalPathOverride{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[23]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1936,7 +2543,7 @@ func (x *LocalPathOverride) String() string {
 func (*LocalPathOverride) ProtoMessage() {}
 
 func (x *LocalPathOverride) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[23]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1949,7 +2556,7 @@ func (x *LocalPathOverride) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LocalPathOverride.ProtoReflect.Descriptor instead.
 func (*LocalPathOverride) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{23}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *LocalPathOverride) GetPath() string {
@@ -1970,7 +2577,7 @@ type Presubmit struct {
 
 func (x *Presubmit) Reset() {
 	*x = Presubmit{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[24]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1982,7 +2589,7 @@ func (x *Presubmit) String() string {
 func (*Presubmit) ProtoMessage() {}
 
 func (x *Presubmit) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[24]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1995,7 +2602,7 @@ func (x *Presubmit) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Presubmit.ProtoReflect.Descriptor instead.
 func (*Presubmit) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{24}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *Presubmit) GetBcrTestModule() *Presubmit_BcrTestModule {
@@ -2034,7 +2641,7 @@ type DependencyTreeNode struct {
 
 func (x *DependencyTreeNode) Reset() {
 	*x = DependencyTreeNode{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[25]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2046,7 +2653,7 @@ func (x *DependencyTreeNode) String() string {
 func (*DependencyTreeNode) ProtoMessage() {}
 
 func (x *DependencyTreeNode) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[25]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2059,7 +2666,7 @@ func (x *DependencyTreeNode) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DependencyTreeNode.ProtoReflect.Descriptor instead.
 func (*DependencyTreeNode) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{25}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *DependencyTreeNode) GetModuleVersion() *ModuleVersion {
@@ -2121,7 +2728,7 @@ type DependencyTree struct {
 
 func (x *DependencyTree) Reset() {
 	*x = DependencyTree{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[26]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2133,7 +2740,7 @@ func (x *DependencyTree) String() string {
 func (*DependencyTree) ProtoMessage() {}
 
 func (x *DependencyTree) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[26]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2146,7 +2753,7 @@ func (x *DependencyTree) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DependencyTree.ProtoReflect.Descriptor instead.
 func (*DependencyTree) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{26}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *DependencyTree) GetModuleVersion() *ModuleVersion {
@@ -2173,7 +2780,7 @@ type MultipleVersionOverride struct {
 
 func (x *MultipleVersionOverride) Reset() {
 	*x = MultipleVersionOverride{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[27]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2185,7 +2792,7 @@ func (x *MultipleVersionOverride) String() string {
 func (*MultipleVersionOverride) ProtoMessage() {}
 
 func (x *MultipleVersionOverride) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[27]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2198,7 +2805,7 @@ func (x *MultipleVersionOverride) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultipleVersionOverride.ProtoReflect.Descriptor instead.
 func (*MultipleVersionOverride) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{27}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *MultipleVersionOverride) GetVersions() []string {
@@ -2215,6 +2822,150 @@ func (x *MultipleVersionOverride) GetRegistry() string {
 	return ""
 }
 
+type ApiIndex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CommitSha     string                 `protobuf:"bytes,1,opt,name=commit_sha,json=commitSha,proto3" json:"commit_sha,omitempty"`
+	CommitDate    string                 `protobuf:"bytes,2,opt,name=commit_date,json=commitDate,proto3" json:"commit_date,omitempty"`
+	RegistryUrl   string                 `protobuf:"bytes,3,opt,name=registry_url,json=registryUrl,proto3" json:"registry_url,omitempty"`
+	Module        []*ApiModuleEntry      `protobuf:"bytes,4,rep,name=module,proto3" json:"module,omitempty"`
+	Flag          []string               `protobuf:"bytes,5,rep,name=flag,proto3" json:"flag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApiIndex) Reset() {
+	*x = ApiIndex{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApiIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiIndex) ProtoMessage() {}
+
+func (x *ApiIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiIndex.ProtoReflect.Descriptor instead.
+func (*ApiIndex) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ApiIndex) GetCommitSha() string {
+	if x != nil {
+		return x.CommitSha
+	}
+	return ""
+}
+
+func (x *ApiIndex) GetCommitDate() string {
+	if x != nil {
+		return x.CommitDate
+	}
+	return ""
+}
+
+func (x *ApiIndex) GetRegistryUrl() string {
+	if x != nil {
+		return x.RegistryUrl
+	}
+	return ""
+}
+
+func (x *ApiIndex) GetModule() []*ApiModuleEntry {
+	if x != nil {
+		return x.Module
+	}
+	return nil
+}
+
+func (x *ApiIndex) GetFlag() []string {
+	if x != nil {
+		return x.Flag
+	}
+	return nil
+}
+
+type ApiModuleEntry struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	LatestVersion  string                 `protobuf:"bytes,2,opt,name=latest_version,json=latestVersion,proto3" json:"latest_version,omitempty"`
+	Version        []string               `protobuf:"bytes,3,rep,name=version,proto3" json:"version,omitempty"`
+	SymbolsVersion []string               `protobuf:"bytes,4,rep,name=symbols_version,json=symbolsVersion,proto3" json:"symbols_version,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ApiModuleEntry) Reset() {
+	*x = ApiModuleEntry{}
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApiModuleEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiModuleEntry) ProtoMessage() {}
+
+func (x *ApiModuleEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiModuleEntry.ProtoReflect.Descriptor instead.
+func (*ApiModuleEntry) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ApiModuleEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ApiModuleEntry) GetLatestVersion() string {
+	if x != nil {
+		return x.LatestVersion
+	}
+	return ""
+}
+
+func (x *ApiModuleEntry) GetVersion() []string {
+	if x != nil {
+		return x.Version
+	}
+	return nil
+}
+
+func (x *ApiModuleEntry) GetSymbolsVersion() []string {
+	if x != nil {
+		return x.SymbolsVersion
+	}
+	return nil
+}
+
 type Attestations_Attestation struct {
 	state         protoimpl.MessageState           `protogen:"open.v1"`
 	Url           string                           `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
@@ -2226,7 +2977,7 @@ type Attestations_Attestation struct {
 
 func (x *Attestations_Attestation) Reset() {
 	*x = Attestations_Attestation{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[33]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2238,7 +2989,7 @@ func (x *Attestations_Attestation) String() string {
 func (*Attestations_Attestation) ProtoMessage() {}
 
 func (x *Attestations_Attestation) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[33]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2251,7 +3002,7 @@ func (x *Attestations_Attestation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Attestations_Attestation.ProtoReflect.Descriptor instead.
 func (*Attestations_Attestation) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{13, 0}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{21, 0}
 }
 
 func (x *Attestations_Attestation) GetUrl() string {
@@ -2300,7 +3051,7 @@ type Attestations_AttestationPayload struct {
 
 func (x *Attestations_AttestationPayload) Reset() {
 	*x = Attestations_AttestationPayload{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[34]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2312,7 +3063,7 @@ func (x *Attestations_AttestationPayload) String() string {
 func (*Attestations_AttestationPayload) ProtoMessage() {}
 
 func (x *Attestations_AttestationPayload) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[34]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2325,7 +3076,7 @@ func (x *Attestations_AttestationPayload) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Attestations_AttestationPayload.ProtoReflect.Descriptor instead.
 func (*Attestations_AttestationPayload) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{13, 1}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{21, 1}
 }
 
 func (x *Attestations_AttestationPayload) GetSubjectName() string {
@@ -2458,7 +3209,7 @@ type Presubmit_BcrTestModule struct {
 
 func (x *Presubmit_BcrTestModule) Reset() {
 	*x = Presubmit_BcrTestModule{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[36]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2470,7 +3221,7 @@ func (x *Presubmit_BcrTestModule) String() string {
 func (*Presubmit_BcrTestModule) ProtoMessage() {}
 
 func (x *Presubmit_BcrTestModule) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[36]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2483,7 +3234,7 @@ func (x *Presubmit_BcrTestModule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Presubmit_BcrTestModule.ProtoReflect.Descriptor instead.
 func (*Presubmit_BcrTestModule) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{24, 0}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{32, 0}
 }
 
 func (x *Presubmit_BcrTestModule) GetModulePath() string {
@@ -2517,7 +3268,7 @@ type Presubmit_PresubmitMatrix struct {
 
 func (x *Presubmit_PresubmitMatrix) Reset() {
 	*x = Presubmit_PresubmitMatrix{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[37]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2529,7 +3280,7 @@ func (x *Presubmit_PresubmitMatrix) String() string {
 func (*Presubmit_PresubmitMatrix) ProtoMessage() {}
 
 func (x *Presubmit_PresubmitMatrix) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[37]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2542,7 +3293,7 @@ func (x *Presubmit_PresubmitMatrix) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Presubmit_PresubmitMatrix.ProtoReflect.Descriptor instead.
 func (*Presubmit_PresubmitMatrix) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{24, 1}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{32, 1}
 }
 
 func (x *Presubmit_PresubmitMatrix) GetPlatform() []string {
@@ -2574,7 +3325,7 @@ type Presubmit_PresubmitTask struct {
 
 func (x *Presubmit_PresubmitTask) Reset() {
 	*x = Presubmit_PresubmitTask{}
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[38]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2586,7 +3337,7 @@ func (x *Presubmit_PresubmitTask) String() string {
 func (*Presubmit_PresubmitTask) ProtoMessage() {}
 
 func (x *Presubmit_PresubmitTask) ProtoReflect() protoreflect.Message {
-	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[38]
+	mi := &file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2599,7 +3350,7 @@ func (x *Presubmit_PresubmitTask) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Presubmit_PresubmitTask.ProtoReflect.Descriptor instead.
 func (*Presubmit_PresubmitTask) Descriptor() ([]byte, []int) {
-	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{24, 2}
+	return file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP(), []int{32, 2}
 }
 
 func (x *Presubmit_PresubmitTask) GetName() string {
@@ -2665,17 +3416,31 @@ const file_build_stack_bazel_registry_v1_bcr_proto_rawDesc = "" +
 	"commit_sha\x18\x05 \x01(\tR\tcommitSha\x12%\n" +
 	"\x0ecommit_message\x18\x06 \x01(\tR\rcommitMessage\x12\x1f\n" +
 	"\vcommit_date\x18\a \x01(\tR\n" +
-	"commitDate\"\x8f\x02\n" +
+	"commitDate\"\xd7\x02\n" +
 	"\x10RegistryManifest\x12\x1d\n" +
 	"\n" +
 	"commit_sha\x18\x01 \x01(\tR\tcommitSha\x12\x1f\n" +
 	"\vcommit_date\x18\x02 \x01(\tR\n" +
 	"commitDate\x12\x16\n" +
 	"\x06branch\x18\x03 \x01(\tR\x06branch\x12c\n" +
-	"\fasset_hashes\x18\x04 \x03(\v2@.build.stack.bazel.registry.v1.RegistryManifest.AssetHashesEntryR\vassetHashes\x1a>\n" +
+	"\fasset_hashes\x18\x04 \x03(\v2@.build.stack.bazel.registry.v1.RegistryManifest.AssetHashesEntryR\vassetHashes\x12F\n" +
+	"\x06deltas\x18\x05 \x03(\v2..build.stack.bazel.registry.v1.ReleaseDeltaRefR\x06deltas\x1a>\n" +
 	"\x10AssetHashesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x95\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc9\x01\n" +
+	"\x0fReleaseDeltaRef\x12&\n" +
+	"\x0ffrom_commit_sha\x18\x01 \x01(\tR\rfromCommitSha\x12,\n" +
+	"\x12from_symbols_asset\x18\x02 \x01(\tR\x10fromSymbolsAsset\x120\n" +
+	"\x14registry_delta_asset\x18\x03 \x01(\tR\x12registryDeltaAsset\x12.\n" +
+	"\x13symbols_delta_asset\x18\x04 \x01(\tR\x11symbolsDeltaAsset\"\xb0\x01\n" +
+	"\rRegistryDelta\x12?\n" +
+	"\x06header\x18\x01 \x01(\v2'.build.stack.bazel.registry.v1.RegistryR\x06header\x12=\n" +
+	"\x06upsert\x18\x02 \x03(\v2%.build.stack.bazel.registry.v1.ModuleR\x06upsert\x12\x1f\n" +
+	"\vmodule_name\x18\x03 \x03(\tR\n" +
+	"moduleName\"\x80\x01\n" +
+	"\fSymbolsDelta\x12I\n" +
+	"\x06upsert\x18\x01 \x03(\v21.build.stack.bazel.symbol.v1.ModuleVersionSymbolsR\x06upsert\x12%\n" +
+	"\x0emodule_version\x18\x02 \x03(\tR\rmoduleVersion\"\x95\x02\n" +
 	"\x06Module\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12I\n" +
 	"\bmetadata\x18\x02 \x01(\v2-.build.stack.bazel.registry.v1.ModuleMetadataR\bmetadata\x12H\n" +
@@ -2687,7 +3452,27 @@ const file_build_stack_bazel_registry_v1_bcr_proto_rawDesc = "" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
 	"\x06github\x18\x03 \x01(\tR\x06github\x12\"\n" +
 	"\rdo_not_notify\x18\x04 \x01(\bR\vdoNotNotify\x12$\n" +
-	"\x0egithub_user_id\x18\x05 \x01(\x05R\fgithubUserId\"\x98\x03\n" +
+	"\x0egithub_user_id\x18\x05 \x01(\x05R\fgithubUserId\"\xd6\x01\n" +
+	"\x0fMaintainerIndex\x12T\n" +
+	"\vmaintainers\x18\x01 \x03(\v22.build.stack.bazel.registry.v1.MaintainerPortfolioR\vmaintainers\x121\n" +
+	"\x14unmaintained_modules\x18\x02 \x03(\tR\x13unmaintainedModules\x12:\n" +
+	"\x1ado_not_notify_only_modules\x18\x03 \x03(\tR\x16doNotNotifyOnlyModules\"\xbd\x02\n" +
+	"\x13MaintainerPortfolio\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12I\n" +
+	"\n" +
+	"maintainer\x18\x02 \x01(\v2).build.stack.bazel.registry.v1.MaintainerR\n" +
+	"maintainer\x12I\n" +
+	"\amodules\x18\x03 \x03(\v2/.build.stack.bazel.registry.v1.MaintainerModuleR\amodules\x12#\n" +
+	"\rrelease_count\x18\x04 \x01(\x05R\freleaseCount\x124\n" +
+	"\x16authored_release_count\x18\x05 \x01(\x05R\x14authoredReleaseCount\x12#\n" +
+	"\rlast_activity\x18\x06 \x01(\tR\flastActivity\"\xf1\x01\n" +
+	"\x10MaintainerModule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12#\n" +
+	"\rrelease_count\x18\x02 \x01(\x05R\freleaseCount\x124\n" +
+	"\x16authored_release_count\x18\x03 \x01(\x05R\x14authoredReleaseCount\x12%\n" +
+	"\x0elatest_version\x18\x04 \x01(\tR\rlatestVersion\x12#\n" +
+	"\rlast_activity\x18\x05 \x01(\tR\flastActivity\x12\"\n" +
+	"\rdo_not_notify\x18\x06 \x01(\bR\vdoNotNotify\"\x98\x03\n" +
 	"\x0eModuleMetadata\x12\x1a\n" +
 	"\bhomepage\x18\x01 \x01(\tR\bhomepage\x12K\n" +
 	"\vmaintainers\x18\x02 \x03(\v2).build.stack.bazel.registry.v1.MaintainerR\vmaintainers\x12\x1e\n" +
@@ -2727,13 +3512,17 @@ const file_build_stack_bazel_registry_v1_bcr_proto_rawDesc = "" +
 	"\x03url\x18\x02 \x01(\tR\x03url\x12C\n" +
 	"\x06commit\x18\x03 \x01(\v2+.build.stack.bazel.registry.v1.ModuleCommitR\x06commit\"X\n" +
 	"\x0fBazelReleaseSet\x12E\n" +
-	"\arelease\x18\x01 \x03(\v2+.build.stack.bazel.registry.v1.BazelReleaseR\arelease\"P\n" +
+	"\arelease\x18\x01 \x03(\v2+.build.stack.bazel.registry.v1.BazelReleaseR\arelease\"\xf1\x01\n" +
 	"\x0eResourceStatus\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12\x12\n" +
 	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"Z\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1c\n" +
+	"\tintegrity\x18\x04 \x01(\tR\tintegrity\x12)\n" +
+	"\x10actual_integrity\x18\x05 \x01(\tR\x0factualIntegrity\x12-\n" +
+	"\x12integrity_mismatch\x18\x06 \x01(\bR\x11integrityMismatch\x12'\n" +
+	"\x0fdownload_failed\x18\a \x01(\bR\x0edownloadFailed\"Z\n" +
 	"\x11ResourceStatusSet\x12E\n" +
-	"\x06status\x18\x01 \x03(\v2-.build.stack.bazel.registry.v1.ResourceStatusR\x06status\"\xb2\a\n" +
+	"\x06status\x18\x01 \x03(\v2-.build.stack.bazel.registry.v1.ResourceStatusR\x06status\"\xaa\t\n" +
 	"\fModuleSource\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1c\n" +
 	"\tintegrity\x18\x02 \x01(\tR\tintegrity\x12!\n" +
@@ -2756,13 +3545,28 @@ const file_build_stack_bazel_registry_v1_bcr_proto_rawDesc = "" +
 	"url_status\x18\x0f \x01(\v2-.build.stack.bazel.registry.v1.ResourceStatusR\turlStatus\x12\x1d\n" +
 	"\n" +
 	"commit_sha\x18\x10 \x01(\tR\tcommitSha\x12N\n" +
-	"\bpackages\x18\x11 \x01(\v22.build.stack.bazel.symbol.v1.ModuleVersionPackagesR\bpackages\x1a:\n" +
+	"\bpackages\x18\x11 \x01(\v22.build.stack.bazel.symbol.v1.ModuleVersionPackagesR\bpackages\x12X\n" +
+	"\x10integrity_status\x18\x12 \x03(\v2-.build.stack.bazel.registry.v1.ResourceStatusR\x0fintegrityStatus\x12J\n" +
+	"\vpatch_stats\x18\x13 \x03(\v2).build.stack.bazel.registry.v1.PatchStatsR\n" +
+	"patchStats\x12P\n" +
+	"\roverlay_stats\x18\x14 \x01(\v2+.build.stack.bazel.registry.v1.OverlayStatsR\foverlayStats\x1a:\n" +
 	"\fPatchesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
 	"\fOverlayEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc1\b\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x84\x01\n" +
+	"\n" +
+	"PatchStats\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x14\n" +
+	"\x05files\x18\x02 \x03(\tR\x05files\x12\x1f\n" +
+	"\vlines_added\x18\x03 \x01(\x05R\n" +
+	"linesAdded\x12#\n" +
+	"\rlines_removed\x18\x04 \x01(\x05R\flinesRemoved\"c\n" +
+	"\fOverlayStats\x122\n" +
+	"\x15replaces_module_bazel\x18\x01 \x01(\bR\x13replacesModuleBazel\x12\x1f\n" +
+	"\vbuild_files\x18\x02 \x03(\tR\n" +
+	"buildFiles\"\xc1\b\n" +
 	"\fAttestations\x12\x1d\n" +
 	"\n" +
 	"media_type\x18\x01 \x01(\tR\tmediaType\x12a\n" +
@@ -2841,7 +3645,7 @@ const file_build_stack_bazel_registry_v1_bcr_proto_rawDesc = "" +
 	"\x13local_path_override\x18\x04 \x01(\v20.build.stack.bazel.registry.v1.LocalPathOverrideH\x00R\x11localPathOverride\x12t\n" +
 	"\x19multiple_version_override\x18\a \x01(\v26.build.stack.bazel.registry.v1.MultipleVersionOverrideH\x00R\x17multipleVersionOverrideB\n" +
 	"\n" +
-	"\boverride\"\x9c\x02\n" +
+	"\boverride\"\xc7\x02\n" +
 	"\x10ModuleDependency\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1b\n" +
@@ -2851,14 +3655,16 @@ const file_build_stack_bazel_registry_v1_bcr_proto_rawDesc = "" +
 	"\boverride\x18\x06 \x01(\v27.build.stack.bazel.registry.v1.ModuleDependencyOverrideR\boverride\x12\x1e\n" +
 	"\n" +
 	"unresolved\x18\a \x01(\bR\n" +
-	"unresolved\"\x90\x01\n" +
+	"unresolved\x12)\n" +
+	"\x10selected_version\x18\b \x01(\tR\x0fselectedVersion\"\xa2\x01\n" +
 	"\vGitOverride\x12\x16\n" +
 	"\x06commit\x18\x01 \x01(\tR\x06commit\x12\x1f\n" +
 	"\vpatch_strip\x18\x02 \x01(\x05R\n" +
 	"patchStrip\x12\x18\n" +
 	"\apatches\x18\x03 \x03(\tR\apatches\x12\x16\n" +
 	"\x06remote\x18\x04 \x01(\tR\x06remote\x12\x16\n" +
-	"\x06branch\x18\x05 \x01(\tR\x06branch\"\xd8\x01\n" +
+	"\x06branch\x18\x05 \x01(\tR\x06branch\x12\x10\n" +
+	"\x03tag\x18\x06 \x01(\tR\x03tag\"\xd8\x01\n" +
 	"\x0fArchiveOverride\x12\x1c\n" +
 	"\tintegrity\x18\x01 \x01(\tR\tintegrity\x12\x1f\n" +
 	"\vpatch_strip\x18\x02 \x01(\x05R\n" +
@@ -2920,7 +3726,20 @@ const file_build_stack_bazel_registry_v1_bcr_proto_rawDesc = "" +
 	"\bchildren\x18\x02 \x03(\v21.build.stack.bazel.registry.v1.DependencyTreeNodeR\bchildren\"Q\n" +
 	"\x17MultipleVersionOverride\x12\x1a\n" +
 	"\bversions\x18\x01 \x03(\tR\bversions\x12\x1a\n" +
-	"\bregistry\x18\x02 \x01(\tR\bregistry*E\n" +
+	"\bregistry\x18\x02 \x01(\tR\bregistry\"\xc8\x01\n" +
+	"\bApiIndex\x12\x1d\n" +
+	"\n" +
+	"commit_sha\x18\x01 \x01(\tR\tcommitSha\x12\x1f\n" +
+	"\vcommit_date\x18\x02 \x01(\tR\n" +
+	"commitDate\x12!\n" +
+	"\fregistry_url\x18\x03 \x01(\tR\vregistryUrl\x12E\n" +
+	"\x06module\x18\x04 \x03(\v2-.build.stack.bazel.registry.v1.ApiModuleEntryR\x06module\x12\x12\n" +
+	"\x04flag\x18\x05 \x03(\tR\x04flag\"\x8e\x01\n" +
+	"\x0eApiModuleEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
+	"\x0elatest_version\x18\x02 \x01(\tR\rlatestVersion\x12\x18\n" +
+	"\aversion\x18\x03 \x03(\tR\aversion\x12'\n" +
+	"\x0fsymbols_version\x18\x04 \x03(\tR\x0esymbolsVersion*E\n" +
 	"\x0eRepositoryType\x12\x1b\n" +
 	"\x17REPOSITORY_TYPE_UNKNOWN\x10\x00\x12\n" +
 	"\n" +
@@ -2941,108 +3760,129 @@ func file_build_stack_bazel_registry_v1_bcr_proto_rawDescGZIP() []byte {
 }
 
 var file_build_stack_bazel_registry_v1_bcr_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_build_stack_bazel_registry_v1_bcr_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
+var file_build_stack_bazel_registry_v1_bcr_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
 var file_build_stack_bazel_registry_v1_bcr_proto_goTypes = []any{
 	(RepositoryType)(0),                     // 0: build.stack.bazel.registry.v1.RepositoryType
 	(*Registry)(nil),                        // 1: build.stack.bazel.registry.v1.Registry
 	(*RegistryManifest)(nil),                // 2: build.stack.bazel.registry.v1.RegistryManifest
-	(*Module)(nil),                          // 3: build.stack.bazel.registry.v1.Module
-	(*Maintainer)(nil),                      // 4: build.stack.bazel.registry.v1.Maintainer
-	(*ModuleMetadata)(nil),                  // 5: build.stack.bazel.registry.v1.ModuleMetadata
-	(*RepositoryMetadata)(nil),              // 6: build.stack.bazel.registry.v1.RepositoryMetadata
-	(*RepositoryMetadataSet)(nil),           // 7: build.stack.bazel.registry.v1.RepositoryMetadataSet
-	(*BazelRepositoryMetadata)(nil),         // 8: build.stack.bazel.registry.v1.BazelRepositoryMetadata
-	(*BazelRelease)(nil),                    // 9: build.stack.bazel.registry.v1.BazelRelease
-	(*BazelReleaseSet)(nil),                 // 10: build.stack.bazel.registry.v1.BazelReleaseSet
-	(*ResourceStatus)(nil),                  // 11: build.stack.bazel.registry.v1.ResourceStatus
-	(*ResourceStatusSet)(nil),               // 12: build.stack.bazel.registry.v1.ResourceStatusSet
-	(*ModuleSource)(nil),                    // 13: build.stack.bazel.registry.v1.ModuleSource
-	(*Attestations)(nil),                    // 14: build.stack.bazel.registry.v1.Attestations
-	(*ModuleVersion)(nil),                   // 15: build.stack.bazel.registry.v1.ModuleVersion
-	(*ModuleCommit)(nil),                    // 16: build.stack.bazel.registry.v1.ModuleCommit
-	(*PRAuthor)(nil),                        // 17: build.stack.bazel.registry.v1.PRAuthor
-	(*PRAuthorSet)(nil),                     // 18: build.stack.bazel.registry.v1.PRAuthorSet
-	(*ModuleDependencyOverride)(nil),        // 19: build.stack.bazel.registry.v1.ModuleDependencyOverride
-	(*ModuleDependency)(nil),                // 20: build.stack.bazel.registry.v1.ModuleDependency
-	(*GitOverride)(nil),                     // 21: build.stack.bazel.registry.v1.GitOverride
-	(*ArchiveOverride)(nil),                 // 22: build.stack.bazel.registry.v1.ArchiveOverride
-	(*SingleVersionOverride)(nil),           // 23: build.stack.bazel.registry.v1.SingleVersionOverride
-	(*LocalPathOverride)(nil),               // 24: build.stack.bazel.registry.v1.LocalPathOverride
-	(*Presubmit)(nil),                       // 25: build.stack.bazel.registry.v1.Presubmit
-	(*DependencyTreeNode)(nil),              // 26: build.stack.bazel.registry.v1.DependencyTreeNode
-	(*DependencyTree)(nil),                  // 27: build.stack.bazel.registry.v1.DependencyTree
-	(*MultipleVersionOverride)(nil),         // 28: build.stack.bazel.registry.v1.MultipleVersionOverride
-	nil,                                     // 29: build.stack.bazel.registry.v1.RegistryManifest.AssetHashesEntry
-	nil,                                     // 30: build.stack.bazel.registry.v1.ModuleMetadata.YankedVersionsEntry
-	nil,                                     // 31: build.stack.bazel.registry.v1.RepositoryMetadata.LanguagesEntry
-	nil,                                     // 32: build.stack.bazel.registry.v1.ModuleSource.PatchesEntry
-	nil,                                     // 33: build.stack.bazel.registry.v1.ModuleSource.OverlayEntry
-	(*Attestations_Attestation)(nil),        // 34: build.stack.bazel.registry.v1.Attestations.Attestation
-	(*Attestations_AttestationPayload)(nil), // 35: build.stack.bazel.registry.v1.Attestations.AttestationPayload
-	nil,                                     // 36: build.stack.bazel.registry.v1.Attestations.AttestationsEntry
-	(*Presubmit_BcrTestModule)(nil),         // 37: build.stack.bazel.registry.v1.Presubmit.BcrTestModule
-	(*Presubmit_PresubmitMatrix)(nil),       // 38: build.stack.bazel.registry.v1.Presubmit.PresubmitMatrix
-	(*Presubmit_PresubmitTask)(nil),         // 39: build.stack.bazel.registry.v1.Presubmit.PresubmitTask
-	nil,                                     // 40: build.stack.bazel.registry.v1.Presubmit.TasksEntry
-	nil,                                     // 41: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.TasksEntry
-	(*v1.ModuleVersionSymbols)(nil),         // 42: build.stack.bazel.symbol.v1.ModuleVersionSymbols
-	(*v1.ModuleVersionPackages)(nil),        // 43: build.stack.bazel.symbol.v1.ModuleVersionPackages
+	(*ReleaseDeltaRef)(nil),                 // 3: build.stack.bazel.registry.v1.ReleaseDeltaRef
+	(*RegistryDelta)(nil),                   // 4: build.stack.bazel.registry.v1.RegistryDelta
+	(*SymbolsDelta)(nil),                    // 5: build.stack.bazel.registry.v1.SymbolsDelta
+	(*Module)(nil),                          // 6: build.stack.bazel.registry.v1.Module
+	(*Maintainer)(nil),                      // 7: build.stack.bazel.registry.v1.Maintainer
+	(*MaintainerIndex)(nil),                 // 8: build.stack.bazel.registry.v1.MaintainerIndex
+	(*MaintainerPortfolio)(nil),             // 9: build.stack.bazel.registry.v1.MaintainerPortfolio
+	(*MaintainerModule)(nil),                // 10: build.stack.bazel.registry.v1.MaintainerModule
+	(*ModuleMetadata)(nil),                  // 11: build.stack.bazel.registry.v1.ModuleMetadata
+	(*RepositoryMetadata)(nil),              // 12: build.stack.bazel.registry.v1.RepositoryMetadata
+	(*RepositoryMetadataSet)(nil),           // 13: build.stack.bazel.registry.v1.RepositoryMetadataSet
+	(*BazelRepositoryMetadata)(nil),         // 14: build.stack.bazel.registry.v1.BazelRepositoryMetadata
+	(*BazelRelease)(nil),                    // 15: build.stack.bazel.registry.v1.BazelRelease
+	(*BazelReleaseSet)(nil),                 // 16: build.stack.bazel.registry.v1.BazelReleaseSet
+	(*ResourceStatus)(nil),                  // 17: build.stack.bazel.registry.v1.ResourceStatus
+	(*ResourceStatusSet)(nil),               // 18: build.stack.bazel.registry.v1.ResourceStatusSet
+	(*ModuleSource)(nil),                    // 19: build.stack.bazel.registry.v1.ModuleSource
+	(*PatchStats)(nil),                      // 20: build.stack.bazel.registry.v1.PatchStats
+	(*OverlayStats)(nil),                    // 21: build.stack.bazel.registry.v1.OverlayStats
+	(*Attestations)(nil),                    // 22: build.stack.bazel.registry.v1.Attestations
+	(*ModuleVersion)(nil),                   // 23: build.stack.bazel.registry.v1.ModuleVersion
+	(*ModuleCommit)(nil),                    // 24: build.stack.bazel.registry.v1.ModuleCommit
+	(*PRAuthor)(nil),                        // 25: build.stack.bazel.registry.v1.PRAuthor
+	(*PRAuthorSet)(nil),                     // 26: build.stack.bazel.registry.v1.PRAuthorSet
+	(*ModuleDependencyOverride)(nil),        // 27: build.stack.bazel.registry.v1.ModuleDependencyOverride
+	(*ModuleDependency)(nil),                // 28: build.stack.bazel.registry.v1.ModuleDependency
+	(*GitOverride)(nil),                     // 29: build.stack.bazel.registry.v1.GitOverride
+	(*ArchiveOverride)(nil),                 // 30: build.stack.bazel.registry.v1.ArchiveOverride
+	(*SingleVersionOverride)(nil),           // 31: build.stack.bazel.registry.v1.SingleVersionOverride
+	(*LocalPathOverride)(nil),               // 32: build.stack.bazel.registry.v1.LocalPathOverride
+	(*Presubmit)(nil),                       // 33: build.stack.bazel.registry.v1.Presubmit
+	(*DependencyTreeNode)(nil),              // 34: build.stack.bazel.registry.v1.DependencyTreeNode
+	(*DependencyTree)(nil),                  // 35: build.stack.bazel.registry.v1.DependencyTree
+	(*MultipleVersionOverride)(nil),         // 36: build.stack.bazel.registry.v1.MultipleVersionOverride
+	(*ApiIndex)(nil),                        // 37: build.stack.bazel.registry.v1.ApiIndex
+	(*ApiModuleEntry)(nil),                  // 38: build.stack.bazel.registry.v1.ApiModuleEntry
+	nil,                                     // 39: build.stack.bazel.registry.v1.RegistryManifest.AssetHashesEntry
+	nil,                                     // 40: build.stack.bazel.registry.v1.ModuleMetadata.YankedVersionsEntry
+	nil,                                     // 41: build.stack.bazel.registry.v1.RepositoryMetadata.LanguagesEntry
+	nil,                                     // 42: build.stack.bazel.registry.v1.ModuleSource.PatchesEntry
+	nil,                                     // 43: build.stack.bazel.registry.v1.ModuleSource.OverlayEntry
+	(*Attestations_Attestation)(nil),        // 44: build.stack.bazel.registry.v1.Attestations.Attestation
+	(*Attestations_AttestationPayload)(nil), // 45: build.stack.bazel.registry.v1.Attestations.AttestationPayload
+	nil,                                     // 46: build.stack.bazel.registry.v1.Attestations.AttestationsEntry
+	(*Presubmit_BcrTestModule)(nil),         // 47: build.stack.bazel.registry.v1.Presubmit.BcrTestModule
+	(*Presubmit_PresubmitMatrix)(nil),       // 48: build.stack.bazel.registry.v1.Presubmit.PresubmitMatrix
+	(*Presubmit_PresubmitTask)(nil),         // 49: build.stack.bazel.registry.v1.Presubmit.PresubmitTask
+	nil,                                     // 50: build.stack.bazel.registry.v1.Presubmit.TasksEntry
+	nil,                                     // 51: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.TasksEntry
+	(*v1.ModuleVersionSymbols)(nil),         // 52: build.stack.bazel.symbol.v1.ModuleVersionSymbols
+	(*v1.ModuleVersionPackages)(nil),        // 53: build.stack.bazel.symbol.v1.ModuleVersionPackages
 }
 var file_build_stack_bazel_registry_v1_bcr_proto_depIdxs = []int32{
-	3,  // 0: build.stack.bazel.registry.v1.Registry.modules:type_name -> build.stack.bazel.registry.v1.Module
-	29, // 1: build.stack.bazel.registry.v1.RegistryManifest.asset_hashes:type_name -> build.stack.bazel.registry.v1.RegistryManifest.AssetHashesEntry
-	5,  // 2: build.stack.bazel.registry.v1.Module.metadata:type_name -> build.stack.bazel.registry.v1.ModuleMetadata
-	15, // 3: build.stack.bazel.registry.v1.Module.versions:type_name -> build.stack.bazel.registry.v1.ModuleVersion
-	6,  // 4: build.stack.bazel.registry.v1.Module.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
-	4,  // 5: build.stack.bazel.registry.v1.ModuleMetadata.maintainers:type_name -> build.stack.bazel.registry.v1.Maintainer
-	30, // 6: build.stack.bazel.registry.v1.ModuleMetadata.yanked_versions:type_name -> build.stack.bazel.registry.v1.ModuleMetadata.YankedVersionsEntry
-	0,  // 7: build.stack.bazel.registry.v1.RepositoryMetadata.type:type_name -> build.stack.bazel.registry.v1.RepositoryType
-	31, // 8: build.stack.bazel.registry.v1.RepositoryMetadata.languages:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata.LanguagesEntry
-	6,  // 9: build.stack.bazel.registry.v1.RepositoryMetadataSet.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
-	6,  // 10: build.stack.bazel.registry.v1.BazelRepositoryMetadata.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
-	9,  // 11: build.stack.bazel.registry.v1.BazelRepositoryMetadata.release:type_name -> build.stack.bazel.registry.v1.BazelRelease
-	16, // 12: build.stack.bazel.registry.v1.BazelRelease.commit:type_name -> build.stack.bazel.registry.v1.ModuleCommit
-	9,  // 13: build.stack.bazel.registry.v1.BazelReleaseSet.release:type_name -> build.stack.bazel.registry.v1.BazelRelease
-	11, // 14: build.stack.bazel.registry.v1.ResourceStatusSet.status:type_name -> build.stack.bazel.registry.v1.ResourceStatus
-	32, // 15: build.stack.bazel.registry.v1.ModuleSource.patches:type_name -> build.stack.bazel.registry.v1.ModuleSource.PatchesEntry
-	33, // 16: build.stack.bazel.registry.v1.ModuleSource.overlay:type_name -> build.stack.bazel.registry.v1.ModuleSource.OverlayEntry
-	42, // 17: build.stack.bazel.registry.v1.ModuleSource.documentation:type_name -> build.stack.bazel.symbol.v1.ModuleVersionSymbols
-	11, // 18: build.stack.bazel.registry.v1.ModuleSource.docs_url_status:type_name -> build.stack.bazel.registry.v1.ResourceStatus
-	11, // 19: build.stack.bazel.registry.v1.ModuleSource.url_status:type_name -> build.stack.bazel.registry.v1.ResourceStatus
-	43, // 20: build.stack.bazel.registry.v1.ModuleSource.packages:type_name -> build.stack.bazel.symbol.v1.ModuleVersionPackages
-	36, // 21: build.stack.bazel.registry.v1.Attestations.attestations:type_name -> build.stack.bazel.registry.v1.Attestations.AttestationsEntry
-	20, // 22: build.stack.bazel.registry.v1.ModuleVersion.deps:type_name -> build.stack.bazel.registry.v1.ModuleDependency
-	13, // 23: build.stack.bazel.registry.v1.ModuleVersion.source:type_name -> build.stack.bazel.registry.v1.ModuleSource
-	14, // 24: build.stack.bazel.registry.v1.ModuleVersion.attestations:type_name -> build.stack.bazel.registry.v1.Attestations
-	25, // 25: build.stack.bazel.registry.v1.ModuleVersion.presubmit:type_name -> build.stack.bazel.registry.v1.Presubmit
-	19, // 26: build.stack.bazel.registry.v1.ModuleVersion.override:type_name -> build.stack.bazel.registry.v1.ModuleDependencyOverride
-	16, // 27: build.stack.bazel.registry.v1.ModuleVersion.commit:type_name -> build.stack.bazel.registry.v1.ModuleCommit
-	6,  // 28: build.stack.bazel.registry.v1.ModuleVersion.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
-	17, // 29: build.stack.bazel.registry.v1.PRAuthorSet.authors:type_name -> build.stack.bazel.registry.v1.PRAuthor
-	21, // 30: build.stack.bazel.registry.v1.ModuleDependencyOverride.git_override:type_name -> build.stack.bazel.registry.v1.GitOverride
-	22, // 31: build.stack.bazel.registry.v1.ModuleDependencyOverride.archive_override:type_name -> build.stack.bazel.registry.v1.ArchiveOverride
-	23, // 32: build.stack.bazel.registry.v1.ModuleDependencyOverride.single_version_override:type_name -> build.stack.bazel.registry.v1.SingleVersionOverride
-	24, // 33: build.stack.bazel.registry.v1.ModuleDependencyOverride.local_path_override:type_name -> build.stack.bazel.registry.v1.LocalPathOverride
-	28, // 34: build.stack.bazel.registry.v1.ModuleDependencyOverride.multiple_version_override:type_name -> build.stack.bazel.registry.v1.MultipleVersionOverride
-	19, // 35: build.stack.bazel.registry.v1.ModuleDependency.override:type_name -> build.stack.bazel.registry.v1.ModuleDependencyOverride
-	37, // 36: build.stack.bazel.registry.v1.Presubmit.bcr_test_module:type_name -> build.stack.bazel.registry.v1.Presubmit.BcrTestModule
-	38, // 37: build.stack.bazel.registry.v1.Presubmit.matrix:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitMatrix
-	40, // 38: build.stack.bazel.registry.v1.Presubmit.tasks:type_name -> build.stack.bazel.registry.v1.Presubmit.TasksEntry
-	15, // 39: build.stack.bazel.registry.v1.DependencyTreeNode.module_version:type_name -> build.stack.bazel.registry.v1.ModuleVersion
-	26, // 40: build.stack.bazel.registry.v1.DependencyTreeNode.children:type_name -> build.stack.bazel.registry.v1.DependencyTreeNode
-	15, // 41: build.stack.bazel.registry.v1.DependencyTree.module_version:type_name -> build.stack.bazel.registry.v1.ModuleVersion
-	26, // 42: build.stack.bazel.registry.v1.DependencyTree.children:type_name -> build.stack.bazel.registry.v1.DependencyTreeNode
-	35, // 43: build.stack.bazel.registry.v1.Attestations.Attestation.payload:type_name -> build.stack.bazel.registry.v1.Attestations.AttestationPayload
-	34, // 44: build.stack.bazel.registry.v1.Attestations.AttestationsEntry.value:type_name -> build.stack.bazel.registry.v1.Attestations.Attestation
-	38, // 45: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.matrix:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitMatrix
-	41, // 46: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.tasks:type_name -> build.stack.bazel.registry.v1.Presubmit.BcrTestModule.TasksEntry
-	39, // 47: build.stack.bazel.registry.v1.Presubmit.TasksEntry.value:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitTask
-	39, // 48: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.TasksEntry.value:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitTask
-	49, // [49:49] is the sub-list for method output_type
-	49, // [49:49] is the sub-list for method input_type
-	49, // [49:49] is the sub-list for extension type_name
-	49, // [49:49] is the sub-list for extension extendee
-	0,  // [0:49] is the sub-list for field type_name
+	6,  // 0: build.stack.bazel.registry.v1.Registry.modules:type_name -> build.stack.bazel.registry.v1.Module
+	39, // 1: build.stack.bazel.registry.v1.RegistryManifest.asset_hashes:type_name -> build.stack.bazel.registry.v1.RegistryManifest.AssetHashesEntry
+	3,  // 2: build.stack.bazel.registry.v1.RegistryManifest.deltas:type_name -> build.stack.bazel.registry.v1.ReleaseDeltaRef
+	1,  // 3: build.stack.bazel.registry.v1.RegistryDelta.header:type_name -> build.stack.bazel.registry.v1.Registry
+	6,  // 4: build.stack.bazel.registry.v1.RegistryDelta.upsert:type_name -> build.stack.bazel.registry.v1.Module
+	52, // 5: build.stack.bazel.registry.v1.SymbolsDelta.upsert:type_name -> build.stack.bazel.symbol.v1.ModuleVersionSymbols
+	11, // 6: build.stack.bazel.registry.v1.Module.metadata:type_name -> build.stack.bazel.registry.v1.ModuleMetadata
+	23, // 7: build.stack.bazel.registry.v1.Module.versions:type_name -> build.stack.bazel.registry.v1.ModuleVersion
+	12, // 8: build.stack.bazel.registry.v1.Module.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
+	9,  // 9: build.stack.bazel.registry.v1.MaintainerIndex.maintainers:type_name -> build.stack.bazel.registry.v1.MaintainerPortfolio
+	7,  // 10: build.stack.bazel.registry.v1.MaintainerPortfolio.maintainer:type_name -> build.stack.bazel.registry.v1.Maintainer
+	10, // 11: build.stack.bazel.registry.v1.MaintainerPortfolio.modules:type_name -> build.stack.bazel.registry.v1.MaintainerModule
+	7,  // 12: build.stack.bazel.registry.v1.ModuleMetadata.maintainers:type_name -> build.stack.bazel.registry.v1.Maintainer
+	40, // 13: build.stack.bazel.registry.v1.ModuleMetadata.yanked_versions:type_name -> build.stack.bazel.registry.v1.ModuleMetadata.YankedVersionsEntry
+	0,  // 14: build.stack.bazel.registry.v1.RepositoryMetadata.type:type_name -> build.stack.bazel.registry.v1.RepositoryType
+	41, // 15: build.stack.bazel.registry.v1.RepositoryMetadata.languages:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata.LanguagesEntry
+	12, // 16: build.stack.bazel.registry.v1.RepositoryMetadataSet.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
+	12, // 17: build.stack.bazel.registry.v1.BazelRepositoryMetadata.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
+	15, // 18: build.stack.bazel.registry.v1.BazelRepositoryMetadata.release:type_name -> build.stack.bazel.registry.v1.BazelRelease
+	24, // 19: build.stack.bazel.registry.v1.BazelRelease.commit:type_name -> build.stack.bazel.registry.v1.ModuleCommit
+	15, // 20: build.stack.bazel.registry.v1.BazelReleaseSet.release:type_name -> build.stack.bazel.registry.v1.BazelRelease
+	17, // 21: build.stack.bazel.registry.v1.ResourceStatusSet.status:type_name -> build.stack.bazel.registry.v1.ResourceStatus
+	42, // 22: build.stack.bazel.registry.v1.ModuleSource.patches:type_name -> build.stack.bazel.registry.v1.ModuleSource.PatchesEntry
+	43, // 23: build.stack.bazel.registry.v1.ModuleSource.overlay:type_name -> build.stack.bazel.registry.v1.ModuleSource.OverlayEntry
+	52, // 24: build.stack.bazel.registry.v1.ModuleSource.documentation:type_name -> build.stack.bazel.symbol.v1.ModuleVersionSymbols
+	17, // 25: build.stack.bazel.registry.v1.ModuleSource.docs_url_status:type_name -> build.stack.bazel.registry.v1.ResourceStatus
+	17, // 26: build.stack.bazel.registry.v1.ModuleSource.url_status:type_name -> build.stack.bazel.registry.v1.ResourceStatus
+	53, // 27: build.stack.bazel.registry.v1.ModuleSource.packages:type_name -> build.stack.bazel.symbol.v1.ModuleVersionPackages
+	17, // 28: build.stack.bazel.registry.v1.ModuleSource.integrity_status:type_name -> build.stack.bazel.registry.v1.ResourceStatus
+	20, // 29: build.stack.bazel.registry.v1.ModuleSource.patch_stats:type_name -> build.stack.bazel.registry.v1.PatchStats
+	21, // 30: build.stack.bazel.registry.v1.ModuleSource.overlay_stats:type_name -> build.stack.bazel.registry.v1.OverlayStats
+	46, // 31: build.stack.bazel.registry.v1.Attestations.attestations:type_name -> build.stack.bazel.registry.v1.Attestations.AttestationsEntry
+	28, // 32: build.stack.bazel.registry.v1.ModuleVersion.deps:type_name -> build.stack.bazel.registry.v1.ModuleDependency
+	19, // 33: build.stack.bazel.registry.v1.ModuleVersion.source:type_name -> build.stack.bazel.registry.v1.ModuleSource
+	22, // 34: build.stack.bazel.registry.v1.ModuleVersion.attestations:type_name -> build.stack.bazel.registry.v1.Attestations
+	33, // 35: build.stack.bazel.registry.v1.ModuleVersion.presubmit:type_name -> build.stack.bazel.registry.v1.Presubmit
+	27, // 36: build.stack.bazel.registry.v1.ModuleVersion.override:type_name -> build.stack.bazel.registry.v1.ModuleDependencyOverride
+	24, // 37: build.stack.bazel.registry.v1.ModuleVersion.commit:type_name -> build.stack.bazel.registry.v1.ModuleCommit
+	12, // 38: build.stack.bazel.registry.v1.ModuleVersion.repository_metadata:type_name -> build.stack.bazel.registry.v1.RepositoryMetadata
+	25, // 39: build.stack.bazel.registry.v1.PRAuthorSet.authors:type_name -> build.stack.bazel.registry.v1.PRAuthor
+	29, // 40: build.stack.bazel.registry.v1.ModuleDependencyOverride.git_override:type_name -> build.stack.bazel.registry.v1.GitOverride
+	30, // 41: build.stack.bazel.registry.v1.ModuleDependencyOverride.archive_override:type_name -> build.stack.bazel.registry.v1.ArchiveOverride
+	31, // 42: build.stack.bazel.registry.v1.ModuleDependencyOverride.single_version_override:type_name -> build.stack.bazel.registry.v1.SingleVersionOverride
+	32, // 43: build.stack.bazel.registry.v1.ModuleDependencyOverride.local_path_override:type_name -> build.stack.bazel.registry.v1.LocalPathOverride
+	36, // 44: build.stack.bazel.registry.v1.ModuleDependencyOverride.multiple_version_override:type_name -> build.stack.bazel.registry.v1.MultipleVersionOverride
+	27, // 45: build.stack.bazel.registry.v1.ModuleDependency.override:type_name -> build.stack.bazel.registry.v1.ModuleDependencyOverride
+	47, // 46: build.stack.bazel.registry.v1.Presubmit.bcr_test_module:type_name -> build.stack.bazel.registry.v1.Presubmit.BcrTestModule
+	48, // 47: build.stack.bazel.registry.v1.Presubmit.matrix:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitMatrix
+	50, // 48: build.stack.bazel.registry.v1.Presubmit.tasks:type_name -> build.stack.bazel.registry.v1.Presubmit.TasksEntry
+	23, // 49: build.stack.bazel.registry.v1.DependencyTreeNode.module_version:type_name -> build.stack.bazel.registry.v1.ModuleVersion
+	34, // 50: build.stack.bazel.registry.v1.DependencyTreeNode.children:type_name -> build.stack.bazel.registry.v1.DependencyTreeNode
+	23, // 51: build.stack.bazel.registry.v1.DependencyTree.module_version:type_name -> build.stack.bazel.registry.v1.ModuleVersion
+	34, // 52: build.stack.bazel.registry.v1.DependencyTree.children:type_name -> build.stack.bazel.registry.v1.DependencyTreeNode
+	38, // 53: build.stack.bazel.registry.v1.ApiIndex.module:type_name -> build.stack.bazel.registry.v1.ApiModuleEntry
+	45, // 54: build.stack.bazel.registry.v1.Attestations.Attestation.payload:type_name -> build.stack.bazel.registry.v1.Attestations.AttestationPayload
+	44, // 55: build.stack.bazel.registry.v1.Attestations.AttestationsEntry.value:type_name -> build.stack.bazel.registry.v1.Attestations.Attestation
+	48, // 56: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.matrix:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitMatrix
+	51, // 57: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.tasks:type_name -> build.stack.bazel.registry.v1.Presubmit.BcrTestModule.TasksEntry
+	49, // 58: build.stack.bazel.registry.v1.Presubmit.TasksEntry.value:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitTask
+	49, // 59: build.stack.bazel.registry.v1.Presubmit.BcrTestModule.TasksEntry.value:type_name -> build.stack.bazel.registry.v1.Presubmit.PresubmitTask
+	60, // [60:60] is the sub-list for method output_type
+	60, // [60:60] is the sub-list for method input_type
+	60, // [60:60] is the sub-list for extension type_name
+	60, // [60:60] is the sub-list for extension extendee
+	0,  // [0:60] is the sub-list for field type_name
 }
 
 func init() { file_build_stack_bazel_registry_v1_bcr_proto_init() }
@@ -3050,7 +3890,7 @@ func file_build_stack_bazel_registry_v1_bcr_proto_init() {
 	if File_build_stack_bazel_registry_v1_bcr_proto != nil {
 		return
 	}
-	file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[18].OneofWrappers = []any{
+	file_build_stack_bazel_registry_v1_bcr_proto_msgTypes[26].OneofWrappers = []any{
 		(*ModuleDependencyOverride_GitOverride)(nil),
 		(*ModuleDependencyOverride_ArchiveOverride)(nil),
 		(*ModuleDependencyOverride_SingleVersionOverride)(nil),
@@ -3063,7 +3903,7 @@ func file_build_stack_bazel_registry_v1_bcr_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_build_stack_bazel_registry_v1_bcr_proto_rawDesc), len(file_build_stack_bazel_registry_v1_bcr_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   41,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
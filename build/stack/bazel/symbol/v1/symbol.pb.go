@@ -147,6 +147,125 @@ func (SymbolSource) EnumDescriptor() ([]byte, []int) {
 	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{1}
 }
 
+type DocFindingKind int32
+
+const (
+	DocFindingKind_DOC_FINDING_KIND_UNKNOWN                     DocFindingKind = 0
+	DocFindingKind_DOC_FINDING_KIND_MISSING_DOCSTRING           DocFindingKind = 1
+	DocFindingKind_DOC_FINDING_KIND_UNDOCUMENTED_ATTRIBUTE      DocFindingKind = 2
+	DocFindingKind_DOC_FINDING_KIND_UNDOCUMENTED_PARAM          DocFindingKind = 3
+	DocFindingKind_DOC_FINDING_KIND_UNDOCUMENTED_PROVIDER_FIELD DocFindingKind = 4
+	DocFindingKind_DOC_FINDING_KIND_UNKNOWN_DOCUMENTED_PARAM    DocFindingKind = 5
+	DocFindingKind_DOC_FINDING_KIND_BROKEN_LINK                 DocFindingKind = 6
+)
+
+// Enum value maps for DocFindingKind.
+var (
+	DocFindingKind_name = map[int32]string{
+		0: "DOC_FINDING_KIND_UNKNOWN",
+		1: "DOC_FINDING_KIND_MISSING_DOCSTRING",
+		2: "DOC_FINDING_KIND_UNDOCUMENTED_ATTRIBUTE",
+		3: "DOC_FINDING_KIND_UNDOCUMENTED_PARAM",
+		4: "DOC_FINDING_KIND_UNDOCUMENTED_PROVIDER_FIELD",
+		5: "DOC_FINDING_KIND_UNKNOWN_DOCUMENTED_PARAM",
+		6: "DOC_FINDING_KIND_BROKEN_LINK",
+	}
+	DocFindingKind_value = map[string]int32{
+		"DOC_FINDING_KIND_UNKNOWN":                     0,
+		"DOC_FINDING_KIND_MISSING_DOCSTRING":           1,
+		"DOC_FINDING_KIND_UNDOCUMENTED_ATTRIBUTE":      2,
+		"DOC_FINDING_KIND_UNDOCUMENTED_PARAM":          3,
+		"DOC_FINDING_KIND_UNDOCUMENTED_PROVIDER_FIELD": 4,
+		"DOC_FINDING_KIND_UNKNOWN_DOCUMENTED_PARAM":    5,
+		"DOC_FINDING_KIND_BROKEN_LINK":                 6,
+	}
+)
+
+func (x DocFindingKind) Enum() *DocFindingKind {
+	p := new(DocFindingKind)
+	*p = x
+	return p
+}
+
+func (x DocFindingKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DocFindingKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_enumTypes[2].Descriptor()
+}
+
+func (DocFindingKind) Type() protoreflect.EnumType {
+	return &file_build_stack_bazel_symbol_v1_symbol_proto_enumTypes[2]
+}
+
+func (x DocFindingKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DocFindingKind.Descriptor instead.
+func (DocFindingKind) EnumDescriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{2}
+}
+
+type ExtractionErrorKind int32
+
+const (
+	ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNKNOWN             ExtractionErrorKind = 0
+	ExtractionErrorKind_EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET ExtractionErrorKind = 1
+	ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN ExtractionErrorKind = 2
+	ExtractionErrorKind_EXTRACTION_ERROR_KIND_SYNTAX_ERROR        ExtractionErrorKind = 3
+	ExtractionErrorKind_EXTRACTION_ERROR_KIND_SERVER_TIMEOUT      ExtractionErrorKind = 4
+	ExtractionErrorKind_EXTRACTION_ERROR_KIND_SHIM_MISSING        ExtractionErrorKind = 5
+)
+
+// Enum value maps for ExtractionErrorKind.
+var (
+	ExtractionErrorKind_name = map[int32]string{
+		0: "EXTRACTION_ERROR_KIND_UNKNOWN",
+		1: "EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET",
+		2: "EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN",
+		3: "EXTRACTION_ERROR_KIND_SYNTAX_ERROR",
+		4: "EXTRACTION_ERROR_KIND_SERVER_TIMEOUT",
+		5: "EXTRACTION_ERROR_KIND_SHIM_MISSING",
+	}
+	ExtractionErrorKind_value = map[string]int32{
+		"EXTRACTION_ERROR_KIND_UNKNOWN":             0,
+		"EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET": 1,
+		"EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN": 2,
+		"EXTRACTION_ERROR_KIND_SYNTAX_ERROR":        3,
+		"EXTRACTION_ERROR_KIND_SERVER_TIMEOUT":      4,
+		"EXTRACTION_ERROR_KIND_SHIM_MISSING":        5,
+	}
+)
+
+func (x ExtractionErrorKind) Enum() *ExtractionErrorKind {
+	p := new(ExtractionErrorKind)
+	*p = x
+	return p
+}
+
+func (x ExtractionErrorKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExtractionErrorKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_enumTypes[3].Descriptor()
+}
+
+func (ExtractionErrorKind) Type() protoreflect.EnumType {
+	return &file_build_stack_bazel_symbol_v1_symbol_proto_enumTypes[3]
+}
+
+func (x ExtractionErrorKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExtractionErrorKind.Descriptor instead.
+func (ExtractionErrorKind) EnumDescriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{3}
+}
+
 type Symbol struct {
 	state       protoimpl.MessageState `protogen:"open.v1"`
 	Type        SymbolType             `protobuf:"varint,1,opt,name=type,proto3,enum=build.stack.bazel.symbol.v1.SymbolType" json:"type,omitempty"`
@@ -406,13 +525,14 @@ func (*Symbol_Load) isSymbol_Info() {}
 func (*Symbol_Struct) isSymbol_Info() {}
 
 type File struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Label         *v1beta1.Label         `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
-	Symbol        []*Symbol              `protobuf:"bytes,2,rep,name=symbol,proto3" json:"symbol,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Label           *v1beta1.Label         `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Symbol          []*Symbol              `protobuf:"bytes,2,rep,name=symbol,proto3" json:"symbol,omitempty"`
+	Description     string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Error           string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	ExtractionError *ExtractionError       `protobuf:"bytes,6,opt,name=extraction_error,json=extractionError,proto3" json:"extraction_error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *File) Reset() {
@@ -473,6 +593,13 @@ func (x *File) GetError() string {
 	return ""
 }
 
+func (x *File) GetExtractionError() *ExtractionError {
+	if x != nil {
+		return x.ExtractionError
+	}
+	return nil
+}
+
 type ModuleVersionSymbols struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ModuleName    string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
@@ -586,13 +713,14 @@ func (x *ModuleRegistrySymbols) GetModuleVersion() []*ModuleVersionSymbols {
 }
 
 type ModuleVersionPackages struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ModuleName    string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Package       []*v1beta1.Package     `protobuf:"bytes,3,rep,name=package,proto3" json:"package,omitempty"`
-	Source        SymbolSource           `protobuf:"varint,4,opt,name=source,proto3,enum=build.stack.bazel.symbol.v1.SymbolSource" json:"source,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ModuleName      string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
+	Version         string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Package         []*v1beta1.Package     `protobuf:"bytes,3,rep,name=package,proto3" json:"package,omitempty"`
+	Source          SymbolSource           `protobuf:"varint,4,opt,name=source,proto3,enum=build.stack.bazel.symbol.v1.SymbolSource" json:"source,omitempty"`
+	ExtractionError []*ExtractionError     `protobuf:"bytes,5,rep,name=extraction_error,json=extractionError,proto3" json:"extraction_error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ModuleVersionPackages) Reset() {
@@ -653,6 +781,13 @@ func (x *ModuleVersionPackages) GetSource() SymbolSource {
 	return SymbolSource_SYMBOL_SOURCE_UNKNOWN
 }
 
+func (x *ModuleVersionPackages) GetExtractionError() []*ExtractionError {
+	if x != nil {
+		return x.ExtractionError
+	}
+	return nil
+}
+
 type ModuleRegistryPackages struct {
 	state         protoimpl.MessageState   `protogen:"open.v1"`
 	ModuleVersion []*ModuleVersionPackages `protobuf:"bytes,1,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
@@ -801,57 +936,1818 @@ func (x *FileLoadTree) GetRoots() []*FileLoadTreeNode {
 	return nil
 }
 
-var File_build_stack_bazel_symbol_v1_symbol_proto protoreflect.FileDescriptor
+type FileRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ModuleName    string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	File          *v1beta1.Label         `protobuf:"bytes,3,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_build_stack_bazel_symbol_v1_symbol_proto_rawDesc = "" +
-	"\n" +
-	"(build/stack/bazel/symbol/v1/symbol.proto\x12\x1bbuild.stack.bazel.symbol.v1\x1a+build/stack/starlark/v1beta1/starlark.proto\"\x9b\a\n" +
-	"\x06Symbol\x12;\n" +
-	"\x04type\x18\x01 \x01(\x0e2'.build.stack.bazel.symbol.v1.SymbolTypeR\x04type\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x128\n" +
-	"\x04rule\x18\x04 \x01(\v2\".build.stack.starlark.v1beta1.RuleH\x00R\x04rule\x12<\n" +
-	"\x04func\x18\x05 \x01(\v2&.build.stack.starlark.v1beta1.FunctionH\x00R\x04func\x12D\n" +
-	"\bprovider\x18\x06 \x01(\v2&.build.stack.starlark.v1beta1.ProviderH\x00R\bprovider\x12>\n" +
-	"\x06aspect\x18\a \x01(\v2$.build.stack.starlark.v1beta1.AspectH\x00R\x06aspect\x12Z\n" +
-	"\x10module_extension\x18\t \x01(\v2-.build.stack.starlark.v1beta1.ModuleExtensionH\x00R\x0fmoduleExtension\x12W\n" +
-	"\x0frepository_rule\x18\n" +
-	" \x01(\v2,.build.stack.starlark.v1beta1.RepositoryRuleH\x00R\x0erepositoryRule\x12;\n" +
-	"\x05macro\x18\v \x01(\v2#.build.stack.starlark.v1beta1.MacroH\x00R\x05macro\x12H\n" +
-	"\n" +
-	"rule_macro\x18\f \x01(\v2'.build.stack.starlark.v1beta1.RuleMacroH\x00R\truleMacro\x12;\n" +
-	"\x05value\x18\r \x01(\v2#.build.stack.starlark.v1beta1.ValueH\x00R\x05value\x12<\n" +
-	"\x04load\x18\x0e \x01(\v2&.build.stack.starlark.v1beta1.LoadStmtH\x00R\x04load\x12>\n" +
-	"\x06struct\x18\x0f \x01(\v2$.build.stack.starlark.v1beta1.StructH\x00R\x06struct\x12!\n" +
-	"\fdisplay_name\x18\x10 \x01(\tR\vdisplayNameB\x06\n" +
-	"\x04info\"\xb6\x01\n" +
-	"\x04File\x129\n" +
-	"\x05label\x18\x01 \x01(\v2#.build.stack.starlark.v1beta1.LabelR\x05label\x12;\n" +
-	"\x06symbol\x18\x02 \x03(\v2#.build.stack.bazel.symbol.v1.SymbolR\x06symbol\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error\"\xcb\x01\n" +
-	"\x14ModuleVersionSymbols\x12\x1f\n" +
-	"\vmodule_name\x18\x01 \x01(\tR\n" +
-	"moduleName\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion\x125\n" +
-	"\x04file\x18\x03 \x03(\v2!.build.stack.bazel.symbol.v1.FileR\x04file\x12A\n" +
-	"\x06source\x18\x04 \x01(\x0e2).build.stack.bazel.symbol.v1.SymbolSourceR\x06source\"q\n" +
-	"\x15ModuleRegistrySymbols\x12X\n" +
-	"\x0emodule_version\x18\x01 \x03(\v21.build.stack.bazel.symbol.v1.ModuleVersionSymbolsR\rmoduleVersion\"\xd6\x01\n" +
-	"\x15ModuleVersionPackages\x12\x1f\n" +
-	"\vmodule_name\x18\x01 \x01(\tR\n" +
-	"moduleName\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion\x12?\n" +
-	"\apackage\x18\x03 \x03(\v2%.build.stack.starlark.v1beta1.PackageR\apackage\x12A\n" +
-	"\x06source\x18\x04 \x01(\x0e2).build.stack.bazel.symbol.v1.SymbolSourceR\x06source\"s\n" +
-	"\x16ModuleRegistryPackages\x12Y\n" +
-	"\x0emodule_version\x18\x01 \x03(\v22.build.stack.bazel.symbol.v1.ModuleVersionPackagesR\rmoduleVersion\"\xac\x01\n" +
-	"\x10FileLoadTreeNode\x125\n" +
-	"\x04file\x18\x01 \x01(\v2!.build.stack.bazel.symbol.v1.FileR\x04file\x12I\n" +
-	"\bchildren\x18\x02 \x03(\v2-.build.stack.bazel.symbol.v1.FileLoadTreeNodeR\bchildren\x12\x16\n" +
-	"\x06pruned\x18\x03 \x01(\bR\x06pruned\"S\n" +
-	"\fFileLoadTree\x12C\n" +
-	"\x05roots\x18\x01 \x03(\v2-.build.stack.bazel.symbol.v1.FileLoadTreeNodeR\x05roots*\xc7\x02\n" +
+func (x *FileRef) Reset() {
+	*x = FileRef{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRef) ProtoMessage() {}
+
+func (x *FileRef) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRef.ProtoReflect.Descriptor instead.
+func (*FileRef) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FileRef) GetModuleName() string {
+	if x != nil {
+		return x.ModuleName
+	}
+	return ""
+}
+
+func (x *FileRef) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *FileRef) GetFile() *v1beta1.Label {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type ResolvedLoadSymbol struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	DefinedIn     *FileRef               `protobuf:"bytes,3,opt,name=defined_in,json=definedIn,proto3" json:"defined_in,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Type          SymbolType             `protobuf:"varint,5,opt,name=type,proto3,enum=build.stack.bazel.symbol.v1.SymbolType" json:"type,omitempty"`
+	Deprecated    bool                   `protobuf:"varint,6,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolvedLoadSymbol) Reset() {
+	*x = ResolvedLoadSymbol{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolvedLoadSymbol) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolvedLoadSymbol) ProtoMessage() {}
+
+func (x *ResolvedLoadSymbol) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolvedLoadSymbol.ProtoReflect.Descriptor instead.
+func (*ResolvedLoadSymbol) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResolvedLoadSymbol) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ResolvedLoadSymbol) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ResolvedLoadSymbol) GetDefinedIn() *FileRef {
+	if x != nil {
+		return x.DefinedIn
+	}
+	return nil
+}
+
+func (x *ResolvedLoadSymbol) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ResolvedLoadSymbol) GetType() SymbolType {
+	if x != nil {
+		return x.Type
+	}
+	return SymbolType_SYMBOL_TYPE_UNKNOWN
+}
+
+func (x *ResolvedLoadSymbol) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+type ResolvedLoad struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         *v1beta1.Label         `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	File          *FileRef               `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Symbol        []*ResolvedLoadSymbol  `protobuf:"bytes,3,rep,name=symbol,proto3" json:"symbol,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolvedLoad) Reset() {
+	*x = ResolvedLoad{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolvedLoad) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolvedLoad) ProtoMessage() {}
+
+func (x *ResolvedLoad) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolvedLoad.ProtoReflect.Descriptor instead.
+func (*ResolvedLoad) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResolvedLoad) GetLabel() *v1beta1.Label {
+	if x != nil {
+		return x.Label
+	}
+	return nil
+}
+
+func (x *ResolvedLoad) GetFile() *FileRef {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *ResolvedLoad) GetSymbol() []*ResolvedLoadSymbol {
+	if x != nil {
+		return x.Symbol
+	}
+	return nil
+}
+
+func (x *ResolvedLoad) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type FileReferences struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         *v1beta1.Label         `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Load          []*ResolvedLoad        `protobuf:"bytes,2,rep,name=load,proto3" json:"load,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileReferences) Reset() {
+	*x = FileReferences{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileReferences) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileReferences) ProtoMessage() {}
+
+func (x *FileReferences) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileReferences.ProtoReflect.Descriptor instead.
+func (*FileReferences) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FileReferences) GetLabel() *v1beta1.Label {
+	if x != nil {
+		return x.Label
+	}
+	return nil
+}
+
+func (x *FileReferences) GetLoad() []*ResolvedLoad {
+	if x != nil {
+		return x.Load
+	}
+	return nil
+}
+
+type ModuleVersionReferences struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ModuleName    string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	File          []*FileReferences      `protobuf:"bytes,3,rep,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModuleVersionReferences) Reset() {
+	*x = ModuleVersionReferences{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleVersionReferences) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleVersionReferences) ProtoMessage() {}
+
+func (x *ModuleVersionReferences) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleVersionReferences.ProtoReflect.Descriptor instead.
+func (*ModuleVersionReferences) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ModuleVersionReferences) GetModuleName() string {
+	if x != nil {
+		return x.ModuleName
+	}
+	return ""
+}
+
+func (x *ModuleVersionReferences) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ModuleVersionReferences) GetFile() []*FileReferences {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type SymbolUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DefinedIn     *FileRef               `protobuf:"bytes,1,opt,name=defined_in,json=definedIn,proto3" json:"defined_in,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type          SymbolType             `protobuf:"varint,3,opt,name=type,proto3,enum=build.stack.bazel.symbol.v1.SymbolType" json:"type,omitempty"`
+	Deprecated    bool                   `protobuf:"varint,4,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	UsedByModule  []string               `protobuf:"bytes,5,rep,name=used_by_module,json=usedByModule,proto3" json:"used_by_module,omitempty"`
+	UsedBy        []*FileRef             `protobuf:"bytes,6,rep,name=used_by,json=usedBy,proto3" json:"used_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymbolUsage) Reset() {
+	*x = SymbolUsage{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymbolUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolUsage) ProtoMessage() {}
+
+func (x *SymbolUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolUsage.ProtoReflect.Descriptor instead.
+func (*SymbolUsage) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SymbolUsage) GetDefinedIn() *FileRef {
+	if x != nil {
+		return x.DefinedIn
+	}
+	return nil
+}
+
+func (x *SymbolUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SymbolUsage) GetType() SymbolType {
+	if x != nil {
+		return x.Type
+	}
+	return SymbolType_SYMBOL_TYPE_UNKNOWN
+}
+
+func (x *SymbolUsage) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+func (x *SymbolUsage) GetUsedByModule() []string {
+	if x != nil {
+		return x.UsedByModule
+	}
+	return nil
+}
+
+func (x *SymbolUsage) GetUsedBy() []*FileRef {
+	if x != nil {
+		return x.UsedBy
+	}
+	return nil
+}
+
+type SymbolReferenceIndex struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	ModuleVersion []*ModuleVersionReferences `protobuf:"bytes,1,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
+	Usage         []*SymbolUsage             `protobuf:"bytes,2,rep,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymbolReferenceIndex) Reset() {
+	*x = SymbolReferenceIndex{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymbolReferenceIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolReferenceIndex) ProtoMessage() {}
+
+func (x *SymbolReferenceIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolReferenceIndex.ProtoReflect.Descriptor instead.
+func (*SymbolReferenceIndex) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SymbolReferenceIndex) GetModuleVersion() []*ModuleVersionReferences {
+	if x != nil {
+		return x.ModuleVersion
+	}
+	return nil
+}
+
+func (x *SymbolReferenceIndex) GetUsage() []*SymbolUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type DocFinding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          DocFindingKind         `protobuf:"varint,1,opt,name=kind,proto3,enum=build.stack.bazel.symbol.v1.DocFindingKind" json:"kind,omitempty"`
+	File          *v1beta1.Label         `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Item          string                 `protobuf:"bytes,4,opt,name=item,proto3" json:"item,omitempty"`
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocFinding) Reset() {
+	*x = DocFinding{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocFinding) ProtoMessage() {}
+
+func (x *DocFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocFinding.ProtoReflect.Descriptor instead.
+func (*DocFinding) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DocFinding) GetKind() DocFindingKind {
+	if x != nil {
+		return x.Kind
+	}
+	return DocFindingKind_DOC_FINDING_KIND_UNKNOWN
+}
+
+func (x *DocFinding) GetFile() *v1beta1.Label {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *DocFinding) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *DocFinding) GetItem() string {
+	if x != nil {
+		return x.Item
+	}
+	return ""
+}
+
+func (x *DocFinding) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DocCoverage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ModuleName    string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Documented    int32                  `protobuf:"varint,4,opt,name=documented,proto3" json:"documented,omitempty"`
+	Percent       float32                `protobuf:"fixed32,5,opt,name=percent,proto3" json:"percent,omitempty"`
+	Finding       []*DocFinding          `protobuf:"bytes,6,rep,name=finding,proto3" json:"finding,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocCoverage) Reset() {
+	*x = DocCoverage{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocCoverage) ProtoMessage() {}
+
+func (x *DocCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocCoverage.ProtoReflect.Descriptor instead.
+func (*DocCoverage) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DocCoverage) GetModuleName() string {
+	if x != nil {
+		return x.ModuleName
+	}
+	return ""
+}
+
+func (x *DocCoverage) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DocCoverage) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *DocCoverage) GetDocumented() int32 {
+	if x != nil {
+		return x.Documented
+	}
+	return 0
+}
+
+func (x *DocCoverage) GetPercent() float32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *DocCoverage) GetFinding() []*DocFinding {
+	if x != nil {
+		return x.Finding
+	}
+	return nil
+}
+
+type ModuleRegistryDocCoverage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ModuleVersion []*DocCoverage         `protobuf:"bytes,1,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModuleRegistryDocCoverage) Reset() {
+	*x = ModuleRegistryDocCoverage{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleRegistryDocCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleRegistryDocCoverage) ProtoMessage() {}
+
+func (x *ModuleRegistryDocCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleRegistryDocCoverage.ProtoReflect.Descriptor instead.
+func (*ModuleRegistryDocCoverage) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ModuleRegistryDocCoverage) GetModuleVersion() []*DocCoverage {
+	if x != nil {
+		return x.ModuleVersion
+	}
+	return nil
+}
+
+type AttributeValueCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeValueCount) Reset() {
+	*x = AttributeValueCount{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeValueCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeValueCount) ProtoMessage() {}
+
+func (x *AttributeValueCount) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeValueCount.ProtoReflect.Descriptor instead.
+func (*AttributeValueCount) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AttributeValueCount) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *AttributeValueCount) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type AttributeUsage struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Count             int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Value             []*AttributeValueCount `protobuf:"bytes,3,rep,name=value,proto3" json:"value,omitempty"`
+	OmittedValueCount int32                  `protobuf:"varint,4,opt,name=omitted_value_count,json=omittedValueCount,proto3" json:"omitted_value_count,omitempty"`
+	NonLiteralCount   int32                  `protobuf:"varint,5,opt,name=non_literal_count,json=nonLiteralCount,proto3" json:"non_literal_count,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AttributeUsage) Reset() {
+	*x = AttributeUsage{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeUsage) ProtoMessage() {}
+
+func (x *AttributeUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeUsage.ProtoReflect.Descriptor instead.
+func (*AttributeUsage) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AttributeUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttributeUsage) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *AttributeUsage) GetValue() []*AttributeValueCount {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *AttributeUsage) GetOmittedValueCount() int32 {
+	if x != nil {
+		return x.OmittedValueCount
+	}
+	return 0
+}
+
+func (x *AttributeUsage) GetNonLiteralCount() int32 {
+	if x != nil {
+		return x.NonLiteralCount
+	}
+	return 0
+}
+
+type RuleUsage struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Kind            string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	DefinedIn       *FileRef               `protobuf:"bytes,2,opt,name=defined_in,json=definedIn,proto3" json:"defined_in,omitempty"`
+	Name            string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Type            SymbolType             `protobuf:"varint,4,opt,name=type,proto3,enum=build.stack.bazel.symbol.v1.SymbolType" json:"type,omitempty"`
+	IsMacro         bool                   `protobuf:"varint,5,opt,name=is_macro,json=isMacro,proto3" json:"is_macro,omitempty"`
+	Count           int32                  `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+	UsedByModule    []string               `protobuf:"bytes,7,rep,name=used_by_module,json=usedByModule,proto3" json:"used_by_module,omitempty"`
+	Attribute       []*AttributeUsage      `protobuf:"bytes,8,rep,name=attribute,proto3" json:"attribute,omitempty"`
+	UnusedAttribute []string               `protobuf:"bytes,9,rep,name=unused_attribute,json=unusedAttribute,proto3" json:"unused_attribute,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RuleUsage) Reset() {
+	*x = RuleUsage{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleUsage) ProtoMessage() {}
+
+func (x *RuleUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleUsage.ProtoReflect.Descriptor instead.
+func (*RuleUsage) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RuleUsage) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *RuleUsage) GetDefinedIn() *FileRef {
+	if x != nil {
+		return x.DefinedIn
+	}
+	return nil
+}
+
+func (x *RuleUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RuleUsage) GetType() SymbolType {
+	if x != nil {
+		return x.Type
+	}
+	return SymbolType_SYMBOL_TYPE_UNKNOWN
+}
+
+func (x *RuleUsage) GetIsMacro() bool {
+	if x != nil {
+		return x.IsMacro
+	}
+	return false
+}
+
+func (x *RuleUsage) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *RuleUsage) GetUsedByModule() []string {
+	if x != nil {
+		return x.UsedByModule
+	}
+	return nil
+}
+
+func (x *RuleUsage) GetAttribute() []*AttributeUsage {
+	if x != nil {
+		return x.Attribute
+	}
+	return nil
+}
+
+func (x *RuleUsage) GetUnusedAttribute() []string {
+	if x != nil {
+		return x.UnusedAttribute
+	}
+	return nil
+}
+
+type RuleUsageIndex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          []*RuleUsage           `protobuf:"bytes,1,rep,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleUsageIndex) Reset() {
+	*x = RuleUsageIndex{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleUsageIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleUsageIndex) ProtoMessage() {}
+
+func (x *RuleUsageIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleUsageIndex.ProtoReflect.Descriptor instead.
+func (*RuleUsageIndex) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RuleUsageIndex) GetRule() []*RuleUsage {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type LicenseTarget struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Label           *v1beta1.Label         `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	LicenseKind     []string               `protobuf:"bytes,2,rep,name=license_kind,json=licenseKind,proto3" json:"license_kind,omitempty"`
+	SpdxId          []string               `protobuf:"bytes,3,rep,name=spdx_id,json=spdxId,proto3" json:"spdx_id,omitempty"`
+	LicenseText     string                 `protobuf:"bytes,4,opt,name=license_text,json=licenseText,proto3" json:"license_text,omitempty"`
+	PackageName     string                 `protobuf:"bytes,5,opt,name=package_name,json=packageName,proto3" json:"package_name,omitempty"`
+	PackageUrl      string                 `protobuf:"bytes,6,opt,name=package_url,json=packageUrl,proto3" json:"package_url,omitempty"`
+	PackageVersion  string                 `protobuf:"bytes,7,opt,name=package_version,json=packageVersion,proto3" json:"package_version,omitempty"`
+	CopyrightNotice string                 `protobuf:"bytes,8,opt,name=copyright_notice,json=copyrightNotice,proto3" json:"copyright_notice,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LicenseTarget) Reset() {
+	*x = LicenseTarget{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LicenseTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LicenseTarget) ProtoMessage() {}
+
+func (x *LicenseTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LicenseTarget.ProtoReflect.Descriptor instead.
+func (*LicenseTarget) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *LicenseTarget) GetLabel() *v1beta1.Label {
+	if x != nil {
+		return x.Label
+	}
+	return nil
+}
+
+func (x *LicenseTarget) GetLicenseKind() []string {
+	if x != nil {
+		return x.LicenseKind
+	}
+	return nil
+}
+
+func (x *LicenseTarget) GetSpdxId() []string {
+	if x != nil {
+		return x.SpdxId
+	}
+	return nil
+}
+
+func (x *LicenseTarget) GetLicenseText() string {
+	if x != nil {
+		return x.LicenseText
+	}
+	return ""
+}
+
+func (x *LicenseTarget) GetPackageName() string {
+	if x != nil {
+		return x.PackageName
+	}
+	return ""
+}
+
+func (x *LicenseTarget) GetPackageUrl() string {
+	if x != nil {
+		return x.PackageUrl
+	}
+	return ""
+}
+
+func (x *LicenseTarget) GetPackageVersion() string {
+	if x != nil {
+		return x.PackageVersion
+	}
+	return ""
+}
+
+func (x *LicenseTarget) GetCopyrightNotice() string {
+	if x != nil {
+		return x.CopyrightNotice
+	}
+	return ""
+}
+
+type PackageLicensing struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	Package                   string                 `protobuf:"bytes,1,opt,name=package,proto3" json:"package,omitempty"`
+	DefaultApplicableLicenses []string               `protobuf:"bytes,2,rep,name=default_applicable_licenses,json=defaultApplicableLicenses,proto3" json:"default_applicable_licenses,omitempty"`
+	DefaultVisibility         []string               `protobuf:"bytes,3,rep,name=default_visibility,json=defaultVisibility,proto3" json:"default_visibility,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *PackageLicensing) Reset() {
+	*x = PackageLicensing{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackageLicensing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackageLicensing) ProtoMessage() {}
+
+func (x *PackageLicensing) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackageLicensing.ProtoReflect.Descriptor instead.
+func (*PackageLicensing) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PackageLicensing) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *PackageLicensing) GetDefaultApplicableLicenses() []string {
+	if x != nil {
+		return x.DefaultApplicableLicenses
+	}
+	return nil
+}
+
+func (x *PackageLicensing) GetDefaultVisibility() []string {
+	if x != nil {
+		return x.DefaultVisibility
+	}
+	return nil
+}
+
+type ModuleVersionLicenses struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ModuleName    string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	License       []*LicenseTarget       `protobuf:"bytes,3,rep,name=license,proto3" json:"license,omitempty"`
+	Package       []*PackageLicensing    `protobuf:"bytes,4,rep,name=package,proto3" json:"package,omitempty"`
+	SpdxId        []string               `protobuf:"bytes,5,rep,name=spdx_id,json=spdxId,proto3" json:"spdx_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModuleVersionLicenses) Reset() {
+	*x = ModuleVersionLicenses{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleVersionLicenses) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleVersionLicenses) ProtoMessage() {}
+
+func (x *ModuleVersionLicenses) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleVersionLicenses.ProtoReflect.Descriptor instead.
+func (*ModuleVersionLicenses) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ModuleVersionLicenses) GetModuleName() string {
+	if x != nil {
+		return x.ModuleName
+	}
+	return ""
+}
+
+func (x *ModuleVersionLicenses) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ModuleVersionLicenses) GetLicense() []*LicenseTarget {
+	if x != nil {
+		return x.License
+	}
+	return nil
+}
+
+func (x *ModuleVersionLicenses) GetPackage() []*PackageLicensing {
+	if x != nil {
+		return x.Package
+	}
+	return nil
+}
+
+func (x *ModuleVersionLicenses) GetSpdxId() []string {
+	if x != nil {
+		return x.SpdxId
+	}
+	return nil
+}
+
+type LicenseInventoryEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SpdxId        string                 `protobuf:"bytes,1,opt,name=spdx_id,json=spdxId,proto3" json:"spdx_id,omitempty"`
+	ModuleVersion []string               `protobuf:"bytes,2,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LicenseInventoryEntry) Reset() {
+	*x = LicenseInventoryEntry{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LicenseInventoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LicenseInventoryEntry) ProtoMessage() {}
+
+func (x *LicenseInventoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LicenseInventoryEntry.ProtoReflect.Descriptor instead.
+func (*LicenseInventoryEntry) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *LicenseInventoryEntry) GetSpdxId() string {
+	if x != nil {
+		return x.SpdxId
+	}
+	return ""
+}
+
+func (x *LicenseInventoryEntry) GetModuleVersion() []string {
+	if x != nil {
+		return x.ModuleVersion
+	}
+	return nil
+}
+
+type ModuleRegistryLicenses struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	ModuleVersion []*ModuleVersionLicenses `protobuf:"bytes,1,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
+	Inventory     []*LicenseInventoryEntry `protobuf:"bytes,2,rep,name=inventory,proto3" json:"inventory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModuleRegistryLicenses) Reset() {
+	*x = ModuleRegistryLicenses{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleRegistryLicenses) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleRegistryLicenses) ProtoMessage() {}
+
+func (x *ModuleRegistryLicenses) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleRegistryLicenses.ProtoReflect.Descriptor instead.
+func (*ModuleRegistryLicenses) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ModuleRegistryLicenses) GetModuleVersion() []*ModuleVersionLicenses {
+	if x != nil {
+		return x.ModuleVersion
+	}
+	return nil
+}
+
+func (x *ModuleRegistryLicenses) GetInventory() []*LicenseInventoryEntry {
+	if x != nil {
+		return x.Inventory
+	}
+	return nil
+}
+
+type ExtractionErrorLocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Line          int32                  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Column        int32                  `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractionErrorLocation) Reset() {
+	*x = ExtractionErrorLocation{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractionErrorLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractionErrorLocation) ProtoMessage() {}
+
+func (x *ExtractionErrorLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractionErrorLocation.ProtoReflect.Descriptor instead.
+func (*ExtractionErrorLocation) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ExtractionErrorLocation) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *ExtractionErrorLocation) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ExtractionErrorLocation) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+type ExtractionError struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Kind          ExtractionErrorKind      `protobuf:"varint,1,opt,name=kind,proto3,enum=build.stack.bazel.symbol.v1.ExtractionErrorKind" json:"kind,omitempty"`
+	Label         string                   `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Location      *ExtractionErrorLocation `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	Cause         string                   `protobuf:"bytes,4,opt,name=cause,proto3" json:"cause,omitempty"`
+	Message       string                   `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractionError) Reset() {
+	*x = ExtractionError{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractionError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractionError) ProtoMessage() {}
+
+func (x *ExtractionError) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractionError.ProtoReflect.Descriptor instead.
+func (*ExtractionError) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ExtractionError) GetKind() ExtractionErrorKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNKNOWN
+}
+
+func (x *ExtractionError) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ExtractionError) GetLocation() *ExtractionErrorLocation {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *ExtractionError) GetCause() string {
+	if x != nil {
+		return x.Cause
+	}
+	return ""
+}
+
+func (x *ExtractionError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ModuleVersionExtractionErrors struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ModuleName    string                 `protobuf:"bytes,1,opt,name=module_name,json=moduleName,proto3" json:"module_name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Error         []*ExtractionError     `protobuf:"bytes,4,rep,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModuleVersionExtractionErrors) Reset() {
+	*x = ModuleVersionExtractionErrors{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleVersionExtractionErrors) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleVersionExtractionErrors) ProtoMessage() {}
+
+func (x *ModuleVersionExtractionErrors) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleVersionExtractionErrors.ProtoReflect.Descriptor instead.
+func (*ModuleVersionExtractionErrors) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ModuleVersionExtractionErrors) GetModuleName() string {
+	if x != nil {
+		return x.ModuleName
+	}
+	return ""
+}
+
+func (x *ModuleVersionExtractionErrors) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ModuleVersionExtractionErrors) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ModuleVersionExtractionErrors) GetError() []*ExtractionError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ExtractionErrorGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          ExtractionErrorKind    `protobuf:"varint,1,opt,name=kind,proto3,enum=build.stack.bazel.symbol.v1.ExtractionErrorKind" json:"kind,omitempty"`
+	Cause         string                 `protobuf:"bytes,2,opt,name=cause,proto3" json:"cause,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Module        []string               `protobuf:"bytes,4,rep,name=module,proto3" json:"module,omitempty"`
+	ModuleVersion []string               `protobuf:"bytes,5,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
+	Example       []*ExtractionError     `protobuf:"bytes,6,rep,name=example,proto3" json:"example,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractionErrorGroup) Reset() {
+	*x = ExtractionErrorGroup{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractionErrorGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractionErrorGroup) ProtoMessage() {}
+
+func (x *ExtractionErrorGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractionErrorGroup.ProtoReflect.Descriptor instead.
+func (*ExtractionErrorGroup) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ExtractionErrorGroup) GetKind() ExtractionErrorKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNKNOWN
+}
+
+func (x *ExtractionErrorGroup) GetCause() string {
+	if x != nil {
+		return x.Cause
+	}
+	return ""
+}
+
+func (x *ExtractionErrorGroup) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ExtractionErrorGroup) GetModule() []string {
+	if x != nil {
+		return x.Module
+	}
+	return nil
+}
+
+func (x *ExtractionErrorGroup) GetModuleVersion() []string {
+	if x != nil {
+		return x.ModuleVersion
+	}
+	return nil
+}
+
+func (x *ExtractionErrorGroup) GetExample() []*ExtractionError {
+	if x != nil {
+		return x.Example
+	}
+	return nil
+}
+
+type ModuleRegistryExtractionErrors struct {
+	state         protoimpl.MessageState           `protogen:"open.v1"`
+	ModuleVersion []*ModuleVersionExtractionErrors `protobuf:"bytes,1,rep,name=module_version,json=moduleVersion,proto3" json:"module_version,omitempty"`
+	Group         []*ExtractionErrorGroup          `protobuf:"bytes,2,rep,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModuleRegistryExtractionErrors) Reset() {
+	*x = ModuleRegistryExtractionErrors{}
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModuleRegistryExtractionErrors) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleRegistryExtractionErrors) ProtoMessage() {}
+
+func (x *ModuleRegistryExtractionErrors) ProtoReflect() protoreflect.Message {
+	mi := &file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleRegistryExtractionErrors.ProtoReflect.Descriptor instead.
+func (*ModuleRegistryExtractionErrors) Descriptor() ([]byte, []int) {
+	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ModuleRegistryExtractionErrors) GetModuleVersion() []*ModuleVersionExtractionErrors {
+	if x != nil {
+		return x.ModuleVersion
+	}
+	return nil
+}
+
+func (x *ModuleRegistryExtractionErrors) GetGroup() []*ExtractionErrorGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+var File_build_stack_bazel_symbol_v1_symbol_proto protoreflect.FileDescriptor
+
+const file_build_stack_bazel_symbol_v1_symbol_proto_rawDesc = "" +
+	"\n" +
+	"(build/stack/bazel/symbol/v1/symbol.proto\x12\x1bbuild.stack.bazel.symbol.v1\x1a+build/stack/starlark/v1beta1/starlark.proto\"\x9b\a\n" +
+	"\x06Symbol\x12;\n" +
+	"\x04type\x18\x01 \x01(\x0e2'.build.stack.bazel.symbol.v1.SymbolTypeR\x04type\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x128\n" +
+	"\x04rule\x18\x04 \x01(\v2\".build.stack.starlark.v1beta1.RuleH\x00R\x04rule\x12<\n" +
+	"\x04func\x18\x05 \x01(\v2&.build.stack.starlark.v1beta1.FunctionH\x00R\x04func\x12D\n" +
+	"\bprovider\x18\x06 \x01(\v2&.build.stack.starlark.v1beta1.ProviderH\x00R\bprovider\x12>\n" +
+	"\x06aspect\x18\a \x01(\v2$.build.stack.starlark.v1beta1.AspectH\x00R\x06aspect\x12Z\n" +
+	"\x10module_extension\x18\t \x01(\v2-.build.stack.starlark.v1beta1.ModuleExtensionH\x00R\x0fmoduleExtension\x12W\n" +
+	"\x0frepository_rule\x18\n" +
+	" \x01(\v2,.build.stack.starlark.v1beta1.RepositoryRuleH\x00R\x0erepositoryRule\x12;\n" +
+	"\x05macro\x18\v \x01(\v2#.build.stack.starlark.v1beta1.MacroH\x00R\x05macro\x12H\n" +
+	"\n" +
+	"rule_macro\x18\f \x01(\v2'.build.stack.starlark.v1beta1.RuleMacroH\x00R\truleMacro\x12;\n" +
+	"\x05value\x18\r \x01(\v2#.build.stack.starlark.v1beta1.ValueH\x00R\x05value\x12<\n" +
+	"\x04load\x18\x0e \x01(\v2&.build.stack.starlark.v1beta1.LoadStmtH\x00R\x04load\x12>\n" +
+	"\x06struct\x18\x0f \x01(\v2$.build.stack.starlark.v1beta1.StructH\x00R\x06struct\x12!\n" +
+	"\fdisplay_name\x18\x10 \x01(\tR\vdisplayNameB\x06\n" +
+	"\x04info\"\x8f\x02\n" +
+	"\x04File\x129\n" +
+	"\x05label\x18\x01 \x01(\v2#.build.stack.starlark.v1beta1.LabelR\x05label\x12;\n" +
+	"\x06symbol\x18\x02 \x03(\v2#.build.stack.bazel.symbol.v1.SymbolR\x06symbol\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12W\n" +
+	"\x10extraction_error\x18\x06 \x01(\v2,.build.stack.bazel.symbol.v1.ExtractionErrorR\x0fextractionError\"\xcb\x01\n" +
+	"\x14ModuleVersionSymbols\x12\x1f\n" +
+	"\vmodule_name\x18\x01 \x01(\tR\n" +
+	"moduleName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x125\n" +
+	"\x04file\x18\x03 \x03(\v2!.build.stack.bazel.symbol.v1.FileR\x04file\x12A\n" +
+	"\x06source\x18\x04 \x01(\x0e2).build.stack.bazel.symbol.v1.SymbolSourceR\x06source\"q\n" +
+	"\x15ModuleRegistrySymbols\x12X\n" +
+	"\x0emodule_version\x18\x01 \x03(\v21.build.stack.bazel.symbol.v1.ModuleVersionSymbolsR\rmoduleVersion\"\xaf\x02\n" +
+	"\x15ModuleVersionPackages\x12\x1f\n" +
+	"\vmodule_name\x18\x01 \x01(\tR\n" +
+	"moduleName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12?\n" +
+	"\apackage\x18\x03 \x03(\v2%.build.stack.starlark.v1beta1.PackageR\apackage\x12A\n" +
+	"\x06source\x18\x04 \x01(\x0e2).build.stack.bazel.symbol.v1.SymbolSourceR\x06source\x12W\n" +
+	"\x10extraction_error\x18\x05 \x03(\v2,.build.stack.bazel.symbol.v1.ExtractionErrorR\x0fextractionError\"s\n" +
+	"\x16ModuleRegistryPackages\x12Y\n" +
+	"\x0emodule_version\x18\x01 \x03(\v22.build.stack.bazel.symbol.v1.ModuleVersionPackagesR\rmoduleVersion\"\xac\x01\n" +
+	"\x10FileLoadTreeNode\x125\n" +
+	"\x04file\x18\x01 \x01(\v2!.build.stack.bazel.symbol.v1.FileR\x04file\x12I\n" +
+	"\bchildren\x18\x02 \x03(\v2-.build.stack.bazel.symbol.v1.FileLoadTreeNodeR\bchildren\x12\x16\n" +
+	"\x06pruned\x18\x03 \x01(\bR\x06pruned\"S\n" +
+	"\fFileLoadTree\x12C\n" +
+	"\x05roots\x18\x01 \x03(\v2-.build.stack.bazel.symbol.v1.FileLoadTreeNodeR\x05roots\"}\n" +
+	"\aFileRef\x12\x1f\n" +
+	"\vmodule_name\x18\x01 \x01(\tR\n" +
+	"moduleName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x127\n" +
+	"\x04file\x18\x03 \x01(\v2#.build.stack.starlark.v1beta1.LabelR\x04file\"\xee\x01\n" +
+	"\x12ResolvedLoadSymbol\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12C\n" +
+	"\n" +
+	"defined_in\x18\x03 \x01(\v2$.build.stack.bazel.symbol.v1.FileRefR\tdefinedIn\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12;\n" +
+	"\x04type\x18\x05 \x01(\x0e2'.build.stack.bazel.symbol.v1.SymbolTypeR\x04type\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\x06 \x01(\bR\n" +
+	"deprecated\"\xe2\x01\n" +
+	"\fResolvedLoad\x129\n" +
+	"\x05label\x18\x01 \x01(\v2#.build.stack.starlark.v1beta1.LabelR\x05label\x128\n" +
+	"\x04file\x18\x02 \x01(\v2$.build.stack.bazel.symbol.v1.FileRefR\x04file\x12G\n" +
+	"\x06symbol\x18\x03 \x03(\v2/.build.stack.bazel.symbol.v1.ResolvedLoadSymbolR\x06symbol\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x8a\x01\n" +
+	"\x0eFileReferences\x129\n" +
+	"\x05label\x18\x01 \x01(\v2#.build.stack.starlark.v1beta1.LabelR\x05label\x12=\n" +
+	"\x04load\x18\x02 \x03(\v2).build.stack.bazel.symbol.v1.ResolvedLoadR\x04load\"\x95\x01\n" +
+	"\x17ModuleVersionReferences\x12\x1f\n" +
+	"\vmodule_name\x18\x01 \x01(\tR\n" +
+	"moduleName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12?\n" +
+	"\x04file\x18\x03 \x03(\v2+.build.stack.bazel.symbol.v1.FileReferencesR\x04file\"\xa8\x02\n" +
+	"\vSymbolUsage\x12C\n" +
+	"\n" +
+	"defined_in\x18\x01 \x01(\v2$.build.stack.bazel.symbol.v1.FileRefR\tdefinedIn\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12;\n" +
+	"\x04type\x18\x03 \x01(\x0e2'.build.stack.bazel.symbol.v1.SymbolTypeR\x04type\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\x04 \x01(\bR\n" +
+	"deprecated\x12$\n" +
+	"\x0eused_by_module\x18\x05 \x03(\tR\fusedByModule\x12=\n" +
+	"\aused_by\x18\x06 \x03(\v2$.build.stack.bazel.symbol.v1.FileRefR\x06usedBy\"\xb3\x01\n" +
+	"\x14SymbolReferenceIndex\x12[\n" +
+	"\x0emodule_version\x18\x01 \x03(\v24.build.stack.bazel.symbol.v1.ModuleVersionReferencesR\rmoduleVersion\x12>\n" +
+	"\x05usage\x18\x02 \x03(\v2(.build.stack.bazel.symbol.v1.SymbolUsageR\x05usage\"\xcc\x01\n" +
+	"\n" +
+	"DocFinding\x12?\n" +
+	"\x04kind\x18\x01 \x01(\x0e2+.build.stack.bazel.symbol.v1.DocFindingKindR\x04kind\x127\n" +
+	"\x04file\x18\x02 \x01(\v2#.build.stack.starlark.v1beta1.LabelR\x04file\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04item\x18\x04 \x01(\tR\x04item\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\"\xdb\x01\n" +
+	"\vDocCoverage\x12\x1f\n" +
+	"\vmodule_name\x18\x01 \x01(\tR\n" +
+	"moduleName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12\x1e\n" +
+	"\n" +
+	"documented\x18\x04 \x01(\x05R\n" +
+	"documented\x12\x18\n" +
+	"\apercent\x18\x05 \x01(\x02R\apercent\x12A\n" +
+	"\afinding\x18\x06 \x03(\v2'.build.stack.bazel.symbol.v1.DocFindingR\afinding\"l\n" +
+	"\x19ModuleRegistryDocCoverage\x12O\n" +
+	"\x0emodule_version\x18\x01 \x03(\v2(.build.stack.bazel.symbol.v1.DocCoverageR\rmoduleVersion\"A\n" +
+	"\x13AttributeValueCount\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\xde\x01\n" +
+	"\x0eAttributeUsage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12F\n" +
+	"\x05value\x18\x03 \x03(\v20.build.stack.bazel.symbol.v1.AttributeValueCountR\x05value\x12.\n" +
+	"\x13omitted_value_count\x18\x04 \x01(\x05R\x11omittedValueCount\x12*\n" +
+	"\x11non_literal_count\x18\x05 \x01(\x05R\x0fnonLiteralCount\"\x82\x03\n" +
+	"\tRuleUsage\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12C\n" +
+	"\n" +
+	"defined_in\x18\x02 \x01(\v2$.build.stack.bazel.symbol.v1.FileRefR\tdefinedIn\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12;\n" +
+	"\x04type\x18\x04 \x01(\x0e2'.build.stack.bazel.symbol.v1.SymbolTypeR\x04type\x12\x19\n" +
+	"\bis_macro\x18\x05 \x01(\bR\aisMacro\x12\x14\n" +
+	"\x05count\x18\x06 \x01(\x05R\x05count\x12$\n" +
+	"\x0eused_by_module\x18\a \x03(\tR\fusedByModule\x12I\n" +
+	"\tattribute\x18\b \x03(\v2+.build.stack.bazel.symbol.v1.AttributeUsageR\tattribute\x12)\n" +
+	"\x10unused_attribute\x18\t \x03(\tR\x0funusedAttribute\"L\n" +
+	"\x0eRuleUsageIndex\x12:\n" +
+	"\x04rule\x18\x01 \x03(\v2&.build.stack.bazel.symbol.v1.RuleUsageR\x04rule\"\xc1\x02\n" +
+	"\rLicenseTarget\x129\n" +
+	"\x05label\x18\x01 \x01(\v2#.build.stack.starlark.v1beta1.LabelR\x05label\x12!\n" +
+	"\flicense_kind\x18\x02 \x03(\tR\vlicenseKind\x12\x17\n" +
+	"\aspdx_id\x18\x03 \x03(\tR\x06spdxId\x12!\n" +
+	"\flicense_text\x18\x04 \x01(\tR\vlicenseText\x12!\n" +
+	"\fpackage_name\x18\x05 \x01(\tR\vpackageName\x12\x1f\n" +
+	"\vpackage_url\x18\x06 \x01(\tR\n" +
+	"packageUrl\x12'\n" +
+	"\x0fpackage_version\x18\a \x01(\tR\x0epackageVersion\x12)\n" +
+	"\x10copyright_notice\x18\b \x01(\tR\x0fcopyrightNotice\"\x9b\x01\n" +
+	"\x10PackageLicensing\x12\x18\n" +
+	"\apackage\x18\x01 \x01(\tR\apackage\x12>\n" +
+	"\x1bdefault_applicable_licenses\x18\x02 \x03(\tR\x19defaultApplicableLicenses\x12-\n" +
+	"\x12default_visibility\x18\x03 \x03(\tR\x11defaultVisibility\"\xfa\x01\n" +
+	"\x15ModuleVersionLicenses\x12\x1f\n" +
+	"\vmodule_name\x18\x01 \x01(\tR\n" +
+	"moduleName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12D\n" +
+	"\alicense\x18\x03 \x03(\v2*.build.stack.bazel.symbol.v1.LicenseTargetR\alicense\x12G\n" +
+	"\apackage\x18\x04 \x03(\v2-.build.stack.bazel.symbol.v1.PackageLicensingR\apackage\x12\x17\n" +
+	"\aspdx_id\x18\x05 \x03(\tR\x06spdxId\"W\n" +
+	"\x15LicenseInventoryEntry\x12\x17\n" +
+	"\aspdx_id\x18\x01 \x01(\tR\x06spdxId\x12%\n" +
+	"\x0emodule_version\x18\x02 \x03(\tR\rmoduleVersion\"\xc5\x01\n" +
+	"\x16ModuleRegistryLicenses\x12Y\n" +
+	"\x0emodule_version\x18\x01 \x03(\v22.build.stack.bazel.symbol.v1.ModuleVersionLicensesR\rmoduleVersion\x12P\n" +
+	"\tinventory\x18\x02 \x03(\v22.build.stack.bazel.symbol.v1.LicenseInventoryEntryR\tinventory\"Y\n" +
+	"\x17ExtractionErrorLocation\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x12\n" +
+	"\x04line\x18\x02 \x01(\x05R\x04line\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\x05R\x06column\"\xef\x01\n" +
+	"\x0fExtractionError\x12D\n" +
+	"\x04kind\x18\x01 \x01(\x0e20.build.stack.bazel.symbol.v1.ExtractionErrorKindR\x04kind\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12P\n" +
+	"\blocation\x18\x03 \x01(\v24.build.stack.bazel.symbol.v1.ExtractionErrorLocationR\blocation\x12\x14\n" +
+	"\x05cause\x18\x04 \x01(\tR\x05cause\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\"\xb4\x01\n" +
+	"\x1dModuleVersionExtractionErrors\x12\x1f\n" +
+	"\vmodule_name\x18\x01 \x01(\tR\n" +
+	"moduleName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12B\n" +
+	"\x05error\x18\x04 \x03(\v2,.build.stack.bazel.symbol.v1.ExtractionErrorR\x05error\"\x8f\x02\n" +
+	"\x14ExtractionErrorGroup\x12D\n" +
+	"\x04kind\x18\x01 \x01(\x0e20.build.stack.bazel.symbol.v1.ExtractionErrorKindR\x04kind\x12\x14\n" +
+	"\x05cause\x18\x02 \x01(\tR\x05cause\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12\x16\n" +
+	"\x06module\x18\x04 \x03(\tR\x06module\x12%\n" +
+	"\x0emodule_version\x18\x05 \x03(\tR\rmoduleVersion\x12F\n" +
+	"\aexample\x18\x06 \x03(\v2,.build.stack.bazel.symbol.v1.ExtractionErrorR\aexample\"\xcc\x01\n" +
+	"\x1eModuleRegistryExtractionErrors\x12a\n" +
+	"\x0emodule_version\x18\x01 \x03(\v2:.build.stack.bazel.symbol.v1.ModuleVersionExtractionErrorsR\rmoduleVersion\x12G\n" +
+	"\x05group\x18\x02 \x03(\v21.build.stack.bazel.symbol.v1.ExtractionErrorGroupR\x05group*\xc7\x02\n" +
 	"\n" +
 	"SymbolType\x12\x17\n" +
 	"\x13SYMBOL_TYPE_UNKNOWN\x10\x00\x12\x14\n" +
@@ -870,7 +2766,22 @@ const file_build_stack_bazel_symbol_v1_symbol_proto_rawDesc = "" +
 	"\fSymbolSource\x12\x19\n" +
 	"\x15SYMBOL_SOURCE_UNKNOWN\x10\x00\x12\r\n" +
 	"\tPUBLISHED\x10\x01\x12\x0f\n" +
-	"\vBEST_EFFORT\x10\x02BIZGgithub.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1;sympbb\x06proto3"
+	"\vBEST_EFFORT\x10\x02*\xaf\x02\n" +
+	"\x0eDocFindingKind\x12\x1c\n" +
+	"\x18DOC_FINDING_KIND_UNKNOWN\x10\x00\x12&\n" +
+	"\"DOC_FINDING_KIND_MISSING_DOCSTRING\x10\x01\x12+\n" +
+	"'DOC_FINDING_KIND_UNDOCUMENTED_ATTRIBUTE\x10\x02\x12'\n" +
+	"#DOC_FINDING_KIND_UNDOCUMENTED_PARAM\x10\x03\x120\n" +
+	",DOC_FINDING_KIND_UNDOCUMENTED_PROVIDER_FIELD\x10\x04\x12-\n" +
+	")DOC_FINDING_KIND_UNKNOWN_DOCUMENTED_PARAM\x10\x05\x12 \n" +
+	"\x1cDOC_FINDING_KIND_BROKEN_LINK\x10\x06*\x90\x02\n" +
+	"\x13ExtractionErrorKind\x12!\n" +
+	"\x1dEXTRACTION_ERROR_KIND_UNKNOWN\x10\x00\x12-\n" +
+	")EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET\x10\x01\x12-\n" +
+	")EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN\x10\x02\x12&\n" +
+	"\"EXTRACTION_ERROR_KIND_SYNTAX_ERROR\x10\x03\x12(\n" +
+	"$EXTRACTION_ERROR_KIND_SERVER_TIMEOUT\x10\x04\x12&\n" +
+	"\"EXTRACTION_ERROR_KIND_SHIM_MISSING\x10\x05BIZGgithub.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1;sympbb\x06proto3"
 
 var (
 	file_build_stack_bazel_symbol_v1_symbol_proto_rawDescOnce sync.Once
@@ -884,62 +2795,125 @@ func file_build_stack_bazel_symbol_v1_symbol_proto_rawDescGZIP() []byte {
 	return file_build_stack_bazel_symbol_v1_symbol_proto_rawDescData
 }
 
-var file_build_stack_bazel_symbol_v1_symbol_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_build_stack_bazel_symbol_v1_symbol_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_build_stack_bazel_symbol_v1_symbol_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
 var file_build_stack_bazel_symbol_v1_symbol_proto_goTypes = []any{
-	(SymbolType)(0),                 // 0: build.stack.bazel.symbol.v1.SymbolType
-	(SymbolSource)(0),               // 1: build.stack.bazel.symbol.v1.SymbolSource
-	(*Symbol)(nil),                  // 2: build.stack.bazel.symbol.v1.Symbol
-	(*File)(nil),                    // 3: build.stack.bazel.symbol.v1.File
-	(*ModuleVersionSymbols)(nil),    // 4: build.stack.bazel.symbol.v1.ModuleVersionSymbols
-	(*ModuleRegistrySymbols)(nil),   // 5: build.stack.bazel.symbol.v1.ModuleRegistrySymbols
-	(*ModuleVersionPackages)(nil),   // 6: build.stack.bazel.symbol.v1.ModuleVersionPackages
-	(*ModuleRegistryPackages)(nil),  // 7: build.stack.bazel.symbol.v1.ModuleRegistryPackages
-	(*FileLoadTreeNode)(nil),        // 8: build.stack.bazel.symbol.v1.FileLoadTreeNode
-	(*FileLoadTree)(nil),            // 9: build.stack.bazel.symbol.v1.FileLoadTree
-	(*v1beta1.Rule)(nil),            // 10: build.stack.starlark.v1beta1.Rule
-	(*v1beta1.Function)(nil),        // 11: build.stack.starlark.v1beta1.Function
-	(*v1beta1.Provider)(nil),        // 12: build.stack.starlark.v1beta1.Provider
-	(*v1beta1.Aspect)(nil),          // 13: build.stack.starlark.v1beta1.Aspect
-	(*v1beta1.ModuleExtension)(nil), // 14: build.stack.starlark.v1beta1.ModuleExtension
-	(*v1beta1.RepositoryRule)(nil),  // 15: build.stack.starlark.v1beta1.RepositoryRule
-	(*v1beta1.Macro)(nil),           // 16: build.stack.starlark.v1beta1.Macro
-	(*v1beta1.RuleMacro)(nil),       // 17: build.stack.starlark.v1beta1.RuleMacro
-	(*v1beta1.Value)(nil),           // 18: build.stack.starlark.v1beta1.Value
-	(*v1beta1.LoadStmt)(nil),        // 19: build.stack.starlark.v1beta1.LoadStmt
-	(*v1beta1.Struct)(nil),          // 20: build.stack.starlark.v1beta1.Struct
-	(*v1beta1.Label)(nil),           // 21: build.stack.starlark.v1beta1.Label
-	(*v1beta1.Package)(nil),         // 22: build.stack.starlark.v1beta1.Package
+	(SymbolType)(0),                        // 0: build.stack.bazel.symbol.v1.SymbolType
+	(SymbolSource)(0),                      // 1: build.stack.bazel.symbol.v1.SymbolSource
+	(DocFindingKind)(0),                    // 2: build.stack.bazel.symbol.v1.DocFindingKind
+	(ExtractionErrorKind)(0),               // 3: build.stack.bazel.symbol.v1.ExtractionErrorKind
+	(*Symbol)(nil),                         // 4: build.stack.bazel.symbol.v1.Symbol
+	(*File)(nil),                           // 5: build.stack.bazel.symbol.v1.File
+	(*ModuleVersionSymbols)(nil),           // 6: build.stack.bazel.symbol.v1.ModuleVersionSymbols
+	(*ModuleRegistrySymbols)(nil),          // 7: build.stack.bazel.symbol.v1.ModuleRegistrySymbols
+	(*ModuleVersionPackages)(nil),          // 8: build.stack.bazel.symbol.v1.ModuleVersionPackages
+	(*ModuleRegistryPackages)(nil),         // 9: build.stack.bazel.symbol.v1.ModuleRegistryPackages
+	(*FileLoadTreeNode)(nil),               // 10: build.stack.bazel.symbol.v1.FileLoadTreeNode
+	(*FileLoadTree)(nil),                   // 11: build.stack.bazel.symbol.v1.FileLoadTree
+	(*FileRef)(nil),                        // 12: build.stack.bazel.symbol.v1.FileRef
+	(*ResolvedLoadSymbol)(nil),             // 13: build.stack.bazel.symbol.v1.ResolvedLoadSymbol
+	(*ResolvedLoad)(nil),                   // 14: build.stack.bazel.symbol.v1.ResolvedLoad
+	(*FileReferences)(nil),                 // 15: build.stack.bazel.symbol.v1.FileReferences
+	(*ModuleVersionReferences)(nil),        // 16: build.stack.bazel.symbol.v1.ModuleVersionReferences
+	(*SymbolUsage)(nil),                    // 17: build.stack.bazel.symbol.v1.SymbolUsage
+	(*SymbolReferenceIndex)(nil),           // 18: build.stack.bazel.symbol.v1.SymbolReferenceIndex
+	(*DocFinding)(nil),                     // 19: build.stack.bazel.symbol.v1.DocFinding
+	(*DocCoverage)(nil),                    // 20: build.stack.bazel.symbol.v1.DocCoverage
+	(*ModuleRegistryDocCoverage)(nil),      // 21: build.stack.bazel.symbol.v1.ModuleRegistryDocCoverage
+	(*AttributeValueCount)(nil),            // 22: build.stack.bazel.symbol.v1.AttributeValueCount
+	(*AttributeUsage)(nil),                 // 23: build.stack.bazel.symbol.v1.AttributeUsage
+	(*RuleUsage)(nil),                      // 24: build.stack.bazel.symbol.v1.RuleUsage
+	(*RuleUsageIndex)(nil),                 // 25: build.stack.bazel.symbol.v1.RuleUsageIndex
+	(*LicenseTarget)(nil),                  // 26: build.stack.bazel.symbol.v1.LicenseTarget
+	(*PackageLicensing)(nil),               // 27: build.stack.bazel.symbol.v1.PackageLicensing
+	(*ModuleVersionLicenses)(nil),          // 28: build.stack.bazel.symbol.v1.ModuleVersionLicenses
+	(*LicenseInventoryEntry)(nil),          // 29: build.stack.bazel.symbol.v1.LicenseInventoryEntry
+	(*ModuleRegistryLicenses)(nil),         // 30: build.stack.bazel.symbol.v1.ModuleRegistryLicenses
+	(*ExtractionErrorLocation)(nil),        // 31: build.stack.bazel.symbol.v1.ExtractionErrorLocation
+	(*ExtractionError)(nil),                // 32: build.stack.bazel.symbol.v1.ExtractionError
+	(*ModuleVersionExtractionErrors)(nil),  // 33: build.stack.bazel.symbol.v1.ModuleVersionExtractionErrors
+	(*ExtractionErrorGroup)(nil),           // 34: build.stack.bazel.symbol.v1.ExtractionErrorGroup
+	(*ModuleRegistryExtractionErrors)(nil), // 35: build.stack.bazel.symbol.v1.ModuleRegistryExtractionErrors
+	(*v1beta1.Rule)(nil),                   // 36: build.stack.starlark.v1beta1.Rule
+	(*v1beta1.Function)(nil),               // 37: build.stack.starlark.v1beta1.Function
+	(*v1beta1.Provider)(nil),               // 38: build.stack.starlark.v1beta1.Provider
+	(*v1beta1.Aspect)(nil),                 // 39: build.stack.starlark.v1beta1.Aspect
+	(*v1beta1.ModuleExtension)(nil),        // 40: build.stack.starlark.v1beta1.ModuleExtension
+	(*v1beta1.RepositoryRule)(nil),         // 41: build.stack.starlark.v1beta1.RepositoryRule
+	(*v1beta1.Macro)(nil),                  // 42: build.stack.starlark.v1beta1.Macro
+	(*v1beta1.RuleMacro)(nil),              // 43: build.stack.starlark.v1beta1.RuleMacro
+	(*v1beta1.Value)(nil),                  // 44: build.stack.starlark.v1beta1.Value
+	(*v1beta1.LoadStmt)(nil),               // 45: build.stack.starlark.v1beta1.LoadStmt
+	(*v1beta1.Struct)(nil),                 // 46: build.stack.starlark.v1beta1.Struct
+	(*v1beta1.Label)(nil),                  // 47: build.stack.starlark.v1beta1.Label
+	(*v1beta1.Package)(nil),                // 48: build.stack.starlark.v1beta1.Package
 }
 var file_build_stack_bazel_symbol_v1_symbol_proto_depIdxs = []int32{
 	0,  // 0: build.stack.bazel.symbol.v1.Symbol.type:type_name -> build.stack.bazel.symbol.v1.SymbolType
-	10, // 1: build.stack.bazel.symbol.v1.Symbol.rule:type_name -> build.stack.starlark.v1beta1.Rule
-	11, // 2: build.stack.bazel.symbol.v1.Symbol.func:type_name -> build.stack.starlark.v1beta1.Function
-	12, // 3: build.stack.bazel.symbol.v1.Symbol.provider:type_name -> build.stack.starlark.v1beta1.Provider
-	13, // 4: build.stack.bazel.symbol.v1.Symbol.aspect:type_name -> build.stack.starlark.v1beta1.Aspect
-	14, // 5: build.stack.bazel.symbol.v1.Symbol.module_extension:type_name -> build.stack.starlark.v1beta1.ModuleExtension
-	15, // 6: build.stack.bazel.symbol.v1.Symbol.repository_rule:type_name -> build.stack.starlark.v1beta1.RepositoryRule
-	16, // 7: build.stack.bazel.symbol.v1.Symbol.macro:type_name -> build.stack.starlark.v1beta1.Macro
-	17, // 8: build.stack.bazel.symbol.v1.Symbol.rule_macro:type_name -> build.stack.starlark.v1beta1.RuleMacro
-	18, // 9: build.stack.bazel.symbol.v1.Symbol.value:type_name -> build.stack.starlark.v1beta1.Value
-	19, // 10: build.stack.bazel.symbol.v1.Symbol.load:type_name -> build.stack.starlark.v1beta1.LoadStmt
-	20, // 11: build.stack.bazel.symbol.v1.Symbol.struct:type_name -> build.stack.starlark.v1beta1.Struct
-	21, // 12: build.stack.bazel.symbol.v1.File.label:type_name -> build.stack.starlark.v1beta1.Label
-	2,  // 13: build.stack.bazel.symbol.v1.File.symbol:type_name -> build.stack.bazel.symbol.v1.Symbol
-	3,  // 14: build.stack.bazel.symbol.v1.ModuleVersionSymbols.file:type_name -> build.stack.bazel.symbol.v1.File
-	1,  // 15: build.stack.bazel.symbol.v1.ModuleVersionSymbols.source:type_name -> build.stack.bazel.symbol.v1.SymbolSource
-	4,  // 16: build.stack.bazel.symbol.v1.ModuleRegistrySymbols.module_version:type_name -> build.stack.bazel.symbol.v1.ModuleVersionSymbols
-	22, // 17: build.stack.bazel.symbol.v1.ModuleVersionPackages.package:type_name -> build.stack.starlark.v1beta1.Package
-	1,  // 18: build.stack.bazel.symbol.v1.ModuleVersionPackages.source:type_name -> build.stack.bazel.symbol.v1.SymbolSource
-	6,  // 19: build.stack.bazel.symbol.v1.ModuleRegistryPackages.module_version:type_name -> build.stack.bazel.symbol.v1.ModuleVersionPackages
-	3,  // 20: build.stack.bazel.symbol.v1.FileLoadTreeNode.file:type_name -> build.stack.bazel.symbol.v1.File
-	8,  // 21: build.stack.bazel.symbol.v1.FileLoadTreeNode.children:type_name -> build.stack.bazel.symbol.v1.FileLoadTreeNode
-	8,  // 22: build.stack.bazel.symbol.v1.FileLoadTree.roots:type_name -> build.stack.bazel.symbol.v1.FileLoadTreeNode
-	23, // [23:23] is the sub-list for method output_type
-	23, // [23:23] is the sub-list for method input_type
-	23, // [23:23] is the sub-list for extension type_name
-	23, // [23:23] is the sub-list for extension extendee
-	0,  // [0:23] is the sub-list for field type_name
+	36, // 1: build.stack.bazel.symbol.v1.Symbol.rule:type_name -> build.stack.starlark.v1beta1.Rule
+	37, // 2: build.stack.bazel.symbol.v1.Symbol.func:type_name -> build.stack.starlark.v1beta1.Function
+	38, // 3: build.stack.bazel.symbol.v1.Symbol.provider:type_name -> build.stack.starlark.v1beta1.Provider
+	39, // 4: build.stack.bazel.symbol.v1.Symbol.aspect:type_name -> build.stack.starlark.v1beta1.Aspect
+	40, // 5: build.stack.bazel.symbol.v1.Symbol.module_extension:type_name -> build.stack.starlark.v1beta1.ModuleExtension
+	41, // 6: build.stack.bazel.symbol.v1.Symbol.repository_rule:type_name -> build.stack.starlark.v1beta1.RepositoryRule
+	42, // 7: build.stack.bazel.symbol.v1.Symbol.macro:type_name -> build.stack.starlark.v1beta1.Macro
+	43, // 8: build.stack.bazel.symbol.v1.Symbol.rule_macro:type_name -> build.stack.starlark.v1beta1.RuleMacro
+	44, // 9: build.stack.bazel.symbol.v1.Symbol.value:type_name -> build.stack.starlark.v1beta1.Value
+	45, // 10: build.stack.bazel.symbol.v1.Symbol.load:type_name -> build.stack.starlark.v1beta1.LoadStmt
+	46, // 11: build.stack.bazel.symbol.v1.Symbol.struct:type_name -> build.stack.starlark.v1beta1.Struct
+	47, // 12: build.stack.bazel.symbol.v1.File.label:type_name -> build.stack.starlark.v1beta1.Label
+	4,  // 13: build.stack.bazel.symbol.v1.File.symbol:type_name -> build.stack.bazel.symbol.v1.Symbol
+	32, // 14: build.stack.bazel.symbol.v1.File.extraction_error:type_name -> build.stack.bazel.symbol.v1.ExtractionError
+	5,  // 15: build.stack.bazel.symbol.v1.ModuleVersionSymbols.file:type_name -> build.stack.bazel.symbol.v1.File
+	1,  // 16: build.stack.bazel.symbol.v1.ModuleVersionSymbols.source:type_name -> build.stack.bazel.symbol.v1.SymbolSource
+	6,  // 17: build.stack.bazel.symbol.v1.ModuleRegistrySymbols.module_version:type_name -> build.stack.bazel.symbol.v1.ModuleVersionSymbols
+	48, // 18: build.stack.bazel.symbol.v1.ModuleVersionPackages.package:type_name -> build.stack.starlark.v1beta1.Package
+	1,  // 19: build.stack.bazel.symbol.v1.ModuleVersionPackages.source:type_name -> build.stack.bazel.symbol.v1.SymbolSource
+	32, // 20: build.stack.bazel.symbol.v1.ModuleVersionPackages.extraction_error:type_name -> build.stack.bazel.symbol.v1.ExtractionError
+	8,  // 21: build.stack.bazel.symbol.v1.ModuleRegistryPackages.module_version:type_name -> build.stack.bazel.symbol.v1.ModuleVersionPackages
+	5,  // 22: build.stack.bazel.symbol.v1.FileLoadTreeNode.file:type_name -> build.stack.bazel.symbol.v1.File
+	10, // 23: build.stack.bazel.symbol.v1.FileLoadTreeNode.children:type_name -> build.stack.bazel.symbol.v1.FileLoadTreeNode
+	10, // 24: build.stack.bazel.symbol.v1.FileLoadTree.roots:type_name -> build.stack.bazel.symbol.v1.FileLoadTreeNode
+	47, // 25: build.stack.bazel.symbol.v1.FileRef.file:type_name -> build.stack.starlark.v1beta1.Label
+	12, // 26: build.stack.bazel.symbol.v1.ResolvedLoadSymbol.defined_in:type_name -> build.stack.bazel.symbol.v1.FileRef
+	0,  // 27: build.stack.bazel.symbol.v1.ResolvedLoadSymbol.type:type_name -> build.stack.bazel.symbol.v1.SymbolType
+	47, // 28: build.stack.bazel.symbol.v1.ResolvedLoad.label:type_name -> build.stack.starlark.v1beta1.Label
+	12, // 29: build.stack.bazel.symbol.v1.ResolvedLoad.file:type_name -> build.stack.bazel.symbol.v1.FileRef
+	13, // 30: build.stack.bazel.symbol.v1.ResolvedLoad.symbol:type_name -> build.stack.bazel.symbol.v1.ResolvedLoadSymbol
+	47, // 31: build.stack.bazel.symbol.v1.FileReferences.label:type_name -> build.stack.starlark.v1beta1.Label
+	14, // 32: build.stack.bazel.symbol.v1.FileReferences.load:type_name -> build.stack.bazel.symbol.v1.ResolvedLoad
+	15, // 33: build.stack.bazel.symbol.v1.ModuleVersionReferences.file:type_name -> build.stack.bazel.symbol.v1.FileReferences
+	12, // 34: build.stack.bazel.symbol.v1.SymbolUsage.defined_in:type_name -> build.stack.bazel.symbol.v1.FileRef
+	0,  // 35: build.stack.bazel.symbol.v1.SymbolUsage.type:type_name -> build.stack.bazel.symbol.v1.SymbolType
+	12, // 36: build.stack.bazel.symbol.v1.SymbolUsage.used_by:type_name -> build.stack.bazel.symbol.v1.FileRef
+	16, // 37: build.stack.bazel.symbol.v1.SymbolReferenceIndex.module_version:type_name -> build.stack.bazel.symbol.v1.ModuleVersionReferences
+	17, // 38: build.stack.bazel.symbol.v1.SymbolReferenceIndex.usage:type_name -> build.stack.bazel.symbol.v1.SymbolUsage
+	2,  // 39: build.stack.bazel.symbol.v1.DocFinding.kind:type_name -> build.stack.bazel.symbol.v1.DocFindingKind
+	47, // 40: build.stack.bazel.symbol.v1.DocFinding.file:type_name -> build.stack.starlark.v1beta1.Label
+	19, // 41: build.stack.bazel.symbol.v1.DocCoverage.finding:type_name -> build.stack.bazel.symbol.v1.DocFinding
+	20, // 42: build.stack.bazel.symbol.v1.ModuleRegistryDocCoverage.module_version:type_name -> build.stack.bazel.symbol.v1.DocCoverage
+	22, // 43: build.stack.bazel.symbol.v1.AttributeUsage.value:type_name -> build.stack.bazel.symbol.v1.AttributeValueCount
+	12, // 44: build.stack.bazel.symbol.v1.RuleUsage.defined_in:type_name -> build.stack.bazel.symbol.v1.FileRef
+	0,  // 45: build.stack.bazel.symbol.v1.RuleUsage.type:type_name -> build.stack.bazel.symbol.v1.SymbolType
+	23, // 46: build.stack.bazel.symbol.v1.RuleUsage.attribute:type_name -> build.stack.bazel.symbol.v1.AttributeUsage
+	24, // 47: build.stack.bazel.symbol.v1.RuleUsageIndex.rule:type_name -> build.stack.bazel.symbol.v1.RuleUsage
+	47, // 48: build.stack.bazel.symbol.v1.LicenseTarget.label:type_name -> build.stack.starlark.v1beta1.Label
+	26, // 49: build.stack.bazel.symbol.v1.ModuleVersionLicenses.license:type_name -> build.stack.bazel.symbol.v1.LicenseTarget
+	27, // 50: build.stack.bazel.symbol.v1.ModuleVersionLicenses.package:type_name -> build.stack.bazel.symbol.v1.PackageLicensing
+	28, // 51: build.stack.bazel.symbol.v1.ModuleRegistryLicenses.module_version:type_name -> build.stack.bazel.symbol.v1.ModuleVersionLicenses
+	29, // 52: build.stack.bazel.symbol.v1.ModuleRegistryLicenses.inventory:type_name -> build.stack.bazel.symbol.v1.LicenseInventoryEntry
+	3,  // 53: build.stack.bazel.symbol.v1.ExtractionError.kind:type_name -> build.stack.bazel.symbol.v1.ExtractionErrorKind
+	31, // 54: build.stack.bazel.symbol.v1.ExtractionError.location:type_name -> build.stack.bazel.symbol.v1.ExtractionErrorLocation
+	32, // 55: build.stack.bazel.symbol.v1.ModuleVersionExtractionErrors.error:type_name -> build.stack.bazel.symbol.v1.ExtractionError
+	3,  // 56: build.stack.bazel.symbol.v1.ExtractionErrorGroup.kind:type_name -> build.stack.bazel.symbol.v1.ExtractionErrorKind
+	32, // 57: build.stack.bazel.symbol.v1.ExtractionErrorGroup.example:type_name -> build.stack.bazel.symbol.v1.ExtractionError
+	33, // 58: build.stack.bazel.symbol.v1.ModuleRegistryExtractionErrors.module_version:type_name -> build.stack.bazel.symbol.v1.ModuleVersionExtractionErrors
+	34, // 59: build.stack.bazel.symbol.v1.ModuleRegistryExtractionErrors.group:type_name -> build.stack.bazel.symbol.v1.ExtractionErrorGroup
+	60, // [60:60] is the sub-list for method output_type
+	60, // [60:60] is the sub-list for method input_type
+	60, // [60:60] is the sub-list for extension type_name
+	60, // [60:60] is the sub-list for extension extendee
+	0,  // [0:60] is the sub-list for field type_name
 }
 
 func init() { file_build_stack_bazel_symbol_v1_symbol_proto_init() }
@@ -965,8 +2939,8 @@ func file_build_stack_bazel_symbol_v1_symbol_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_build_stack_bazel_symbol_v1_symbol_proto_rawDesc), len(file_build_stack_bazel_symbol_v1_symbol_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   8,
+			NumEnums:      4,
+			NumMessages:   32,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
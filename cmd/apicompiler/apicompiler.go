@@ -0,0 +1,281 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	bhpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/help/v1"
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+const toolName = "apicompiler"
+
+// apiDir is the tarball directory of the static API tree.
+const apiDir = "api"
+
+type Config struct {
+	OutputFile      string
+	RegistryFile    string
+	SymbolsFile     string
+	BazelFlagDbFile string
+}
+
+// document is a single API resource. Path is the tarball path without
+// extension; both the .json and .pb encodings are written there.
+type document struct {
+	path    string
+	message proto.Message
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.RegistryFile == "" {
+		return fmt.Errorf("registry_file is required")
+	}
+
+	var registry bzpb.Registry
+	if err := protoutil.ReadFile(cfg.RegistryFile, &registry); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.RegistryFile, err)
+	}
+	var symbols sympb.ModuleRegistrySymbols
+	if cfg.SymbolsFile != "" {
+		if err := protoutil.ReadFile(cfg.SymbolsFile, &symbols); err != nil {
+			return fmt.Errorf("reading %s: %v", cfg.SymbolsFile, err)
+		}
+	}
+	var flagDb bhpb.BazelFlagDb
+	if cfg.BazelFlagDbFile != "" {
+		if err := protoutil.ReadFile(cfg.BazelFlagDbFile, &flagDb); err != nil {
+			return fmt.Errorf("reading %s: %v", cfg.BazelFlagDbFile, err)
+		}
+	}
+
+	docs := buildDocuments(&registry, &symbols, &flagDb)
+
+	tarball, err := writeAPITar(docs)
+	if err != nil {
+		return fmt.Errorf("failed to create api tarball: %v", err)
+	}
+
+	if err := os.WriteFile(cfg.OutputFile, tarball, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	log.Printf("Compiled %d API documents", len(docs))
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the tar file of API documents to write")
+	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file to read")
+	fs.StringVar(&cfg.SymbolsFile, "symbols_file", "", "the ModuleRegistrySymbols protobuf file to read (optional)")
+	fs.StringVar(&cfg.BazelFlagDbFile, "bazel_flag_db_file", "", "the BazelFlagDb protobuf file to read (optional)")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+// buildDocuments returns every document of the API tree, the index last.
+func buildDocuments(registry *bzpb.Registry, symbols *sympb.ModuleRegistrySymbols, flagDb *bhpb.BazelFlagDb) []*document {
+	var docs []*document
+	index := &bzpb.ApiIndex{
+		CommitSha:   registry.CommitSha,
+		CommitDate:  registry.CommitDate,
+		RegistryUrl: registry.RegistryUrl,
+	}
+
+	symbolsByID := make(map[string]*sympb.ModuleVersionSymbols)
+	for _, mvs := range symbols.ModuleVersion {
+		symbolsByID[mvs.ModuleName+"@"+mvs.Version] = mvs
+	}
+
+	modules := slices.Clone(registry.Modules)
+	slices.SortFunc(modules, func(a, b *bzpb.Module) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for _, module := range modules {
+		entry := &bzpb.ApiModuleEntry{Name: module.Name}
+		for i, mv := range module.Versions {
+			entry.Version = append(entry.Version, mv.Version)
+			if mv.IsLatestVersion || (i == 0 && entry.LatestVersion == "") {
+				entry.LatestVersion = mv.Version
+			}
+			docs = append(docs, &document{
+				path:    modulePath(module.Name, mv.Version),
+				message: mv,
+			})
+			if mvs, ok := symbolsByID[module.Name+"@"+mv.Version]; ok {
+				entry.SymbolsVersion = append(entry.SymbolsVersion, mv.Version)
+				docs = append(docs, &document{
+					path:    path.Join(apiDir, "symbols", escape(module.Name), escape(mv.Version)),
+					message: mvs,
+				})
+			}
+		}
+		docs = append(docs, &document{
+			path:    path.Join(apiDir, "modules", escape(module.Name)),
+			message: moduleSummary(module),
+		})
+		index.Module = append(index.Module, entry)
+	}
+
+	for _, flag := range flagDb.Flag {
+		docs = append(docs, &document{
+			path:    path.Join(apiDir, "flags", escape(flag.Name)),
+			message: flagDetail(flagDb, flag),
+		})
+		index.Flag = append(index.Flag, flag.Name)
+	}
+	slices.Sort(index.Flag)
+
+	docs = append(docs, &document{path: path.Join(apiDir, "index"), message: index})
+	return docs
+}
+
+func modulePath(name, version string) string {
+	return path.Join(apiDir, "modules", escape(name), escape(version))
+}
+
+// escape makes a name safe for use as a single path segment.
+func escape(s string) string {
+	return url.PathEscape(s)
+}
+
+// moduleSummary returns a copy of module whose versions carry only the
+// fields needed to choose one; the full version is a separate document.
+func moduleSummary(module *bzpb.Module) *bzpb.Module {
+	summary := &bzpb.Module{
+		Name:               module.Name,
+		Metadata:           module.Metadata,
+		RepositoryMetadata: module.RepositoryMetadata,
+	}
+	for _, mv := range module.Versions {
+		summary.Versions = append(summary.Versions, &bzpb.ModuleVersion{
+			Name:               mv.Name,
+			Version:            mv.Version,
+			CompatibilityLevel: mv.CompatibilityLevel,
+			BazelCompatibility: mv.BazelCompatibility,
+			Commit:             mv.Commit,
+			IsLatestVersion:    mv.IsLatestVersion,
+		})
+	}
+	return summary
+}
+
+// flagDetail resolves the version and command indices of flag.
+func flagDetail(db *bhpb.BazelFlagDb, flag *bhpb.BazelFlag) *bhpb.BazelFlagDetail {
+	detail := &bhpb.BazelFlagDetail{Flag: flag}
+	for _, i := range flag.VersionIndex {
+		if int(i) < len(db.BazelVersions) {
+			detail.BazelVersion = append(detail.BazelVersion, db.BazelVersions[i])
+		}
+	}
+	for _, i := range flag.CommandIndex {
+		if int(i) < len(db.Commands) {
+			detail.Command = append(detail.Command, db.Commands[i])
+		}
+	}
+	slices.Sort(detail.Command)
+	return detail
+}
+
+func writeAPITar(docs []*document) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	for _, doc := range docs {
+		data, err := marshalJSON(doc.message)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s.json: %v", doc.path, err)
+		}
+		if err := addFileToTar(tw, doc.path+".json", data); err != nil {
+			return nil, fmt.Errorf("adding %s.json: %v", doc.path, err)
+		}
+		data, err = proto.MarshalOptions{Deterministic: true}.Marshal(doc.message)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s.pb: %v", doc.path, err)
+		}
+		if err := addFileToTar(tw, doc.path+".pb", data); err != nil {
+			return nil, fmt.Errorf("adding %s.pb: %v", doc.path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close tar writer: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalJSON encodes msg as compact JSON. protojson deliberately varies its
+// whitespace between runs; compacting makes the output byte-stable so
+// unchanged documents keep their hashes across releases.
+func marshalJSON(msg proto.Message) ([]byte, error) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func addFileToTar(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(content)),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	bhpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/help/v1"
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+)
+
+func newTestRegistry() *bzpb.Registry {
+	return &bzpb.Registry{
+		CommitSha: "abc123",
+		Modules: []*bzpb.Module{
+			{
+				Name: "rules_foo",
+				Versions: []*bzpb.ModuleVersion{
+					{Name: "rules_foo", Version: "2.0.0", CompatibilityLevel: 2, Deps: []*bzpb.ModuleDependency{{Name: "rules_bar", Version: "0.1.0"}}},
+					{Name: "rules_foo", Version: "1.0.0", IsLatestVersion: true},
+				},
+			},
+			{
+				Name:     "rules_bar",
+				Versions: []*bzpb.ModuleVersion{{Name: "rules_bar", Version: "0.1.0"}},
+			},
+		},
+	}
+}
+
+func docPaths(docs []*document) []string {
+	var paths []string
+	for _, d := range docs {
+		paths = append(paths, d.path)
+	}
+	return paths
+}
+
+func TestBuildDocuments(t *testing.T) {
+	symbols := &sympb.ModuleRegistrySymbols{
+		ModuleVersion: []*sympb.ModuleVersionSymbols{{ModuleName: "rules_foo", Version: "2.0.0"}},
+	}
+	flagDb := &bhpb.BazelFlagDb{
+		BazelVersions: []string{"7.0.0", "8.0.0"},
+		Commands:      []string{"build", "test"},
+		Flag: []*bhpb.BazelFlag{
+			{Name: "jobs", VersionIndex: []int32{1, 0}, CommandIndex: []int32{1, 0, 9}},
+		},
+	}
+
+	docs := buildDocuments(newTestRegistry(), symbols, flagDb)
+
+	want := []string{
+		"api/modules/rules_bar/0.1.0",
+		"api/modules/rules_bar",
+		"api/modules/rules_foo/2.0.0",
+		"api/symbols/rules_foo/2.0.0",
+		"api/modules/rules_foo/1.0.0",
+		"api/modules/rules_foo",
+		"api/flags/jobs",
+		"api/index",
+	}
+	if got := docPaths(docs); !slices.Equal(got, want) {
+		t.Fatalf("paths = %v, want %v", got, want)
+	}
+
+	index := docs[len(docs)-1].message.(*bzpb.ApiIndex)
+	if index.CommitSha != "abc123" {
+		t.Errorf("index commit_sha = %q", index.CommitSha)
+	}
+	foo := index.Module[1]
+	if foo.LatestVersion != "1.0.0" {
+		t.Errorf("latest_version = %q, want 1.0.0", foo.LatestVersion)
+	}
+	if !slices.Equal(foo.SymbolsVersion, []string{"2.0.0"}) {
+		t.Errorf("symbols_version = %v", foo.SymbolsVersion)
+	}
+
+	summary := docs[5].message.(*bzpb.Module)
+	if len(summary.Versions[0].Deps) != 0 {
+		t.Errorf("module summary should not carry deps: %v", summary.Versions[0])
+	}
+	if summary.Versions[0].CompatibilityLevel != 2 {
+		t.Errorf("compatibility_level not preserved: %v", summary.Versions[0])
+	}
+
+	detail := docs[6].message.(*bhpb.BazelFlagDetail)
+	if !slices.Equal(detail.BazelVersion, []string{"8.0.0", "7.0.0"}) {
+		t.Errorf("bazel_version = %v", detail.BazelVersion)
+	}
+	if !slices.Equal(detail.Command, []string{"build", "test"}) {
+		t.Errorf("command = %v", detail.Command)
+	}
+}
+
+func TestLatestVersionDefaultsToNewest(t *testing.T) {
+	registry := &bzpb.Registry{Modules: []*bzpb.Module{{
+		Name:     "m",
+		Versions: []*bzpb.ModuleVersion{{Version: "2.0.0"}, {Version: "1.0.0"}},
+	}}}
+	docs := buildDocuments(registry, &sympb.ModuleRegistrySymbols{}, &bhpb.BazelFlagDb{})
+	index := docs[len(docs)-1].message.(*bzpb.ApiIndex)
+	if got := index.Module[0].LatestVersion; got != "2.0.0" {
+		t.Errorf("latest_version = %q, want 2.0.0", got)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("1.0.0+bcr/1"); got != "1.0.0+bcr%2F1" {
+		t.Errorf("escape = %q", got)
+	}
+}
+
+func TestWriteAPITar(t *testing.T) {
+	docs := buildDocuments(newTestRegistry(), &sympb.ModuleRegistrySymbols{}, &bhpb.BazelFlagDb{})
+	data, err := writeAPITar(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := writeAPITar(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Error("tarball is not deterministic")
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = content
+	}
+	if len(files) != 2*len(docs) {
+		t.Errorf("got %d files, want %d", len(files), 2*len(docs))
+	}
+
+	var fromJSON, fromPB bzpb.ModuleVersion
+	if err := protojson.Unmarshal(files["api/modules/rules_foo/2.0.0.json"], &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(files["api/modules/rules_foo/2.0.0.pb"], &fromPB); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(&fromJSON, &fromPB) {
+		t.Errorf("json and pb documents differ: %v vs %v", &fromJSON, &fromPB)
+	}
+	if fromPB.Deps[0].Name != "rules_bar" {
+		t.Errorf("deps not preserved: %v", &fromPB)
+	}
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/netutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+const toolName = "bcrmirror"
+
+// downloadTimeout bounds a single archive download attempt.
+const downloadTimeout = 10 * time.Minute
+
+// Module selection modes.
+const (
+	selectAll     = "all"
+	selectLatest  = "latest"
+	selectClosure = "closure"
+)
+
+type Config struct {
+	RegistryFile   string
+	RegistryDir    string
+	OutputDir      string
+	MirrorURL      string
+	Select         string
+	Roots          stringSliceFlag
+	IncludeDevDeps bool
+	Jobs           int
+}
+
+// stringSliceFlag is a custom flag type for repeatable flags
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string { return strings.Join(*s, ",") }
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.RegistryFile == "" {
+		return fmt.Errorf("registry_file is required")
+	}
+	if cfg.OutputDir == "" {
+		return fmt.Errorf("output_dir is required")
+	}
+	if cfg.MirrorURL == "" {
+		return fmt.Errorf("mirror_url is required")
+	}
+
+	var registry bzpb.Registry
+	if err := protoutil.ReadFile(cfg.RegistryFile, &registry); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.RegistryFile, err)
+	}
+
+	selected, err := selectModuleVersions(&registry, cfg.Select, cfg.Roots, cfg.IncludeDevDeps)
+	if err != nil {
+		return err
+	}
+	log.Printf("Selected %d module versions (%s)", len(selected), cfg.Select)
+
+	store := &blobStore{dir: cfg.OutputDir, client: &http.Client{Timeout: downloadTimeout}}
+	result := mirror(context.Background(), store, selected, cfg.Jobs)
+
+	var needRegistry int
+	for _, mv := range selected {
+		if result.failed[mv.Source.GetIntegrity()] != nil || !isArchiveSource(mv.Source) {
+			continue
+		}
+		if err := writeSourceJSON(cfg.OutputDir, cfg.MirrorURL, mv); err != nil {
+			return err
+		}
+		if len(mv.Source.Patches) == 0 && len(mv.Source.Overlay) == 0 {
+			continue
+		}
+		if cfg.RegistryDir == "" {
+			needRegistry++
+			continue
+		}
+		if err := copySourceFiles(cfg.RegistryDir, cfg.OutputDir, mv); err != nil {
+			return err
+		}
+	}
+	if needRegistry > 0 {
+		log.Printf("note: %d module versions have patches or overlays; without --registry_dir the output must be layered over a full registry", needRegistry)
+	}
+
+	log.Printf("Mirrored %d archives (%d downloaded, %d already present, %d skipped)",
+		result.downloaded+result.cached, result.downloaded, result.cached, result.skipped)
+	if len(result.failed) > 0 {
+		for _, integrity := range sortedKeys(result.failed) {
+			log.Printf("failed %s: %v", integrity, result.failed[integrity])
+		}
+		return fmt.Errorf("%d archives could not be mirrored", len(result.failed))
+	}
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file to read")
+	fs.StringVar(&cfg.RegistryDir, "registry_dir", "", "a registry checkout (the directory containing modules/) to copy the patches/ and overlay/ files of mirrored module versions from (optional)")
+	fs.StringVar(&cfg.OutputDir, "output_dir", "", "the directory to write the archive store and rewritten source.json files to")
+	fs.StringVar(&cfg.MirrorURL, "mirror_url", "", "the base URL output_dir will be served from")
+	fs.StringVar(&cfg.Select, "select", selectLatest, "which module versions to mirror: all, latest or closure")
+	fs.Var(&cfg.Roots, "root", "a module@version whose dependency closure is mirrored with --select=closure (repeatable)")
+	fs.BoolVar(&cfg.IncludeDevDeps, "include_dev_deps", false, "include the dev dependencies of each --root")
+	fs.IntVar(&cfg.Jobs, "jobs", 8, "the number of concurrent downloads")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s --registry_file=registry.pb --output_dir=DIR --mirror_url=URL [--select=all|latest|closure] [--root=NAME@VERSION]\n", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+// selectModuleVersions returns the module versions to mirror, sorted by name
+// and version.
+func selectModuleVersions(registry *bzpb.Registry, mode string, roots []string, includeDevDeps bool) ([]*bzpb.ModuleVersion, error) {
+	var selected []*bzpb.ModuleVersion
+	switch mode {
+	case selectAll:
+		for _, module := range registry.Modules {
+			selected = append(selected, module.Versions...)
+		}
+	case selectLatest:
+		for _, module := range registry.Modules {
+			if mv := latestVersion(module); mv != nil {
+				selected = append(selected, mv)
+			}
+		}
+	case selectClosure:
+		if len(roots) == 0 {
+			return nil, fmt.Errorf("--select=closure requires at least one --root")
+		}
+		var err error
+		if selected, err = newModuleIndex(registry).closure(roots, includeDevDeps); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown --select mode %q (want all, latest or closure)", mode)
+	}
+
+	slices.SortFunc(selected, func(a, b *bzpb.ModuleVersion) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Version, b.Version))
+	})
+	return selected, nil
+}
+
+// latestVersion returns the version marked is_latest_version, or the newest
+// version if none is.
+func latestVersion(module *bzpb.Module) *bzpb.ModuleVersion {
+	for _, mv := range module.Versions {
+		if mv.IsLatestVersion {
+			return mv
+		}
+	}
+	if len(module.Versions) > 0 {
+		return module.Versions[0]
+	}
+	return nil
+}
+
+// moduleIndex looks up module versions and orders them. Versions are ranked
+// by their position in Module.versions, which the registry sorts newest
+// first; this avoids reimplementing Bazel's version comparison.
+type moduleIndex struct {
+	versions map[string]map[string]*bzpb.ModuleVersion
+	rank     map[string]map[string]int
+}
+
+func newModuleIndex(registry *bzpb.Registry) *moduleIndex {
+	idx := &moduleIndex{
+		versions: make(map[string]map[string]*bzpb.ModuleVersion),
+		rank:     make(map[string]map[string]int),
+	}
+	for _, module := range registry.Modules {
+		idx.versions[module.Name] = make(map[string]*bzpb.ModuleVersion)
+		idx.rank[module.Name] = make(map[string]int)
+		for i, mv := range module.Versions {
+			idx.versions[module.Name][mv.Version] = mv
+			idx.rank[module.Name][mv.Version] = len(module.Versions) - i
+		}
+	}
+	return idx
+}
+
+func (idx *moduleIndex) lookup(name, version string) *bzpb.ModuleVersion {
+	return idx.versions[name][version]
+}
+
+// deps returns the dependencies Bazel would follow from mv. Dev dependencies
+// only count for the root module.
+func (idx *moduleIndex) deps(mv *bzpb.ModuleVersion, isRoot, includeDevDeps bool) []*bzpb.ModuleVersion {
+	var deps []*bzpb.ModuleVersion
+	for _, dep := range mv.Deps {
+		if dep.Unresolved || (dep.Dev && !(isRoot && includeDevDeps)) {
+			continue
+		}
+		if d := idx.lookup(dep.Name, dep.Version); d != nil {
+			deps = append(deps, d)
+		} else {
+			log.Printf("warning: %s@%s depends on unknown %s@%s", mv.Name, mv.Version, dep.Name, dep.Version)
+		}
+	}
+	return deps
+}
+
+// closure runs Minimal Version Selection from roots (name@version) and
+// returns the selected version of every module in the resolved graph.
+// Overrides declared by the roots are not applied.
+func (idx *moduleIndex) closure(roots []string, includeDevDeps bool) ([]*bzpb.ModuleVersion, error) {
+	var rootVersions []*bzpb.ModuleVersion
+	for _, root := range roots {
+		name, version, ok := strings.Cut(root, "@")
+		if !ok {
+			return nil, fmt.Errorf("invalid --root %q (want NAME@VERSION)", root)
+		}
+		mv := idx.lookup(name, version)
+		if mv == nil {
+			return nil, fmt.Errorf("--root %s is not in the registry", root)
+		}
+		rootVersions = append(rootVersions, mv)
+	}
+
+	// Visit every reachable module version and keep the highest version of
+	// each module name.
+	selected := make(map[string]*bzpb.ModuleVersion)
+	visited := make(map[*bzpb.ModuleVersion]bool)
+	var visit func(mv *bzpb.ModuleVersion, isRoot bool)
+	visit = func(mv *bzpb.ModuleVersion, isRoot bool) {
+		if visited[mv] {
+			return
+		}
+		visited[mv] = true
+		if cur, ok := selected[mv.Name]; !ok || idx.rank[mv.Name][mv.Version] > idx.rank[cur.Name][cur.Version] {
+			selected[mv.Name] = mv
+		}
+		for _, dep := range idx.deps(mv, isRoot, includeDevDeps) {
+			visit(dep, false)
+		}
+	}
+	for _, mv := range rootVersions {
+		visit(mv, true)
+	}
+
+	// Keep only the selected versions still reachable once every edge points
+	// at its module's selected version.
+	var result []*bzpb.ModuleVersion
+	reachable := make(map[string]bool)
+	var walk func(mv *bzpb.ModuleVersion, isRoot bool)
+	walk = func(mv *bzpb.ModuleVersion, isRoot bool) {
+		mv = selected[mv.Name]
+		if reachable[mv.Name] {
+			return
+		}
+		reachable[mv.Name] = true
+		result = append(result, mv)
+		for _, dep := range idx.deps(mv, isRoot, includeDevDeps) {
+			walk(dep, false)
+		}
+	}
+	for _, mv := range rootVersions {
+		walk(mv, true)
+	}
+	return result, nil
+}
+
+// isArchiveSource reports whether source describes an archive download, as
+// opposed to a git_repository or local_path source.
+func isArchiveSource(source *bzpb.ModuleSource) bool {
+	return source != nil && source.Url != "" && source.Integrity != "" &&
+		(source.Type == "" || source.Type == "archive")
+}
+
+// mirrorResult summarizes a mirror run. failed maps an integrity to the
+// error of its last attempted URL.
+type mirrorResult struct {
+	downloaded int
+	cached     int
+	skipped    int
+	failed     map[string]error
+}
+
+// mirror fetches the source archive of every module version into store.
+// Module versions sharing an integrity are fetched once.
+func mirror(ctx context.Context, store *blobStore, selected []*bzpb.ModuleVersion, jobs int) *mirrorResult {
+	result := &mirrorResult{failed: make(map[string]error)}
+
+	urls := make(map[string][]string) // integrity -> candidate URLs
+	for _, mv := range selected {
+		if !isArchiveSource(mv.Source) {
+			result.skipped++
+			continue
+		}
+		for _, u := range append([]string{mv.Source.Url}, mv.Source.MirrorUrls...) {
+			if !slices.Contains(urls[mv.Source.Integrity], u) {
+				urls[mv.Source.Integrity] = append(urls[mv.Source.Integrity], u)
+			}
+		}
+	}
+
+	integrities := sortedKeys(urls)
+	bar := netutil.NewProgressBar("Mirroring source archives", len(integrities))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan string)
+	for range max(1, min(jobs, len(integrities))) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for integrity := range work {
+				fetched, err := store.fetch(ctx, urls[integrity], integrity)
+				mu.Lock()
+				switch {
+				case err != nil:
+					result.failed[integrity] = err
+				case fetched:
+					result.downloaded++
+				default:
+					result.cached++
+				}
+				mu.Unlock()
+				bar.Add(1)
+			}
+		}()
+	}
+	for _, integrity := range integrities {
+		work <- integrity
+	}
+	close(work)
+	wg.Wait()
+
+	return result
+}
+
+// writeSourceJSON writes modules/NAME/VERSION/source.json under dir with the
+// archive url pointing at the mirror. The upstream URLs are kept as
+// mirror_urls so the rewrite also works where the internet is reachable.
+// The mirror URL has no file extension, so archive_type is always set.
+func writeSourceJSON(dir, mirrorURL string, mv *bzpb.ModuleVersion) error {
+	source := mv.Source
+	blob, err := blobPath(source.Integrity)
+	if err != nil {
+		return fmt.Errorf("%s@%s: %v", mv.Name, mv.Version, err)
+	}
+	archiveType := source.ArchiveType
+	if archiveType == "" {
+		archiveType = archiveTypeFromURL(source.Url)
+	}
+	if archiveType == "" {
+		return fmt.Errorf("%s@%s: cannot determine the archive type of %s", mv.Name, mv.Version, source.Url)
+	}
+
+	// Emit only the fields of the registry's source.json schema; the proto
+	// carries many derived fields that Bazel does not expect.
+	doc := map[string]any{
+		"url":          strings.TrimSuffix(mirrorURL, "/") + "/" + blob,
+		"integrity":    source.Integrity,
+		"mirror_urls":  append([]string{source.Url}, source.MirrorUrls...),
+		"archive_type": archiveType,
+	}
+	if source.StripPrefix != "" {
+		doc["strip_prefix"] = source.StripPrefix
+	}
+	if len(source.Patches) > 0 {
+		doc["patches"] = source.Patches
+		doc["patch_strip"] = source.PatchStrip
+	}
+	if len(source.Overlay) > 0 {
+		doc["overlay"] = source.Overlay
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(dir, "modules", mv.Name, mv.Version, "source.json")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+// archiveTypes are the archive types Bazel infers from a URL's file
+// extension.
+var archiveTypes = []string{
+	"tar.bz2", "tar.gz", "tar.xz", "tar.zst",
+	"7z", "aar", "ar", "deb", "jar", "tar", "tbz", "tgz", "txz", "tzst", "war", "zip",
+}
+
+// archiveTypeFromURL returns the archive type of url's path extension, as
+// Bazel's download_and_extract infers it, or "" if it has none.
+func archiveTypeFromURL(rawURL string) string {
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	p = strings.ToLower(p)
+	for _, t := range archiveTypes {
+		if strings.HasSuffix(p, "."+t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// copySourceFiles copies the patches and overlay files listed in the
+// source.json of mv from the registry checkout to dir, verifying each
+// against its integrity as Bazel does when it fetches them.
+func copySourceFiles(registryDir, dir string, mv *bzpb.ModuleVersion) error {
+	files := make(map[string]string) // path under the version dir -> integrity
+	for name, integrity := range mv.Source.Patches {
+		files[path.Join("patches", name)] = integrity
+	}
+	for name, integrity := range mv.Source.Overlay {
+		files[path.Join("overlay", name)] = integrity
+	}
+	for _, rel := range sortedKeys(files) {
+		name := filepath.Join("modules", mv.Name, mv.Version, filepath.FromSlash(rel))
+		data, err := os.ReadFile(filepath.Join(registryDir, name))
+		if err != nil {
+			return fmt.Errorf("%s@%s: %v", mv.Name, mv.Version, err)
+		}
+		actual, err := netutil.ComputeIntegrity(files[rel], bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%s@%s: %s: %v", mv.Name, mv.Version, rel, err)
+		}
+		if actual != files[rel] {
+			return fmt.Errorf("%s@%s: %s: integrity mismatch: got %s, want %s", mv.Name, mv.Version, rel, actual, files[rel])
+		}
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
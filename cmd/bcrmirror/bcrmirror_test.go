@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+func sha256Integrity(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func ids(mvs []*bzpb.ModuleVersion) []string {
+	var result []string
+	for _, mv := range mvs {
+		result = append(result, mv.Name+"@"+mv.Version)
+	}
+	return result
+}
+
+func dep(name, version string) *bzpb.ModuleDependency {
+	return &bzpb.ModuleDependency{Name: name, Version: version}
+}
+
+func newTestRegistry() *bzpb.Registry {
+	return &bzpb.Registry{Modules: []*bzpb.Module{
+		{Name: "a", Versions: []*bzpb.ModuleVersion{
+			{Name: "a", Version: "1.0", Deps: []*bzpb.ModuleDependency{
+				dep("b", "1.0"),
+				dep("c", "1.0"),
+				{Name: "d", Version: "1.0", Dev: true},
+			}},
+		}},
+		{Name: "b", Versions: []*bzpb.ModuleVersion{
+			{Name: "b", Version: "1.0", Deps: []*bzpb.ModuleDependency{dep("c", "2.0")}},
+		}},
+		{Name: "c", Versions: []*bzpb.ModuleVersion{
+			// newest first; 10.0 sorts before 2.0 lexically but is newer
+			{Name: "c", Version: "10.0"},
+			{Name: "c", Version: "2.0", Deps: []*bzpb.ModuleDependency{{Name: "e", Version: "1.0", Dev: true}}},
+			{Name: "c", Version: "1.0", Deps: []*bzpb.ModuleDependency{dep("e", "1.0")}},
+		}},
+		{Name: "d", Versions: []*bzpb.ModuleVersion{{Name: "d", Version: "1.0"}}},
+		{Name: "e", Versions: []*bzpb.ModuleVersion{{Name: "e", Version: "1.0"}}},
+	}}
+}
+
+func TestSelectClosure(t *testing.T) {
+	registry := newTestRegistry()
+
+	got, err := selectModuleVersions(registry, selectClosure, []string{"a@1.0"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// c@1.0 loses to c@2.0, which drops e; d is a dev dependency.
+	if want := []string{"a@1.0", "b@1.0", "c@2.0"}; !slices.Equal(ids(got), want) {
+		t.Errorf("closure = %v, want %v", ids(got), want)
+	}
+
+	got, err = selectModuleVersions(registry, selectClosure, []string{"a@1.0"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a@1.0", "b@1.0", "c@2.0", "d@1.0"}; !slices.Equal(ids(got), want) {
+		t.Errorf("closure with dev deps = %v, want %v", ids(got), want)
+	}
+
+	if _, err := selectModuleVersions(registry, selectClosure, []string{"a@9.9"}, false); err == nil {
+		t.Error("expected an error for an unknown root")
+	}
+}
+
+func TestSelectLatest(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Modules[2].Versions[1].IsLatestVersion = true
+
+	got, err := selectModuleVersions(registry, selectLatest, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a@1.0", "b@1.0", "c@2.0", "d@1.0", "e@1.0"}; !slices.Equal(ids(got), want) {
+		t.Errorf("latest = %v, want %v", ids(got), want)
+	}
+}
+
+// archiveServer serves content by path with Range support and records the
+// requests it receives.
+type archiveServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+func newArchiveServer(t *testing.T, files map[string]string) *archiveServer {
+	s := &archiveServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path+" "+r.Header.Get("Range"))
+		s.mu.Unlock()
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, strings.NewReader(content))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestStore(t *testing.T) *blobStore {
+	return &blobStore{dir: t.TempDir(), client: http.DefaultClient}
+}
+
+func TestMirror(t *testing.T) {
+	const good, other = "archive contents", "other contents"
+	srv := newArchiveServer(t, map[string]string{
+		"/good.tar.gz":    good,
+		"/mirror.tar.gz":  other,
+		"/changed.tar.gz": "re-tagged contents",
+	})
+
+	selected := []*bzpb.ModuleVersion{
+		{Name: "a", Version: "1.0", Source: &bzpb.ModuleSource{
+			Url:       srv.URL + "/good.tar.gz",
+			Integrity: sha256Integrity(good),
+			Patches:   map[string]string{"fix.patch": "sha256-x"},
+		}},
+		{Name: "b", Version: "1.0", Source: &bzpb.ModuleSource{
+			Url:        srv.URL + "/missing.tar.gz",
+			MirrorUrls: []string{srv.URL + "/mirror.tar.gz"},
+			Integrity:  sha256Integrity(other),
+		}},
+		{Name: "c", Version: "1.0", Source: &bzpb.ModuleSource{
+			Url:       srv.URL + "/changed.tar.gz",
+			Integrity: sha256Integrity("original contents"),
+		}},
+		{Name: "d", Version: "1.0", Source: &bzpb.ModuleSource{
+			Type:   "git_repository",
+			Remote: "https://example.com/d.git",
+		}},
+	}
+
+	store := newTestStore(t)
+	result := mirror(context.Background(), store, selected, 2)
+	if result.downloaded != 2 || result.cached != 0 || result.skipped != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if err := result.failed[sha256Integrity("original contents")]; err == nil || !strings.Contains(err.Error(), "integrity mismatch") {
+		t.Errorf("expected an integrity mismatch for c, got %v", err)
+	}
+
+	rel, err := blobPath(sha256Integrity(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(store.dir, rel))
+	if err != nil || string(data) != other {
+		t.Errorf("mirror fallback blob = %q, %v", data, err)
+	}
+
+	// A second run finds everything in the store.
+	result = mirror(context.Background(), store, selected[:2], 2)
+	if result.downloaded != 0 || result.cached != 2 {
+		t.Errorf("rerun result = %+v", result)
+	}
+}
+
+func TestDownloadResumes(t *testing.T) {
+	const content = "0123456789abcdefghij"
+	srv := newArchiveServer(t, map[string]string{"/archive.tar.gz": content})
+	integrity := sha256Integrity(content)
+
+	store := newTestStore(t)
+	rel, err := blobPath(integrity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(store.dir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target+".partial", []byte(content[:8]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fetched, err := store.fetch(context.Background(), []string{srv.URL + "/archive.tar.gz"}, integrity)
+	if err != nil || !fetched {
+		t.Fatalf("fetch = %v, %v", fetched, err)
+	}
+	if want := []string{"/archive.tar.gz bytes=8-"}; !slices.Equal(srv.requests, want) {
+		t.Errorf("requests = %v, want %v", srv.requests, want)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil || string(data) != content {
+		t.Errorf("blob = %q, %v", data, err)
+	}
+	if _, err := os.Stat(target + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("partial file should be gone: %v", err)
+	}
+}
+
+func TestWriteSourceJSON(t *testing.T) {
+	dir := t.TempDir()
+	integrity := sha256Integrity("x")
+	mv := &bzpb.ModuleVersion{Name: "a", Version: "1.0", Source: &bzpb.ModuleSource{
+		Url:         "https://example.com/a.tar.gz",
+		Integrity:   integrity,
+		StripPrefix: "a-1.0",
+		PatchStrip:  1,
+		Patches:     map[string]string{"fix.patch": "sha256-p"},
+		DocsUrl:     "https://example.com/docs.zip",
+	}}
+	if err := writeSourceJSON(dir, "https://mirror.example/bcr/", mv); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "modules", "a", "1.0", "source.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	rel, _ := blobPath(integrity)
+	if got["url"] != "https://mirror.example/bcr/"+rel {
+		t.Errorf("url = %v", got["url"])
+	}
+	if urls, _ := got["mirror_urls"].([]any); len(urls) != 1 || urls[0] != "https://example.com/a.tar.gz" {
+		t.Errorf("mirror_urls = %v", got["mirror_urls"])
+	}
+	if got["strip_prefix"] != "a-1.0" || got["patch_strip"] != 1.0 || got["archive_type"] != "tar.gz" {
+		t.Errorf("source.json = %s", data)
+	}
+	if bytes.Contains(data, []byte("docs_url")) {
+		t.Errorf("derived fields should not be written: %s", data)
+	}
+	mv.Source.Url = "https://example.com/download?id=1"
+	if err := writeSourceJSON(dir, "https://mirror.example/bcr/", mv); err == nil {
+		t.Error("want an error for a URL without an archive type")
+	}
+}
+
+// resolveSource fetches what the source.json of name@version under dir
+// points at the way Bazel does against a registry served from dir at
+// mirrorURL: the archive from url, whose type must be known without a file
+// extension, and each patch and overlay file from the module version
+// directory, all checked against their integrity.
+func resolveSource(t *testing.T, dir, mirrorURL, name, version string) {
+	t.Helper()
+	versionDir := filepath.Join(dir, "modules", name, version)
+	data, err := os.ReadFile(filepath.Join(versionDir, "source.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var source struct {
+		URL         string            `json:"url"`
+		Integrity   string            `json:"integrity"`
+		ArchiveType string            `json:"archive_type"`
+		Patches     map[string]string `json:"patches"`
+		Overlay     map[string]string `json:"overlay"`
+	}
+	if err := json.Unmarshal(data, &source); err != nil {
+		t.Fatal(err)
+	}
+
+	archiveType := source.ArchiveType
+	if archiveType == "" {
+		archiveType = archiveTypeFromURL(source.URL)
+	}
+	if !slices.Contains(archiveTypes, archiveType) {
+		t.Errorf("%s: cannot extract %s: unknown archive type %q", name, source.URL, archiveType)
+	}
+
+	check := func(file, integrity string) {
+		t.Helper()
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			return
+		}
+		if got := sha256Integrity(string(content)); got != integrity {
+			t.Errorf("%s: %s has integrity %s, want %s", name, file, got, integrity)
+		}
+	}
+	rel, ok := strings.CutPrefix(source.URL, mirrorURL)
+	if !ok {
+		t.Fatalf("%s: url %s is not on the mirror", name, source.URL)
+	}
+	check(filepath.Join(dir, filepath.FromSlash(rel)), source.Integrity)
+	for patch, integrity := range source.Patches {
+		check(filepath.Join(versionDir, "patches", patch), integrity)
+	}
+	for file, integrity := range source.Overlay {
+		check(filepath.Join(versionDir, "overlay", filepath.FromSlash(file)), integrity)
+	}
+}
+
+func TestMirroredSourceResolves(t *testing.T) {
+	const archive, patch, build = "archive contents", "--- a\n+++ b\n", "cc_library(name = 'a')\n"
+	srv := newArchiveServer(t, map[string]string{"/a-1.0.tar.gz": archive})
+
+	registryDir := t.TempDir()
+	for name, content := range map[string]string{
+		"modules/a/1.0/patches/fix.patch":       patch,
+		"modules/a/1.0/overlay/BUILD.bazel":     build,
+		"modules/a/1.0/overlay/src/BUILD.bazel": build,
+		"modules/a/1.0/overlay/unlisted.txt":    "not in source.json",
+		"modules/a/1.0/patches/unlisted.patch":  patch,
+		"modules/other/1.0/overlay/BUILD.bazel": build,
+	} {
+		file := filepath.Join(registryDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mv := &bzpb.ModuleVersion{Name: "a", Version: "1.0", Source: &bzpb.ModuleSource{
+		// no archive_type, as in almost every BCR source.json
+		Url:       srv.URL + "/a-1.0.tar.gz",
+		Integrity: sha256Integrity(archive),
+		Patches:   map[string]string{"fix.patch": sha256Integrity(patch)},
+		Overlay: map[string]string{
+			"BUILD.bazel":     sha256Integrity(build),
+			"src/BUILD.bazel": sha256Integrity(build),
+		},
+	}}
+
+	store := newTestStore(t)
+	if result := mirror(context.Background(), store, []*bzpb.ModuleVersion{mv}, 1); len(result.failed) > 0 {
+		t.Fatalf("mirror failed: %v", result.failed)
+	}
+	const mirrorURL = "https://mirror.example/bcr/"
+	if err := writeSourceJSON(store.dir, mirrorURL, mv); err != nil {
+		t.Fatal(err)
+	}
+	if err := copySourceFiles(registryDir, store.dir, mv); err != nil {
+		t.Fatal(err)
+	}
+
+	resolveSource(t, store.dir, mirrorURL, "a", "1.0")
+	if _, err := os.Stat(filepath.Join(store.dir, "modules", "a", "1.0", "overlay", "unlisted.txt")); !os.IsNotExist(err) {
+		t.Errorf("files not listed in source.json should not be copied: %v", err)
+	}
+
+	// A registry file that does not match source.json is not mirrored.
+	mv.Source.Patches["fix.patch"] = sha256Integrity("other patch")
+	if err := copySourceFiles(registryDir, t.TempDir(), mv); err == nil || !strings.Contains(err.Error(), "integrity mismatch") {
+		t.Errorf("want an integrity mismatch, got %v", err)
+	}
+}
+
+func TestArchiveTypeFromURL(t *testing.T) {
+	for u, want := range map[string]string{
+		"https://github.com/o/r/archive/refs/tags/v1.0.tar.gz": "tar.gz",
+		"https://example.com/a-1.0.TGZ":                        "tgz",
+		"https://example.com/a.zip?download=1":                 "zip",
+		"https://example.com/a-1.0.tar.zst#frag":               "tar.zst",
+		"https://mirror.example/blobs/sha256/0123abcd":         "",
+		"https://example.com/releases/download/v1.0/a-1.0.deb": "deb",
+	} {
+		if got := archiveTypeFromURL(u); got != want {
+			t.Errorf("archiveTypeFromURL(%s) = %q, want %q", u, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bazel-contrib/bcr-frontend/pkg/netutil"
+)
+
+// blobStore is a content-addressed store of source archives. An archive with
+// integrity ALGO-BASE64 lives at blobs/ALGO/HEX under dir. Downloads land in
+// a .partial file that is renamed into place only once its integrity has
+// been verified, so an interrupted run leaves no corrupt blobs behind and
+// the next run resumes where it stopped.
+type blobStore struct {
+	dir    string
+	client *http.Client
+}
+
+// blobPath returns the slash-separated store path of an integrity.
+func blobPath(integrity string) (string, error) {
+	algo, digest, ok := strings.Cut(integrity, "-")
+	if !ok {
+		return "", fmt.Errorf("malformed integrity %q", integrity)
+	}
+	if _, _, err := netutil.NewIntegrityHash(integrity); err != nil {
+		return "", err
+	}
+	sum, err := base64.StdEncoding.DecodeString(digest)
+	if err != nil {
+		return "", fmt.Errorf("malformed integrity %q: %v", integrity, err)
+	}
+	return path.Join("blobs", algo, hex.EncodeToString(sum)), nil
+}
+
+// fetch ensures the archive with the given integrity is in the store,
+// trying each URL in turn. It reports whether anything was downloaded.
+func (s *blobStore) fetch(ctx context.Context, urls []string, integrity string) (bool, error) {
+	rel, err := blobPath(integrity)
+	if err != nil {
+		return false, err
+	}
+	target := filepath.Join(s.dir, filepath.FromSlash(rel))
+	if _, err := os.Stat(target); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return false, err
+	}
+
+	var errs []error
+	for _, url := range urls {
+		err := s.download(ctx, url, target, integrity)
+		if err == nil {
+			return true, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %v", url, err))
+	}
+	return false, errors.Join(errs...)
+}
+
+// download fetches url into target, resuming a previous partial download
+// with a Range request when the server supports it.
+func (s *blobStore) download(ctx context.Context, url, target, integrity string) error {
+	partial := target + ".partial"
+
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file already holds the whole archive.
+		return s.commit(partial, target, integrity)
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+	default:
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	f, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err // keep the partial file for the next attempt
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.commit(partial, target, integrity)
+}
+
+// commit verifies partial against integrity and moves it to target. A
+// mismatching partial file is discarded so the next attempt starts over.
+func (s *blobStore) commit(partial, target, integrity string) error {
+	f, err := os.Open(partial)
+	if err != nil {
+		return err
+	}
+	actual, err := netutil.ComputeIntegrity(integrity, f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	if actual != integrity {
+		os.Remove(partial)
+		return fmt.Errorf("integrity mismatch: got %s", actual)
+	}
+	return os.Rename(partial, target)
+}
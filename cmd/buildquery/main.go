@@ -0,0 +1,257 @@
+// Command buildquery evaluates a Bazel-style query over the BUILD file
+// targets of a ModuleRegistryPackages file, without running Bazel.
+//
+//	buildquery --packages_file=packages.pb --registry_file=registry.pb --module=rules_go 'kind(go_test, //...)'
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/buildgraph"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+const toolName = "buildquery"
+
+type Config struct {
+	PackagesFile  string
+	RegistryFile  string
+	Modules       paramsfile.StringSlice
+	DefaultModule string
+	Output        string
+	Query         string
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0)
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %w", err)
+	}
+
+	var registry sympb.ModuleRegistryPackages
+	if err := protoutil.ReadFile(cfg.PackagesFile, &registry); err != nil {
+		return fmt.Errorf("failed to read packages file: %v", err)
+	}
+
+	modules := newModuleIndex(nil)
+	if cfg.RegistryFile != "" {
+		var reg bzpb.Registry
+		if err := protoutil.ReadFile(cfg.RegistryFile, &reg); err != nil {
+			return fmt.Errorf("failed to read registry file: %v", err)
+		}
+		modules = newModuleIndex(&reg)
+	}
+
+	moduleVersions, err := selectModuleVersions(registry.ModuleVersion, cfg.Modules, modules)
+	if err != nil {
+		return err
+	}
+
+	graph := buildgraph.NewWithOptions(moduleVersions, buildgraph.Options{
+		ModuleVersion: modules.lookup,
+	})
+	nodes, err := graph.Query(cfg.Query, cfg.DefaultModule)
+	if err != nil {
+		return fmt.Errorf("query failed: %v", err)
+	}
+
+	for _, n := range nodes {
+		switch cfg.Output {
+		case "label_kind":
+			kind := n.Kind
+			if n.Target != nil {
+				kind += " rule"
+			}
+			fmt.Printf("%s %s\n", kind, graph.DisplayLabel(n))
+		default:
+			fmt.Println(graph.DisplayLabel(n))
+		}
+	}
+
+	return nil
+}
+
+// moduleIndex looks up the registry metadata of module versions.
+type moduleIndex struct {
+	versions map[string]*bzpb.ModuleVersion
+}
+
+func newModuleIndex(registry *bzpb.Registry) *moduleIndex {
+	idx := &moduleIndex{versions: make(map[string]*bzpb.ModuleVersion)}
+	for _, module := range registry.GetModules() {
+		for _, mv := range module.Versions {
+			idx.versions[module.Name+"@"+mv.Version] = mv
+		}
+	}
+	return idx
+}
+
+func (idx *moduleIndex) lookup(module, version string) *bzpb.ModuleVersion {
+	return idx.versions[module+"@"+version]
+}
+
+// selectModuleVersions picks the module versions to load. A module listed
+// in modules as NAME@VERSION uses that version, and may be listed with
+// several versions; otherwise its latest version is used: the one marked
+// is_latest_version in the registry, or else the highest. When modules is
+// non-empty, only the listed modules are included. The first version of
+// each module is the one labels resolve to by default.
+func selectModuleVersions(all []*sympb.ModuleVersionPackages, modules []string, idx *moduleIndex) ([]*sympb.ModuleVersionPackages, error) {
+	var names []string
+	wanted := make(map[string][]string)
+	for _, m := range modules {
+		name, version, _ := strings.Cut(m, "@")
+		if _, ok := wanted[name]; !ok {
+			names = append(names, name)
+		}
+		if version != "" {
+			wanted[name] = append(wanted[name], version)
+		} else if wanted[name] == nil {
+			wanted[name] = []string{}
+		}
+	}
+
+	available := make(map[string][]*sympb.ModuleVersionPackages)
+	for _, mv := range all {
+		if _, ok := available[mv.ModuleName]; !ok && len(modules) == 0 {
+			names = append(names, mv.ModuleName)
+		}
+		available[mv.ModuleName] = append(available[mv.ModuleName], mv)
+	}
+
+	var result []*sympb.ModuleVersionPackages
+	for _, name := range names {
+		versions := wanted[name]
+		if len(versions) == 0 {
+			latest := latestVersion(available[name], idx)
+			if latest == nil {
+				return nil, fmt.Errorf("module not found: %s", name)
+			}
+			result = append(result, latest)
+			continue
+		}
+		for _, version := range versions {
+			i := slices.IndexFunc(available[name], func(mv *sympb.ModuleVersionPackages) bool {
+				return mv.Version == version
+			})
+			if i < 0 {
+				return nil, fmt.Errorf("module not found: %s@%s", name, version)
+			}
+			result = append(result, available[name][i])
+		}
+	}
+	return result, nil
+}
+
+// latestVersion returns the version marked is_latest_version in the
+// registry, or the highest version if none is.
+func latestVersion(versions []*sympb.ModuleVersionPackages, idx *moduleIndex) *sympb.ModuleVersionPackages {
+	var latest *sympb.ModuleVersionPackages
+	for _, mv := range versions {
+		if rmv := idx.lookup(mv.ModuleName, mv.Version); rmv != nil && rmv.IsLatestVersion {
+			return mv
+		}
+		if latest == nil || compareVersions(mv.Version, latest.Version) > 0 {
+			latest = mv
+		}
+	}
+	return latest
+}
+
+// compareVersions orders module versions like Bazel: release segments are
+// compared numerically when both are numeric (and lexically otherwise, with
+// numeric segments first), and a prerelease sorts before its release.
+func compareVersions(a, b string) int {
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	aRelease, aPre, aHasPre := strings.Cut(a, "-")
+	bRelease, bPre, bHasPre := strings.Cut(b, "-")
+	if c := compareIdentifiers(strings.Split(aRelease, "."), strings.Split(bRelease, ".")); c != 0 {
+		return c
+	}
+	switch {
+	case aHasPre && !bHasPre:
+		return -1
+	case !aHasPre && bHasPre:
+		return 1
+	}
+	return compareIdentifiers(strings.Split(aPre, "."), strings.Split(bPre, "."))
+}
+
+func compareIdentifiers(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.PackagesFile, "packages_file", "", "the ModuleRegistryPackages file to query")
+	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file, used for is_latest_version and to resolve bazel_dep repo_name aliases (optional)")
+	fs.Var(&cfg.Modules, "module", "a module NAME or NAME@VERSION to load into the graph; repeatable, also with several versions of a module (default: every module, latest version)")
+	fs.StringVar(&cfg.DefaultModule, "default_module", "", "the module that target patterns without a repository refer to (default: the only --module, or every module)")
+	fs.StringVar(&cfg.Output, "output", "label", "output format: label or label_kind")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s --packages_file=FILE [--module=NAME[@VERSION]...] QUERY\n", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if cfg.PackagesFile == "" {
+		return cfg, fmt.Errorf("packages_file is required")
+	}
+	switch cfg.Output {
+	case "label", "label_kind":
+	default:
+		return cfg, fmt.Errorf("unknown output format %q", cfg.Output)
+	}
+
+	cfg.Query = strings.Join(fs.Args(), " ")
+	if cfg.Query == "" {
+		return cfg, fmt.Errorf("a query is required")
+	}
+	if cfg.DefaultModule == "" && len(cfg.Modules) == 1 {
+		cfg.DefaultModule, _, _ = strings.Cut(cfg.Modules[0], "@")
+	}
+
+	return
+}
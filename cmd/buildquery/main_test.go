@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+)
+
+func TestCompareVersions(t *testing.T) {
+	// ascending
+	versions := []string{"1.0.0-rc1", "1.0.0-rc2", "1.0.0", "1.2", "1.10", "1.10.1", "2.0.0.bcr.1", "10.0"}
+	for i := 1; i < len(versions); i++ {
+		if c := compareVersions(versions[i-1], versions[i]); c >= 0 {
+			t.Errorf("compareVersions(%s, %s) = %d; want < 0", versions[i-1], versions[i], c)
+		}
+		if c := compareVersions(versions[i], versions[i-1]); c <= 0 {
+			t.Errorf("compareVersions(%s, %s) = %d; want > 0", versions[i], versions[i-1], c)
+		}
+	}
+}
+
+func TestSelectModuleVersions(t *testing.T) {
+	var all []*sympb.ModuleVersionPackages
+	for _, id := range []string{"a@10.0", "a@9.0", "a@2.0", "b@1.0-rc1", "b@1.0", "b@0.9"} {
+		name, version, _ := strings.Cut(id, "@")
+		all = append(all, &sympb.ModuleVersionPackages{ModuleName: name, Version: version})
+	}
+	registry := &bzpb.Registry{Modules: []*bzpb.Module{
+		{Name: "a", Versions: []*bzpb.ModuleVersion{{Version: "10.0"}, {Version: "9.0", IsLatestVersion: true}, {Version: "2.0"}}},
+	}}
+
+	for _, tc := range []struct {
+		modules  []string
+		registry *bzpb.Registry
+		want     string
+	}{
+		{nil, nil, "a@10.0 b@1.0"},
+		{nil, registry, "a@9.0 b@1.0"},
+		{[]string{"b"}, nil, "b@1.0"},
+		{[]string{"a@2.0", "a@9.0", "b"}, nil, "a@2.0 a@9.0 b@1.0"},
+	} {
+		got, err := selectModuleVersions(all, tc.modules, newModuleIndex(tc.registry))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, mv := range got {
+			ids = append(ids, mv.ModuleName+"@"+mv.Version)
+		}
+		if got := strings.Join(ids, " "); got != tc.want {
+			t.Errorf("selectModuleVersions(%v) = %s; want %s", tc.modules, got, tc.want)
+		}
+	}
+
+	for _, modules := range [][]string{{"c"}, {"a@1.0"}} {
+		if _, err := selectModuleVersions(all, modules, newModuleIndex(nil)); err == nil {
+			t.Errorf("selectModuleVersions(%v): expected error", modules)
+		}
+	}
+}
@@ -31,6 +31,7 @@ import (
 	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
 	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
 	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/starlarkserver"
 )
 
 const toolName = "builtininfocompiler"
@@ -91,19 +92,24 @@ func run(args []string) error {
 		return err
 	}
 
-	resources, cleanup, err := initializeServer(cfg.javaInterpreterFile, cfg.serverJarFile, cfg.port, cfg.logFile, cfg.logger)
+	server, err := starlarkserver.Start(starlarkserver.Options{
+		JavaInterpreter: cfg.javaInterpreterFile,
+		ServerJar:       cfg.serverJarFile,
+		Port:            cfg.port,
+		LogFilePrefix:   cfg.logFile,
+		ToolName:        toolName,
+		Logger:          cfg.logger,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize server: %v", err)
 	}
-	defer cleanup()
+	defer server.Close()
 	cfg.logger.Println("Server ready")
 
-	client := slpb.NewStarlarkClient(resources.conn)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	resp, err := client.BuiltinInfo(ctx, &slpb.BuiltinInfoRequest{})
+	resp, err := server.Client.BuiltinInfo(ctx, &slpb.BuiltinInfoRequest{})
 	if err != nil {
 		return fmt.Errorf("BuiltinInfo RPC failed: %v", err)
 	}
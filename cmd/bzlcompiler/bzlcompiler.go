@@ -1,19 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"time"
 
-	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
-
-	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
-
 	wppb "github.com/bazel-contrib/bcr-frontend/blaze/worker"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/persistentworker"
 	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/starlarkserver"
 )
 
 const (
@@ -59,16 +61,17 @@ func run(args []string) error {
 		cfg.Logger.Println("Received EOF, shutting down persistent worker")
 	} else {
 		// Initialize server and gRPC client for batch work
-		resources, cleanup, err := initializeServer(cfg.JavaInterpreterFile, cfg.ServerJarFile, cfg.Port, cfg.LogFile, cfg.Logger)
+		server, err := starlarkserver.Start(serverOptions(cfg, cfg.Logger))
 		if err != nil {
 			return fmt.Errorf("failed to initialize server: %v", err)
 		}
 		cfg.Logger.Println("Server ready")
-		defer cleanup()
+		defer server.Close()
 
-		cfg.Client = slpb.NewStarlarkClient(resources.conn)
+		cfg.Port = server.Port
+		cfg.Client = server.Client
 
-		if err := runBatch(cfg); err != nil {
+		if err := runBatch(context.Background(), cfg); err != nil {
 			return fmt.Errorf("while performing batch work: %v", err)
 		}
 	}
@@ -76,78 +79,71 @@ func run(args []string) error {
 	return nil
 }
 
-func runPersistent(persistentCfg *config) error {
-	var resources *serverResources
-
-	for {
-		var req wppb.WorkRequest
-		if err := protoutil.ReadDelimitedFrom(&req, os.Stdin); err != nil {
-			if err == io.EOF {
-				// this is the signal to terminate the program
-				return nil
-			}
-			return fmt.Errorf("reading work request: %v", err)
-		}
+func serverOptions(cfg *config, logger *log.Logger) starlarkserver.Options {
+	return starlarkserver.Options{
+		JavaInterpreter: cfg.JavaInterpreterFile,
+		ServerJar:       cfg.ServerJarFile,
+		Port:            cfg.Port,
+		LogFilePrefix:   cfg.LogFile,
+		ToolName:        toolName,
+		Logger:          logger,
+	}
+}
 
-		var resp wppb.WorkResponse
-		resp.RequestId = req.RequestId
+// runPersistent serves work requests until EOF. Multiplexed requests run
+// concurrently, each with its own work directory and a server from a pool.
+// Requests whose arguments configure the servers differently get separate
+// pools.
+func runPersistent(persistentCfg *config) error {
+	var pools starlarkserver.Pools
+	defer pools.Close()
 
+	return persistentworker.Serve(os.Stdin, os.Stdout, persistentCfg.Logger, func(ctx context.Context, req *wppb.WorkRequest) error {
 		batchCfg, err := parseConfig(req.Arguments)
 		if err != nil {
-			// Don't kill the worker on parse errors - report error and continue
-			errMsg := fmt.Sprintf("parsing work request arguments: %v", err)
-			persistentCfg.Logger.Println("ERROR:", errMsg)
-			resp.Output = errMsg
-			resp.ExitCode = 1
-		} else {
-			// Start server on first request
-			if resources == nil {
-				r, cleanup, err := initializeServer(batchCfg.JavaInterpreterFile, batchCfg.ServerJarFile, batchCfg.Port, batchCfg.LogFile, persistentCfg.Logger)
-				if err != nil {
-					errMsg := fmt.Sprintf("failed to initialize server: %v", err)
-					batchCfg.Logger.Println("ERROR:", errMsg)
-					resp.Output = errMsg
-					resp.ExitCode = 1
-					if err := protoutil.WriteDelimitedTo(&resp, os.Stdout); err != nil {
-						return fmt.Errorf("writing work response: %v", err)
-					}
-					os.Stdout.Sync()
-					continue
-				}
-				resources = r
-				defer cleanup()
-				persistentCfg.Port = r.port
-				persistentCfg.Client = slpb.NewStarlarkClient(resources.conn)
-				persistentCfg.Logger.Println("Server ready")
-			}
+			return fmt.Errorf("parsing work request arguments: %v", err)
+		}
 
-			batchCfg.Logger = persistentCfg.Logger
-			batchCfg.Cwd = persistentCfg.Cwd
-			batchCfg.Port = persistentCfg.Port
-			batchCfg.Client = persistentCfg.Client
-
-			if err := runBatch(batchCfg); err != nil {
-				// Don't kill the worker on work errors - report error and continue
-				errMsg := fmt.Sprintf("performing work: %v", err)
-				persistentCfg.Logger.Println("ERROR:", errMsg)
-				resp.Output = errMsg
-				resp.ExitCode = 1
-			} else {
-				// Success
-				resp.ExitCode = 0
+		size := batchCfg.MaxServers
+		if size <= 0 {
+			size = min(4, runtime.NumCPU())
+		}
+		if batchCfg.Port != 0 {
+			// an external server cannot be multiplied
+			size = 1
+		}
+		opts := serverOptions(batchCfg, persistentCfg.Logger)
+		pool := pools.Get(opts, size, func() (*starlarkserver.Server, error) {
+			server, err := starlarkserver.Start(opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize server: %v", err)
 			}
+			persistentCfg.Logger.Println("Server ready")
+			return server, nil
+		})
+
+		server, err := pool.Acquire(ctx)
+		if err != nil {
+			return err
 		}
 
-		if err := protoutil.WriteDelimitedTo(&resp, os.Stdout); err != nil {
-			return fmt.Errorf("writing work response: %v", err)
+		batchCfg.Logger = persistentCfg.Logger
+		batchCfg.Cwd = persistentCfg.Cwd
+		batchCfg.Port = server.Port
+		batchCfg.Client = server.Client
+		if req.RequestId != 0 {
+			// concurrent requests must not rewrite files into the same tree
+			batchCfg.WorkDir = filepath.Join(defaultWorkDir, strconv.Itoa(int(req.RequestId)))
+			defer os.RemoveAll(filepath.Join(batchCfg.Cwd, batchCfg.WorkDir))
 		}
 
-		// Flush stdout to ensure Bazel receives the response immediately
-		os.Stdout.Sync()
-	}
+		err = runBatch(ctx, batchCfg)
+		pool.Release(server, errors.Is(err, errConstellateUnavailable))
+		return err
+	})
 }
 
-func runBatch(cfg *config) error {
+func runBatch(ctx context.Context, cfg *config) error {
 	now := time.Now()
 	fail := func(err error) error {
 		return fmt.Errorf("%v (%v)", err, time.Since(now))
@@ -159,12 +155,13 @@ func runBatch(cfg *config) error {
 	}
 
 	prepareShimBzlFiles(cfg)
+	prepareCache(cfg)
 
 	if debugSandbox {
-		listFiles(cfg.Logger, filepath.Join(workDir, "external"))
+		listFiles(cfg.Logger, filepath.Join(cfg.WorkDir, "external"))
 	}
 
-	result, err := extractModuleVersionSymbols(cfg, bzlFilesByPath, cfg.FilesToExtract)
+	result, err := extractModuleVersionSymbols(ctx, cfg, bzlFilesByPath, cfg.FilesToExtract)
 	if err != nil {
 		return fail(fmt.Errorf("failed to extract module info: %v", err))
 	}
@@ -5,31 +5,30 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
-	"strings"
 	"time"
 
 	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
 	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/extractcache"
+	"github.com/bazel-contrib/bcr-frontend/pkg/extracterror"
 	"github.com/bazel-contrib/bcr-frontend/pkg/stardoc"
+	"github.com/bazel-contrib/bcr-frontend/pkg/starlarkserver"
+	"google.golang.org/grpc/status"
 )
 
 var errConstellateUnavailable = errors.New("constellate server unavailable")
 
-func isConnectionError(err error) bool {
-	s := err.Error()
-	return strings.Contains(s, "connection refused") ||
-		strings.Contains(s, "connection reset") ||
-		strings.Contains(s, "connect: no route to host") ||
-		(strings.Contains(s, "dial tcp") && strings.Contains(s, "connect:"))
-}
-
-func extractModuleVersionSymbols(cfg *config, bzlFileByPath map[string]*bzlFile, filesToExtract []string) (*sympb.ModuleVersionSymbols, error) {
+func extractModuleVersionSymbols(ctx context.Context, cfg *config, bzlFileByPath map[string]*bzlFile, filesToExtract []string) (*sympb.ModuleVersionSymbols, error) {
 	result := &sympb.ModuleVersionSymbols{
 		Source: sympb.SymbolSource_BEST_EFFORT,
 	}
 
 	var errCount int
+	var xerrs []*sympb.ExtractionError
 	for _, filePath := range filesToExtract {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		bzlFile, found := bzlFileByPath[filePath]
 		if !found {
 			return nil, fmt.Errorf("file not found: %q (was in also included as a --bzl_file?)", filePath)
@@ -43,12 +42,17 @@ func extractModuleVersionSymbols(cfg *config, bzlFileByPath map[string]*bzlFile,
 
 		file := &sympb.File{Label: bzlFile.Label}
 
-		module, err := extractModule(cfg, bzlFile)
+		module, err := extractModule(ctx, cfg, bzlFile)
 		if err != nil {
 			if errors.Is(err, errConstellateUnavailable) {
 				return nil, err
 			}
 			file.Error = err.Error()
+			var xerr *extracterror.Error
+			if errors.As(err, &xerr) {
+				file.ExtractionError = xerr.Proto
+				xerrs = append(xerrs, xerr.Proto)
+			}
 			if cfg.ErrorLimit > 0 && errCount > cfg.ErrorLimit {
 				cfg.Logger.Panicf("🔴 failed to extract %+v: %v", bzlFile, err)
 			} else {
@@ -69,32 +73,88 @@ func extractModuleVersionSymbols(cfg *config, bzlFileByPath map[string]*bzlFile,
 	success := total - errCount
 	pct := float64(success) / float64(total) * 100.0
 	cfg.Logger.Printf("Extraction: %d/%d %.1f%%", success, total, pct)
+	if len(xerrs) > 0 {
+		cfg.Logger.Printf("Extraction errors: %s", extracterror.Summary(xerrs))
+	}
+	if cfg.Cache != nil {
+		cfg.Logger.Printf("Extraction cache: %v", cfg.Cache.Stats())
+	}
 
 	return result, nil
 }
 
-func extractModule(cfg *config, file *bzlFile) (*slpb.Module, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+func extractModule(ctx context.Context, cfg *config, file *bzlFile) (*slpb.Module, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
 	targetFileLabel := stardoc.LabelFromProto(file.Label).String()
+
+	key := cacheKey(cfg, file.Label, targetFileLabel)
+	if key != "" {
+		var cached slpb.Module
+		if cfg.Cache.Get(key, &cached) {
+			return &cached, nil
+		}
+	}
 	// log.Printf("targetFileLabel: %s", targetFileLabel)
 
 	response, err := cfg.Client.ModuleInfo(ctx, &slpb.ModuleInfoRequest{
 		TargetFileLabel: targetFileLabel,
-		BuiltinsBzlPath: filepath.Join(cfg.Cwd, workDir, "external/_builtins/src/main/starlark/builtins_bzl"),
+		BuiltinsBzlPath: filepath.Join(cfg.Cwd, cfg.WorkDir, "external/_builtins/src/main/starlark/builtins_bzl"),
 		DepRoots: []string{
-			filepath.Join(cfg.Cwd, workDir),
+			filepath.Join(cfg.Cwd, cfg.WorkDir),
 		},
 	})
 	if err != nil {
 		// Strip absolute path prefix from error messages
-		cleanErr := cleanErrorMessage(err, cfg.Cwd)
-		if isConnectionError(err) {
+		cleanErr := cleanErrorMessage(err, cfg.Cwd, cfg.WorkDir)
+		if starlarkserver.IsConnectionError(err) {
 			return nil, fmt.Errorf("%w: %v", errConstellateUnavailable, cleanErr)
 		}
-		return nil, fmt.Errorf("ModuleInfo request error: %v", cleanErr)
+		x := extracterror.Classify(targetFileLabel, status.Code(err), cleanErr.Error(), extracterror.RepoExists(filepath.Join(cfg.Cwd, cfg.WorkDir)))
+		return nil, extracterror.Wrap(fmt.Errorf("ModuleInfo request error: %v", cleanErr), x)
+	}
+
+	if key != "" {
+		if err := cfg.Cache.Put(key, response); err != nil {
+			cfg.Logger.Printf("WARN: writing extraction cache entry for %s: %v", targetFileLabel, err)
+		}
 	}
 
 	return response, nil
 }
+
+// prepareCache opens the extraction cache, if configured, once the work
+// directory has been populated. The cache is an optimization: failures are
+// logged and extraction proceeds without it.
+func prepareCache(cfg *config) {
+	if cfg.CacheDir == "" {
+		return
+	}
+	cache, err := extractcache.Open(cfg.CacheDir)
+	if err != nil {
+		cfg.Logger.Printf("WARN: extraction cache disabled: %v", err)
+		return
+	}
+	keyer, err := extractcache.NewKeyer(filepath.Join(cfg.Cwd, cfg.WorkDir), "ModuleInfo", cfg.ServerJarFile)
+	if err != nil {
+		cfg.Logger.Printf("WARN: extraction cache disabled: %v", err)
+		return
+	}
+	cfg.Cache = cache
+	cfg.cacheKeyer = keyer
+}
+
+// cacheKey returns the extraction cache key of the file, or "" if the cache
+// is disabled or the key cannot be computed.
+func cacheKey(cfg *config, lbl *slpb.Label, targetFileLabel string) extractcache.Key {
+	if cfg.cacheKeyer == nil {
+		return ""
+	}
+	key, err := cfg.cacheKeyer.Key(filepath.Join("external", lbl.Repo, lbl.Pkg, lbl.Name), targetFileLabel)
+	if err != nil {
+		cfg.Logger.Printf("WARN: extraction cache key for %s: %v", targetFileLabel, err)
+		return ""
+	}
+	return key
+}
@@ -33,7 +33,7 @@ func TestCleanErrorMessage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := cleanErrorMessage(tt.err, tt.cwd)
+			result := cleanErrorMessage(tt.err, tt.cwd, defaultWorkDir)
 			if tt.err == nil {
 				if result != nil {
 					t.Errorf("expected nil, got %v", result)
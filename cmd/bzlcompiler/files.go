@@ -115,7 +115,7 @@ func rewriteBzlFile(cfg *config, file *bzlFile) error {
 		data = build.Format(ast)
 	}
 
-	workingPath := filepath.Join(workDir, "external", file.Label.Repo, file.Label.Pkg, file.Label.Name)
+	workingPath := filepath.Join(cfg.WorkDir, "external", file.Label.Repo, file.Label.Pkg, file.Label.Name)
 	dstPath := filepath.Join(cfg.Cwd, workingPath)
 
 	return writeFile(dstPath, data, os.ModePerm)
@@ -151,7 +151,7 @@ func readBzlFile(cfg *config, path string) (*build.File, []*build.LoadStmt, []by
 
 func mustWriteWorkDirFile(cfg *config, relPath string, content string) {
 	if err := writeFile(
-		filepath.Join(cfg.Cwd, workDir, relPath),
+		filepath.Join(cfg.Cwd, cfg.WorkDir, relPath),
 		[]byte(content),
 		os.ModePerm,
 	); err != nil {
@@ -180,7 +180,7 @@ func listFiles(logger *log.Logger, dir string) error {
 }
 
 // cleanErrorMessage removes the absolute working directory prefix and unwraps gRPC errors
-func cleanErrorMessage(err error, cwd string) error {
+func cleanErrorMessage(err error, cwd, workDir string) error {
 	if err == nil {
 		return nil
 	}
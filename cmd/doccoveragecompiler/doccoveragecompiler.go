@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/doclint"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+const toolName = "doccoveragecompiler"
+
+type Config struct {
+	OutputFile  string
+	SymbolsFile string
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.SymbolsFile == "" {
+		return fmt.Errorf("symbols_file is required")
+	}
+
+	var symbols sympb.ModuleRegistrySymbols
+	if err := protoutil.ReadFile(cfg.SymbolsFile, &symbols); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.SymbolsFile, err)
+	}
+
+	result := buildDocCoverage(&symbols)
+
+	if err := protoutil.WriteFile(cfg.OutputFile, result); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	var total, documented int32
+	var findings int
+	for _, cov := range result.ModuleVersion {
+		total += cov.Total
+		documented += cov.Documented
+		findings += len(cov.Finding)
+	}
+	log.Printf("Analyzed %d module versions: %.1f%% documented, %d findings",
+		len(result.ModuleVersion), doclint.Percent(documented, total), findings)
+	return nil
+}
+
+// buildDocCoverage lints every module version that has extracted symbols.
+// Stub entries without files are skipped rather than reported as 100%.
+func buildDocCoverage(symbols *sympb.ModuleRegistrySymbols) *sympb.ModuleRegistryDocCoverage {
+	result := &sympb.ModuleRegistryDocCoverage{}
+	for _, mvs := range symbols.ModuleVersion {
+		if len(mvs.File) == 0 {
+			continue
+		}
+		result.ModuleVersion = append(result.ModuleVersion, doclint.Analyze(mvs))
+	}
+	return result
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the ModuleRegistryDocCoverage file to write (.pb or .json)")
+	fs.StringVar(&cfg.SymbolsFile, "symbols_file", "", "the ModuleRegistrySymbols protobuf file to read")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+)
+
+func newTestSymbols() *sympb.ModuleVersionSymbols {
+	return &sympb.ModuleVersionSymbols{
+		ModuleName: "rules_foo",
+		Version:    "1.2.3",
+		File: []*sympb.File{
+			{
+				Label:       &slpb.Label{Repo: "rules_foo", Pkg: "foo", Name: "defs.bzl"},
+				Description: "Public API for rules_foo.",
+				Symbol: []*sympb.Symbol{
+					{
+						Type:        sympb.SymbolType_SYMBOL_TYPE_RULE,
+						Name:        "foo_library",
+						Description: "Builds a foo library.",
+						Info: &sympb.Symbol_Rule{Rule: &slpb.Rule{Info: &sdpb.RuleInfo{
+							RuleName: "foo_library",
+							Attribute: []*sdpb.AttributeInfo{
+								{Name: "name", Type: sdpb.AttributeType_NAME, Mandatory: true},
+								{Name: "deps", Type: sdpb.AttributeType_LABEL_LIST, DefaultValue: "[]", DocString: "Deps | with pipe.",
+									ProviderNameGroup: []*sdpb.ProviderNameGroup{{
+										ProviderName: []string{"FooInfo"},
+										OriginKey:    []*sdpb.OriginKey{{Name: "FooInfo", File: "@rules_foo//foo/private:providers.bzl"}},
+									}}},
+							},
+						}}},
+					},
+					{
+						Type: sympb.SymbolType_SYMBOL_TYPE_FUNCTION,
+						Name: "foo_binary",
+						Info: &sympb.Symbol_Func{Func: &slpb.Function{Info: &sdpb.StarlarkFunctionInfo{
+							FunctionName: "foo_binary",
+							Parameter: []*sdpb.FunctionParamInfo{
+								{Name: "name", Mandatory: true},
+								{Name: "visibility", DefaultValue: "None"},
+								{Name: "kwargs", Role: sdpb.FunctionParamRole_PARAM_ROLE_KWARGS},
+							},
+							Return: &sdpb.FunctionReturnInfo{DocString: "Nothing."},
+						}}},
+					},
+					{
+						Type: sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT,
+						Name: "//foo/private:providers.bzl",
+						Info: &sympb.Symbol_Load{Load: &slpb.LoadStmt{
+							Label:  &slpb.Label{Pkg: "foo/private", Name: "providers.bzl"},
+							Symbol: []*slpb.LoadSymbol{{From: "FooInfo", To: "FooInfo"}},
+						}},
+					},
+				},
+			},
+			{
+				Label: &slpb.Label{Repo: "rules_foo", Pkg: "foo/private", Name: "providers.bzl"},
+				Symbol: []*sympb.Symbol{
+					{
+						Type: sympb.SymbolType_SYMBOL_TYPE_PROVIDER,
+						Name: "FooInfo",
+						Info: &sympb.Symbol_Provider{Provider: &slpb.Provider{Info: &sdpb.ProviderInfo{
+							ProviderName: "FooInfo",
+							FieldInfo:    []*sdpb.ProviderFieldInfo{{Name: "srcs", DocString: "Source files."}},
+						}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildPages(t *testing.T) {
+	pages := buildPages(newTestSymbols())
+
+	var paths []string
+	for _, p := range pages {
+		paths = append(paths, p.path)
+	}
+	if got, want := strings.Join(paths, ","), "index,foo/defs.bzl,foo/private/providers.bzl"; got != want {
+		t.Fatalf("page paths = %s; want %s", got, want)
+	}
+	if pages[1].title != "//foo:defs.bzl" {
+		t.Errorf("title = %q", pages[1].title)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	pages := buildPages(newTestSymbols())
+	md := string(renderMarkdown(pages[1]))
+
+	for _, want := range []string{
+		"# `//foo:defs.bzl`\n",
+		"<a id=\"foo_library\"></a>\n\n## `foo_library`\n",
+		"load(\"@rules_foo//foo:defs.bzl\", \"foo_library\")\n\nfoo_library(name, deps = [])\n",
+		"| `deps` | Deps \\| with pipe. Required providers: [`FooInfo`](private/providers.bzl.md#FooInfo) | List of labels | `[]` |",
+		"| `name` |  | Name | required |",
+		"foo_binary(name, visibility = None, **kwargs)\n",
+		"#### Returns\n\nNothing.\n",
+		"- [`FooInfo`](private/providers.bzl.md#FooInfo) re-exported from `//foo/private:providers.bzl`\n",
+		"- [`foo_library`](#foo_library) (rule)\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q\n%s", want, md)
+		}
+	}
+
+	index := string(renderMarkdown(pages[0]))
+	if want := "- [`//foo:defs.bzl`](foo/defs.bzl.md) — Public API for rules_foo.\n"; !strings.Contains(index, want) {
+		t.Errorf("index missing %q\n%s", want, index)
+	}
+
+	providers := string(renderMarkdown(pages[2]))
+	if want := "| `srcs` | Source files. |"; !strings.Contains(providers, want) {
+		t.Errorf("providers missing %q\n%s", want, providers)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	pages := buildPages(newTestSymbols())
+	out := string(renderHTML(pages[1], pages))
+
+	for _, want := range []string{
+		"<title>//foo:defs.bzl</title>",
+		`<h2 id="foo_library"><code>foo_library</code></h2>`,
+		`<a href="private/providers.bzl.html#FooInfo"><code>FooInfo</code></a>`,
+		`<a href="../index.html">rules_foo 1.2.3</a>`,
+		"<p>Builds a foo library.</p>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+}
+
+func TestDocHTML(t *testing.T) {
+	got := docHTML("Does `x` & y.\n\n```starlark\nfoo(a < b)\n```\n\n- one\n- two")
+	want := "<p>Does <code>x</code> &amp; y.</p>\n" +
+		"<pre><code class=\"language-starlark\">foo(a &lt; b)</code></pre>\n" +
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n"
+	if got != want {
+		t.Errorf("docHTML =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInlineMarkdownHTML(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"**bold** and `code`", "<strong>bold</strong> and <code>code</code>"},
+		{"`a**b**` stays", "<code>a**b**</code> stays"},
+		{"`[x](https://a.b)` stays", "<code>[x](https://a.b)</code> stays"},
+		{"**see `x < y`**", "<strong>see <code>x &lt; y</code></strong>"},
+		{"[`load`](https://bazel.build)", `<a href="https://bazel.build"><code>load</code></a>`},
+	} {
+		if got := inlineMarkdownHTML(tc.in); got != tc.want {
+			t.Errorf("inlineMarkdownHTML(%q) = %q; want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,606 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/stardoc"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+	"github.com/bazelbuild/bazel-gazelle/label"
+)
+
+// indexPage is the page path of the generated table of contents.
+const indexPage = "index"
+
+// page is a format-neutral document rendered by both the markdown and html
+// writers. Path is relative to the output root and has no extension.
+type page struct {
+	path   string
+	title  string
+	blocks []block
+}
+
+// block is one of heading, doc, code, table or list.
+type block any
+
+type heading struct {
+	level  int
+	anchor string
+	text   []inline
+}
+
+// doc is a markdown docstring, emitted verbatim to markdown output.
+type doc struct {
+	text string
+}
+
+type code struct {
+	lang string
+	text string
+}
+
+type table struct {
+	header []string
+	rows   [][][]inline
+}
+
+type list struct {
+	items [][]inline
+}
+
+// inline is a run of text, optionally code-formatted and/or linked.
+type inline struct {
+	text string
+	code bool
+	link *target
+}
+
+// target is a link destination within the generated tree. An empty page
+// refers to the page containing the link.
+type target struct {
+	page   string
+	anchor string
+}
+
+func text(s string) inline     { return inline{text: s} }
+func codeText(s string) inline { return inline{text: s, code: true} }
+
+// linker resolves labels and origin keys to pages within the tree.
+type linker struct {
+	module string
+	// repos are the repository names that refer to this module
+	repos map[string]bool
+	pages map[label.Label]string
+	// symbols records the exported names of each page so that links to
+	// unknown anchors are not emitted.
+	symbols map[string]map[string]bool
+}
+
+func newLinker(symbols *sympb.ModuleVersionSymbols) *linker {
+	l := &linker{
+		module:  symbols.ModuleName,
+		repos:   map[string]bool{"": true, symbols.ModuleName: true},
+		pages:   make(map[label.Label]string),
+		symbols: make(map[string]map[string]bool),
+	}
+	for _, file := range symbols.File {
+		if file.Label != nil {
+			l.repos[file.Label.Repo] = true
+		}
+	}
+	for _, file := range symbols.File {
+		if file.Label == nil {
+			continue
+		}
+		p := filePagePath(file.Label)
+		l.pages[l.normalize(stardoc.LabelFromProto(file.Label))] = p
+		names := make(map[string]bool)
+		for _, sym := range file.Symbol {
+			names[sym.Name] = true
+		}
+		l.symbols[p] = names
+	}
+	return l
+}
+
+// normalize strips the repository from labels that refer to this module so
+// that "//foo:bar.bzl" and "@module//foo:bar.bzl" resolve identically.
+func (l *linker) normalize(lbl label.Label) label.Label {
+	if l.repos[lbl.Repo] {
+		lbl.Repo = ""
+	}
+	lbl.Relative = false
+	lbl.Canonical = false
+	return lbl
+}
+
+// resolve returns the link target for symbol name in the file identified by
+// lbl, or nil if the file is not part of this module version.
+func (l *linker) resolve(lbl label.Label, name string) *target {
+	p, ok := l.pages[l.normalize(lbl)]
+	if !ok {
+		return nil
+	}
+	t := &target{page: p}
+	if l.symbols[p][name] {
+		t.anchor = name
+	}
+	return t
+}
+
+// resolveString parses a label string (as found in origin keys) and resolves
+// it.
+func (l *linker) resolveString(labelStr, name string) *target {
+	if labelStr == "" {
+		return nil
+	}
+	lbl, err := label.Parse(labelStr)
+	if err != nil {
+		return nil
+	}
+	return l.resolve(lbl, name)
+}
+
+// filePagePath maps a .bzl file label to its page path, e.g.
+// //lib/private:copy_file.bzl -> lib/private/copy_file.bzl.
+func filePagePath(lbl *slpb.Label) string {
+	return path.Join(lbl.Pkg, lbl.Name)
+}
+
+// loadLabel returns the label users would write to load from this file.
+func (l *linker) loadLabel(lbl *slpb.Label) string {
+	return label.New(l.module, lbl.Pkg, lbl.Name).String()
+}
+
+// buildPages converts a ModuleVersionSymbols into an index page followed by
+// one page per .bzl file, sorted by path.
+func buildPages(symbols *sympb.ModuleVersionSymbols) []*page {
+	l := newLinker(symbols)
+
+	files := slices.Clone(symbols.File)
+	slices.SortFunc(files, func(a, b *sympb.File) int {
+		return strings.Compare(filePagePath(a.GetLabel()), filePagePath(b.GetLabel()))
+	})
+
+	index := &page{
+		path:  indexPage,
+		title: fmt.Sprintf("%s %s", symbols.ModuleName, symbols.Version),
+	}
+	index.blocks = append(index.blocks, heading{level: 1, text: []inline{text(index.title)}})
+	var items [][]inline
+	pages := []*page{index}
+	for _, file := range files {
+		if file.Label == nil {
+			continue
+		}
+		p := buildFilePage(l, file)
+		pages = append(pages, p)
+		item := []inline{{text: p.title, code: true, link: &target{page: p.path}}}
+		if summary := firstSentence(file.Description); summary != "" {
+			item = append(item, text(" — "+summary))
+		}
+		items = append(items, item)
+	}
+	if len(items) > 0 {
+		index.blocks = append(index.blocks, list{items: items})
+	}
+
+	return pages
+}
+
+func buildFilePage(l *linker, file *sympb.File) *page {
+	p := &page{
+		path:  filePagePath(file.Label),
+		title: label.New("", file.Label.Pkg, file.Label.Name).String(),
+	}
+	p.blocks = append(p.blocks, heading{level: 1, text: []inline{codeText(p.title)}})
+	if file.Description != "" {
+		p.blocks = append(p.blocks, doc{text: file.Description})
+	}
+	if file.Error != "" {
+		p.blocks = append(p.blocks, doc{text: "> **Extraction error:** " + file.Error})
+	}
+
+	var toc [][]inline
+	for _, sym := range file.Symbol {
+		if sym.Type == sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT {
+			continue
+		}
+		toc = append(toc, []inline{
+			{text: sym.Name, code: true, link: &target{anchor: sym.Name}},
+			text(" (" + symbolKind(sym.Type) + ")"),
+		})
+	}
+	if len(toc) > 0 {
+		p.blocks = append(p.blocks, list{items: toc})
+	}
+
+	for _, sym := range file.Symbol {
+		p.blocks = append(p.blocks, symbolBlocks(l, file, sym)...)
+	}
+
+	return p
+}
+
+// symbolKind is the human-readable name of a symbol type.
+func symbolKind(t sympb.SymbolType) string {
+	switch t {
+	case sympb.SymbolType_SYMBOL_TYPE_RULE:
+		return "rule"
+	case sympb.SymbolType_SYMBOL_TYPE_FUNCTION:
+		return "function"
+	case sympb.SymbolType_SYMBOL_TYPE_PROVIDER:
+		return "provider"
+	case sympb.SymbolType_SYMBOL_TYPE_ASPECT:
+		return "aspect"
+	case sympb.SymbolType_SYMBOL_TYPE_MODULE_EXTENSION:
+		return "module extension"
+	case sympb.SymbolType_SYMBOL_TYPE_REPOSITORY_RULE:
+		return "repository rule"
+	case sympb.SymbolType_SYMBOL_TYPE_MACRO:
+		return "macro"
+	case sympb.SymbolType_SYMBOL_TYPE_RULE_MACRO:
+		return "rule macro"
+	case sympb.SymbolType_SYMBOL_TYPE_VALUE:
+		return "value"
+	case sympb.SymbolType_SYMBOL_TYPE_STRUCT:
+		return "struct"
+	case sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT:
+		return "load"
+	}
+	return "symbol"
+}
+
+func symbolBlocks(l *linker, file *sympb.File, sym *sympb.Symbol) []block {
+	if sym.Type == sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT {
+		return loadBlocks(l, sym.GetLoad())
+	}
+
+	blocks := []block{heading{
+		level:  2,
+		anchor: sym.Name,
+		text:   []inline{codeText(sym.Name)},
+	}}
+	loadLine := fmt.Sprintf("load(%q, %q)", l.loadLabel(file.Label), sym.Name)
+
+	switch info := sym.Info.(type) {
+	case *sympb.Symbol_Rule:
+		blocks = append(blocks, code{lang: "starlark", text: loadLine + "\n\n" + attributeSignature(sym.Name, info.Rule.GetInfo().GetAttribute())})
+		blocks = appendDoc(blocks, sym.Description)
+		if providers := info.Rule.GetInfo().GetAdvertisedProviders(); providers != nil {
+			blocks = append(blocks, list{items: [][]inline{append([]inline{text("Provides: ")}, providerLinks(l, providers)...)}})
+		}
+		blocks = appendAttributeTable(blocks, l, info.Rule.GetInfo().GetAttribute())
+	case *sympb.Symbol_Macro:
+		blocks = append(blocks, code{lang: "starlark", text: loadLine + "\n\n" + attributeSignature(sym.Name, info.Macro.GetInfo().GetAttribute())})
+		blocks = appendDoc(blocks, sym.Description)
+		blocks = appendAttributeTable(blocks, l, info.Macro.GetInfo().GetAttribute())
+	case *sympb.Symbol_RepositoryRule:
+		blocks = append(blocks, code{lang: "starlark", text: loadLine + "\n\n" + attributeSignature(sym.Name, info.RepositoryRule.GetInfo().GetAttribute())})
+		blocks = appendDoc(blocks, sym.Description)
+		blocks = appendAttributeTable(blocks, l, info.RepositoryRule.GetInfo().GetAttribute())
+		if environ := info.RepositoryRule.GetInfo().GetEnviron(); len(environ) > 0 {
+			var items [][]inline
+			for _, env := range environ {
+				items = append(items, []inline{codeText(env)})
+			}
+			blocks = append(blocks, heading{level: 4, text: []inline{text("Environment variables")}}, list{items: items})
+		}
+	case *sympb.Symbol_Aspect:
+		blocks = append(blocks, code{lang: "starlark", text: loadLine + "\n\n" + attributeSignature(sym.Name, info.Aspect.GetInfo().GetAttribute())})
+		blocks = appendDoc(blocks, sym.Description)
+		if attrs := info.Aspect.GetInfo().GetAspectAttribute(); len(attrs) > 0 {
+			var items []inline
+			items = append(items, text("Propagates along: "))
+			for i, a := range attrs {
+				if i > 0 {
+					items = append(items, text(", "))
+				}
+				items = append(items, codeText(a))
+			}
+			blocks = append(blocks, list{items: [][]inline{items}})
+		}
+		blocks = appendAttributeTable(blocks, l, info.Aspect.GetInfo().GetAttribute())
+	case *sympb.Symbol_Func:
+		blocks = append(blocks, functionBlocks(loadLine, sym.Name, sym.Description, info.Func.GetInfo())...)
+	case *sympb.Symbol_RuleMacro:
+		fn := info.RuleMacro.GetFunction()
+		blocks = append(blocks, functionBlocks(loadLine, sym.Name, sym.Description, fn.GetInfo())...)
+		if rule := info.RuleMacro.GetRule().GetInfo(); rule != nil {
+			wraps := []inline{text("Wraps rule "), codeText(rule.RuleName)}
+			if origin := rule.GetOriginKey(); origin != nil {
+				wraps[1].link = l.resolveString(origin.File, cmp.Or(origin.Name, rule.RuleName))
+			}
+			blocks = append(blocks, list{items: [][]inline{wraps}})
+			blocks = appendAttributeTable(blocks, l, rule.Attribute)
+		}
+	case *sympb.Symbol_Provider:
+		provider := info.Provider.GetInfo()
+		blocks = append(blocks, code{lang: "starlark", text: loadLine + "\n\n" + providerSignature(sym.Name, provider)})
+		blocks = appendDoc(blocks, sym.Description)
+		if fields := provider.GetFieldInfo(); len(fields) > 0 {
+			t := table{header: []string{"Field", "Description"}}
+			for _, f := range fields {
+				t.rows = append(t.rows, [][]inline{{codeText(f.Name)}, {text(f.DocString)}})
+			}
+			blocks = append(blocks, heading{level: 4, text: []inline{text("Fields")}}, t)
+		}
+		if init := provider.GetInit(); init != nil && len(init.Parameter) > 0 {
+			blocks = append(blocks, heading{level: 4, text: []inline{text("Initializer")}})
+			blocks = appendParamTable(blocks, init.Parameter)
+		}
+	case *sympb.Symbol_ModuleExtension:
+		ext := info.ModuleExtension.GetInfo()
+		blocks = appendDoc(blocks, sym.Description)
+		for _, tag := range ext.GetTagClass() {
+			anchor := sym.Name + "." + tag.TagName
+			blocks = append(blocks,
+				heading{level: 3, anchor: anchor, text: []inline{codeText(anchor)}},
+				code{lang: "starlark", text: fmt.Sprintf("%s = use_extension(%q, %q)\n%s", sym.Name, l.loadLabel(file.Label), sym.Name, attributeSignature(anchor, tag.Attribute))},
+			)
+			blocks = appendDoc(blocks, tag.DocString)
+			blocks = appendAttributeTable(blocks, l, tag.Attribute)
+		}
+	case *sympb.Symbol_Struct:
+		blocks = append(blocks, code{lang: "starlark", text: loadLine})
+		blocks = appendDoc(blocks, sym.Description)
+		if fields := info.Struct.GetField(); len(fields) > 0 {
+			t := table{header: []string{"Field", "Target"}}
+			for _, f := range fields {
+				t.rows = append(t.rows, [][]inline{{codeText(f.QualifiedName)}, {codeText(f.TargetSymbol)}})
+			}
+			blocks = append(blocks, t)
+		}
+	case *sympb.Symbol_Value:
+		blocks = append(blocks, code{lang: "starlark", text: loadLine + "\n\n" + sym.Name + " = " + formatValue(info.Value)})
+	default:
+		blocks = appendDoc(blocks, sym.Description)
+	}
+	return blocks
+}
+
+// loadBlocks renders a re-export (load statement symbol) as a list of links
+// to the symbols' original definitions.
+func loadBlocks(l *linker, load *slpb.LoadStmt) []block {
+	if load == nil || load.Label == nil || len(load.Symbol) == 0 {
+		return nil
+	}
+	from := stardoc.LabelFromProto(load.Label)
+	var items [][]inline
+	for _, sym := range load.Symbol {
+		if strings.HasPrefix(sym.To, "_") {
+			continue
+		}
+		item := inline{text: sym.To, code: true}
+		item.link = l.resolve(from, sym.From)
+		items = append(items, []inline{item, text(" re-exported from "), codeText(from.String())})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return []block{list{items: items}}
+}
+
+func appendDoc(blocks []block, s string) []block {
+	if strings.TrimSpace(s) == "" {
+		return blocks
+	}
+	return append(blocks, doc{text: s})
+}
+
+func functionBlocks(loadLine, name, description string, info *sdpb.StarlarkFunctionInfo) []block {
+	blocks := []block{code{lang: "starlark", text: loadLine + "\n\n" + functionSignature(name, info.GetParameter())}}
+	if d := info.GetDeprecated(); d != nil {
+		blocks = append(blocks, doc{text: "> **Deprecated:** " + cmp.Or(d.DocString, "this function is deprecated.")})
+	}
+	blocks = appendDoc(blocks, description)
+	blocks = appendParamTable(blocks, info.GetParameter())
+	if r := info.GetReturn(); r != nil && r.DocString != "" {
+		blocks = append(blocks, heading{level: 4, text: []inline{text("Returns")}}, doc{text: r.DocString})
+	}
+	return blocks
+}
+
+func appendParamTable(blocks []block, params []*sdpb.FunctionParamInfo) []block {
+	if len(params) == 0 {
+		return blocks
+	}
+	t := table{header: []string{"Parameter", "Description", "Default"}}
+	for _, p := range params {
+		def := []inline{codeText(p.DefaultValue)}
+		if p.DefaultValue == "" {
+			def = []inline{text(mandatoryText(p.Mandatory))}
+		}
+		t.rows = append(t.rows, [][]inline{{codeText(paramName(p))}, {text(p.DocString)}, def})
+	}
+	return append(blocks, heading{level: 4, text: []inline{text("Parameters")}}, t)
+}
+
+func appendAttributeTable(blocks []block, l *linker, attrs []*sdpb.AttributeInfo) []block {
+	if len(attrs) == 0 {
+		return blocks
+	}
+	t := table{header: []string{"Attribute", "Description", "Type", "Default"}}
+	for _, a := range attrs {
+		desc := []inline{text(a.DocString)}
+		for _, group := range a.ProviderNameGroup {
+			desc = append(desc, text(" Required providers: "))
+			desc = append(desc, providerLinks(l, group)...)
+		}
+		def := []inline{codeText(a.DefaultValue)}
+		if a.DefaultValue == "" {
+			def = []inline{text(mandatoryText(a.Mandatory))}
+		}
+		t.rows = append(t.rows, [][]inline{{codeText(a.Name)}, desc, {text(attributeTypeName(a.Type))}, def})
+	}
+	return append(blocks, heading{level: 4, text: []inline{text("Attributes")}}, t)
+}
+
+// providerLinks renders a provider name group, linking each provider to its
+// definition when it lives in this module version.
+func providerLinks(l *linker, group *sdpb.ProviderNameGroup) []inline {
+	var out []inline
+	for i, name := range group.ProviderName {
+		if i > 0 {
+			out = append(out, text(", "))
+		}
+		item := codeText(name)
+		if i < len(group.OriginKey) {
+			origin := group.OriginKey[i]
+			item.link = l.resolveString(origin.File, cmp.Or(origin.Name, name))
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func mandatoryText(mandatory bool) string {
+	if mandatory {
+		return "required"
+	}
+	return "optional"
+}
+
+func paramName(p *sdpb.FunctionParamInfo) string {
+	switch p.Role {
+	case sdpb.FunctionParamRole_PARAM_ROLE_VARARGS:
+		return "*" + p.Name
+	case sdpb.FunctionParamRole_PARAM_ROLE_KWARGS:
+		return "**" + p.Name
+	}
+	return p.Name
+}
+
+// functionSignature formats a def-style call signature including defaults,
+// inserting the bare `*` separator before keyword-only params.
+func functionSignature(name string, params []*sdpb.FunctionParamInfo) string {
+	var args []string
+	sawStar := false
+	for _, p := range params {
+		switch p.Role {
+		case sdpb.FunctionParamRole_PARAM_ROLE_VARARGS:
+			sawStar = true
+		case sdpb.FunctionParamRole_PARAM_ROLE_KEYWORD_ONLY:
+			if !sawStar {
+				args = append(args, "*")
+				sawStar = true
+			}
+		}
+		arg := paramName(p)
+		if p.DefaultValue != "" {
+			arg += " = " + p.DefaultValue
+		}
+		args = append(args, arg)
+	}
+	return formatCall(name, args)
+}
+
+// attributeSignature formats a rule-style call listing every attribute, with
+// defaults for optional ones.
+func attributeSignature(name string, attrs []*sdpb.AttributeInfo) string {
+	var args []string
+	for _, a := range attrs {
+		arg := a.Name
+		if !a.Mandatory && a.DefaultValue != "" {
+			arg += " = " + a.DefaultValue
+		}
+		args = append(args, arg)
+	}
+	return formatCall(name, args)
+}
+
+func providerSignature(name string, info *sdpb.ProviderInfo) string {
+	var args []string
+	for _, f := range info.GetFieldInfo() {
+		args = append(args, f.Name)
+	}
+	return formatCall(name, args)
+}
+
+// formatCall keeps short calls on one line and otherwise puts one argument
+// per line.
+func formatCall(name string, args []string) string {
+	oneLine := name + "(" + strings.Join(args, ", ") + ")"
+	if len(oneLine) <= 80 {
+		return oneLine
+	}
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteString("(\n")
+	for _, a := range args {
+		sb.WriteString("    ")
+		sb.WriteString(a)
+		sb.WriteString(",\n")
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func attributeTypeName(t sdpb.AttributeType) string {
+	switch t {
+	case sdpb.AttributeType_NAME:
+		return "Name"
+	case sdpb.AttributeType_INT:
+		return "Integer"
+	case sdpb.AttributeType_LABEL:
+		return "Label"
+	case sdpb.AttributeType_STRING:
+		return "String"
+	case sdpb.AttributeType_STRING_LIST:
+		return "List of strings"
+	case sdpb.AttributeType_INT_LIST:
+		return "List of integers"
+	case sdpb.AttributeType_LABEL_LIST:
+		return "List of labels"
+	case sdpb.AttributeType_BOOLEAN:
+		return "Boolean"
+	case sdpb.AttributeType_LABEL_STRING_DICT:
+		return "Dictionary: Label -> String"
+	case sdpb.AttributeType_STRING_DICT:
+		return "Dictionary: String -> String"
+	case sdpb.AttributeType_STRING_LIST_DICT:
+		return "Dictionary: String -> List of strings"
+	case sdpb.AttributeType_OUTPUT:
+		return "Label; output"
+	case sdpb.AttributeType_OUTPUT_LIST:
+		return "List of labels; output"
+	case sdpb.AttributeType_LABEL_DICT_UNARY:
+		return "Dictionary: Label -> Label"
+	}
+	return ""
+}
+
+func formatValue(v *slpb.Value) string {
+	switch v := v.GetValue().(type) {
+	case *slpb.Value_String_:
+		return fmt.Sprintf("%q", v.String_)
+	case *slpb.Value_Int:
+		return fmt.Sprintf("%d", v.Int)
+	case *slpb.Value_Bool:
+		if v.Bool {
+			return "True"
+		}
+		return "False"
+	case *slpb.Value_List:
+		var items []string
+		for _, item := range v.List.Value {
+			items = append(items, formatValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return "..."
+}
+
+// firstSentence returns the first line of a docstring's first paragraph.
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
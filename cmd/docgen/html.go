@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const htmlStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;margin:0;display:flex;color:#1f2328;line-height:1.5}
+nav{width:18rem;flex-shrink:0;padding:1rem;border-right:1px solid #d0d7de;height:100vh;overflow:auto;position:sticky;top:0;font-size:.875rem}
+nav ul{list-style:none;padding:0;margin:0}
+nav a{display:block;padding:.125rem 0}
+main{padding:1rem 2rem;max-width:60rem;min-width:0}
+a{color:#0969da;text-decoration:none}
+a:hover{text-decoration:underline}
+code,pre{font-family:ui-monospace,SFMono-Regular,Menlo,monospace;font-size:85%}
+code{background:#eff1f3;padding:.1em .3em;border-radius:4px}
+pre{background:#f6f8fa;padding:1rem;overflow:auto;border-radius:6px}
+pre code{background:none;padding:0}
+table{border-collapse:collapse;margin:1rem 0;width:100%}
+th,td{border:1px solid #d0d7de;padding:.375rem .75rem;text-align:left;vertical-align:top}
+th{background:#f6f8fa}
+blockquote{margin:0;padding:0 1em;color:#59636e;border-left:.25em solid #d0d7de}
+h2{border-bottom:1px solid #d0d7de;padding-bottom:.3em;margin-top:2rem}
+`
+
+// renderHTML renders p as a standalone HTML document. nav lists every page
+// of the site and is repeated in each document's sidebar.
+func renderHTML(p *page, nav []*page) []byte {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(p.title))
+	fmt.Fprintf(&sb, "<style>\n%s</style>\n</head>\n<body>\n", htmlStyle)
+
+	sb.WriteString("<nav>\n<ul>\n")
+	for _, n := range nav {
+		fmt.Fprintf(&sb, "<li><a href=\"%s\">%s</a></li>\n",
+			html.EscapeString(relativeHref(p.path, &target{page: n.path}, ".html")),
+			html.EscapeString(n.title))
+	}
+	sb.WriteString("</ul>\n</nav>\n<main>\n")
+
+	for _, b := range p.blocks {
+		switch b := b.(type) {
+		case heading:
+			id := ""
+			if b.anchor != "" {
+				id = fmt.Sprintf(" id=\"%s\"", html.EscapeString(b.anchor))
+			}
+			fmt.Fprintf(&sb, "<h%d%s>%s</h%d>\n", b.level, id, htmlInlines(p.path, b.text), b.level)
+		case doc:
+			sb.WriteString(docHTML(b.text))
+		case code:
+			fmt.Fprintf(&sb, "<pre><code class=\"language-%s\">%s</code></pre>\n", b.lang, html.EscapeString(b.text))
+		case list:
+			sb.WriteString("<ul>\n")
+			for _, item := range b.items {
+				fmt.Fprintf(&sb, "<li>%s</li>\n", htmlInlines(p.path, item))
+			}
+			sb.WriteString("</ul>\n")
+		case table:
+			sb.WriteString("<table>\n<thead><tr>")
+			for _, h := range b.header {
+				fmt.Fprintf(&sb, "<th>%s</th>", html.EscapeString(h))
+			}
+			sb.WriteString("</tr></thead>\n<tbody>\n")
+			for _, row := range b.rows {
+				sb.WriteString("<tr>")
+				for _, cell := range row {
+					fmt.Fprintf(&sb, "<td>%s</td>", htmlInlines(p.path, cell))
+				}
+				sb.WriteString("</tr>\n")
+			}
+			sb.WriteString("</tbody>\n</table>\n")
+		}
+	}
+
+	sb.WriteString("</main>\n</body>\n</html>\n")
+	return []byte(sb.String())
+}
+
+func htmlInlines(from string, inlines []inline) string {
+	var sb strings.Builder
+	for _, in := range inlines {
+		var s string
+		if in.code {
+			if in.text == "" {
+				continue
+			}
+			s = "<code>" + html.EscapeString(in.text) + "</code>"
+		} else {
+			s = inlineMarkdownHTML(in.text)
+		}
+		if in.link != nil {
+			s = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(relativeHref(from, in.link, ".html")), s)
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+var (
+	codeSpanPattern    = regexp.MustCompile("`([^`]+)`")
+	boldPattern        = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	linkPattern        = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	placeholderPattern = regexp.MustCompile("\x00([0-9]+)\x00")
+)
+
+// inlineMarkdownHTML escapes s and converts the common inline markdown
+// constructs found in docstrings: code spans, bold text and absolute links.
+// Code spans are swapped for placeholders before the other constructs are
+// converted, so their content is never rewritten.
+func inlineMarkdownHTML(s string) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	var spans []string
+	s = codeSpanPattern.ReplaceAllStringFunc(s, func(m string) string {
+		spans = append(spans, "<code>"+html.EscapeString(m[1:len(m)-1])+"</code>")
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	})
+	s = html.EscapeString(s)
+	s = boldPattern.ReplaceAllString(s, "<strong>$1</strong>")
+	s = linkPattern.ReplaceAllString(s, `<a href="$2">$1</a>`)
+	return placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		i, _ := strconv.Atoi(m[1 : len(m)-1])
+		return spans[i]
+	})
+}
+
+// docHTML converts a markdown docstring to HTML. It understands the subset
+// of markdown commonly used in Starlark docstrings: paragraphs, fenced code
+// blocks, bullet lists, block quotes and inline code; everything else is
+// rendered as escaped text.
+func docHTML(s string) string {
+	var sb strings.Builder
+	var para []string
+	var items []string
+
+	flushPara := func() {
+		if len(para) > 0 {
+			fmt.Fprintf(&sb, "<p>%s</p>\n", inlineMarkdownHTML(strings.Join(para, "\n")))
+			para = nil
+		}
+	}
+	flushList := func() {
+		if len(items) > 0 {
+			sb.WriteString("<ul>\n")
+			for _, item := range items {
+				fmt.Fprintf(&sb, "<li>%s</li>\n", inlineMarkdownHTML(item))
+			}
+			sb.WriteString("</ul>\n")
+			items = nil
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushPara()
+			flushList()
+			lang := strings.TrimPrefix(trimmed, "```")
+			var body []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				body = append(body, lines[i])
+			}
+			class := ""
+			if lang != "" {
+				class = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(lang))
+			}
+			fmt.Fprintf(&sb, "<pre><code%s>%s</code></pre>\n", class, html.EscapeString(strings.Join(body, "\n")))
+		case trimmed == "":
+			flushPara()
+			flushList()
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushPara()
+			items = append(items, trimmed[2:])
+		case strings.HasPrefix(trimmed, "> "):
+			flushPara()
+			flushList()
+			fmt.Fprintf(&sb, "<blockquote>%s</blockquote>\n", inlineMarkdownHTML(trimmed[2:]))
+		case len(items) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			// continuation of the previous list item
+			items[len(items)-1] += " " + trimmed
+		default:
+			flushList()
+			para = append(para, trimmed)
+		}
+	}
+	flushPara()
+	flushList()
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+// Command docgen renders the symbols of a single module version (a
+// ModuleVersionSymbols, as produced by bzlcompiler or stardoccompiler) into
+// a static documentation tree: one markdown file per .bzl file and,
+// optionally, a self-contained HTML site with the same layout.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+const toolName = "docgen"
+
+type Config struct {
+	SymbolsFile string
+	MarkdownDir string
+	HtmlDir     string
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.SymbolsFile == "" {
+		return fmt.Errorf("symbols_file is required")
+	}
+	if cfg.MarkdownDir == "" && cfg.HtmlDir == "" {
+		return fmt.Errorf("at least one of markdown_dir or html_dir is required")
+	}
+
+	var symbols sympb.ModuleVersionSymbols
+	if err := protoutil.ReadFile(cfg.SymbolsFile, &symbols); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.SymbolsFile, err)
+	}
+
+	pages := buildPages(&symbols)
+
+	if cfg.MarkdownDir != "" {
+		for _, p := range pages {
+			if err := writePage(cfg.MarkdownDir, p.path+".md", renderMarkdown(p)); err != nil {
+				return err
+			}
+		}
+		log.Printf("Wrote %d markdown pages to %s", len(pages), cfg.MarkdownDir)
+	}
+
+	if cfg.HtmlDir != "" {
+		for _, p := range pages {
+			if err := writePage(cfg.HtmlDir, p.path+".html", renderHTML(p, pages)); err != nil {
+				return err
+			}
+		}
+		log.Printf("Wrote %d html pages to %s", len(pages), cfg.HtmlDir)
+	}
+
+	return nil
+}
+
+func writePage(dir, name string, content []byte) error {
+	filename := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("creating directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return fmt.Errorf("writing %s: %v", name, err)
+	}
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.SymbolsFile, "symbols_file", "", "the ModuleVersionSymbols file to read (.pb, .json, or .gz)")
+	fs.StringVar(&cfg.MarkdownDir, "markdown_dir", "", "the directory to write the markdown tree to")
+	fs.StringVar(&cfg.HtmlDir, "html_dir", "", "optional directory to write a self-contained HTML site to")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
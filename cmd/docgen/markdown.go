@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// relativeHref returns the link from page `from` to t, using ext as the
+// extension of generated pages.
+func relativeHref(from string, t *target, ext string) string {
+	var href string
+	if t.page != "" && t.page != from {
+		rel, err := filepath.Rel(path.Dir(from), t.page)
+		if err != nil {
+			rel = t.page
+		}
+		href = filepath.ToSlash(rel) + ext
+	}
+	if t.anchor != "" {
+		href += "#" + t.anchor
+	}
+	return href
+}
+
+// renderMarkdown renders p as GitHub-flavored markdown.
+func renderMarkdown(p *page) []byte {
+	var sb strings.Builder
+	for i, b := range p.blocks {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		switch b := b.(type) {
+		case heading:
+			if b.anchor != "" {
+				fmt.Fprintf(&sb, "<a id=\"%s\"></a>\n\n", b.anchor)
+			}
+			sb.WriteString(strings.Repeat("#", b.level))
+			sb.WriteString(" ")
+			sb.WriteString(markdownInlines(p.path, b.text))
+			sb.WriteString("\n")
+		case doc:
+			sb.WriteString(strings.TrimSpace(b.text))
+			sb.WriteString("\n")
+		case code:
+			fmt.Fprintf(&sb, "```%s\n%s\n```\n", b.lang, b.text)
+		case list:
+			for _, item := range b.items {
+				sb.WriteString("- ")
+				sb.WriteString(markdownInlines(p.path, item))
+				sb.WriteString("\n")
+			}
+		case table:
+			sb.WriteString("| " + strings.Join(b.header, " | ") + " |\n")
+			sb.WriteString("|" + strings.Repeat(" --- |", len(b.header)) + "\n")
+			for _, row := range b.rows {
+				sb.WriteString("|")
+				for _, cell := range row {
+					sb.WriteString(" ")
+					sb.WriteString(markdownTableCell(markdownInlines(p.path, cell)))
+					sb.WriteString(" |")
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return []byte(sb.String())
+}
+
+func markdownInlines(from string, inlines []inline) string {
+	var sb strings.Builder
+	for _, in := range inlines {
+		s := in.text
+		if in.code {
+			if s == "" {
+				continue
+			}
+			s = markdownCodeSpan(s)
+		}
+		if in.link != nil {
+			s = fmt.Sprintf("[%s](%s)", s, relativeHref(from, in.link, ".md"))
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+// markdownCodeSpan wraps s in enough backticks that any backticks inside it
+// do not terminate the span.
+func markdownCodeSpan(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// markdownTableCell flattens multi-line content and escapes pipes so that it
+// stays within a single table cell.
+func markdownTableCell(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\n\n", "<br><br>")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
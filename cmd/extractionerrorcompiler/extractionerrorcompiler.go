@@ -0,0 +1,238 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/extracterror"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/stardoc"
+)
+
+const toolName = "extractionerrorcompiler"
+
+type Config struct {
+	OutputFile   string
+	SymbolsFile  string
+	PackagesFile string
+	MaxExamples  int
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.SymbolsFile == "" && cfg.PackagesFile == "" {
+		return fmt.Errorf("at least one of symbols_file or packages_file is required")
+	}
+
+	var symbols sympb.ModuleRegistrySymbols
+	if cfg.SymbolsFile != "" {
+		if err := protoutil.ReadFile(cfg.SymbolsFile, &symbols); err != nil {
+			return fmt.Errorf("reading %s: %v", cfg.SymbolsFile, err)
+		}
+	}
+	var packages sympb.ModuleRegistryPackages
+	if cfg.PackagesFile != "" {
+		if err := protoutil.ReadFile(cfg.PackagesFile, &packages); err != nil {
+			return fmt.Errorf("reading %s: %v", cfg.PackagesFile, err)
+		}
+	}
+
+	result := buildReport(&symbols, &packages, cfg.MaxExamples)
+
+	if err := protoutil.WriteFile(cfg.OutputFile, result); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	var total int
+	for _, mv := range result.ModuleVersion {
+		total += len(mv.Error)
+	}
+	log.Printf("Reported %d extraction errors in %d module versions (%d causes)",
+		total, len(result.ModuleVersion), len(result.Group))
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the ModuleRegistryExtractionErrors file to write")
+	fs.StringVar(&cfg.SymbolsFile, "symbols_file", "", "the ModuleRegistrySymbols protobuf file to read")
+	fs.StringVar(&cfg.PackagesFile, "packages_file", "", "the ModuleRegistryPackages protobuf file to read")
+	fs.IntVar(&cfg.MaxExamples, "max_examples", 3, "maximum number of example errors to keep per cause")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+// buildReport collects the extraction errors of every module version and
+// groups them by kind and cause.
+func buildReport(symbols *sympb.ModuleRegistrySymbols, packages *sympb.ModuleRegistryPackages, maxExamples int) *sympb.ModuleRegistryExtractionErrors {
+	byID := make(map[string]*sympb.ModuleVersionExtractionErrors)
+	get := func(name, version string) *sympb.ModuleVersionExtractionErrors {
+		id := name + "@" + version
+		mv, ok := byID[id]
+		if !ok {
+			mv = &sympb.ModuleVersionExtractionErrors{ModuleName: name, Version: version}
+			byID[id] = mv
+		}
+		return mv
+	}
+
+	for _, mvs := range symbols.ModuleVersion {
+		mv := get(mvs.ModuleName, mvs.Version)
+		mv.Total += int32(len(mvs.File))
+		for _, file := range mvs.File {
+			if x := fileError(file); x != nil {
+				mv.Error = append(mv.Error, x)
+			}
+		}
+	}
+	for _, mvp := range packages.ModuleVersion {
+		mv := get(mvp.ModuleName, mvp.Version)
+		mv.Total += int32(len(mvp.Package))
+		mv.Error = append(mv.Error, packageErrors(mvp)...)
+	}
+
+	result := &sympb.ModuleRegistryExtractionErrors{}
+	for _, mv := range byID {
+		if len(mv.Error) > 0 {
+			result.ModuleVersion = append(result.ModuleVersion, mv)
+		}
+	}
+	slices.SortFunc(result.ModuleVersion, func(a, b *sympb.ModuleVersionExtractionErrors) int {
+		return cmp.Or(cmp.Compare(a.ModuleName, b.ModuleName), cmp.Compare(a.Version, b.Version))
+	})
+
+	result.Group = groupErrors(result.ModuleVersion, maxExamples)
+	return result
+}
+
+// fileError returns the classified error of a .bzl file, classifying the
+// error message of files extracted before errors were classified.
+func fileError(file *sympb.File) *sympb.ExtractionError {
+	if file.ExtractionError != nil {
+		return file.ExtractionError
+	}
+	if file.Error == "" {
+		return nil
+	}
+	var lbl string
+	if file.Label != nil {
+		lbl = stardoc.LabelFromProto(file.Label).String()
+	}
+	return extracterror.Classify(lbl, codes.Unknown, file.Error, nil)
+}
+
+// packageErrors returns the classified errors of BUILD files. Errors are
+// joined with failed packages on the package label: the extractor names a
+// failed package after the package of the BUILD file its errors are labeled
+// with. A failed package without a recorded error is reported as UNKNOWN.
+// When no errors were recorded at all, the packages were extracted before
+// errors were classified and are classified from their error messages.
+func packageErrors(mvp *sympb.ModuleVersionPackages) []*sympb.ExtractionError {
+	errs := slices.Clone(mvp.ExtractionError)
+	recorded := make(map[string]bool, len(mvp.ExtractionError))
+	for _, x := range mvp.ExtractionError {
+		recorded[extracterror.PackageLabel(x.Label)] = true
+	}
+	for _, pkg := range mvp.Package {
+		if len(pkg.Error) == 0 || recorded[pkg.Name] {
+			continue
+		}
+		msg := pkg.Error[0]
+		lbl := cmp.Or(pkg.Name, pkg.Filename)
+		if len(mvp.ExtractionError) == 0 {
+			errs = append(errs, extracterror.Classify(lbl, codes.Unknown, msg, nil))
+		} else {
+			errs = append(errs, &sympb.ExtractionError{
+				Kind:    sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNKNOWN,
+				Label:   lbl,
+				Message: msg,
+			})
+		}
+	}
+	return errs
+}
+
+type groupKey struct {
+	kind  sympb.ExtractionErrorKind
+	cause string
+}
+
+// groupErrors groups errors by kind and cause, most affected modules first.
+func groupErrors(moduleVersions []*sympb.ModuleVersionExtractionErrors, maxExamples int) []*sympb.ExtractionErrorGroup {
+	groups := make(map[groupKey]*sympb.ExtractionErrorGroup)
+	for _, mv := range moduleVersions {
+		id := mv.ModuleName + "@" + mv.Version
+		for _, x := range mv.Error {
+			key := groupKey{x.Kind, x.Cause}
+			g, ok := groups[key]
+			if !ok {
+				g = &sympb.ExtractionErrorGroup{Kind: x.Kind, Cause: x.Cause}
+				groups[key] = g
+			}
+			g.Count++
+			if !slices.Contains(g.Module, mv.ModuleName) {
+				g.Module = append(g.Module, mv.ModuleName)
+			}
+			if !slices.Contains(g.ModuleVersion, id) {
+				g.ModuleVersion = append(g.ModuleVersion, id)
+			}
+			if len(g.Example) < maxExamples {
+				g.Example = append(g.Example, x)
+			}
+		}
+	}
+
+	result := make([]*sympb.ExtractionErrorGroup, 0, len(groups))
+	for _, g := range groups {
+		slices.Sort(g.Module)
+		slices.Sort(g.ModuleVersion)
+		result = append(result, g)
+	}
+	slices.SortFunc(result, func(a, b *sympb.ExtractionErrorGroup) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Module), len(a.Module)),
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Cause, b.Cause),
+		)
+	})
+	return result
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+)
+
+func missingShim(lbl string) *sympb.ExtractionError {
+	return &sympb.ExtractionError{
+		Kind:  sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SHIM_MISSING,
+		Label: lbl,
+		Cause: "@local_config_cc",
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	symbols := &sympb.ModuleRegistrySymbols{
+		ModuleVersion: []*sympb.ModuleVersionSymbols{
+			{
+				ModuleName: "rules_b",
+				Version:    "1.0.0",
+				File: []*sympb.File{
+					{Label: &slpb.Label{Repo: "rules_b", Name: "defs.bzl"}},
+					{Label: &slpb.Label{Repo: "rules_b", Name: "cc.bzl"}, Error: "x", ExtractionError: missingShim("@rules_b//:cc.bzl")},
+				},
+			},
+			{
+				ModuleName: "rules_a",
+				Version:    "2.0.0",
+				File: []*sympb.File{
+					{Label: &slpb.Label{Repo: "rules_a", Name: "cc.bzl"}, Error: "x", ExtractionError: missingShim("@rules_a//:cc.bzl")},
+					// Extracted before classification: classified from the message.
+					{Label: &slpb.Label{Repo: "rules_a", Name: "bad.bzl"}, Error: "ModuleInfo request error: syntax error at 'def'"},
+				},
+			},
+			{
+				ModuleName: "rules_c",
+				Version:    "1.0.0",
+				File:       []*sympb.File{{Label: &slpb.Label{Repo: "rules_c", Name: "ok.bzl"}}},
+			},
+		},
+	}
+	packages := &sympb.ModuleRegistryPackages{
+		ModuleVersion: []*sympb.ModuleVersionPackages{{
+			ModuleName: "rules_a",
+			Version:    "2.0.0",
+			Package: []*slpb.Package{
+				{Name: "@@rules_a+//"},
+				{Name: "@rules_a//lib", Filename: "rules_a/lib/BUILD.bazel.package", Error: []string{"PackageInfo request error: x"}},
+			},
+			ExtractionError: []*sympb.ExtractionError{missingShim("@rules_a//lib:BUILD.bazel")},
+		}},
+	}
+
+	report := buildReport(symbols, packages, 2)
+
+	if len(report.ModuleVersion) != 2 {
+		t.Fatalf("got %d module versions, want 2", len(report.ModuleVersion))
+	}
+	a := report.ModuleVersion[0]
+	if a.ModuleName != "rules_a" || a.Total != 4 || len(a.Error) != 3 {
+		t.Errorf("rules_a = %v", a)
+	}
+
+	if len(report.Group) != 2 {
+		t.Fatalf("got %d groups, want 2: %v", len(report.Group), report.Group)
+	}
+	shim := report.Group[0]
+	if shim.Kind != sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SHIM_MISSING || shim.Cause != "@local_config_cc" || shim.Count != 3 {
+		t.Errorf("shim group = %v", shim)
+	}
+	if want := []string{"rules_a", "rules_b"}; !slices.Equal(shim.Module, want) {
+		t.Errorf("shim modules = %v, want %v", shim.Module, want)
+	}
+	if len(shim.Example) != 2 {
+		t.Errorf("examples should be truncated to 2, got %d", len(shim.Example))
+	}
+	syntax := report.Group[1]
+	if syntax.Kind != sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SYNTAX_ERROR || syntax.Example[0].Label != "@rules_a//:bad.bzl" {
+		t.Errorf("syntax group = %v", syntax)
+	}
+}
+
+func TestPackageErrors(t *testing.T) {
+	// Two packages fail with the same message but different classifications;
+	// the join on the package label keeps each error with its package.
+	syntax := &sympb.ExtractionError{
+		Kind:    sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SYNTAX_ERROR,
+		Label:   "@rules_a//lib:BUILD.bazel",
+		Message: "failed",
+	}
+	timeout := &sympb.ExtractionError{
+		Kind:    sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SERVER_TIMEOUT,
+		Label:   "@rules_a//:BUILD.bazel",
+		Message: "failed",
+	}
+	mvp := &sympb.ModuleVersionPackages{
+		Package: []*slpb.Package{
+			{Name: "@@rules_a+//tools"},
+			{Name: "@rules_a//", Filename: "rules_a/BUILD.bazel.package", Error: []string{"PackageInfo request error: failed"}},
+			{Name: "@rules_a//lib", Filename: "rules_a/lib/BUILD.bazel.package", Error: []string{"PackageInfo request error: failed"}},
+			// Failed without a recorded error.
+			{Name: "@rules_a//bin", Filename: "rules_a/bin/BUILD.bazel.package", Error: []string{"reading cache entry: unexpected EOF"}},
+		},
+		ExtractionError: []*sympb.ExtractionError{syntax, timeout},
+	}
+
+	errs := packageErrors(mvp)
+	if len(errs) != 3 {
+		t.Fatalf("got %d errors, want 3: %v", len(errs), errs)
+	}
+	if errs[0] != syntax || errs[1] != timeout {
+		t.Errorf("errs[:2] = %v, want the recorded errors", errs[:2])
+	}
+	if got := errs[2]; got.Kind != sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNKNOWN ||
+		got.Label != "@rules_a//bin" || got.Message != "reading cache entry: unexpected EOF" {
+		t.Errorf("errs[2] = %v, want UNKNOWN for the package without a recorded error", got)
+	}
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+const toolName = "feedcompiler"
+
+const (
+	atomNamespace   = "http://www.w3.org/2005/Atom"
+	jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+	feedsDir        = "feeds"
+)
+
+type Config struct {
+	OutputFile   string
+	RegistryFile string
+	BaseURL      string
+	MaxEntries   int
+}
+
+// feedEntry is a single module version, dated by the commit that introduced
+// it into the registry.
+type feedEntry struct {
+	module    string
+	version   string
+	published time.Time
+	commit    *bzpb.ModuleCommit
+	yanked    string
+}
+
+// feed is a named, newest-first list of entries. Path is the tarball path
+// without extension; both the .atom and .json documents are written there.
+type feed struct {
+	path        string
+	title       string
+	description string
+	homePageURL string
+	entries     []*feedEntry
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.RegistryFile == "" {
+		return fmt.Errorf("registry_file is required")
+	}
+	if cfg.BaseURL == "" {
+		return fmt.Errorf("base_url is required")
+	}
+
+	var registry bzpb.Registry
+	if err := protoutil.ReadFile(cfg.RegistryFile, &registry); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.RegistryFile, err)
+	}
+
+	feeds := buildFeeds(&registry, cfg.BaseURL, cfg.MaxEntries)
+
+	tarball, err := writeFeedsTar(feeds, &registry, strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return fmt.Errorf("failed to create feeds tarball: %v", err)
+	}
+
+	if err := os.WriteFile(cfg.OutputFile, tarball, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	log.Printf("Compiled %d feeds", len(feeds))
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the tar file of feed documents to write")
+	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file to read")
+	fs.StringVar(&cfg.BaseURL, "base_url", "", "the public registry UI URL used for entry and feed links")
+	fs.IntVar(&cfg.MaxEntries, "max_entries", 100, "maximum number of entries per feed (0 for unlimited)")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+// collectEntries returns one entry per module version that has a parseable
+// commit date, sorted newest first. Versions without commit metadata cannot
+// be placed on a timeline and are omitted.
+func collectEntries(registry *bzpb.Registry) []*feedEntry {
+	var entries []*feedEntry
+	for _, module := range registry.Modules {
+		yanked := module.GetMetadata().GetYankedVersions()
+		for _, version := range module.Versions {
+			if version.Commit == nil || version.Commit.Date == "" {
+				continue
+			}
+			published, err := time.Parse(time.RFC3339, version.Commit.Date)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, &feedEntry{
+				module:    module.Name,
+				version:   version.Version,
+				published: published.UTC(),
+				commit:    version.Commit,
+				yanked:    yanked[version.Version],
+			})
+		}
+	}
+	sortEntries(entries)
+	return entries
+}
+
+func sortEntries(entries []*feedEntry) {
+	slices.SortStableFunc(entries, func(a, b *feedEntry) int {
+		return cmp.Or(
+			b.published.Compare(a.published),
+			cmp.Compare(a.module, b.module),
+			cmp.Compare(a.version, b.version),
+		)
+	})
+}
+
+// buildFeeds assembles the global feed, one feed per module, and one feed
+// per maintainer GitHub handle. Maintainers without a GitHub handle have no
+// stable public identifier to key a feed URL on and are skipped.
+func buildFeeds(registry *bzpb.Registry, baseURL string, maxEntries int) []*feed {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	entries := collectEntries(registry)
+
+	byModule := make(map[string][]*feedEntry)
+	for _, e := range entries {
+		byModule[e.module] = append(byModule[e.module], e)
+	}
+
+	feeds := []*feed{{
+		path:        feedsDir + "/modules",
+		title:       "New module versions",
+		description: "Module versions recently added to the registry",
+		homePageURL: baseURL + "/modules",
+		entries:     entries,
+	}}
+
+	maintained := make(map[string][]*feedEntry)
+	for _, module := range registry.Modules {
+		feeds = append(feeds, &feed{
+			path:        fmt.Sprintf("%s/modules/%s", feedsDir, module.Name),
+			title:       module.Name + " releases",
+			description: fmt.Sprintf("Versions of %s added to the registry", module.Name),
+			homePageURL: fmt.Sprintf("%s/modules/%s", baseURL, module.Name),
+			entries:     byModule[module.Name],
+		})
+
+		seen := make(map[string]bool)
+		for _, m := range module.GetMetadata().GetMaintainers() {
+			handle := strings.ToLower(m.Github)
+			if handle == "" || seen[handle] {
+				continue
+			}
+			seen[handle] = true
+			maintained[handle] = append(maintained[handle], byModule[module.Name]...)
+		}
+	}
+
+	for _, handle := range slices.Sorted(maps.Keys(maintained)) {
+		list := maintained[handle]
+		sortEntries(list)
+		feeds = append(feeds, &feed{
+			path:        fmt.Sprintf("%s/maintainers/%s", feedsDir, handle),
+			title:       fmt.Sprintf("Modules maintained by %s", handle),
+			description: fmt.Sprintf("Versions of modules maintained by @%s added to the registry", handle),
+			homePageURL: "https://github.com/" + handle,
+			entries:     list,
+		})
+	}
+
+	if maxEntries > 0 {
+		for _, f := range feeds {
+			if len(f.entries) > maxEntries {
+				f.entries = f.entries[:maxEntries]
+			}
+		}
+	}
+
+	return feeds
+}
+
+// feedUpdated returns the newest entry date, falling back to the registry
+// commit date so that empty feeds still carry a meaningful timestamp.
+func feedUpdated(f *feed, registry *bzpb.Registry) time.Time {
+	if len(f.entries) > 0 {
+		return f.entries[0].published
+	}
+	// commit_date is written in `git log --format=%cI` (strict RFC 3339)
+	// form, but accept the looser %ci form as well.
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, registry.CommitDate); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+func entryURL(baseURL string, e *feedEntry) string {
+	return fmt.Sprintf("%s/modules/%s/%s", baseURL, e.module, e.version)
+}
+
+// pullRequestURL returns the link to the registry pull request that added
+// the version, or "" if unknown.
+func pullRequestURL(registry *bzpb.Registry, e *feedEntry) string {
+	if e.commit.PullRequest == "" || registry.RepositoryUrl == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/pull/%s", strings.TrimSuffix(registry.RepositoryUrl, "/"), e.commit.PullRequest)
+}
+
+func entryTitle(e *feedEntry) string {
+	title := fmt.Sprintf("%s %s", e.module, e.version)
+	if e.yanked != "" {
+		title += " (yanked)"
+	}
+	return title
+}
+
+func entrySummary(registry *bzpb.Registry, e *feedEntry) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s was added to the registry.", e.module, e.version)
+	if e.yanked != "" {
+		fmt.Fprintf(&sb, " This version has been yanked: %s", e.yanked)
+	}
+	if pr := pullRequestURL(registry, e); pr != "" {
+		fmt.Fprintf(&sb, "\n\nPull request: %s", pr)
+	}
+	return sb.String()
+}
+
+// entryAuthor returns the display name and profile URL of the PR author.
+func entryAuthor(e *feedEntry) (name, uri string) {
+	if e.commit.GithubUser == "" {
+		return "", ""
+	}
+	name = cmp.Or(e.commit.GithubName, e.commit.GithubUser)
+	return name, "https://github.com/" + e.commit.GithubUser
+}
+
+// atomFeed is the root element of an Atom (RFC 4287) document.
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary"`
+}
+
+func renderAtom(f *feed, registry *bzpb.Registry, baseURL string) ([]byte, error) {
+	selfURL := fmt.Sprintf("%s/%s.atom", baseURL, f.path)
+	doc := atomFeed{
+		Xmlns:    atomNamespace,
+		ID:       selfURL,
+		Title:    f.title,
+		Subtitle: f.description,
+		Updated:  feedUpdated(f, registry).Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: selfURL},
+			{Rel: "alternate", Type: "text/html", Href: f.homePageURL},
+		},
+	}
+	for _, e := range f.entries {
+		url := entryURL(baseURL, e)
+		date := e.published.Format(time.RFC3339)
+		entry := atomEntry{
+			ID:         url,
+			Title:      entryTitle(e),
+			Updated:    date,
+			Published:  date,
+			Links:      []atomLink{{Rel: "alternate", Type: "text/html", Href: url}},
+			Categories: []atomCategory{{Term: e.module}},
+			Summary:    entrySummary(registry, e),
+		}
+		if pr := pullRequestURL(registry, e); pr != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "related", Type: "text/html", Href: pr})
+		}
+		if name, uri := entryAuthor(e); name != "" {
+			entry.Author = &atomPerson{Name: name, URI: uri}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// jsonFeed is the root object of a JSON Feed 1.1 document.
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	ExternalURL   string           `json:"external_url,omitempty"`
+	Title         string           `json:"title"`
+	ContentText   string           `json:"content_text"`
+	DatePublished string           `json:"date_published"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+func renderJSONFeed(f *feed, registry *bzpb.Registry, baseURL string) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       f.title,
+		Description: f.description,
+		HomePageURL: f.homePageURL,
+		FeedURL:     fmt.Sprintf("%s/%s.json", baseURL, f.path),
+		Items:       []jsonFeedItem{},
+	}
+	for _, e := range f.entries {
+		url := entryURL(baseURL, e)
+		item := jsonFeedItem{
+			ID:            url,
+			URL:           url,
+			ExternalURL:   pullRequestURL(registry, e),
+			Title:         entryTitle(e),
+			ContentText:   entrySummary(registry, e),
+			DatePublished: e.published.Format(time.RFC3339),
+			Tags:          []string{e.module},
+		}
+		if name, uri := entryAuthor(e); name != "" {
+			item.Authors = []jsonFeedAuthor{{Name: name, URL: uri}}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// writeFeedsTar renders every feed as both Atom and JSON Feed into a tar
+// whose entries are merged verbatim into the release tarball.
+func writeFeedsTar(feeds []*feed, registry *bzpb.Registry, baseURL string) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	for _, f := range feeds {
+		atom, err := renderAtom(f, registry, baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s.atom: %v", f.path, err)
+		}
+		if err := addFileToTar(tw, f.path+".atom", atom); err != nil {
+			return nil, fmt.Errorf("adding %s.atom: %v", f.path, err)
+		}
+		jf, err := renderJSONFeed(f, registry, baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s.json: %v", f.path, err)
+		}
+		if err := addFileToTar(tw, f.path+".json", jf); err != nil {
+			return nil, fmt.Errorf("adding %s.json: %v", f.path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close tar writer: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func addFileToTar(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(content)),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
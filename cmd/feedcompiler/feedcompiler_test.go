@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+func newTestRegistry() *bzpb.Registry {
+	return &bzpb.Registry{
+		RepositoryUrl: "https://github.com/bazelbuild/bazel-central-registry",
+		CommitDate:    "2025-07-01 12:00:00 +0000",
+		Modules: []*bzpb.Module{
+			{
+				Name: "rules_foo",
+				Metadata: &bzpb.ModuleMetadata{
+					Maintainers:    []*bzpb.Maintainer{{Github: "Alice"}, {Email: "nobody@example.com"}},
+					YankedVersions: map[string]string{"1.0.0": "broken"},
+				},
+				Versions: []*bzpb.ModuleVersion{
+					{Version: "2.0.0", Commit: &bzpb.ModuleCommit{Date: "2025-03-01T10:00:00Z", PullRequest: "42", GithubUser: "alice", GithubName: "Alice A."}},
+					{Version: "1.0.0", Commit: &bzpb.ModuleCommit{Date: "2024-01-01T10:00:00Z"}},
+				},
+			},
+			{
+				Name:     "rules_bar",
+				Metadata: &bzpb.ModuleMetadata{Maintainers: []*bzpb.Maintainer{{Github: "alice"}}},
+				Versions: []*bzpb.ModuleVersion{
+					{Version: "0.1.0", Commit: &bzpb.ModuleCommit{Date: "2025-06-01T02:00:00+02:00"}},
+					{Version: "0.0.1"}, // no commit metadata
+				},
+			},
+			{
+				Name:     "empty",
+				Metadata: &bzpb.ModuleMetadata{},
+			},
+		},
+	}
+}
+
+func feedPaths(feeds []*feed) []string {
+	var paths []string
+	for _, f := range feeds {
+		paths = append(paths, f.path)
+	}
+	return paths
+}
+
+func entryIDs(f *feed) []string {
+	var ids []string
+	for _, e := range f.entries {
+		ids = append(ids, e.module+"@"+e.version)
+	}
+	return ids
+}
+
+func TestBuildFeeds(t *testing.T) {
+	feeds := buildFeeds(newTestRegistry(), "https://registry.example.com/", 0)
+
+	want := []string{
+		"feeds/modules",
+		"feeds/modules/rules_foo",
+		"feeds/modules/rules_bar",
+		"feeds/modules/empty",
+		"feeds/maintainers/alice",
+	}
+	if got := feedPaths(feeds); !slices.Equal(got, want) {
+		t.Fatalf("feed paths = %v; want %v", got, want)
+	}
+
+	global := feeds[0]
+	if got, want := entryIDs(global), []string{"rules_bar@0.1.0", "rules_foo@2.0.0", "rules_foo@1.0.0"}; !slices.Equal(got, want) {
+		t.Errorf("global entries = %v; want %v", got, want)
+	}
+	if got, want := entryIDs(feeds[4]), []string{"rules_bar@0.1.0", "rules_foo@2.0.0", "rules_foo@1.0.0"}; !slices.Equal(got, want) {
+		t.Errorf("maintainer entries = %v; want %v", got, want)
+	}
+	if got := global.entries[0].published.Format("2006-01-02T15:04:05Z07:00"); got != "2025-06-01T00:00:00Z" {
+		t.Errorf("published = %s; want UTC normalized date", got)
+	}
+}
+
+func TestBuildFeedsMaxEntries(t *testing.T) {
+	feeds := buildFeeds(newTestRegistry(), "https://registry.example.com", 1)
+	for _, f := range feeds {
+		if len(f.entries) > 1 {
+			t.Errorf("%s: got %d entries; want at most 1", f.path, len(f.entries))
+		}
+	}
+}
+
+func TestRenderAtom(t *testing.T) {
+	registry := newTestRegistry()
+	feeds := buildFeeds(registry, "https://registry.example.com", 0)
+
+	data, err := renderAtom(feeds[1], registry, "https://registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc atomFeed
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, data)
+	}
+	if doc.ID != "https://registry.example.com/feeds/modules/rules_foo.atom" {
+		t.Errorf("feed id = %q", doc.ID)
+	}
+	if doc.Updated != "2025-03-01T10:00:00Z" {
+		t.Errorf("feed updated = %q", doc.Updated)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("got %d entries; want 2", len(doc.Entries))
+	}
+	first := doc.Entries[0]
+	if first.ID != "https://registry.example.com/modules/rules_foo/2.0.0" {
+		t.Errorf("entry id = %q", first.ID)
+	}
+	if first.Author == nil || first.Author.Name != "Alice A." || first.Author.URI != "https://github.com/alice" {
+		t.Errorf("entry author = %+v", first.Author)
+	}
+	var related string
+	for _, link := range first.Links {
+		if link.Rel == "related" {
+			related = link.Href
+		}
+	}
+	if related != "https://github.com/bazelbuild/bazel-central-registry/pull/42" {
+		t.Errorf("related link = %q", related)
+	}
+	if second := doc.Entries[1]; second.Title != "rules_foo 1.0.0 (yanked)" || !strings.Contains(second.Summary, "broken") {
+		t.Errorf("yanked entry = %q / %q", second.Title, second.Summary)
+	}
+}
+
+func TestRenderAtomEmptyFeed(t *testing.T) {
+	registry := newTestRegistry()
+	feeds := buildFeeds(registry, "https://registry.example.com", 0)
+
+	data, err := renderAtom(feeds[3], registry, "https://registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc atomFeed
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Updated != "2025-07-01T12:00:00Z" {
+		t.Errorf("empty feed updated = %q; want registry commit date", doc.Updated)
+	}
+}
+
+func TestRenderJSONFeed(t *testing.T) {
+	registry := newTestRegistry()
+	feeds := buildFeeds(registry, "https://registry.example.com", 0)
+
+	data, err := renderJSONFeed(feeds[0], registry, "https://registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc jsonFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != jsonFeedVersion {
+		t.Errorf("version = %q", doc.Version)
+	}
+	if doc.FeedURL != "https://registry.example.com/feeds/modules.json" {
+		t.Errorf("feed_url = %q", doc.FeedURL)
+	}
+	if len(doc.Items) != 3 {
+		t.Fatalf("got %d items; want 3", len(doc.Items))
+	}
+	item := doc.Items[1]
+	if item.URL != "https://registry.example.com/modules/rules_foo/2.0.0" || item.DatePublished != "2025-03-01T10:00:00Z" {
+		t.Errorf("item = %+v", item)
+	}
+	if item.ExternalURL != "https://github.com/bazelbuild/bazel-central-registry/pull/42" {
+		t.Errorf("external_url = %q", item.ExternalURL)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/label"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+const toolName = "licensecompiler"
+
+// spdxPackage is the rules_license package that declares one license_kind
+// per SPDX identifier.
+const spdxPackage = "licenses/spdx"
+
+type Config struct {
+	OutputFile   string
+	PackagesFile string
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.PackagesFile == "" {
+		return fmt.Errorf("packages_file is required")
+	}
+
+	var packages sympb.ModuleRegistryPackages
+	if err := protoutil.ReadFile(cfg.PackagesFile, &packages); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.PackagesFile, err)
+	}
+
+	result := buildLicenses(&packages)
+
+	if err := protoutil.WriteFile(cfg.OutputFile, result); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	log.Printf("Extracted licenses for %d module versions (%d distinct SPDX identifiers)",
+		len(result.ModuleVersion), len(result.Inventory))
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the ModuleRegistryLicenses file to write")
+	fs.StringVar(&cfg.PackagesFile, "packages_file", "", "the ModuleRegistryPackages protobuf file to read")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+// buildLicenses extracts license targets and package defaults from every
+// module version, and indexes the module versions by SPDX identifier.
+func buildLicenses(packages *sympb.ModuleRegistryPackages) *sympb.ModuleRegistryLicenses {
+	result := &sympb.ModuleRegistryLicenses{}
+	inventory := make(map[string][]string)
+
+	for _, mvp := range packages.ModuleVersion {
+		mvl := extractModuleVersionLicenses(mvp)
+		if len(mvl.License) == 0 && len(mvl.Package) == 0 {
+			continue
+		}
+		result.ModuleVersion = append(result.ModuleVersion, mvl)
+		for _, id := range mvl.SpdxId {
+			inventory[id] = append(inventory[id], mvp.ModuleName+"@"+mvp.Version)
+		}
+	}
+
+	slices.SortFunc(result.ModuleVersion, func(a, b *sympb.ModuleVersionLicenses) int {
+		return cmp.Or(cmp.Compare(a.ModuleName, b.ModuleName), cmp.Compare(a.Version, b.Version))
+	})
+	for id, moduleVersions := range inventory {
+		slices.Sort(moduleVersions)
+		result.Inventory = append(result.Inventory, &sympb.LicenseInventoryEntry{
+			SpdxId:        id,
+			ModuleVersion: slices.Compact(moduleVersions),
+		})
+	}
+	slices.SortFunc(result.Inventory, func(a, b *sympb.LicenseInventoryEntry) int {
+		return cmp.Compare(a.SpdxId, b.SpdxId)
+	})
+	return result
+}
+
+func extractModuleVersionLicenses(mvp *sympb.ModuleVersionPackages) *sympb.ModuleVersionLicenses {
+	mvl := &sympb.ModuleVersionLicenses{
+		ModuleName: mvp.ModuleName,
+		Version:    mvp.Version,
+	}
+
+	pkgs := slices.Clone(mvp.Package)
+	slices.SortFunc(pkgs, func(a, b *slpb.Package) int { return cmp.Compare(a.Name, b.Name) })
+
+	for _, pkg := range pkgs {
+		pkgPath, ok := packagePath(pkg.Name)
+		if !ok {
+			continue
+		}
+		if decl := packageLicensing(mvp.ModuleName, pkgPath, pkg.PackageDeclaration); decl != nil {
+			mvl.Package = append(mvl.Package, decl)
+		}
+		for _, target := range pkg.Target {
+			if target.Kind == "license" && target.Name != "" {
+				lic := licenseTarget(mvp.ModuleName, pkgPath, target)
+				mvl.License = append(mvl.License, lic)
+				mvl.SpdxId = append(mvl.SpdxId, lic.SpdxId...)
+			}
+		}
+	}
+
+	slices.Sort(mvl.SpdxId)
+	mvl.SpdxId = slices.Compact(mvl.SpdxId)
+	return mvl
+}
+
+// packageLicensing returns the license and visibility defaults of a
+// package(...) declaration, or nil if it sets neither.
+func packageLicensing(moduleName, pkgPath string, decl *slpb.PackageDeclaration) *sympb.PackageLicensing {
+	if decl == nil {
+		return nil
+	}
+	var licenses []string
+	for _, name := range []string{"default_applicable_licenses", "default_package_metadata"} {
+		for _, s := range stringValues(decl.Attribute[name]) {
+			licenses = append(licenses, normalizeLabel(moduleName, pkgPath, s))
+		}
+	}
+	var visibility []string
+	for _, s := range stringValues(decl.Attribute["default_visibility"]) {
+		visibility = append(visibility, normalizeLabel(moduleName, pkgPath, s))
+	}
+	if len(licenses) == 0 && len(visibility) == 0 {
+		return nil
+	}
+	slices.Sort(licenses)
+	return &sympb.PackageLicensing{
+		Package:                   pkgPath,
+		DefaultApplicableLicenses: slices.Compact(licenses),
+		DefaultVisibility:         visibility,
+	}
+}
+
+func licenseTarget(moduleName, pkgPath string, target *slpb.Target) *sympb.LicenseTarget {
+	lic := &sympb.LicenseTarget{
+		Label:       &slpb.Label{Pkg: pkgPath, Name: target.Name},
+		LicenseText: normalizeLabel(moduleName, pkgPath, "LICENSE"),
+	}
+	for _, attr := range target.Attribute {
+		values := stringValues(attr.Value)
+		first := ""
+		if len(values) > 0 {
+			first = values[0]
+		}
+		switch attr.Name {
+		case "license_kinds":
+			for _, s := range values {
+				kind := normalizeLabel(moduleName, pkgPath, s)
+				lic.LicenseKind = append(lic.LicenseKind, kind)
+				if id, ok := spdxID(moduleName, kind); ok {
+					lic.SpdxId = append(lic.SpdxId, id)
+				}
+			}
+		case "license_text":
+			if first != "" {
+				lic.LicenseText = normalizeLabel(moduleName, pkgPath, first)
+			}
+		case "package_name":
+			lic.PackageName = first
+		case "package_url":
+			lic.PackageUrl = first
+		case "package_version":
+			lic.PackageVersion = first
+		case "copyright_notice":
+			lic.CopyrightNotice = first
+		}
+	}
+	return lic
+}
+
+// spdxID returns the SPDX identifier of a normalized license_kind label in
+// @rules_license//licenses/spdx, as written in moduleName. Within
+// rules_license itself normalizeLabel drops the repository, so the kinds
+// are plain //licenses/spdx labels there.
+func spdxID(moduleName, kind string) (string, bool) {
+	l, err := label.Parse(kind)
+	if err != nil || l.Pkg != spdxPackage {
+		return "", false
+	}
+	if l.Repo != "rules_license" && (l.Repo != "" || moduleName != "rules_license") {
+		return "", false
+	}
+	return l.Name, true
+}
+
+// packagePath returns the package path of a package name such as
+// "@@repo//foo/bar".
+func packagePath(name string) (string, bool) {
+	i := strings.Index(name, "//")
+	if i < 0 {
+		return "", false
+	}
+	return strings.TrimSuffix(name[i+2:], "/"), true
+}
+
+// normalizeLabel resolves s, as written in package pkgPath of moduleName,
+// to "//pkg:name", or "@repo//pkg:name" for other repositories. Canonical
+// repository names are reduced to the module name. Strings that are not
+// labels are returned unchanged.
+func normalizeLabel(moduleName, pkgPath, s string) string {
+	l, err := label.Parse(s)
+	if err != nil {
+		return s
+	}
+	if l.Relative {
+		l = label.New("", pkgPath, l.Name)
+	}
+	repo := l.Repo
+	if i := strings.IndexAny(repo, "+~"); i >= 0 {
+		repo = repo[:i]
+	}
+	if repo == moduleName {
+		repo = ""
+	}
+	l = label.New(repo, l.Pkg, l.Name)
+	return l.String()
+}
+
+// stringValues returns the strings of a string or list-of-strings value.
+func stringValues(v *slpb.Value) []string {
+	switch x := v.GetValue().(type) {
+	case *slpb.Value_String_:
+		return []string{x.String_}
+	case *slpb.Value_List:
+		var values []string
+		for _, elem := range x.List.Value {
+			if s, ok := elem.Value.(*slpb.Value_String_); ok {
+				values = append(values, s.String_)
+			}
+		}
+		return values
+	}
+	return nil
+}
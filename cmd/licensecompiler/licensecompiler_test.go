@@ -0,0 +1,173 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+)
+
+func strValue(s string) *slpb.Value {
+	return &slpb.Value{Value: &slpb.Value_String_{String_: s}}
+}
+
+func listValue(values ...string) *slpb.Value {
+	l := &slpb.ValueList{}
+	for _, v := range values {
+		l.Value = append(l.Value, strValue(v))
+	}
+	return &slpb.Value{Value: &slpb.Value_List{List: l}}
+}
+
+func TestBuildLicenses(t *testing.T) {
+	packages := &sympb.ModuleRegistryPackages{
+		ModuleVersion: []*sympb.ModuleVersionPackages{
+			{
+				ModuleName: "zlib",
+				Version:    "1.3.1",
+				Package: []*slpb.Package{
+					{
+						Name: "@@zlib+//",
+						PackageDeclaration: &slpb.PackageDeclaration{
+							Attribute: map[string]*slpb.Value{
+								"default_applicable_licenses": listValue(":license"),
+								"default_visibility":          listValue("//visibility:public"),
+							},
+						},
+						Target: []*slpb.Target{{
+							Kind: "license",
+							Name: "license",
+							Attribute: []*slpb.TargetAttribute{
+								{Name: "license_kinds", Value: listValue("@rules_license//licenses/spdx:Zlib")},
+								{Name: "package_version", Value: strValue("1.3.1")},
+							},
+						}},
+					},
+					{
+						Name: "@@zlib+//contrib",
+						Target: []*slpb.Target{{
+							Kind: "license",
+							Name: "contrib_license",
+							Attribute: []*slpb.TargetAttribute{
+								{Name: "license_kinds", Value: listValue("@@rules_license+//licenses/spdx:MIT", "//licenses:custom")},
+								{Name: "license_text", Value: strValue("COPYING")},
+							},
+						}},
+					},
+				},
+			},
+			{
+				ModuleName: "abseil-cpp",
+				Version:    "1.0.0",
+				Package: []*slpb.Package{{
+					Name: "@@abseil-cpp+//absl",
+					PackageDeclaration: &slpb.PackageDeclaration{
+						Attribute: map[string]*slpb.Value{"default_visibility": listValue(":__subpackages__")},
+					},
+				}},
+			},
+			{
+				ModuleName: "empty",
+				Version:    "1.0.0",
+				Package:    []*slpb.Package{{Name: "@@empty+//"}},
+			},
+		},
+	}
+
+	result := buildLicenses(packages)
+	if len(result.ModuleVersion) != 2 {
+		t.Fatalf("got %d module versions, want 2", len(result.ModuleVersion))
+	}
+
+	absl := result.ModuleVersion[0]
+	if absl.ModuleName != "abseil-cpp" || len(absl.Package) != 1 ||
+		!slices.Equal(absl.Package[0].DefaultVisibility, []string{"//absl:__subpackages__"}) {
+		t.Errorf("abseil-cpp = %v", absl)
+	}
+
+	zlib := result.ModuleVersion[1]
+	if want := []string{"MIT", "Zlib"}; !slices.Equal(zlib.SpdxId, want) {
+		t.Errorf("spdx_id = %v; want %v", zlib.SpdxId, want)
+	}
+	if len(zlib.Package) != 1 {
+		t.Fatalf("got %d packages, want 1", len(zlib.Package))
+	}
+	root := zlib.Package[0]
+	if !slices.Equal(root.DefaultApplicableLicenses, []string{"//:license"}) ||
+		!slices.Equal(root.DefaultVisibility, []string{"//visibility:public"}) {
+		t.Errorf("root package = %v", root)
+	}
+
+	lic := zlib.License[0]
+	if lic.LicenseText != "//:LICENSE" || lic.PackageVersion != "1.3.1" ||
+		!slices.Equal(lic.LicenseKind, []string{"@rules_license//licenses/spdx:Zlib"}) {
+		t.Errorf("license = %v", lic)
+	}
+	contrib := zlib.License[1]
+	if contrib.Label.Pkg != "contrib" || contrib.LicenseText != "//contrib:COPYING" ||
+		!slices.Equal(contrib.LicenseKind, []string{"@rules_license//licenses/spdx:MIT", "//licenses:custom"}) ||
+		!slices.Equal(contrib.SpdxId, []string{"MIT"}) {
+		t.Errorf("contrib license = %v", contrib)
+	}
+
+	var ids []string
+	for _, entry := range result.Inventory {
+		ids = append(ids, entry.SpdxId)
+		if !slices.Equal(entry.ModuleVersion, []string{"zlib@1.3.1"}) {
+			t.Errorf("%s: module_version = %v", entry.SpdxId, entry.ModuleVersion)
+		}
+	}
+	if want := []string{"MIT", "Zlib"}; !slices.Equal(ids, want) {
+		t.Errorf("inventory = %v; want %v", ids, want)
+	}
+}
+
+func TestSpdxID(t *testing.T) {
+	for _, tc := range []struct {
+		module, kind, want string
+	}{
+		{"zlib", "@rules_license//licenses/spdx:Zlib", "Zlib"},
+		{"zlib", "//licenses/spdx:Zlib", ""},
+		{"zlib", "@other//licenses/spdx:Zlib", ""},
+		{"rules_license", "//licenses/spdx:Apache-2.0", "Apache-2.0"},
+		{"rules_license", "//licenses/generic:notice", ""},
+	} {
+		got, ok := spdxID(tc.module, tc.kind)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Errorf("spdxID(%s, %s) = %q, %v; want %q", tc.module, tc.kind, got, ok, tc.want)
+		}
+	}
+}
+
+func TestRulesLicenseSelf(t *testing.T) {
+	mvl := extractModuleVersionLicenses(&sympb.ModuleVersionPackages{
+		ModuleName: "rules_license",
+		Version:    "1.0.0",
+		Package: []*slpb.Package{{
+			Name: "@@rules_license+//",
+			Target: []*slpb.Target{{
+				Kind: "license",
+				Name: "license",
+				Attribute: []*slpb.TargetAttribute{
+					{Name: "license_kinds", Value: listValue("@rules_license//licenses/spdx:Apache-2.0", "//licenses/spdx:MIT")},
+				},
+			}},
+		}},
+	})
+	if want := []string{"Apache-2.0", "MIT"}; !slices.Equal(mvl.SpdxId, want) {
+		t.Errorf("SpdxId = %v; want %v", mvl.SpdxId, want)
+	}
+}
+
+func TestPackageLicensingDedup(t *testing.T) {
+	decl := packageLicensing("zlib", "", &slpb.PackageDeclaration{
+		Attribute: map[string]*slpb.Value{
+			"default_applicable_licenses": listValue(":license", "//other:license"),
+			"default_package_metadata":    listValue("//:license"),
+		},
+	})
+	if want := []string{"//:license", "//other:license"}; !slices.Equal(decl.DefaultApplicableLicenses, want) {
+		t.Errorf("DefaultApplicableLicenses = %v; want %v", decl.DefaultApplicableLicenses, want)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+	"google.golang.org/protobuf/proto"
+)
+
+const toolName = "maintainerindexcompiler"
+
+type Config struct {
+	OutputFile   string
+	RegistryFile string
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.RegistryFile == "" {
+		return fmt.Errorf("registry_file is required")
+	}
+
+	var registry bzpb.Registry
+	if err := protoutil.ReadFile(cfg.RegistryFile, &registry); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.RegistryFile, err)
+	}
+
+	index := buildMaintainerIndex(&registry)
+
+	if err := protoutil.WriteFile(cfg.OutputFile, index); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	log.Printf("Indexed %d maintainers (%d unmaintained modules, %d do_not_notify-only modules)",
+		len(index.Maintainers), len(index.UnmaintainedModules), len(index.DoNotNotifyOnlyModules))
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the MaintainerIndex file to write")
+	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file to read")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+// maintainerKey returns the stable identity of a maintainer: the numeric
+// github user id survives handle renames, so it wins when present. A record
+// with only a handle is keyed by the id that handle has elsewhere in the
+// registry, if any (see githubUserIDs). Returns "" for a record with no
+// usable identity.
+func maintainerKey(m *bzpb.Maintainer, ids map[string]int32) string {
+	id := m.GithubUserId
+	if id == 0 && m.Github != "" {
+		id = ids[strings.ToLower(m.Github)]
+	}
+	switch {
+	case id != 0:
+		return "github_user_id:" + strconv.Itoa(int(id))
+	case m.Github != "":
+		return "github:" + strings.ToLower(m.Github)
+	case m.Email != "":
+		return "email:" + strings.ToLower(m.Email)
+	}
+	return ""
+}
+
+// githubUserIDs maps the (lowercased) github handles of maintainer records
+// that carry both a handle and a user id to that id. A handle seen with
+// different ids (renamed and taken over by someone else) is left out, as it
+// cannot be attributed.
+func githubUserIDs(registry *bzpb.Registry) map[string]int32 {
+	ids := make(map[string]int32)
+	ambiguous := make(map[string]bool)
+	for _, module := range registry.Modules {
+		for _, m := range module.GetMetadata().GetMaintainers() {
+			if m.Github == "" || m.GithubUserId == 0 {
+				continue
+			}
+			handle := strings.ToLower(m.Github)
+			if id, ok := ids[handle]; ok && id != m.GithubUserId {
+				ambiguous[handle] = true
+			}
+			ids[handle] = m.GithubUserId
+		}
+	}
+	for handle := range ambiguous {
+		delete(ids, handle)
+	}
+	return ids
+}
+
+// buildMaintainerIndex groups every module in the registry under each of its
+// maintainers and flags modules that have nobody to notify.
+func buildMaintainerIndex(registry *bzpb.Registry) *bzpb.MaintainerIndex {
+	index := &bzpb.MaintainerIndex{}
+	portfolios := make(map[string]*bzpb.MaintainerPortfolio)
+	ids := githubUserIDs(registry)
+
+	for _, module := range registry.Modules {
+		maintainers := module.GetMetadata().GetMaintainers()
+		if len(maintainers) == 0 {
+			index.UnmaintainedModules = append(index.UnmaintainedModules, module.Name)
+			continue
+		}
+		if !slices.ContainsFunc(maintainers, func(m *bzpb.Maintainer) bool { return !m.DoNotNotify }) {
+			index.DoNotNotifyOnlyModules = append(index.DoNotNotifyOnlyModules, module.Name)
+		}
+
+		seen := make(map[string]bool)
+		for _, m := range maintainers {
+			key := maintainerKey(m, ids)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			portfolio, ok := portfolios[key]
+			if !ok {
+				portfolio = &bzpb.MaintainerPortfolio{Key: key, Maintainer: proto.CloneOf(m)}
+				portfolios[key] = portfolio
+			} else {
+				mergeMaintainer(portfolio.Maintainer, m)
+			}
+
+			entry := makeMaintainerModule(module, m)
+			portfolio.Modules = append(portfolio.Modules, entry)
+			portfolio.ReleaseCount += entry.ReleaseCount
+			portfolio.AuthoredReleaseCount += entry.AuthoredReleaseCount
+			portfolio.LastActivity = laterDate(portfolio.LastActivity, entry.LastActivity)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(portfolios)) {
+		portfolio := portfolios[key]
+		slices.SortFunc(portfolio.Modules, func(a, b *bzpb.MaintainerModule) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		index.Maintainers = append(index.Maintainers, portfolio)
+	}
+	slices.Sort(index.UnmaintainedModules)
+	slices.Sort(index.DoNotNotifyOnlyModules)
+
+	return index
+}
+
+// makeMaintainerModule summarizes the releases of a module from the point of
+// view of one of its maintainers.
+func makeMaintainerModule(module *bzpb.Module, m *bzpb.Maintainer) *bzpb.MaintainerModule {
+	entry := &bzpb.MaintainerModule{
+		Name:         module.Name,
+		ReleaseCount: int32(len(module.Versions)),
+		DoNotNotify:  m.DoNotNotify,
+	}
+	for i, mv := range module.Versions {
+		// versions are sorted newest first; prefer the explicit flag when set
+		if mv.IsLatestVersion || (i == 0 && entry.LatestVersion == "") {
+			entry.LatestVersion = mv.Version
+		}
+		if mv.Commit == nil {
+			continue
+		}
+		entry.LastActivity = laterDate(entry.LastActivity, mv.Commit.Date)
+		if m.Github != "" && strings.EqualFold(mv.Commit.GithubUser, m.Github) {
+			entry.AuthoredReleaseCount++
+		}
+	}
+	return entry
+}
+
+// mergeMaintainer fills fields missing from dst with those from src, so a
+// maintainer listed with only a handle in one module and a full record in
+// another ends up with the full record.
+func mergeMaintainer(dst, src *bzpb.Maintainer) {
+	if dst.Name == "" {
+		dst.Name = src.Name
+	}
+	if dst.Email == "" {
+		dst.Email = src.Email
+	}
+	if dst.Github == "" {
+		dst.Github = src.Github
+	}
+	if dst.GithubUserId == 0 {
+		dst.GithubUserId = src.GithubUserId
+	}
+}
+
+// laterDate returns the later of two ISO 8601 dates. Unparseable dates lose
+// to parseable ones; empty strings are ignored.
+func laterDate(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	switch {
+	case errB != nil:
+		return a
+	case errA != nil:
+		return b
+	case tb.After(ta):
+		return b
+	}
+	return a
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+func newTestRegistry() *bzpb.Registry {
+	alice := &bzpb.Maintainer{Name: "Alice", Github: "alice", GithubUserId: 1}
+	return &bzpb.Registry{
+		Modules: []*bzpb.Module{
+			{
+				Name:     "rules_foo",
+				Metadata: &bzpb.ModuleMetadata{Maintainers: []*bzpb.Maintainer{alice, {Github: "bob", DoNotNotify: true}}},
+				Versions: []*bzpb.ModuleVersion{
+					{Version: "2.0.0", IsLatestVersion: true, Commit: &bzpb.ModuleCommit{Date: "2025-03-01T10:00:00Z", GithubUser: "Alice"}},
+					{Version: "1.0.0", Commit: &bzpb.ModuleCommit{Date: "2024-01-01T10:00:00Z", GithubUser: "bcr-bot"}},
+				},
+			},
+			{
+				// Same maintainer under a renamed handle; the user id keys it.
+				Name:     "rules_bar",
+				Metadata: &bzpb.ModuleMetadata{Maintainers: []*bzpb.Maintainer{{Github: "alice-renamed", GithubUserId: 1, Email: "alice@example.com"}}},
+				Versions: []*bzpb.ModuleVersion{
+					{Version: "0.1.0", Commit: &bzpb.ModuleCommit{Date: "2025-06-01T00:00:00+02:00", GithubUser: "alice-renamed"}},
+				},
+			},
+			{
+				Name:     "orphan",
+				Metadata: &bzpb.ModuleMetadata{},
+				Versions: []*bzpb.ModuleVersion{{Version: "1.0"}},
+			},
+			{
+				Name:     "quiet",
+				Metadata: &bzpb.ModuleMetadata{Maintainers: []*bzpb.Maintainer{{Email: "q@example.com", DoNotNotify: true}}},
+			},
+		},
+	}
+}
+
+func TestBuildMaintainerIndex(t *testing.T) {
+	index := buildMaintainerIndex(newTestRegistry())
+
+	if want := []string{"orphan"}; !slices.Equal(index.UnmaintainedModules, want) {
+		t.Errorf("UnmaintainedModules = %v; want %v", index.UnmaintainedModules, want)
+	}
+	if want := []string{"quiet"}; !slices.Equal(index.DoNotNotifyOnlyModules, want) {
+		t.Errorf("DoNotNotifyOnlyModules = %v; want %v", index.DoNotNotifyOnlyModules, want)
+	}
+
+	var keys []string
+	for _, p := range index.Maintainers {
+		keys = append(keys, p.Key)
+	}
+	if want := []string{"email:q@example.com", "github:bob", "github_user_id:1"}; !slices.Equal(keys, want) {
+		t.Fatalf("maintainer keys = %v; want %v", keys, want)
+	}
+
+	alice := index.Maintainers[2]
+	if alice.ReleaseCount != 3 {
+		t.Errorf("alice.ReleaseCount = %d; want 3", alice.ReleaseCount)
+	}
+	if alice.AuthoredReleaseCount != 2 {
+		t.Errorf("alice.AuthoredReleaseCount = %d; want 2", alice.AuthoredReleaseCount)
+	}
+	if alice.LastActivity != "2025-06-01T00:00:00+02:00" {
+		t.Errorf("alice.LastActivity = %q", alice.LastActivity)
+	}
+	if alice.Maintainer.Email != "alice@example.com" {
+		t.Errorf("alice email not merged from second record: %v", alice.Maintainer)
+	}
+	var modules []string
+	for _, m := range alice.Modules {
+		modules = append(modules, m.Name)
+	}
+	if want := []string{"rules_bar", "rules_foo"}; !slices.Equal(modules, want) {
+		t.Errorf("alice modules = %v; want %v", modules, want)
+	}
+	if got := alice.Modules[1].LatestVersion; got != "2.0.0" {
+		t.Errorf("rules_foo LatestVersion = %q; want 2.0.0", got)
+	}
+
+	bob := index.Maintainers[1]
+	if len(bob.Modules) != 1 || !bob.Modules[0].DoNotNotify {
+		t.Errorf("bob modules = %v; want rules_foo with do_not_notify", bob.Modules)
+	}
+}
+
+func TestBuildMaintainerIndexHandleAlias(t *testing.T) {
+	// carol has a user id in rules_a but only a handle (in another case) in
+	// rules_b; dave's handle was seen with two ids and is not aliased.
+	registry := &bzpb.Registry{
+		Modules: []*bzpb.Module{
+			{Name: "rules_a", Metadata: &bzpb.ModuleMetadata{Maintainers: []*bzpb.Maintainer{
+				{Github: "carol", GithubUserId: 7},
+				{Github: "dave", GithubUserId: 8},
+			}}},
+			{Name: "rules_b", Metadata: &bzpb.ModuleMetadata{Maintainers: []*bzpb.Maintainer{
+				{Github: "Carol", Email: "carol@example.com"},
+				{Github: "dave"},
+			}}},
+			{Name: "rules_c", Metadata: &bzpb.ModuleMetadata{Maintainers: []*bzpb.Maintainer{
+				{Github: "dave", GithubUserId: 9},
+			}}},
+		},
+	}
+	index := buildMaintainerIndex(registry)
+
+	portfolios := make(map[string][]string)
+	for _, p := range index.Maintainers {
+		for _, m := range p.Modules {
+			portfolios[p.Key] = append(portfolios[p.Key], m.Name)
+		}
+	}
+	for key, want := range map[string][]string{
+		"github_user_id:7": {"rules_a", "rules_b"},
+		"github_user_id:8": {"rules_a"},
+		"github_user_id:9": {"rules_c"},
+		"github:dave":      {"rules_b"},
+	} {
+		if got := portfolios[key]; !slices.Equal(got, want) {
+			t.Errorf("%s modules = %v; want %v", key, got, want)
+		}
+	}
+	if len(portfolios) != 4 {
+		t.Errorf("got %d portfolios; want 4: %v", len(portfolios), portfolios)
+	}
+	if carol := index.Maintainers[slices.IndexFunc(index.Maintainers, func(p *bzpb.MaintainerPortfolio) bool {
+		return p.Key == "github_user_id:7"
+	})]; carol.Maintainer.Email != "carol@example.com" {
+		t.Errorf("carol email not merged from the handle-only record: %v", carol.Maintainer)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	registryFile := filepath.Join(dir, "registry.pb")
+	outputFile := filepath.Join(dir, "maintainers.pb")
+	if err := protoutil.WriteFile(registryFile, newTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"--registry_file", registryFile, "--output_file", outputFile}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var index bzpb.MaintainerIndex
+	if err := protoutil.ReadFile(outputFile, &index); err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Maintainers) != 3 {
+		t.Errorf("got %d maintainers; want 3", len(index.Maintainers))
+	}
+}
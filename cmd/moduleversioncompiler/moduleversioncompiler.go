@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strings"
 
 	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
 	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
 	gitpkg "github.com/bazel-contrib/bcr-frontend/pkg/git"
 	"github.com/bazel-contrib/bcr-frontend/pkg/modulebazel"
+	"github.com/bazel-contrib/bcr-frontend/pkg/netutil"
 	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/patchfile"
 	"github.com/bazel-contrib/bcr-frontend/pkg/presubmityml"
 	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
 	"github.com/bazel-contrib/bcr-frontend/pkg/sourcejson"
@@ -36,6 +39,10 @@ type Config struct {
 	UrlStatusMessage         string
 	DocsUrlStatusCode        int
 	DocsUrlStatusMessage     string
+	IntegrityStatus          paramsfile.StringSlice
+	PatchStats               paramsfile.StringSlice
+	Mvs                      paramsfile.StringSlice
+	MvsDev                   paramsfile.StringSlice
 	SourceCommitSha          string
 	IsLatestVersion          bool
 }
@@ -92,8 +99,14 @@ func run(args []string) error {
 				Message: cfg.UrlStatusMessage,
 			}
 		}
+		if err := applyPatchStats(module.Source, cfg.PatchStats); err != nil {
+			return err
+		}
+		if err := applyIntegrityStatus(module.Source, cfg.IntegrityStatus); err != nil {
+			return err
+		}
 		if module.Source.DocsUrl != "" {
-			module.Source.UrlStatus = &bzpb.ResourceStatus{
+			module.Source.DocsUrlStatus = &bzpb.ResourceStatus{
 				Url:     module.Source.DocsUrl,
 				Code:    int32(cfg.DocsUrlStatusCode),
 				Message: cfg.DocsUrlStatusMessage,
@@ -150,6 +163,10 @@ func run(args []string) error {
 		}
 	}
 
+	if err := applySelectedVersions(module, cfg.Mvs, cfg.MvsDev); err != nil {
+		return err
+	}
+
 	// Write the compiled ModuleVersion to output file
 	if err := protoutil.WriteFile(cfg.OutputFile, module); err != nil {
 		return fmt.Errorf("failed to write output file: %v", err)
@@ -177,6 +194,10 @@ func parseFlags(args []string) (cfg Config, err error) {
 	fs.StringVar(&cfg.UrlStatusMessage, "url_status_message", "", "HTTP status message for the source URL (optional)")
 	fs.IntVar(&cfg.DocsUrlStatusCode, "docs_url_status_code", 0, "HTTP status code for the docs URL (optional)")
 	fs.StringVar(&cfg.DocsUrlStatusMessage, "docs_url_status_message", "", "HTTP status message for the docs URL (optional)")
+	fs.Var(&cfg.IntegrityStatus, "integrity_status", "the JSON-encoded integrity verification result of a source or mirror URL (repeatable)")
+	fs.Var(&cfg.PatchStats, "patch_stats", "the JSON-encoded content stats of a patch file (repeatable)")
+	fs.Var(&cfg.Mvs, "mvs", "the version selected by MVS for a regular dependency in the format NAME=VERSION (repeatable)")
+	fs.Var(&cfg.MvsDev, "mvs_dev", "the version selected by MVS for a dev dependency in the format NAME=VERSION (repeatable)")
 	fs.StringVar(&cfg.SourceCommitSha, "source_commit_sha", "", "the git commit SHA for the source URL (resolved from tags/releases, optional)")
 	fs.BoolVar(&cfg.IsLatestVersion, "is_latest_version", false, "if true, marks this module version as the latest one")
 
@@ -192,6 +213,82 @@ func parseFlags(args []string) (cfg Config, err error) {
 	return
 }
 
+// applyIntegrityStatus decodes the integrity verification results computed
+// by the gazelle extension. Only URLs that were actually verified get an
+// entry; a URL without one was not checked, and a URL that could not be
+// downloaded is recorded as a download failure rather than as verified.
+func applyIntegrityStatus(source *bzpb.ModuleSource, values []string) error {
+	for _, value := range values {
+		url, status, err := netutil.ParseIntegrityStatus(value)
+		if err != nil {
+			return fmt.Errorf("--integrity_status: %v", err)
+		}
+		source.IntegrityStatus = append(source.IntegrityStatus, &bzpb.ResourceStatus{
+			Url:               url,
+			Code:              int32(status.Code),
+			Message:           status.Message,
+			Integrity:         status.Expected,
+			ActualIntegrity:   status.Actual,
+			IntegrityMismatch: status.Mismatch(),
+			DownloadFailed:    status.DownloadFailed(),
+		})
+	}
+	slices.SortFunc(source.IntegrityStatus, func(a, b *bzpb.ResourceStatus) int {
+		return strings.Compare(a.Url, b.Url)
+	})
+	return nil
+}
+
+// applyPatchStats decodes the per-patch stats computed by the gazelle
+// extension and derives the overlay stats from source.json.
+func applyPatchStats(source *bzpb.ModuleSource, patchStats []string) error {
+	for _, value := range patchStats {
+		stats, err := patchfile.ParseStats(value)
+		if err != nil {
+			return fmt.Errorf("--patch_stats: %v", err)
+		}
+		source.PatchStats = append(source.PatchStats, stats)
+	}
+	slices.SortFunc(source.PatchStats, func(a, b *bzpb.PatchStats) int {
+		return strings.Compare(a.Filename, b.Filename)
+	})
+	source.OverlayStats = patchfile.MakeOverlayStats(source.Overlay)
+	return nil
+}
+
+// applySelectedVersions records the MVS-selected version on each direct
+// dependency. The MVS result covers the whole transitive graph; entries for
+// modules that are not direct dependencies are ignored.
+func applySelectedVersions(module *bzpb.ModuleVersion, mvs, mvsDev []string) error {
+	parse := func(flagName string, values []string) (map[string]string, error) {
+		selected := make(map[string]string)
+		for _, value := range values {
+			name, version, ok := strings.Cut(value, "=")
+			if !ok {
+				return nil, fmt.Errorf("malformed --%s %q: want NAME=VERSION", flagName, value)
+			}
+			selected[name] = version
+		}
+		return selected, nil
+	}
+	regular, err := parse("mvs", mvs)
+	if err != nil {
+		return err
+	}
+	dev, err := parse("mvs_dev", mvsDev)
+	if err != nil {
+		return err
+	}
+	for _, dep := range module.Deps {
+		if dep.Dev {
+			dep.SelectedVersion = dev[dep.Name]
+		} else {
+			dep.SelectedVersion = regular[dep.Name]
+		}
+	}
+	return nil
+}
+
 func mustFindDependencyByName(module *bzpb.ModuleVersion, name string) *bzpb.ModuleDependency {
 	for _, dep := range module.Deps {
 		if name == dep.Name {
@@ -10,30 +10,36 @@ import (
 
 	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
 	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/extractcache"
 	"github.com/bazelbuild/bazel-gazelle/label"
 )
 
 const (
-	// workDir is a relative path (from the execroot) that we rewrite files
+	// defaultWorkDir is a relative path (from the execroot) that we rewrite files
 	// into.  See bzlcompiler/config.go for why this matters.
-	workDir = "work"
+	defaultWorkDir = "work"
 )
 
 type config struct {
+	Cache               *extractcache.Cache
+	CacheDir            string
 	Client              slpb.StarlarkClient
 	Cwd                 string
 	JavaInterpreterFile string
 	LogFile             string
 	Logger              *log.Logger
+	MaxServers          int
 	OutputFile          string
 	PersistentWorker    bool
 	ErrorLimit          int
 	Port                int
 	ServerJarFile       string
+	WorkDir             string // the directory (relative to Cwd) that files are rewritten into
 	BzlFiles            bzlFileSlice
 	PackageFiles        packageFileSlice
 	FilesToExtract      []string
 	moduleDeps          moduleDepsMap
+	cacheKeyer          *extractcache.Keyer
 }
 
 func parseConfig(args []string) (*config, error) {
@@ -42,9 +48,11 @@ func parseConfig(args []string) (*config, error) {
 	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
 	fs.StringVar(&cfg.JavaInterpreterFile, "java_interpreter_file", "", "path to a java interpreter")
 	fs.StringVar(&cfg.ServerJarFile, "server_jar_file", "", "the executable jar file for the server")
+	fs.StringVar(&cfg.CacheDir, "cache_dir", "", "directory of the content-addressed extraction cache (optional, shared between runs and tools)")
 	fs.StringVar(&cfg.LogFile, "log_file", "", "path to log file (optional, defaults to stderr)")
 	fs.StringVar(&cfg.OutputFile, "output_file", "", "the output file to write")
 	fs.IntVar(&cfg.Port, "port", 0, "the port number to use for the server process.  If a port is assigned, assume server is running external to this worker.  If it is unassigned, self-host the server as a child process.")
+	fs.IntVar(&cfg.MaxServers, "max_servers", 0, "the maximum number of server processes a multiplex persistent worker may start (defaults to min(4, NumCPU))")
 	fs.BoolVar(&cfg.PersistentWorker, "persistent_worker", false, "present if this tool is being invoked as a bazel persistent worker")
 	fs.IntVar(&cfg.ErrorLimit, "error_limit", 0, "fail if we exceed this limit (must be non-zero to take effect)")
 	fs.Var(&cfg.PackageFiles, "package_file", "package source file mapping in the format REPO|LABEL|PATH (repeatable)")
@@ -64,6 +72,7 @@ func parseConfig(args []string) (*config, error) {
 		return nil, fmt.Errorf("getting os cwd: %v", err)
 	}
 	cfg.Cwd = wd
+	cfg.WorkDir = defaultWorkDir
 
 	if cfg.LogFile != "" {
 		logFile, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
@@ -5,37 +5,35 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
-	"strings"
 	"time"
 
 	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
 	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/extractcache"
+	"github.com/bazel-contrib/bcr-frontend/pkg/extracterror"
 	"github.com/bazel-contrib/bcr-frontend/pkg/stardoc"
+	"github.com/bazel-contrib/bcr-frontend/pkg/starlarkserver"
+	"google.golang.org/grpc/status"
 )
 
 var errConstellateUnavailable = errors.New("constellate server unavailable")
 
-func isConnectionError(err error) bool {
-	s := err.Error()
-	return strings.Contains(s, "connection refused") ||
-		strings.Contains(s, "connection reset") ||
-		strings.Contains(s, "connect: no route to host") ||
-		(strings.Contains(s, "dial tcp") && strings.Contains(s, "connect:"))
-}
-
-func extractModuleVersionPackages(cfg *config, packageFileByPath map[string]*packageFile, filesToExtract []string) (*sympb.ModuleVersionPackages, error) {
+func extractModuleVersionPackages(ctx context.Context, cfg *config, packageFileByPath map[string]*packageFile, filesToExtract []string) (*sympb.ModuleVersionPackages, error) {
 	result := &sympb.ModuleVersionPackages{
 		Source: sympb.SymbolSource_BEST_EFFORT,
 	}
 
 	var errCount int
 	for _, filePath := range filesToExtract {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		pkgFile, found := packageFileByPath[filePath]
 		if !found {
 			return nil, fmt.Errorf("file not found: %q (was it also included as a --package_file?)", filePath)
 		}
 
-		pkg, err := extractPackage(cfg, pkgFile)
+		pkg, err := extractPackage(ctx, cfg, pkgFile)
 		if err != nil {
 			if errors.Is(err, errConstellateUnavailable) {
 				return nil, err
@@ -46,7 +44,23 @@ func extractModuleVersionPackages(cfg *config, packageFileByPath map[string]*pac
 				cfg.Logger.Printf("🔴 failed to extract %+v: %v", pkgFile, err)
 			}
 			errCount++
+			// Every failure gets an error labeled with the BUILD file, and
+			// the failed package is named after its package label so the
+			// two can be joined. Errors the server did not report are
+			// UNKNOWN.
+			targetFileLabel := stardoc.LabelFromProto(pkgFile.Label).String()
+			var xerr *extracterror.Error
+			if errors.As(err, &xerr) {
+				result.ExtractionError = append(result.ExtractionError, xerr.Proto)
+			} else {
+				result.ExtractionError = append(result.ExtractionError, &sympb.ExtractionError{
+					Kind:    sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNKNOWN,
+					Label:   targetFileLabel,
+					Message: err.Error(),
+				})
+			}
 			result.Package = append(result.Package, &slpb.Package{
+				Name:     extracterror.PackageLabel(targetFileLabel),
 				Filename: filePath,
 				Error:    []string{err.Error()},
 			})
@@ -59,31 +73,87 @@ func extractModuleVersionPackages(cfg *config, packageFileByPath map[string]*pac
 	success := total - errCount
 	pct := float64(success) / float64(total) * 100.0
 	cfg.Logger.Printf("Extraction: %d/%d %.1f%%", success, total, pct)
+	if len(result.ExtractionError) > 0 {
+		cfg.Logger.Printf("Extraction errors: %s", extracterror.Summary(result.ExtractionError))
+	}
+	if cfg.Cache != nil {
+		cfg.Logger.Printf("Extraction cache: %v", cfg.Cache.Stats())
+	}
 
 	return result, nil
 }
 
-func extractPackage(cfg *config, file *packageFile) (*slpb.Package, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+func extractPackage(ctx context.Context, cfg *config, file *packageFile) (*slpb.Package, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
 	targetFileLabel := stardoc.LabelFromProto(file.Label).String()
 
+	key := cacheKey(cfg, file.Label, targetFileLabel)
+	if key != "" {
+		var cached slpb.Package
+		if cfg.Cache.Get(key, &cached) {
+			return &cached, nil
+		}
+	}
+
 	response, err := cfg.Client.PackageInfo(ctx, &slpb.PackageInfoRequest{
 		TargetFileLabel: targetFileLabel,
 		Rel:             file.Label.Pkg,
-		BuiltinsBzlPath: filepath.Join(cfg.Cwd, workDir, "external/_builtins/src/main/starlark/builtins_bzl"),
+		BuiltinsBzlPath: filepath.Join(cfg.Cwd, cfg.WorkDir, "external/_builtins/src/main/starlark/builtins_bzl"),
 		DepRoots: []string{
-			filepath.Join(cfg.Cwd, workDir),
+			filepath.Join(cfg.Cwd, cfg.WorkDir),
 		},
 	})
 	if err != nil {
-		cleanErr := cleanErrorMessage(err, cfg.Cwd)
-		if isConnectionError(err) {
+		cleanErr := cleanErrorMessage(err, cfg.Cwd, cfg.WorkDir)
+		if starlarkserver.IsConnectionError(err) {
 			return nil, fmt.Errorf("%w: %v", errConstellateUnavailable, cleanErr)
 		}
-		return nil, fmt.Errorf("PackageInfo request error: %v", cleanErr)
+		x := extracterror.Classify(targetFileLabel, status.Code(err), cleanErr.Error(), extracterror.RepoExists(filepath.Join(cfg.Cwd, cfg.WorkDir)))
+		return nil, extracterror.Wrap(fmt.Errorf("PackageInfo request error: %v", cleanErr), x)
+	}
+
+	if key != "" {
+		if err := cfg.Cache.Put(key, response); err != nil {
+			cfg.Logger.Printf("WARN: writing extraction cache entry for %s: %v", targetFileLabel, err)
+		}
 	}
 
 	return response, nil
 }
+
+// prepareCache opens the extraction cache, if configured, once the work
+// directory has been populated. The cache is an optimization: failures are
+// logged and extraction proceeds without it.
+func prepareCache(cfg *config) {
+	if cfg.CacheDir == "" {
+		return
+	}
+	cache, err := extractcache.Open(cfg.CacheDir)
+	if err != nil {
+		cfg.Logger.Printf("WARN: extraction cache disabled: %v", err)
+		return
+	}
+	keyer, err := extractcache.NewKeyer(filepath.Join(cfg.Cwd, cfg.WorkDir), "PackageInfo", cfg.ServerJarFile)
+	if err != nil {
+		cfg.Logger.Printf("WARN: extraction cache disabled: %v", err)
+		return
+	}
+	cfg.Cache = cache
+	cfg.cacheKeyer = keyer
+}
+
+// cacheKey returns the extraction cache key of the file, or "" if the cache
+// is disabled or the key cannot be computed.
+func cacheKey(cfg *config, lbl *slpb.Label, targetFileLabel string) extractcache.Key {
+	if cfg.cacheKeyer == nil {
+		return ""
+	}
+	key, err := cfg.cacheKeyer.Key(filepath.Join("external", lbl.Repo, lbl.Pkg, lbl.Name), targetFileLabel)
+	if err != nil {
+		cfg.Logger.Printf("WARN: extraction cache key for %s: %v", targetFileLabel, err)
+		return ""
+	}
+	return key
+}
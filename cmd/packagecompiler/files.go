@@ -110,7 +110,7 @@ func rewriteBzlFile(cfg *config, file *bzlFile) error {
 		data = build.Format(ast)
 	}
 
-	workingPath := filepath.Join(workDir, "external", file.Label.Repo, file.Label.Pkg, file.Label.Name)
+	workingPath := filepath.Join(cfg.WorkDir, "external", file.Label.Repo, file.Label.Pkg, file.Label.Name)
 	dstPath := filepath.Join(cfg.Cwd, workingPath)
 	return writeFile(dstPath, data, os.ModePerm)
 }
@@ -191,7 +191,7 @@ func rewritePackageFile(cfg *config, file *packageFile) error {
 		data = build.Format(ast)
 	}
 
-	workingPath := filepath.Join(workDir, "external", file.Label.Repo, file.Label.Pkg, file.Label.Name)
+	workingPath := filepath.Join(cfg.WorkDir, "external", file.Label.Repo, file.Label.Pkg, file.Label.Name)
 	dstPath := filepath.Join(cfg.Cwd, workingPath)
 
 	return writeFile(dstPath, data, os.ModePerm)
@@ -229,7 +229,7 @@ func readPackageFile(cfg *config, path string) (*build.File, []*build.LoadStmt,
 
 func mustWriteWorkDirFile(cfg *config, relPath string, content string) {
 	if err := writeFile(
-		filepath.Join(cfg.Cwd, workDir, relPath),
+		filepath.Join(cfg.Cwd, cfg.WorkDir, relPath),
 		[]byte(content),
 		os.ModePerm,
 	); err != nil {
@@ -256,7 +256,7 @@ func listFiles(logger *log.Logger, dir string) error {
 	})
 }
 
-func cleanErrorMessage(err error, cwd string) error {
+func cleanErrorMessage(err error, cwd, workDir string) error {
 	if err == nil {
 		return nil
 	}
@@ -0,0 +1,356 @@
+// prerendercheck validates a tarball of prerendered pages (the output of
+// prerender_pages) before it is merged into a release. Every page must
+//
+//   - have a non-empty <title>,
+//   - mention the module (modules/NAME/...) or flag (bazel/flags/NAME) it
+//     was rendered for,
+//   - show no error banner (flash-error, a not-found page, or a component
+//     still loading), and
+//   - meet a minimum document size and visible text length.
+//
+// With --baseline_tar (the previous release's prerender tarball, or the
+// previous release itself) the visible text of each page is also compared
+// to its baseline counterpart, and pages whose text changed by more than
+// --max_text_change are reported.
+//
+// A report listing every regressed URL is written to --report_file. The
+// run fails when more than --max_failures pages regressed.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/bazel-contrib/bcr-frontend/pkg/ssr"
+)
+
+const toolName = "prerendercheck"
+
+// errorTexts are rendered by the SPA when a route fails to resolve or a
+// component never finished loading.
+var errorTexts = []string{
+	"Page Not Found",
+	"Module Not Found",
+	"Module Version Not Found",
+	"Loading flag database…",
+}
+
+type Config struct {
+	PrerenderTar  string
+	BaselineTar   string
+	ReportFile    string
+	MinBytes      int
+	MinTextChars  int
+	MaxTextChange float64
+	MaxFailures   int
+}
+
+// page is what the checks need to know about one rendered document.
+type page struct {
+	size        int
+	title       string
+	errorBanner bool
+	// prerendered is false for the plain SPA shell, which some renderers
+	// emit for routes they do not cover.
+	prerendered bool
+	// goRendered marks output of the pure-Go renderer; its text is not
+	// comparable with a Chrome snapshot.
+	goRendered bool
+	text       []string
+}
+
+// result is the outcome of checking one URL.
+type result struct {
+	url      string
+	problems []string
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0)
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %w", err)
+	}
+	if cfg.PrerenderTar == "" {
+		return fmt.Errorf("--prerender_tar is required")
+	}
+
+	pages, err := readPages(cfg.PrerenderTar)
+	if err != nil {
+		return err
+	}
+	var baseline map[string]*page
+	if cfg.BaselineTar != "" {
+		if baseline, err = readPages(cfg.BaselineTar); err != nil {
+			return err
+		}
+	}
+
+	results, skipped := check(cfg, pages, baseline)
+	report := formatReport(len(pages), skipped, results, removedPages(pages, baseline))
+	if cfg.ReportFile != "" {
+		if err := os.WriteFile(cfg.ReportFile, []byte(report), 0644); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+
+	log.Printf("Checked %d pages: %d regressed, %d skipped", len(pages), len(results), skipped)
+	if len(results) > cfg.MaxFailures {
+		log.Print(report)
+		return fmt.Errorf("%d pages regressed (at most %d allowed)", len(results), cfg.MaxFailures)
+	}
+	return nil
+}
+
+// check validates every page and compares it with its baseline. It returns
+// the regressed URLs, sorted, and the number of pages skipped because they
+// are the unrendered shell.
+func check(cfg *Config, pages, baseline map[string]*page) ([]result, int) {
+	var results []result
+	skipped := 0
+	for name, p := range pages {
+		if !p.prerendered {
+			skipped++
+			continue
+		}
+		problems := validate(cfg, name, p)
+		if base := baseline[name]; base != nil && base.prerendered && base.goRendered == p.goRendered {
+			if change := textChange(base.text, p.text); change > cfg.MaxTextChange {
+				problems = append(problems, fmt.Sprintf("text changed by %.0f%% since the baseline", change*100))
+			}
+		}
+		if len(problems) > 0 {
+			results = append(results, result{url: urlPath(name), problems: problems})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].url < results[j].url })
+	return results, skipped
+}
+
+// validate runs the per-page marker checks.
+func validate(cfg *Config, name string, p *page) []string {
+	var problems []string
+	if strings.TrimSpace(p.title) == "" {
+		problems = append(problems, "empty <title>")
+	}
+	text := strings.Join(p.text, " ")
+	if want := expectedText(name); want != "" && !strings.Contains(text, want) {
+		problems = append(problems, fmt.Sprintf("%q not in page text", want))
+	}
+	if p.errorBanner {
+		problems = append(problems, "error banner")
+	}
+	for _, e := range errorTexts {
+		if strings.Contains(text, e) {
+			problems = append(problems, fmt.Sprintf("shows %q", e))
+		}
+	}
+	if p.size < cfg.MinBytes {
+		problems = append(problems, fmt.Sprintf("%d bytes (minimum %d)", p.size, cfg.MinBytes))
+	}
+	if n := len(text); n < cfg.MinTextChars {
+		problems = append(problems, fmt.Sprintf("%d characters of text (minimum %d)", n, cfg.MinTextChars))
+	}
+	return problems
+}
+
+// expectedText returns the text a page at tarball path name must contain:
+// the module name for module pages and the flag for flag pages.
+func expectedText(name string) string {
+	parts := strings.Split(strings.TrimSuffix(name, "/index.html"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "modules":
+		return parts[1]
+	case len(parts) == 3 && parts[0] == "bazel" && parts[1] == "flags":
+		return "--" + parts[2]
+	}
+	return ""
+}
+
+// textChange returns the fraction of words that differ between two texts,
+// counting words as a multiset: 0 for identical texts, 1 for disjoint ones.
+func textChange(before, after []string) float64 {
+	counts := make(map[string]int)
+	total := 0
+	for _, text := range before {
+		for _, word := range strings.Fields(text) {
+			counts[word]++
+			total++
+		}
+	}
+	common := 0
+	for _, text := range after {
+		for _, word := range strings.Fields(text) {
+			if counts[word] > 0 {
+				counts[word]--
+				common++
+			}
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 1 - float64(2*common)/float64(total)
+}
+
+// removedPages returns the URLs of baseline pages that are no longer
+// prerendered.
+func removedPages(pages, baseline map[string]*page) []string {
+	var removed []string
+	for name, base := range baseline {
+		if _, ok := pages[name]; !ok && base.prerendered && expectedText(name) != "" {
+			removed = append(removed, urlPath(name))
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+func formatReport(total, skipped int, results []result, removed []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d pages checked, %d regressed, %d skipped (not prerendered)\n", total, len(results), skipped)
+	for _, r := range results {
+		fmt.Fprintf(&b, "%s: %s\n", r.url, strings.Join(r.problems, "; "))
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(&b, "\n%d pages in the baseline are no longer prerendered:\n", len(removed))
+		for _, url := range removed {
+			fmt.Fprintf(&b, "%s\n", url)
+		}
+	}
+	return b.String()
+}
+
+// urlPath returns the URL a tarball path is served at.
+func urlPath(name string) string {
+	return ssr.NormalizePath(strings.TrimSuffix(name, "index.html"))
+}
+
+// readPages parses every .html entry of a tarball, keyed by path.
+func readPages(path string) (map[string]*page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pages := make(map[string]*page)
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(name, ".html") {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s from %s: %w", name, path, err)
+		}
+		p, err := parsePage(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s in %s: %w", name, path, err)
+		}
+		pages[name] = p
+	}
+	return pages, nil
+}
+
+func parsePage(content []byte) (*page, error) {
+	root, err := html.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	p := &page{size: len(content)}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if n.FirstChild != nil && p.title == "" {
+					p.title = n.FirstChild.Data
+				}
+			case atom.Meta:
+				if attr(n, "name") == ssr.PrerenderedPathMeta {
+					p.prerendered = true
+				}
+			}
+			if _, ok := lookupAttr(n, "data-ssr"); ok {
+				p.goRendered = true
+			}
+			for _, class := range strings.Fields(attr(n, "class")) {
+				if class == "flash-error" {
+					p.errorBanner = true
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	if p.text, err = ssr.Text(content); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func attr(n *html.Node, key string) string {
+	value, _ := lookupAttr(n, key)
+	return value
+}
+
+func lookupAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func parseFlags(args []string) (*Config, error) {
+	cfg := &Config{}
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.PrerenderTar, "prerender_tar", "", "tarball of prerendered pages to validate")
+	fs.StringVar(&cfg.BaselineTar, "baseline_tar", "", "optional previous release (or its prerender tarball) to compare page text against")
+	fs.StringVar(&cfg.ReportFile, "report_file", "", "write the summary report to this file")
+	fs.IntVar(&cfg.MinBytes, "min_bytes", 2048, "minimum size of a prerendered document in bytes")
+	fs.IntVar(&cfg.MinTextChars, "min_text_chars", 40, "minimum length of a prerendered page's visible text")
+	fs.Float64Var(&cfg.MaxTextChange, "max_text_change", 0.5, "largest tolerated fraction of changed words relative to the baseline page")
+	fs.IntVar(&cfg.MaxFailures, "max_failures", 0, "number of regressed pages tolerated before the run fails")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s --prerender_tar=FILE [--baseline_tar=FILE] [--report_file=FILE]\n", toolName)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
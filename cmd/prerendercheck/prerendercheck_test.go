@@ -0,0 +1,139 @@
+package main
+
+import (
+	"archive/tar"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const filler = "Rules for building foo targets with remote caching and hermetic toolchains."
+
+func doc(title, body string) string {
+	return `<html><head><title>` + title + `</title><meta name="bcr:prerendered-path" content="/x"></head><body>` +
+		body + `<script>bcr.main(REGISTRY_DATA)</script></body></html>`
+}
+
+func writeTar(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pages.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func checkTars(t *testing.T, cfg *Config, current, baseline map[string]string) ([]result, int) {
+	t.Helper()
+	pages, err := readPages(writeTar(t, current))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var base map[string]*page
+	if baseline != nil {
+		if base, err = readPages(writeTar(t, baseline)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return check(cfg, pages, base)
+}
+
+func TestCheck(t *testing.T) {
+	cfg := &Config{MinTextChars: 40, MaxTextChange: 0.5}
+	results, skipped := checkTars(t, cfg, map[string]string{
+		"modules/rules_foo/index.html":  doc("rules_foo", "<h1>rules_foo 1.0</h1><p>"+filler+"</p>"),
+		"modules/rules_bar/index.html":  doc("rules_bar", "<h1>something else</h1><p>"+filler+"</p>"),
+		"modules/rules_baz/index.html":  doc("", `<div class="flash flash-error">rules_baz failed</div><p>`+filler+"</p>"),
+		"modules/rules_qux/index.html":  doc("rules_qux", "<p>rules_qux</p>"),
+		"bazel/flags/jobs/index.html":   doc("jobs", "<h2>--jobs</h2><p>"+filler+"</p>"),
+		"bazel/flags/config/index.html": doc("config", "<p>Page Not Found</p><p>--config "+filler+"</p>"),
+		"home/index.html":               "<html><head><title>shell</title></head><body></body></html>",
+	}, nil)
+
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1 (the unrendered shell)", skipped)
+	}
+	got := make(map[string]string)
+	for _, r := range results {
+		got[r.url] = strings.Join(r.problems, "; ")
+	}
+	for url, want := range map[string]string{
+		"/modules/rules_bar":  `"rules_bar" not in page text`,
+		"/modules/rules_baz":  "empty <title>; error banner",
+		"/modules/rules_qux":  "characters of text",
+		"/bazel/flags/config": `shows "Page Not Found"`,
+	} {
+		if !strings.Contains(got[url], want) {
+			t.Errorf("%s: problems %q, want %q", url, got[url], want)
+		}
+	}
+	for _, url := range []string{"/modules/rules_foo", "/bazel/flags/jobs"} {
+		if p, ok := got[url]; ok {
+			t.Errorf("%s: unexpected problems %q", url, p)
+		}
+	}
+}
+
+func TestCheckBaseline(t *testing.T) {
+	cfg := &Config{MaxTextChange: 0.5}
+	before := doc("rules_foo", "<h1>rules_foo 1.0</h1><p>"+filler+"</p>")
+	results, _ := checkTars(t, cfg, map[string]string{
+		"modules/rules_foo/index.html": doc("rules_foo", "<h1>rules_foo 1.1</h1><p>"+filler+"</p>"),
+		"modules/rules_bar/index.html": doc("rules_bar", "<h1>rules_bar</h1>"),
+		// A Go rendering is not compared with a Chrome baseline.
+		"modules/rules_go/index.html": doc("rules_go", `<div data-ssr>rules_go</div>`),
+	}, map[string]string{
+		"modules/rules_foo/index.html": before,
+		"modules/rules_bar/index.html": doc("rules_bar", "<h1>rules_bar</h1><p>"+filler+"</p>"),
+		"modules/rules_go/index.html":  doc("rules_go", "<h1>rules_go</h1><p>"+filler+"</p>"),
+	})
+	if len(results) != 1 || results[0].url != "/modules/rules_bar" || !strings.Contains(results[0].problems[0], "text changed by") {
+		t.Errorf("results = %+v, want only /modules/rules_bar with a text change", results)
+	}
+}
+
+func TestTextChange(t *testing.T) {
+	for _, tc := range []struct {
+		before, after []string
+		want          float64
+	}{
+		{nil, nil, 0},
+		{[]string{"a b c d"}, []string{"a b", "c d"}, 0},
+		{[]string{"a b"}, []string{"c d"}, 1},
+		{[]string{"a b c d"}, []string{"a b"}, 1 - 4.0/6},
+	} {
+		if got := textChange(tc.before, tc.after); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("textChange(%q, %q) = %v, want %v", tc.before, tc.after, got, tc.want)
+		}
+	}
+}
+
+func TestRemovedPages(t *testing.T) {
+	pages := map[string]*page{"modules/a/index.html": {prerendered: true}}
+	baseline := map[string]*page{
+		"modules/a/index.html": {prerendered: true},
+		"modules/b/index.html": {prerendered: true},
+		"index.html":           {prerendered: true},
+	}
+	if got := removedPages(pages, baseline); len(got) != 1 || got[0] != "/modules/b" {
+		t.Errorf("removed = %q, want [/modules/b]", got)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/releasedelta"
+)
+
+// previousRelease is the data of a prior release tarball that deltas are
+// computed against.
+type previousRelease struct {
+	path         string
+	registry     *bzpb.Registry
+	symbols      *sympb.ModuleRegistrySymbols // nil if the release had none
+	symbolsAsset string                       // hashed symbols.pb.gz name
+}
+
+// readPreviousRelease loads the registry and symbols bundles of a release
+// tarball written by this tool. The symbols bundle is located through the
+// release's manifest.pb.gz, since its name is content-hashed.
+func readPreviousRelease(path string) (*previousRelease, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read entry: %v", err)
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if name != "manifest.pb.gz" && name != "registry.pb.gz" && !(strings.HasPrefix(name, "symbols.") && strings.HasSuffix(name, ".pb.gz")) {
+			continue
+		}
+		if files[name], err = io.ReadAll(tr); err != nil {
+			return nil, fmt.Errorf("read %s: %v", name, err)
+		}
+	}
+
+	release := &previousRelease{path: path, registry: &bzpb.Registry{}}
+	var manifest bzpb.RegistryManifest
+	if err := unmarshalGzip(files["manifest.pb.gz"], &manifest); err != nil {
+		return nil, fmt.Errorf("manifest.pb.gz: %v", err)
+	}
+	if err := unmarshalGzip(files["registry.pb.gz"], release.registry); err != nil {
+		return nil, fmt.Errorf("registry.pb.gz: %v", err)
+	}
+	if name := manifest.AssetHashes["symbols.pb.gz"]; name != "" && files[name] != nil {
+		release.symbols = &sympb.ModuleRegistrySymbols{}
+		if err := unmarshalGzip(files[name], release.symbols); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		release.symbolsAsset = name
+	}
+	return release, nil
+}
+
+// buildDeltaAssets computes registry and symbols deltas from each previous
+// release to the current one. A delta asset is only emitted when it is
+// smaller than the full bundle it replaces; otherwise clients are better off
+// refetching. Releases at the current commit need no delta and are skipped.
+func buildDeltaAssets(registryPath, symbolsPath string, previous []*previousRelease) ([]HashedAsset, []*bzpb.ReleaseDeltaRef, error) {
+	if len(previous) == 0 {
+		return nil, nil, nil
+	}
+	registryContent, err := os.ReadFile(registryPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read registry: %v", err)
+	}
+	registry := &bzpb.Registry{}
+	if err := proto.Unmarshal(registryContent, registry); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal registry: %v", err)
+	}
+	symbolsContent, err := os.ReadFile(symbolsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read symbols: %v", err)
+	}
+	symbols := &sympb.ModuleRegistrySymbols{}
+	if err := proto.Unmarshal(symbolsContent, symbols); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal symbols: %v", err)
+	}
+	registryFullSize := len(gzipBytes(registryContent))
+	symbolsFullSize := len(gzipBytes(symbolsContent))
+
+	var assets []HashedAsset
+	var refs []*bzpb.ReleaseDeltaRef
+	seen := make(map[string]bool)
+	addAsset := func(originalName string, msg proto.Message, fullSize int) (string, error) {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return "", err
+		}
+		content := gzipBytes(data)
+		if len(content) >= fullSize {
+			return "", nil
+		}
+		hashedName := hashFilename(originalName, content)
+		if !seen[hashedName] {
+			seen[hashedName] = true
+			assets = append(assets, HashedAsset{
+				OriginalName: originalName,
+				HashedName:   hashedName,
+				Content:      content,
+			})
+		}
+		return hashedName, nil
+	}
+
+	for _, prev := range previous {
+		if prev.registry.CommitSha == registry.CommitSha {
+			log.Printf("Skipping delta from %s: same commit %s", prev.path, registry.CommitSha)
+			continue
+		}
+		ref := &bzpb.ReleaseDeltaRef{
+			FromCommitSha:    prev.registry.CommitSha,
+			FromSymbolsAsset: prev.symbolsAsset,
+		}
+		if ref.RegistryDeltaAsset, err = addAsset("registrydelta.pb.gz", releasedelta.DiffRegistry(prev.registry, registry), registryFullSize); err != nil {
+			return nil, nil, fmt.Errorf("registry delta from %s: %v", prev.path, err)
+		}
+		if prev.symbols != nil {
+			if ref.SymbolsDeltaAsset, err = addAsset("symbolsdelta.pb.gz", releasedelta.DiffSymbols(prev.symbols, symbols), symbolsFullSize); err != nil {
+				return nil, nil, fmt.Errorf("symbols delta from %s: %v", prev.path, err)
+			}
+		}
+		if ref.RegistryDeltaAsset == "" && ref.SymbolsDeltaAsset == "" {
+			continue
+		}
+		refs = append(refs, ref)
+		log.Printf("Delta from %s (%s): registry=%q symbols=%q", prev.path, ref.FromCommitSha, ref.RegistryDeltaAsset, ref.SymbolsDeltaAsset)
+	}
+	return assets, refs, nil
+}
+
+func unmarshalGzip(data []byte, msg proto.Message) error {
+	if data == nil {
+		return fmt.Errorf("not found")
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(raw, msg)
+}
+
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	gw.Write(data) // writes to a bytes.Buffer cannot fail
+	gw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/pagemeta"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+// pageHeads injects title, description, canonical URL, OpenGraph and
+// JSON-LD metadata into the HTML documents of the release.
+type pageHeads struct {
+	registry *bzpb.Registry
+	licenses map[string][]string // NAME@VERSION -> SPDX identifiers
+	injected int
+}
+
+// newPageHeads loads the registry and, if licensesPath is set, the license
+// inventory that supplies the JSON-LD license of each version.
+func newPageHeads(registryPath, licensesPath string) (*pageHeads, error) {
+	content, err := os.ReadFile(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("read registry: %v", err)
+	}
+	registry := &bzpb.Registry{}
+	if err := proto.Unmarshal(content, registry); err != nil {
+		return nil, fmt.Errorf("unmarshal registry: %v", err)
+	}
+
+	heads := &pageHeads{registry: registry, licenses: make(map[string][]string)}
+	if licensesPath != "" {
+		var licenses sympb.ModuleRegistryLicenses
+		if err := protoutil.ReadFile(licensesPath, &licenses); err != nil {
+			return nil, fmt.Errorf("read licenses: %v", err)
+		}
+		for _, mv := range licenses.ModuleVersion {
+			heads.licenses[mv.ModuleName+"@"+mv.Version] = mv.SpdxId
+		}
+	}
+	return heads, nil
+}
+
+// apply returns content with the metadata of the page at tarball path name
+// injected. Non-HTML entries and pages without metadata of their own are
+// returned unchanged. A nil receiver is a no-op.
+func (h *pageHeads) apply(name string, content []byte) []byte {
+	if h == nil || !strings.HasSuffix(name, ".html") {
+		return content
+	}
+	page := pagemeta.ForPath(h.registry, h.licenses, name)
+	if page == nil {
+		return content
+	}
+	result, err := pagemeta.Inject(content, page)
+	if err != nil {
+		log.Printf("warning: page metadata for %s: %v", name, err)
+		return content
+	}
+	h.injected++
+	return result
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -22,16 +23,28 @@ import (
 
 const toolName = "releasecompiler"
 
+// registryTreeDir is the tarball directory of the Bazel-compatible registry
+// mirror; clients use --registry=<base url>/registry.
+const registryTreeDir = "registry"
+
 type Config struct {
 	OutputFile                 string
 	IndexHtmlFile              string
 	RegistryFile               string
 	ModuleRegistrySymbolsFile  string
 	ModuleRegistryPackagesFile string
+	LicensesFile               string
 	BazelFlagDbFile            string
 	PrerenderedPagesTar        string
+	FeedsTar                   string
+	ApiTar                     string
+	SitemapTar                 string
 	AssetFiles                 []string
 	ModulesSrcFiles            stringSliceFlag
+	RegistrySrcFiles           stringSliceFlag
+	RegistryMirrors            stringSliceFlag
+	PreviousReleases           stringSliceFlag
+	MaxPreviousReleases        int
 	ExcludeFromHash            map[string]bool // basenames to exclude from hashing
 }
 
@@ -121,16 +134,38 @@ func run(args []string) error {
 		log.Printf("Processed bazel flag db file: %s -> %s", asset.OriginalName, asset.HashedName)
 	}
 
+	// Compute deltas from recent prior releases so a client can apply small
+	// updates instead of refetching the full bundles. The refresh poller does
+	// not apply them yet, so none are built unless --max_previous_releases
+	// is set.
+	var previous []*previousRelease
+	for _, path := range cfg.PreviousReleases[:min(len(cfg.PreviousReleases), cfg.MaxPreviousReleases)] {
+		release, err := readPreviousRelease(path)
+		if err != nil {
+			log.Printf("warning: skipping previous release %s: %v", path, err)
+			continue
+		}
+		previous = append(previous, release)
+	}
+	deltaAssets, deltas, err := buildDeltaAssets(cfg.RegistryFile, cfg.ModuleRegistrySymbolsFile, previous)
+	if err != nil {
+		return fmt.Errorf("failed to build deltas: %v", err)
+	}
+
 	// Emit manifest.pb.gz at the tarball root for the in-browser refresh
 	// poller. Must be appended AFTER every other asset's HashedName is
 	// finalized — the manifest records those hashes so clients can detect
 	// code redeploys without re-fetching the bundles themselves.
-	manifestAsset, err := buildManifestAsset(cfg.RegistryFile, assets)
+	manifestAsset, err := buildManifestAsset(cfg.RegistryFile, assets, deltas)
 	if err != nil {
 		return fmt.Errorf("failed to build manifest: %v", err)
 	}
 	assets = append(assets, manifestAsset)
-	log.Printf("Processed manifest: %s (%d asset_hashes)", manifestAsset.OriginalName, len(assets)-1)
+	log.Printf("Processed manifest: %s (%d asset_hashes, %d deltas)", manifestAsset.OriginalName, len(assets)-1, len(deltas))
+
+	// Delta assets are referenced through manifest.deltas rather than
+	// asset_hashes, so they are added after the manifest is built.
+	assets = append(assets, deltaAssets...)
 
 	// Read and update index.html
 	indexContent, err := updateIndexHtml(cfg.IndexHtmlFile, assets)
@@ -138,8 +173,16 @@ func run(args []string) error {
 		return fmt.Errorf("failed to update index.html: %v", err)
 	}
 
+	// Stamp page metadata into index.html and the prerendered pages so
+	// crawlers and link previews see it without running the SPA.
+	heads, err := newPageHeads(cfg.RegistryFile, cfg.LicensesFile)
+	if err != nil {
+		return fmt.Errorf("failed to load page metadata: %v", err)
+	}
+	indexContent = heads.apply("index.html", indexContent)
+
 	// Create tarball
-	tarball, err := createTarball(indexContent, assets, cfg.ModulesSrcFiles, cfg.PrerenderedPagesTar)
+	tarball, err := createTarball(&cfg, indexContent, assets, heads)
 	if err != nil {
 		return fmt.Errorf("failed to create tarball: %v", err)
 	}
@@ -149,6 +192,7 @@ func run(args []string) error {
 		return fmt.Errorf("failed to write output file: %v", err)
 	}
 
+	log.Printf("Injected page metadata into %d HTML document(s)", heads.injected)
 	log.Printf("Successfully created %s with %d assets", cfg.OutputFile, len(assets))
 	return nil
 }
@@ -203,10 +247,10 @@ func processRegistryFile(registryPath string) ([]HashedAsset, error) {
 }
 
 // buildManifestAsset reads the registry proto for commit metadata, collects
-// content-hashed asset names from `assets`, and returns a HashedAsset for
+// content-hashed asset names from `assets`, records `deltas`, and returns a HashedAsset for
 // manifest.pb.gz at the tarball root (un-hashed name — clients fetch it by
 // fixed URL and the releaseserver/CDN serves it no-cache).
-func buildManifestAsset(registryPath string, assets []HashedAsset) (HashedAsset, error) {
+func buildManifestAsset(registryPath string, assets []HashedAsset, deltas []*bzpb.ReleaseDeltaRef) (HashedAsset, error) {
 	registryBytes, err := os.ReadFile(registryPath)
 	if err != nil {
 		return HashedAsset{}, fmt.Errorf("read registry: %v", err)
@@ -217,10 +261,11 @@ func buildManifestAsset(registryPath string, assets []HashedAsset) (HashedAsset,
 	}
 
 	manifest := &bzpb.RegistryManifest{
-		CommitSha:    registry.GetCommitSha(),
-		CommitDate:   registry.GetCommitDate(),
-		Branch:       registry.GetBranch(),
-		AssetHashes:  map[string]string{},
+		CommitSha:   registry.GetCommitSha(),
+		CommitDate:  registry.GetCommitDate(),
+		Branch:      registry.GetBranch(),
+		AssetHashes: map[string]string{},
+		Deltas:      deltas,
 	}
 	for _, a := range assets {
 		if a.OriginalName == a.HashedName {
@@ -448,7 +493,9 @@ func updateIndexHtml(indexPath string, assets []HashedAsset) ([]byte, error) {
 	return []byte(htmlStr), nil
 }
 
-func createTarball(indexContent []byte, assets []HashedAsset, modulesSrcFiles []string, prerenderedPagesTar string) ([]byte, error) {
+// createTarball writes index.html and the hashed assets, then the modules,
+// prerendered pages, feeds, API, sitemap and registry inputs named by cfg.
+func createTarball(cfg *Config, indexContent []byte, assets []HashedAsset, heads *pageHeads) ([]byte, error) {
 	var buf bytes.Buffer
 	tw := tar.NewWriter(&buf)
 
@@ -465,7 +512,7 @@ func createTarball(indexContent []byte, assets []HashedAsset, modulesSrcFiles []
 	}
 
 	// Add modules_src files preserving path relative to "modules/"
-	for _, path := range modulesSrcFiles {
+	for _, path := range cfg.ModulesSrcFiles {
 		const marker = "modules/"
 		idx := strings.LastIndex(path, marker)
 		if idx == -1 {
@@ -484,15 +531,55 @@ func createTarball(indexContent []byte, assets []HashedAsset, modulesSrcFiles []
 
 	// Merge entries from a prerendered-pages tarball, if provided. Entries
 	// are added at their existing paths (e.g. modules/rules_buf/index.html)
-	// without rewriting; the prerender ran against the unprerendered tarball
-	// which already had hashed asset names stamped, so references inside
-	// these HTML files match the assets shipped here.
-	if prerenderedPagesTar != "" {
-		count, err := mergePrerenderedPages(tw, prerenderedPagesTar)
+	// with only their page metadata rewritten; the prerender ran against the
+	// unprerendered tarball which already had hashed asset names stamped, so
+	// references inside these HTML files match the assets shipped here.
+	if cfg.PrerenderedPagesTar != "" {
+		count, err := mergeTar(tw, cfg.PrerenderedPagesTar, heads.apply)
 		if err != nil {
 			return nil, fmt.Errorf("failed to merge prerendered_pages_tar: %v", err)
 		}
-		log.Printf("Merged %d prerendered page(s) from %s", count, prerenderedPagesTar)
+		log.Printf("Merged %d prerendered page(s) from %s", count, cfg.PrerenderedPagesTar)
+	}
+
+	// Merge Atom / JSON Feed documents (feeds/...) produced by
+	// cmd/feedcompiler, if provided.
+	if cfg.FeedsTar != "" {
+		count, err := mergeTar(tw, cfg.FeedsTar, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge feeds_tar: %v", err)
+		}
+		log.Printf("Merged %d feed document(s) from %s", count, cfg.FeedsTar)
+	}
+
+	// Merge the static API tree (api/...) produced by cmd/apicompiler, if
+	// provided.
+	if cfg.ApiTar != "" {
+		count, err := mergeTar(tw, cfg.ApiTar, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge api_tar: %v", err)
+		}
+		log.Printf("Merged %d API document(s) from %s", count, cfg.ApiTar)
+	}
+
+	// Merge the sitemap index (sitemap.xml) and its shards (sitemaps/...)
+	// produced by cmd/sitemapcompiler, if provided.
+	if cfg.SitemapTar != "" {
+		count, err := mergeTar(tw, cfg.SitemapTar, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge sitemap_tar: %v", err)
+		}
+		log.Printf("Merged %d sitemap file(s) from %s", count, cfg.SitemapTar)
+	}
+
+	// Lay out the raw registry files as an index registry (registry/...),
+	// if provided.
+	if len(cfg.RegistrySrcFiles) > 0 {
+		count, err := addRegistryTree(tw, cfg.RegistrySrcFiles, cfg.RegistryMirrors)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add registry tree: %v", err)
+		}
+		log.Printf("Added %d registry file(s) under %s/", count, registryTreeDir)
 	}
 
 	if err := tw.Close(); err != nil {
@@ -502,11 +589,64 @@ func createTarball(indexContent []byte, assets []HashedAsset, modulesSrcFiles []
 	return buf.Bytes(), nil
 }
 
-// mergePrerenderedPages reads the input tar at path and copies each regular
-// file entry into tw at the same name. Returns the number of entries copied.
+// addRegistryTree writes bazel_registry.json and each registry source file
+// under registryTreeDir, at its path relative to the registry root (e.g.
+// registry/modules/rules_go/0.50.1/MODULE.bazel). Returns the number of
+// files written.
+func addRegistryTree(tw *tar.Writer, srcFiles, mirrors []string) (int, error) {
+	bazelRegistry, err := json.MarshalIndent(map[string]any{
+		"mirrors": append([]string{}, mirrors...),
+	}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := addFileToTar(tw, registryTreeDir+"/bazel_registry.json", append(bazelRegistry, '\n')); err != nil {
+		return 0, fmt.Errorf("add bazel_registry.json: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range srcFiles {
+		rel, ok := registryRelPath(path)
+		if !ok {
+			return 0, fmt.Errorf("registry_src file %q is not under a modules/ directory", path)
+		}
+		name := registryTreeDir + "/" + rel
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read registry_src %s: %v", path, err)
+		}
+		if err := addFileToTar(tw, name, content); err != nil {
+			return 0, fmt.Errorf("failed to add %s: %v", name, err)
+		}
+	}
+	return len(seen) + 1, nil
+}
+
+// registryRelPath returns path relative to the registry root, starting at
+// its first "modules" path segment. Matching whole segments keeps module
+// names such as "go_modules" from being mistaken for the root.
+func registryRelPath(path string) (string, bool) {
+	path = filepath.ToSlash(path)
+	if strings.HasPrefix(path, "modules/") {
+		return path, true
+	}
+	if i := strings.Index(path, "/modules/"); i >= 0 {
+		return path[i+1:], true
+	}
+	return "", false
+}
+
+// mergeTar reads the input tar at path and copies each regular
+// file entry into tw at the same name, passing its content through
+// transform if non-nil. Returns the number of entries copied.
 // Skips directory entries; preserves leading "./" stripping for consistency
 // with the rest of this tool's tar entries.
-func mergePrerenderedPages(tw *tar.Writer, path string) (int, error) {
+func mergeTar(tw *tar.Writer, path string, transform func(name string, content []byte) []byte) (int, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return 0, fmt.Errorf("open %s: %v", path, err)
@@ -534,6 +674,9 @@ func mergePrerenderedPages(tw *tar.Writer, path string) (int, error) {
 		if name == "" {
 			continue
 		}
+		if transform != nil {
+			content = transform(name, content)
+		}
 		if err := addFileToTar(tw, name, content); err != nil {
 			return count, fmt.Errorf("add %s: %v", name, err)
 		}
@@ -569,9 +712,17 @@ func parseFlags(args []string) (cfg Config, err error) {
 	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file to process (gzipped and base64 encoded)")
 	fs.StringVar(&cfg.ModuleRegistrySymbolsFile, "module_registry_symbols_file", "", "the documentation registry protobuf file to process (gzipped and base64 encoded)")
 	fs.StringVar(&cfg.ModuleRegistryPackagesFile, "module_registry_packages_file", "", "the packages registry protobuf file to process (gzipped into the tarball as packages.<hash>.pb.gz)")
+	fs.StringVar(&cfg.LicensesFile, "licenses_file", "", "optional ModuleRegistryLicenses protobuf file supplying the license of each version in page metadata")
 	fs.StringVar(&cfg.BazelFlagDbFile, "bazel_flag_db_file", "", "the bazel flag database protobuf file (gzipped into the tarball as bazelflagdb.pb.gz)")
-	fs.StringVar(&cfg.PrerenderedPagesTar, "prerendered_pages_tar", "", "optional tar of prerendered HTML files to merge into the output tarball verbatim (entries are added as-is)")
+	fs.StringVar(&cfg.PrerenderedPagesTar, "prerendered_pages_tar", "", "optional tar of prerendered HTML files to merge into the output tarball (entries keep their paths; only page metadata is rewritten)")
+	fs.StringVar(&cfg.FeedsTar, "feeds_tar", "", "optional tar of Atom and JSON Feed documents to merge into the output tarball verbatim")
+	fs.StringVar(&cfg.ApiTar, "api_tar", "", "optional tar of static API documents (api/...) to merge into the output tarball verbatim")
+	fs.StringVar(&cfg.SitemapTar, "sitemap_tar", "", "optional tar of the sitemap index (sitemap.xml) and its shards (sitemaps/...) to merge into the output tarball verbatim")
 	fs.Var(&cfg.ModulesSrcFiles, "modules_src", "a file to include under modules/ in the tarball (repeatable)")
+	fs.Var(&cfg.RegistrySrcFiles, "registry_src", "a raw registry file (metadata.json, MODULE.bazel, source.json, patches/...) to include under registry/modules/ in the tarball (repeatable)")
+	fs.Var(&cfg.PreviousReleases, "previous_release", "a prior release tarball to compute registry and symbols deltas from, newest first (repeatable)")
+	fs.IntVar(&cfg.MaxPreviousReleases, "max_previous_releases", 0, "the number of --previous_release tarballs to compute deltas from (0, the default, emits no deltas; no client applies them yet)")
+	fs.Var(&cfg.RegistryMirrors, "registry_mirror", "a mirror URL to list in registry/bazel_registry.json (repeatable)")
 	fs.StringVar(&excludeFromHashStr, "exclude_from_hash", "", "comma-separated list of basenames to exclude from hashing (e.g., favicon.png,robots.txt)")
 	fs.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
@@ -581,6 +732,10 @@ func parseFlags(args []string) (cfg Config, err error) {
 	if err = fs.Parse(args); err != nil {
 		return
 	}
+	if cfg.MaxPreviousReleases < 0 {
+		err = fmt.Errorf("--max_previous_releases must not be negative: %d", cfg.MaxPreviousReleases)
+		return
+	}
 
 	cfg.AssetFiles = fs.Args()
 	// log.Println("assets:", cfg.AssetFiles)
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// encodings are the content codings the server negotiates, in order of
+// preference, with the tarball suffix of their precompressed variants.
+var encodings = []struct {
+	name string
+	ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// minCompressSize is the smallest response worth gzipping on the fly.
+const minCompressSize = 1024
+
+// asset is one file of the release with its precomputed response metadata.
+type asset struct {
+	content      []byte
+	contentType  string
+	etag         string
+	compressible bool
+	// immutable is set for the content-hashed names listed in the release
+	// manifest.
+	immutable bool
+	// variants holds precompressed tarball entries, keyed by encoding.
+	variants map[string]*variant
+
+	gzipOnce sync.Once
+	gzip     *variant
+}
+
+// variant is an encoded representation of an asset. It carries its own
+// ETag since it is a different byte sequence.
+type variant struct {
+	content []byte
+	etag    string
+}
+
+func newAsset(path string, content []byte) *asset {
+	sum := sha256.Sum256(content)
+	contentType := contentTypeFor(path)
+	return &asset{
+		content:      content,
+		contentType:  contentType,
+		etag:         `"` + hex.EncodeToString(sum[:8]) + `"`,
+		compressible: len(content) >= minCompressSize && isCompressible(contentType),
+		variants:     make(map[string]*variant),
+	}
+}
+
+// gzipped lazily compresses the asset, so startup does not pay for files
+// that are never requested. It returns nil if compression does not help.
+func (a *asset) gzipped() *variant {
+	a.gzipOnce.Do(func() {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.DefaultCompression)
+		zw.Write(a.content)
+		zw.Close()
+		if buf.Len() < len(a.content) {
+			a.gzip = &variant{content: buf.Bytes(), etag: variantETag(a.etag, "gzip")}
+		}
+	})
+	return a.gzip
+}
+
+// variantETag derives the ETag of an encoded representation.
+func variantETag(etag, encoding string) string {
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// contentTypeFor returns the Content-Type for a file based on its extension.
+func contentTypeFor(path string) string {
+	ext := filepath.Ext(path)
+	contentType := mime.TypeByExtension(ext)
+	if ext == ".atom" {
+		contentType = "application/atom+xml; charset=utf-8"
+	}
+	if strings.HasPrefix(path, registryTreePrefix) && (ext == ".bazel" || ext == ".patch" || ext == ".yml") {
+		contentType = "text/plain; charset=utf-8"
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return contentType
+}
+
+// isCompressible reports whether the CDN would compress a response of the
+// given type. Protobufs are already gzipped and images are compressed.
+func isCompressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+xml"),
+		strings.HasSuffix(mediaType, "/json"),
+		mediaType == "application/javascript",
+		mediaType == "application/xml",
+		mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// parseAcceptEncoding parses an Accept-Encoding header into a predicate
+// reporting whether a coding is acceptable. Codings with q=0 are refused;
+// "*" covers codings not listed explicitly.
+func parseAcceptEncoding(header string) func(string) bool {
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		qs[coding] = q
+	}
+	return func(coding string) bool {
+		if q, ok := qs[coding]; ok {
+			return q > 0
+		}
+		q, ok := qs["*"]
+		return ok && q > 0
+	}
+}
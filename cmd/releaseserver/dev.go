@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveReloadPath is the server-sent events endpoint browsers subscribe to
+// in --watch mode.
+const liveReloadPath = "/__livereload"
+
+// liveReloadScript is injected into every HTML page in --watch mode. It
+// reloads the page when the release changes, and also when the event
+// stream reconnects after an error, which means the server was restarted
+// and is likely serving something new.
+const liveReloadScript = `<script>(()=>{let down=false;const es=new EventSource("` + liveReloadPath + `");` +
+	`es.addEventListener("reload",()=>location.reload());` +
+	`es.onerror=()=>{down=true};es.onopen=()=>{if(down)location.reload()}})();</script>`
+
+// liveReload fans reload notifications out to connected browsers over
+// server-sent events.
+type liveReload struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]bool
+	closed  bool
+}
+
+func newLiveReload() *liveReload {
+	return &liveReload{clients: make(map[chan struct{}]bool)}
+}
+
+// ServeHTTP streams events to one browser until it disconnects or the
+// server shuts down.
+func (lr *liveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ch := lr.subscribe()
+	if ch == nil {
+		http.Error(w, "Shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer lr.unsubscribe(ch)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		log.Printf("Live reload: %v", err)
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (lr *liveReload) subscribe() chan struct{} {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	if lr.closed {
+		return nil
+	}
+	ch := make(chan struct{}, 1)
+	lr.clients[ch] = true
+	return ch
+}
+
+func (lr *liveReload) unsubscribe(ch chan struct{}) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	delete(lr.clients, ch)
+}
+
+// notify asks every connected browser to reload. A browser with a reload
+// already pending is not sent a second one.
+func (lr *liveReload) notify() int {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	for ch := range lr.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return len(lr.clients)
+}
+
+// close ends every event stream; http.Server.Shutdown would otherwise wait
+// for them until its timeout.
+func (lr *liveReload) close() {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	lr.closed = true
+	for ch := range lr.clients {
+		close(ch)
+		delete(lr.clients, ch)
+	}
+}
+
+// injectLiveReload adds liveReloadScript to every HTML file, before
+// </body> when there is one, and drops the precompressed variants of those
+// files so that clients accepting br or gzip get the injected page too.
+func injectLiveReload(files map[string][]byte) {
+	for path, content := range files {
+		if !strings.HasSuffix(path, ".html") {
+			continue
+		}
+		for _, enc := range encodings {
+			delete(files, path+enc.ext)
+		}
+		i := bytes.LastIndex(content, []byte("</body>"))
+		if i < 0 {
+			i = len(content)
+		}
+		out := make([]byte, 0, len(content)+len(liveReloadScript))
+		out = append(out, content[:i]...)
+		out = append(out, liveReloadScript...)
+		out = append(out, content[i:]...)
+		files[path] = out
+	}
+}
+
+// workerAPIPrefix is the path prefix the API worker (app/api) serves. The
+// rest of /api/ is the static JSON tree of the release.
+const workerAPIPrefix = "/api/v1alpha1/"
+
+// devHandler routes the live reload endpoint and, when apiProxy is set,
+// workerAPIPrefix to a locally running worker (e.g. `wrangler dev`);
+// everything else, including the static /api/ tree, goes to next.
+func devHandler(next http.Handler, lr *liveReload, apiProxy string) (http.Handler, error) {
+	mux := http.NewServeMux()
+	mux.Handle(liveReloadPath, lr)
+	if apiProxy != "" {
+		target, err := url.Parse(apiProxy)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return nil, fmt.Errorf("invalid --api_proxy %q: want a URL like http://localhost:8787", apiProxy)
+		}
+		mux.Handle(workerAPIPrefix, httputil.NewSingleHostReverseProxy(target))
+	}
+	mux.Handle("/", next)
+	return mux, nil
+}
+
+// watch polls path (a tarball or a directory) every interval and calls
+// reload once a change has settled, i.e. the source looked the same on two
+// consecutive polls. This tolerates a tarball being rewritten in place by
+// the build. It returns when ctx is done.
+func watch(ctx context.Context, path string, interval time.Duration, reload func()) {
+	last, err := fingerprint(path)
+	if err != nil {
+		log.Printf("Watch %s: %v", path, err)
+	}
+	pending := ""
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fp, err := fingerprint(path)
+		if err != nil {
+			// Mid-rebuild the source may briefly not exist.
+			continue
+		}
+		switch {
+		case fp == last:
+			pending = ""
+		case fp == pending:
+			last, pending = fp, ""
+			reload()
+		default:
+			pending = fp
+		}
+	}
+}
+
+// fingerprint summarizes the size and modification time of path, or of
+// every regular file under it if it is a directory.
+func fingerprint(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano()), nil
+	}
+	var b strings.Builder
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		// Stat follows symlinks, which is how bazel-out trees are built.
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return b.String(), err
+}
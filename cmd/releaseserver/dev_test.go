@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInjectLiveReload(t *testing.T) {
+	files := map[string][]byte{
+		"index.html":         []byte("<html><body>app</body></html>"),
+		"index.html.br":      []byte("brotli"),
+		"index.html.gz":      []byte("gzip"),
+		"partial.html":       []byte("<p>no body</p>"),
+		"bcr.0123abcd.js":    []byte("</body>"),
+		"bcr.0123abcd.js.br": []byte("brotli"),
+	}
+	injectLiveReload(files)
+	if got := string(files["index.html"]); got != "<html><body>app"+liveReloadScript+"</body></html>" {
+		t.Errorf("index.html = %q", got)
+	}
+	if got := string(files["partial.html"]); !strings.HasSuffix(got, liveReloadScript) {
+		t.Errorf("partial.html = %q", got)
+	}
+	if got := string(files["bcr.0123abcd.js"]); got != "</body>" {
+		t.Errorf("non-HTML file modified: %q", got)
+	}
+	for _, name := range []string{"index.html.br", "index.html.gz"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("stale precompressed variant %s kept", name)
+		}
+	}
+	if _, ok := files["bcr.0123abcd.js.br"]; !ok {
+		t.Error("precompressed variant of a non-HTML file dropped")
+	}
+}
+
+func TestSwapAndNotify(t *testing.T) {
+	server := NewSPAServer(map[string][]byte{"index.html": []byte("v1")})
+	lr := newLiveReload()
+	h, err := devHandler(server, lr, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + liveReloadPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	events := bufio.NewScanner(resp.Body)
+	events.Scan() // ": connected"
+
+	server.Swap(map[string][]byte{"index.html": []byte("v2")})
+	if n := lr.notify(); n != 1 {
+		t.Errorf("notified %d browsers, want 1", n)
+	}
+	done := make(chan string)
+	go func() {
+		for events.Scan() {
+			if strings.HasPrefix(events.Text(), "event:") {
+				done <- events.Text()
+				return
+			}
+		}
+	}()
+	select {
+	case got := <-done:
+		if got != "event: reload" {
+			t.Errorf("event = %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload event")
+	}
+
+	if w := get(h, "/"); w.Body.String() != "v2" {
+		t.Errorf("after swap served %q, want v2", w.Body.String())
+	}
+	lr.close()
+}
+
+func TestDevHandlerAPIProxy(t *testing.T) {
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("worker " + r.URL.Path))
+	}))
+	defer worker.Close()
+
+	release := NewSPAServer(map[string][]byte{
+		"index.html":     []byte("<html>shell</html>"),
+		"api/index.json": []byte(`{"modules":[]}`),
+	})
+	h, err := devHandler(release, newLiveReload(), worker.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := get(h, "/api/v1alpha1/version"); w.Body.String() != "worker /api/v1alpha1/version" {
+		t.Errorf("proxied body %q", w.Body.String())
+	}
+	// The static API tree of the release is not the worker's.
+	if w := get(h, "/api/index.json"); w.Body.String() != `{"modules":[]}` {
+		t.Errorf("static API body %q", w.Body.String())
+	}
+	if w := get(h, "/modules/rules_foo"); w.Body.String() != "<html>shell</html>" {
+		t.Errorf("SPA body %q", w.Body.String())
+	}
+	if _, err := devHandler(newTestServer(t), newLiveReload(), "localhost:8787"); err == nil {
+		t.Error("want an error for a URL without scheme")
+	}
+}
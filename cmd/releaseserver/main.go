@@ -2,16 +2,26 @@ package main
 
 import (
 	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
-	"mime"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"google.golang.org/protobuf/proto"
 )
 
 func main() {
@@ -20,18 +30,25 @@ func main() {
 	log.SetFlags(0)
 
 	var (
-		port     = flag.Int("port", 8080, "port to listen on (0 = pick a free port)")
-		host     = flag.String("host", "localhost", "host to bind to")
-		portFile = flag.String("port_file", "", "if set, write the chosen port to this file once listening")
+		port            = flag.Int("port", 8080, "port to listen on (0 = pick a free port)")
+		host            = flag.String("host", "localhost", "host to bind to")
+		portFile        = flag.String("port_file", "", "if set, write the chosen port to this file once listening")
+		accessLog       = flag.String("access_log", "-", "write a Combined Log Format access log to this file (\"-\" = stderr, \"\" = off)")
+		compress        = flag.Bool("compress", true, "gzip compressible responses that have no precompressed variant in the tarball, like the CDN does")
+		shutdownTimeout = flag.Duration("shutdown_timeout", 5*time.Second, "how long to let in-flight requests finish on SIGINT/SIGTERM")
+		watchSource     = flag.Bool("watch", false, "development mode: reload the release when the tarball or directory changes and live-reload connected browsers")
+		watchInterval   = flag.Duration("watch_interval", 500*time.Millisecond, "how often --watch polls for changes")
+		apiProxy        = flag.String("api_proxy", "", "in --watch mode, proxy /api/v1alpha1/ to this worker URL (e.g. http://localhost:8787 for wrangler dev)")
 	)
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <tarball>\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Serve a tarball as a Single Page Application with fallback to index.html.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <tarball|directory>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Serve a tarball (or an unpacked release directory) as a Single Page Application with fallback to index.html.\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExample:\n")
 		fmt.Fprintf(os.Stderr, "  %s --port=8080 release.tar\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --watch --api_proxy=http://localhost:8787 release.tar\n", os.Args[0])
 	}
 
 	flag.Parse()
@@ -43,12 +60,18 @@ func main() {
 	}
 
 	tarballPath := args[0]
+	if *apiProxy != "" && !*watchSource {
+		log.Fatalf("--api_proxy requires --watch")
+	}
 
 	// Load the tarball into memory
-	files, err := loadTarball(tarballPath)
+	files, err := loadRelease(tarballPath)
 	if err != nil {
 		log.Fatalf("Failed to load tarball: %v", err)
 	}
+	if *watchSource {
+		injectLiveReload(files)
+	}
 
 	// Calculate total size
 	var totalSize int
@@ -69,6 +92,42 @@ func main() {
 
 	// Create and start server
 	server := NewSPAServer(files)
+	server.compress = *compress
+	var handler http.Handler = server
+	var onShutdown []func()
+	if *watchSource {
+		lr := newLiveReload()
+		if handler, err = devHandler(server, lr, *apiProxy); err != nil {
+			log.Fatal(err)
+		}
+		onShutdown = append(onShutdown, lr.close)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go watch(ctx, tarballPath, *watchInterval, func() {
+			files, err := loadRelease(tarballPath)
+			if err != nil {
+				// Keep serving the previous release until the next change.
+				log.Printf("Reload failed: %v", err)
+				return
+			}
+			injectLiveReload(files)
+			server.Swap(files)
+			log.Printf("Reloaded %d files from %s, notified %d browsers", len(files), tarballPath, lr.notify())
+		})
+		log.Printf("Watching %s for changes", tarballPath)
+	}
+	if *accessLog != "" {
+		out := io.Writer(os.Stderr)
+		if *accessLog != "-" {
+			f, err := os.OpenFile(*accessLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				log.Fatalf("Failed to open access log: %v", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		handler = withAccessLog(handler, out)
+	}
 
 	listener, err := listenSafePort(*host, *port)
 	if err != nil {
@@ -85,33 +144,86 @@ func main() {
 		}
 	}
 
-	if err := http.Serve(listener, server); err != nil {
+	if err := serve(listener, handler, *shutdownTimeout, onShutdown...); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
 
-// SPAServer serves files from memory with SPA fallback behavior
+// serve runs the server until SIGINT or SIGTERM, then stops accepting
+// connections and waits up to timeout for in-flight requests. onShutdown
+// hooks run when shutdown starts, to end long-lived responses.
+func serve(listener net.Listener, handler http.Handler, timeout time.Duration, onShutdown ...func()) error {
+	srv := &http.Server{Handler: handler}
+	for _, f := range onShutdown {
+		srv.RegisterOnShutdown(f)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		log.Printf("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		done <- srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return <-done
+}
+
+// registryTreePrefix is the tarball directory holding the Bazel-compatible
+// registry mirror written by releasecompiler.
+const registryTreePrefix = "registry/"
+
+// SPAServer serves files from memory with SPA fallback behavior. Responses
+// carry the validators and caching policy of the production CDN: a strong
+// ETag, immutable caching for content-hashed assets and revalidation for
+// everything else. Conditional and range requests are answered by
+// http.ServeContent, and precompressed NAME.br / NAME.gz entries are
+// served for NAME when the client accepts them.
 type SPAServer struct {
-	files     map[string][]byte
-	indexHTML []byte
-	hasIndex  bool
+	// files is replaced wholesale by Swap, so a request always sees one
+	// consistent release.
+	files atomic.Pointer[map[string]*asset]
+	// compress gzips compressible files without a precompressed variant.
+	compress bool
 }
 
 // NewSPAServer creates a new SPA server
 func NewSPAServer(files map[string][]byte) *SPAServer {
-	indexHTML, hasIndex := files["index.html"]
-	return &SPAServer{
-		files:     files,
-		indexHTML: indexHTML,
-		hasIndex:  hasIndex,
+	s := &SPAServer{}
+	s.Swap(files)
+	return s
+}
+
+// Swap atomically replaces the served files. Requests in flight finish
+// against the previous set.
+func (s *SPAServer) Swap(files map[string][]byte) {
+	assets := make(map[string]*asset, len(files))
+	for path, content := range files {
+		assets[path] = newAsset(path, content)
+	}
+	for _, name := range hashedAssetNames(files) {
+		if a, ok := assets[name]; ok {
+			a.immutable = true
+		}
+	}
+	for path, a := range assets {
+		for _, enc := range encodings {
+			if v, ok := files[path+enc.ext]; ok {
+				a.variants[enc.name] = &variant{content: v, etag: variantETag(a.etag, enc.name)}
+			}
+		}
 	}
+	s.files.Store(&assets)
 }
 
 // ServeHTTP implements http.Handler
 func (s *SPAServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Log the request
-	log.Printf("%s %s", r.Method, r.URL.Path)
-
 	// Only handle GET requests
 	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -123,28 +235,33 @@ func (s *SPAServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if path == "" {
 		path = "index.html"
 	}
+	files := *s.files.Load()
 
 	// Try to serve the exact file
-	if content, ok := s.files[path]; ok {
-		s.serveFile(w, r, path, content)
+	if a, ok := files[path]; ok {
+		s.serveFile(w, r, path, a)
+		return
+	}
+
+	// Bazel probes registries for modules they may not have and relies on
+	// a 404 to move on to the next --registry; never answer those with the
+	// SPA shell.
+	if strings.HasPrefix(path, registryTreePrefix) {
+		http.NotFound(w, r)
 		return
 	}
 
 	// Directory-style: try <path>/index.html (so /modules/rules_buf
 	// resolves to modules/rules_buf/index.html when present).
-	if path != "" {
-		dirIndex := strings.TrimSuffix(path, "/") + "/index.html"
-		if content, ok := s.files[dirIndex]; ok {
-			log.Printf("  -> %s", dirIndex)
-			s.serveFile(w, r, dirIndex, content)
-			return
-		}
+	dirIndex := strings.TrimSuffix(path, "/") + "/index.html"
+	if a, ok := files[dirIndex]; ok {
+		s.serveFile(w, r, dirIndex, a)
+		return
 	}
 
 	// SPA fallback: serve index.html for unknown paths
-	if s.hasIndex {
-		log.Printf("  -> fallback to index.html")
-		s.serveFile(w, r, "index.html", s.indexHTML)
+	if a, ok := files["index.html"]; ok {
+		s.serveFile(w, r, "index.html", a)
 		return
 	}
 
@@ -153,38 +270,105 @@ func (s *SPAServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // serveFile serves a file with appropriate headers
-func (s *SPAServer) serveFile(w http.ResponseWriter, r *http.Request, path string, content []byte) {
-	// Set Content-Type based on file extension
-	ext := filepath.Ext(path)
-	contentType := mime.TypeByExtension(ext)
-	if contentType == "" {
-		contentType = "application/octet-stream"
-	}
-	w.Header().Set("Content-Type", contentType)
-
-	// Set caching headers
-	// For HTML files, raw protobufs that aren't hashed, and the refresh
-	// manifest (always served from a fixed un-hashed path), don't cache.
-	// For assets, cache for a long time (they should be content-hashed).
-	if strings.HasSuffix(path, ".html") || strings.HasSuffix(path, ".pb") || path == "manifest.pb.gz" {
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	} else {
-		// Assets are content-hashed, so cache aggressively
-		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-	}
-
-	// Handle HEAD requests
-	if r.Method == http.MethodHead {
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
-		w.WriteHeader(http.StatusOK)
-		return
+func (s *SPAServer) serveFile(w http.ResponseWriter, r *http.Request, path string, a *asset) {
+	h := w.Header()
+	h.Set("Content-Type", a.contentType)
+	h.Set("Cache-Control", cacheControl(a))
+
+	content, etag := a.content, a.etag
+	if len(a.variants) > 0 || (s.compress && a.compressible) {
+		h.Add("Vary", "Accept-Encoding")
+		if name, v := s.negotiate(r, a); v != nil {
+			h.Set("Content-Encoding", name)
+			content, etag = v.content, v.etag
+		}
+	}
+	h.Set("ETag", etag)
+
+	// ServeContent answers HEAD, If-None-Match and Range requests. The
+	// tarball carries no meaningful mtimes, so there is no Last-Modified.
+	http.ServeContent(w, r, path, time.Time{}, bytes.NewReader(content))
+}
+
+// negotiate picks the encoding to serve a to r with, preferring brotli over
+// gzip. It returns a nil variant for the identity encoding.
+func (s *SPAServer) negotiate(r *http.Request, a *asset) (string, *variant) {
+	accepted := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	for _, enc := range encodings {
+		if !accepted(enc.name) {
+			continue
+		}
+		if v, ok := a.variants[enc.name]; ok {
+			return enc.name, v
+		}
+		if enc.name == "gzip" && s.compress && a.compressible {
+			if v := a.gzipped(); v != nil {
+				return enc.name, v
+			}
+		}
+	}
+	return "", nil
+}
+
+// manifestName is the refresh manifest releasecompiler writes at the
+// tarball root.
+const manifestName = "manifest.pb.gz"
+
+// hashedAssetNames returns the content-hashed file names recorded in the
+// release's manifest.pb.gz: the asset_hashes values and the delta assets.
+// Names that merely look hashed (api/modules/boringssl/0.20240913.0.json)
+// are not in it. A release without a readable manifest has none.
+func hashedAssetNames(files map[string][]byte) []string {
+	data, ok := files[manifestName]
+	if !ok {
+		return nil
+	}
+	manifest, err := readManifest(data)
+	if err != nil {
+		log.Printf("warning: %s: %v; serving every file with no-cache", manifestName, err)
+		return nil
+	}
+	var names []string
+	for _, name := range manifest.AssetHashes {
+		names = append(names, name)
+	}
+	for _, delta := range manifest.Deltas {
+		for _, name := range []string{delta.RegistryDeltaAsset, delta.SymbolsDeltaAsset} {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func readManifest(data []byte) (*bzpb.RegistryManifest, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &bzpb.RegistryManifest{}
+	if err := proto.Unmarshal(raw, manifest); err != nil {
+		return nil, err
 	}
+	return manifest, nil
+}
 
-	// Write the content
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write(content); err != nil {
-		log.Printf("Error writing response: %v", err)
+// cacheControl returns the caching policy for an asset. Content-hashed
+// assets never change and are cached for a year. Everything else — HTML,
+// the refresh manifest, feeds, api/ files, un-hashed protobufs and the
+// registry mirror — is served from a fixed path and must be revalidated,
+// which the ETag makes cheap.
+func cacheControl(a *asset) string {
+	if a.immutable {
+		return "public, max-age=31536000, immutable"
 	}
+	return "no-cache"
 }
 
 // formatBytes formats byte count as human-readable size
@@ -201,7 +385,41 @@ func formatBytes(bytes int) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// loadTarball loads a tarball into memory
+// loadRelease loads the files of a release tarball, or of a directory
+// holding an unpacked release.
+func loadRelease(path string) (map[string][]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadDir(path)
+	}
+	return loadTarball(path)
+}
+
+// loadDir reads every file under dir, keyed by slash-separated relative
+// path.
+func loadDir(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	return files, err
+}
+
 func loadTarball(path string) (map[string][]byte, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// testManifest returns a gzipped RegistryManifest listing the hashed assets
+// of the test release.
+func testManifest(t testing.TB) []byte {
+	data, err := proto.Marshal(&bzpb.RegistryManifest{
+		CommitSha:   "abc123",
+		AssetHashes: map[string]string{"bcr.js": "bcr.0123abcd.js"},
+		Deltas:      []*bzpb.ReleaseDeltaRef{{RegistryDeltaAsset: "registry.89abcdef.delta.pb.gz"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func newTestServer(t testing.TB) *SPAServer {
+	js := []byte(strings.Repeat("console.log('bcr');\n", 200))
+	s := NewSPAServer(map[string][]byte{
+		"index.html":                              []byte("<html>shell</html>"),
+		"bcr.0123abcd.js":                         js,
+		"bcr.0123abcd.js.br":                      []byte("brotli"),
+		"registry.89abcdef.delta.pb.gz":           []byte("delta"),
+		"manifest.pb.gz":                          testManifest(t),
+		"favicon.png":                             []byte("png"),
+		"api/modules/boringssl/0.20240913.0.json": []byte("{}"),
+		"registry/bazel_registry.json":            []byte("{}"),
+	})
+	s.compress = true
+	return s
+}
+
+func get(s http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestCacheControl(t *testing.T) {
+	s := newTestServer(t)
+	for path, want := range map[string]string{
+		"/bcr.0123abcd.js":                         "public, max-age=31536000, immutable",
+		"/registry.89abcdef.delta.pb.gz":           "public, max-age=31536000, immutable",
+		"/api/modules/boringssl/0.20240913.0.json": "no-cache",
+		"/":                             "no-cache",
+		"/modules/rules_foo":            "no-cache",
+		"/manifest.pb.gz":               "no-cache",
+		"/favicon.png":                  "no-cache",
+		"/registry/bazel_registry.json": "no-cache",
+	} {
+		w := get(s, path)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status %d", path, w.Code)
+		}
+		if got := w.Header().Get("Cache-Control"); got != want {
+			t.Errorf("%s: Cache-Control %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	s := newTestServer(t)
+	w := get(s, "/manifest.pb.gz")
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if w := get(s, "/manifest.pb.gz", "If-None-Match", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("If-None-Match: status %d with %d bytes, want 304", w.Code, w.Body.Len())
+	}
+	if w := get(s, "/manifest.pb.gz", "If-None-Match", `"stale"`); w.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match: status %d, want 200", w.Code)
+	}
+}
+
+func TestContentEncoding(t *testing.T) {
+	s := newTestServer(t)
+
+	w := get(s, "/bcr.0123abcd.js", "Accept-Encoding", "gzip, br")
+	if got := w.Header().Get("Content-Encoding"); got != "br" || w.Body.String() != "brotli" {
+		t.Errorf("br: Content-Encoding %q body %q, want the precompressed variant", got, w.Body.String())
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q", got)
+	}
+	brETag := w.Header().Get("ETag")
+
+	w = get(s, "/bcr.0123abcd.js", "Accept-Encoding", "gzip, br;q=0")
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("gzip: Content-Encoding %q", got)
+	}
+	if w.Header().Get("ETag") == brETag {
+		t.Errorf("encodings share ETag %s", brETag)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(body, []byte("console.log")) {
+		t.Errorf("gzip body decodes to %q", body[:20])
+	}
+
+	w = get(s, "/bcr.0123abcd.js")
+	if got := w.Header().Get("Content-Encoding"); got != "" || !strings.HasPrefix(w.Body.String(), "console.log") {
+		t.Errorf("identity: Content-Encoding %q", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	w := get(newTestServer(t), "/bcr.0123abcd.js", "Range", "bytes=0-6")
+	if w.Code != http.StatusPartialContent || w.Body.String() != "console" {
+		t.Errorf("status %d body %q, want 206 console", w.Code, w.Body.String())
+	}
+}
+
+func TestHead(t *testing.T) {
+	req := httptest.NewRequest(http.MethodHead, "/favicon.png", nil)
+	w := httptest.NewRecorder()
+	newTestServer(t).ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || w.Header().Get("Content-Length") != "3" {
+		t.Errorf("status %d body %d bytes Content-Length %q", w.Code, w.Body.Len(), w.Header().Get("Content-Length"))
+	}
+}
+
+func TestRegistryNotFound(t *testing.T) {
+	if w := get(newTestServer(t), "/registry/modules/missing/metadata.json"); w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	h := withAccessLog(newTestServer(t), &buf)
+	get(h, "/favicon.png", "User-Agent", "test-agent", "Referer", "http://example.com/")
+	line := buf.String()
+	for _, want := range []string{`"GET /favicon.png HTTP/1.1" 200 3 "http://example.com/" "test-agent"`, " - - ["} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log line %q missing %q", line, want)
+		}
+	}
+}
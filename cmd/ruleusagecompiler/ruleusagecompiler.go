@@ -0,0 +1,325 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/buildgraph"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/symbolref"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+)
+
+const toolName = "ruleusagecompiler"
+
+type Config struct {
+	OutputFile   string
+	RegistryFile string
+	PackagesFile string
+	SymbolsFile  string
+	MaxValues    int
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.RegistryFile == "" {
+		return fmt.Errorf("registry_file is required")
+	}
+	if cfg.PackagesFile == "" {
+		return fmt.Errorf("packages_file is required")
+	}
+	if cfg.SymbolsFile == "" {
+		return fmt.Errorf("symbols_file is required")
+	}
+
+	var registry bzpb.Registry
+	if err := protoutil.ReadFile(cfg.RegistryFile, &registry); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.RegistryFile, err)
+	}
+	var packages sympb.ModuleRegistryPackages
+	if err := protoutil.ReadFile(cfg.PackagesFile, &packages); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.PackagesFile, err)
+	}
+	var symbols sympb.ModuleRegistrySymbols
+	if err := protoutil.ReadFile(cfg.SymbolsFile, &symbols); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.SymbolsFile, err)
+	}
+
+	index := buildRuleUsageIndex(&registry, &packages, &symbols, cfg.MaxValues)
+
+	if err := protoutil.WriteFile(cfg.OutputFile, index); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	var resolved int
+	for _, rule := range index.Rule {
+		if rule.DefinedIn != nil {
+			resolved++
+		}
+	}
+	log.Printf("Aggregated usage of %d rule kinds (%d resolved to their definition)", len(index.Rule), resolved)
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the RuleUsageIndex file to write")
+	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file to read (for dependencies and MVS-selected versions)")
+	fs.StringVar(&cfg.PackagesFile, "packages_file", "", "the ModuleRegistryPackages protobuf file to read")
+	fs.StringVar(&cfg.SymbolsFile, "symbols_file", "", "the ModuleRegistrySymbols protobuf file to read")
+	fs.IntVar(&cfg.MaxValues, "max_values", 20, "the maximum number of distinct literal values to record per attribute")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+// ruleStats accumulates the call sites of one rule kind.
+type ruleStats struct {
+	usage      *sympb.RuleUsage
+	symbol     *sympb.Symbol
+	modules    map[string]bool
+	attributes map[string]*attributeStats
+}
+
+type attributeStats struct {
+	count      int32
+	nonLiteral int32
+	values     map[string]int32
+}
+
+// buildRuleUsageIndex aggregates the targets of the newest version of each
+// module that has extracted packages. Counting every version would weigh
+// modules by their number of releases.
+func buildRuleUsageIndex(registry *bzpb.Registry, packages *sympb.ModuleRegistryPackages, symbols *sympb.ModuleRegistrySymbols, maxValues int) *sympb.RuleUsageIndex {
+	r := symbolref.NewResolver(registry, symbols)
+
+	packagesByVersion := make(map[string]*sympb.ModuleVersionPackages)
+	for _, mvp := range packages.ModuleVersion {
+		packagesByVersion[symbolref.ModuleVersionKey(mvp.ModuleName, mvp.Version)] = mvp
+	}
+
+	stats := make(map[string]*ruleStats)
+	modules := slices.Clone(registry.Modules)
+	slices.SortFunc(modules, func(a, b *bzpb.Module) int { return cmp.Compare(a.Name, b.Name) })
+
+	for _, module := range modules {
+		// versions are listed newest first
+		for _, mv := range module.Versions {
+			mvp, ok := packagesByVersion[symbolref.ModuleVersionKey(module.Name, mv.Version)]
+			if !ok || len(mvp.Package) == 0 {
+				continue
+			}
+			for _, pkg := range mvp.Package {
+				collectPackage(r, module.Name, mv, pkg, stats)
+			}
+			break
+		}
+	}
+
+	index := &sympb.RuleUsageIndex{}
+	for _, s := range stats {
+		index.Rule = append(index.Rule, s.finish(maxValues))
+	}
+	slices.SortFunc(index.Rule, func(a, b *sympb.RuleUsage) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.DefinedIn.GetModuleName(), b.DefinedIn.GetModuleName()),
+		)
+	})
+	return index
+}
+
+// collectPackage adds the targets of pkg to stats, resolving each target
+// kind through the load statements of the package.
+func collectPackage(r *symbolref.Resolver, moduleName string, mv *bzpb.ModuleVersion, pkg *slpb.Package, stats map[string]*ruleStats) {
+	pkgPath := pkg.Name
+	if i := strings.Index(pkgPath, "//"); i >= 0 {
+		pkgPath = pkgPath[i+2:]
+	}
+
+	loaded := make(map[string]*sympb.ResolvedLoadSymbol)
+	for _, load := range pkg.Load {
+		for _, sym := range r.ResolveLoad(moduleName, mv, pkgPath, load).Symbol {
+			loaded[sym.To] = sym
+		}
+	}
+
+	for _, target := range pkg.Target {
+		if target.Kind == "" {
+			continue
+		}
+		key := target.Kind
+		sym := loaded[target.Kind]
+		if sym != nil && sym.DefinedIn != nil {
+			key = symbolref.ModuleVersionKey(sym.DefinedIn.ModuleName, sym.DefinedIn.Version) +
+				"//" + sym.DefinedIn.File.GetPkg() + ":" + sym.DefinedIn.File.GetName() + "%" + sym.Name
+		}
+
+		s, ok := stats[key]
+		if !ok {
+			s = &ruleStats{
+				usage:      &sympb.RuleUsage{Kind: target.Kind},
+				modules:    make(map[string]bool),
+				attributes: make(map[string]*attributeStats),
+			}
+			if sym != nil && sym.DefinedIn != nil {
+				s.usage.DefinedIn = sym.DefinedIn
+				s.usage.Name = sym.Name
+				s.usage.Type = sym.Type
+				s.symbol = r.Symbol(sym.DefinedIn, sym.Name)
+			}
+			stats[key] = s
+		}
+		s.add(moduleName, target)
+	}
+}
+
+func (s *ruleStats) add(moduleName string, target *slpb.Target) {
+	s.usage.Count++
+	s.usage.IsMacro = s.usage.IsMacro || target.IsMacro
+	s.modules[moduleName] = true
+
+	for _, attr := range target.Attribute {
+		if attr.Name == "name" {
+			continue
+		}
+		a, ok := s.attributes[attr.Name]
+		if !ok {
+			a = &attributeStats{values: make(map[string]int32)}
+			s.attributes[attr.Name] = a
+		}
+		a.count++
+		if isLiteral(attr.Value) {
+			a.values[buildgraph.ValueString(attr.Value)]++
+		} else {
+			a.nonLiteral++
+		}
+	}
+}
+
+// finish converts the accumulated stats into a RuleUsage, keeping at most
+// maxValues literal values per attribute.
+func (s *ruleStats) finish(maxValues int) *sympb.RuleUsage {
+	usage := s.usage
+	for module := range s.modules {
+		usage.UsedByModule = append(usage.UsedByModule, module)
+	}
+	slices.Sort(usage.UsedByModule)
+
+	for name, a := range s.attributes {
+		au := &sympb.AttributeUsage{Name: name, Count: a.count, NonLiteralCount: a.nonLiteral}
+		for value, count := range a.values {
+			au.Value = append(au.Value, &sympb.AttributeValueCount{Value: value, Count: count})
+		}
+		slices.SortFunc(au.Value, func(x, y *sympb.AttributeValueCount) int {
+			return cmp.Or(cmp.Compare(y.Count, x.Count), cmp.Compare(x.Value, y.Value))
+		})
+		if len(au.Value) > maxValues {
+			au.OmittedValueCount = int32(len(au.Value) - maxValues)
+			au.Value = au.Value[:maxValues]
+		}
+		usage.Attribute = append(usage.Attribute, au)
+	}
+	slices.SortFunc(usage.Attribute, func(x, y *sympb.AttributeUsage) int {
+		return cmp.Or(cmp.Compare(y.Count, x.Count), cmp.Compare(x.Name, y.Name))
+	})
+
+	for _, name := range declaredAttributes(s.symbol) {
+		if _, ok := s.attributes[name]; !ok {
+			usage.UnusedAttribute = append(usage.UnusedAttribute, name)
+		}
+	}
+	return usage
+}
+
+// declaredAttributes returns the public attribute (or parameter) names a
+// call site of sym could set, excluding `name`.
+func declaredAttributes(sym *sympb.Symbol) []string {
+	var names []string
+	addAttrs := func(attrs []*sdpb.AttributeInfo) {
+		for _, attr := range attrs {
+			names = append(names, attr.Name)
+		}
+	}
+	addParams := func(params []*sdpb.FunctionParamInfo) {
+		for _, param := range params {
+			switch param.Role {
+			case sdpb.FunctionParamRole_PARAM_ROLE_VARARGS, sdpb.FunctionParamRole_PARAM_ROLE_KWARGS:
+				continue
+			}
+			names = append(names, param.Name)
+		}
+	}
+
+	switch info := sym.GetInfo().(type) {
+	case *sympb.Symbol_Rule:
+		addAttrs(info.Rule.GetInfo().GetAttribute())
+	case *sympb.Symbol_Macro:
+		addAttrs(info.Macro.GetInfo().GetAttribute())
+	case *sympb.Symbol_RuleMacro:
+		addParams(info.RuleMacro.GetFunction().GetInfo().GetParameter())
+	case *sympb.Symbol_Func:
+		addParams(info.Func.GetInfo().GetParameter())
+	}
+
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return name == "name" || strings.HasPrefix(name, "_")
+	})
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// isLiteral reports whether v is a string, int or bool, or a list of them.
+func isLiteral(v *slpb.Value) bool {
+	switch x := v.GetValue().(type) {
+	case *slpb.Value_String_, *slpb.Value_Int, *slpb.Value_Bool:
+		return true
+	case *slpb.Value_List:
+		for _, elem := range x.List.Value {
+			if !isLiteral(elem) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
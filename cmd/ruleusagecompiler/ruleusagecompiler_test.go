@@ -0,0 +1,128 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+)
+
+func strValue(s string) *slpb.Value {
+	return &slpb.Value{Value: &slpb.Value_String_{String_: s}}
+}
+
+func target(kind, name string, attrs ...*slpb.TargetAttribute) *slpb.Target {
+	return &slpb.Target{Kind: kind, Name: name, Attribute: attrs}
+}
+
+func attr(name string, value *slpb.Value) *slpb.TargetAttribute {
+	return &slpb.TargetAttribute{Name: name, Value: value}
+}
+
+func TestBuildRuleUsageIndex(t *testing.T) {
+	registry := &bzpb.Registry{
+		Modules: []*bzpb.Module{
+			{Name: "rules_cc", Versions: []*bzpb.ModuleVersion{{Version: "0.2.0"}}},
+			{Name: "app", Versions: []*bzpb.ModuleVersion{
+				{Version: "2.0.0", Deps: []*bzpb.ModuleDependency{{Name: "rules_cc", Version: "0.2.0"}}},
+				{Version: "1.0.0", Deps: []*bzpb.ModuleDependency{{Name: "rules_cc", Version: "0.2.0"}}},
+			}},
+			{Name: "lib", Versions: []*bzpb.ModuleVersion{
+				{Version: "1.0.0", Deps: []*bzpb.ModuleDependency{{Name: "rules_cc", Version: "0.2.0"}}},
+			}},
+		},
+	}
+	symbols := &sympb.ModuleRegistrySymbols{
+		ModuleVersion: []*sympb.ModuleVersionSymbols{{
+			ModuleName: "rules_cc",
+			Version:    "0.2.0",
+			File: []*sympb.File{{
+				Label: &slpb.Label{Pkg: "cc", Name: "defs.bzl"},
+				Symbol: []*sympb.Symbol{{
+					Type: sympb.SymbolType_SYMBOL_TYPE_RULE,
+					Name: "cc_library",
+					Info: &sympb.Symbol_Rule{Rule: &slpb.Rule{Info: &sdpb.RuleInfo{
+						Attribute: []*sdpb.AttributeInfo{
+							{Name: "name"}, {Name: "srcs"}, {Name: "linkstatic"}, {Name: "_private"},
+						},
+					}}},
+				}},
+			}},
+		}},
+	}
+	ccLoad := &slpb.LoadStmt{
+		Label:  &slpb.Label{Repo: "rules_cc", Pkg: "cc", Name: "defs.bzl"},
+		Symbol: []*slpb.LoadSymbol{{From: "cc_library", To: "cc_library"}},
+	}
+	glob := &slpb.Value{Value: &slpb.Value_Call{Call: &slpb.ValueCall{FunctionName: "glob"}}}
+	packages := &sympb.ModuleRegistryPackages{
+		ModuleVersion: []*sympb.ModuleVersionPackages{
+			{
+				ModuleName: "app",
+				Version:    "2.0.0",
+				Package: []*slpb.Package{{
+					Name: "@@app+//src",
+					Load: []*slpb.LoadStmt{ccLoad},
+					Target: []*slpb.Target{
+						target("cc_library", "a", attr("srcs", strValue("a.cc"))),
+						target("cc_library", "b", attr("srcs", glob)),
+						target("genrule", "gen"),
+					},
+				}},
+			},
+			{
+				// Older version, not counted.
+				ModuleName: "app",
+				Version:    "1.0.0",
+				Package: []*slpb.Package{{
+					Name:   "@@app+//src",
+					Load:   []*slpb.LoadStmt{ccLoad},
+					Target: []*slpb.Target{target("cc_library", "old")},
+				}},
+			},
+			{
+				ModuleName: "lib",
+				Version:    "1.0.0",
+				Package: []*slpb.Package{{
+					Name: "@@lib+//",
+					Load: []*slpb.LoadStmt{ccLoad},
+					Target: []*slpb.Target{
+						target("cc_library", "lib", attr("srcs", strValue("a.cc")), attr("visibility", strValue("//visibility:public"))),
+					},
+				}},
+			},
+		},
+	}
+
+	index := buildRuleUsageIndex(registry, packages, symbols, 20)
+	if len(index.Rule) != 2 {
+		t.Fatalf("got %d rules, want 2: %v", len(index.Rule), index.Rule)
+	}
+
+	cc := index.Rule[0]
+	if cc.Kind != "cc_library" || cc.Count != 3 {
+		t.Errorf("cc_library: kind %q count %d", cc.Kind, cc.Count)
+	}
+	if cc.DefinedIn.GetModuleName() != "rules_cc" || cc.Name != "cc_library" || cc.Type != sympb.SymbolType_SYMBOL_TYPE_RULE {
+		t.Errorf("cc_library not resolved: %v", cc)
+	}
+	if want := []string{"app", "lib"}; !slices.Equal(cc.UsedByModule, want) {
+		t.Errorf("used_by_module = %v; want %v", cc.UsedByModule, want)
+	}
+	srcs := cc.Attribute[0]
+	if srcs.Name != "srcs" || srcs.Count != 3 || srcs.NonLiteralCount != 1 ||
+		len(srcs.Value) != 1 || srcs.Value[0].Value != "a.cc" || srcs.Value[0].Count != 2 {
+		t.Errorf("srcs usage = %v", srcs)
+	}
+	if want := []string{"linkstatic"}; !slices.Equal(cc.UnusedAttribute, want) {
+		t.Errorf("unused_attribute = %v; want %v", cc.UnusedAttribute, want)
+	}
+
+	genrule := index.Rule[1]
+	if genrule.Kind != "genrule" || genrule.Count != 1 || genrule.DefinedIn != nil {
+		t.Errorf("genrule = %v", genrule)
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
-	"encoding/xml"
+	"archive/tar"
+	"bytes"
+	"cmp"
 	"flag"
 	"fmt"
 	"log"
@@ -14,36 +16,53 @@ import (
 
 	bhpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/help/v1"
 	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
 	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/sitemap"
 )
 
-// URLSet represents the root element of a sitemap
-type URLSet struct {
-	XMLName xml.Name `xml:"urlset"`
-	Xmlns   string   `xml:"xmlns,attr"`
-	URLs    []URL    `xml:"url"`
-}
+// dateLayouts are the commit date forms found in the registry: `git log
+// --format=%ci` for the registry commit and RFC3339 for version commits.
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05 -0700"}
 
-// URL represents a single URL entry in the sitemap
-type URL struct {
-	Loc        string  `xml:"loc"`
-	LastMod    string  `xml:"lastmod,omitempty"`
-	ChangeFreq string  `xml:"changefreq,omitempty"`
-	Priority   float64 `xml:"priority,omitempty"`
+// commitLastMod returns a commit date as YYYY-MM-DD, or "" if the date is
+// missing or unparseable.
+func commitLastMod(date string) string {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.UTC().Format("2006-01-02")
+		}
+	}
+	return ""
 }
 
 // versionLastMod returns the version's commit date as YYYY-MM-DD, or "" if
 // the date is missing or unparseable. Used as the <lastmod> for every
 // per-version URL emitted into the sitemap.
 func versionLastMod(version *bzpb.ModuleVersion) string {
-	if version == nil || version.Commit == nil || version.Commit.Date == "" {
+	if version == nil || version.Commit == nil {
 		return ""
 	}
-	t, err := time.Parse(time.RFC3339, version.Commit.Date)
-	if err != nil {
-		return ""
+	return commitLastMod(version.Commit.Date)
+}
+
+// moduleLastMod returns the newest version commit date of a module, so the
+// module page changes whenever a version is published.
+func moduleLastMod(module *bzpb.Module) string {
+	var newest string
+	for _, version := range module.Versions {
+		newest = max(newest, versionLastMod(version))
+	}
+	return newest
+}
+
+// repositoryImages returns the repository owner's avatar as the page image
+// for GitHub-hosted modules, mirroring the avatar shown on module pages.
+func repositoryImages(md *bzpb.RepositoryMetadata) []sitemap.Image {
+	if md == nil || md.Type != bzpb.RepositoryType_GITHUB || md.Organization == "" {
+		return nil
 	}
-	return t.Format("2006-01-02")
+	return []sitemap.Image{{Loc: fmt.Sprintf("https://github.com/%s.png", md.Organization)}}
 }
 
 // safeURLPath %-escapes each `/`-separated segment of a path component while
@@ -85,8 +104,59 @@ func loadLabelURLKey(repo, pkg, file, symbol string) string {
 type Config struct {
 	RegistryFile    string
 	BazelFlagDbFile string
+	SymbolsFile     string
 	OutputFile      string
 	BaseURL         string
+	SymbolURLs      bool
+	MaxURLs         int
+	Alternates      []string
+}
+
+// stringSliceFlag is a custom flag type for repeatable flags
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string { return strings.Join(*s, ",") }
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// alternate is a localized mirror of the site. Every entry gets an
+// xhtml:link to the same path under baseURL.
+type alternate struct {
+	hreflang string
+	baseURL  string
+}
+
+// options controls which entries generateSitemap emits.
+type options struct {
+	baseURL    string
+	symbolURLs bool
+	alternates []alternate
+}
+
+// sitemapBuilder accumulates sitemap entries. Every entry is given a
+// lastmod (the registry commit date unless the caller has a more specific
+// one) and the configured alternate links.
+type sitemapBuilder struct {
+	baseURL        string
+	defaultLastMod string
+	alternates     []alternate
+	urls           []sitemap.URL
+}
+
+func (b *sitemapBuilder) add(u sitemap.URL) {
+	if u.LastMod == "" {
+		u.LastMod = b.defaultLastMod
+	}
+	for _, alt := range b.alternates {
+		u.Alternates = append(u.Alternates, sitemap.Alternate{
+			Rel:      "alternate",
+			Hreflang: alt.hreflang,
+			Href:     alt.baseURL + strings.TrimPrefix(u.Loc, b.baseURL),
+		})
+	}
+	b.urls = append(b.urls, u)
 }
 
 func main() {
@@ -115,6 +185,18 @@ func run(args []string) error {
 		return fmt.Errorf("--base_url is required")
 	}
 
+	opts := &options{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		symbolURLs: cfg.SymbolURLs,
+	}
+	for _, value := range cfg.Alternates {
+		hreflang, altURL, ok := strings.Cut(value, "=")
+		if !ok || hreflang == "" || altURL == "" {
+			return fmt.Errorf("--alternate must be HREFLANG=BASE_URL, got %q", value)
+		}
+		opts.alternates = append(opts.alternates, alternate{hreflang: hreflang, baseURL: strings.TrimRight(altURL, "/")})
+	}
+
 	registry := &bzpb.Registry{}
 	if err := protoutil.ReadFile(cfg.RegistryFile, registry); err != nil {
 		return fmt.Errorf("failed to read registry file: %w", err)
@@ -130,16 +212,27 @@ func run(args []string) error {
 		}
 	}
 
-	sitemap, err := generateSitemap(registry, flagDb, cfg.BaseURL)
+	// The symbols file is optional too; without it documentation URLs come
+	// from whatever the registry file embeds.
+	var symbols *sympb.ModuleRegistrySymbols
+	if cfg.SymbolsFile != "" {
+		symbols = &sympb.ModuleRegistrySymbols{}
+		if err := protoutil.ReadFile(cfg.SymbolsFile, symbols); err != nil {
+			return fmt.Errorf("failed to read symbols file: %w", err)
+		}
+	}
+
+	urls, err := generateSitemap(registry, flagDb, symbols, opts)
 	if err != nil {
 		return fmt.Errorf("failed to generate sitemap: %w", err)
 	}
 
-	if err := writeSitemap(cfg.OutputFile, sitemap); err != nil {
+	shards, err := writeSitemapTar(cfg.OutputFile, urls, opts.baseURL, sitemap.Limits{MaxURLs: cfg.MaxURLs})
+	if err != nil {
 		return fmt.Errorf("failed to write sitemap: %w", err)
 	}
 
-	log.Printf("Generated sitemap with %d URLs", len(sitemap.URLs))
+	log.Printf("Generated sitemap with %d URLs in %d shards", len(urls), shards)
 	return nil
 }
 
@@ -147,8 +240,12 @@ func parseFlags(args []string) (cfg Config, err error) {
 	fs := flag.NewFlagSet("sitemapcompiler", flag.ExitOnError)
 	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "path to the registry protobuf file")
 	fs.StringVar(&cfg.BazelFlagDbFile, "bazel_flag_db_file", "", "optional path to the bazel flag database protobuf (enables /bazel/flags URLs)")
-	fs.StringVar(&cfg.OutputFile, "output_file", "", "path to the output sitemap.xml file")
+	fs.StringVar(&cfg.SymbolsFile, "symbols_file", "", "optional path to the ModuleRegistrySymbols protobuf (documentation URLs for versions the registry file carries no documentation for)")
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "path to the output tar of the sitemap index and its shards")
 	fs.StringVar(&cfg.BaseURL, "base_url", "", "base URL for the sitemap (e.g., https://example.com)")
+	fs.BoolVar(&cfg.SymbolURLs, "symbol_urls", true, "emit a URL per documented symbol in addition to per-file documentation URLs")
+	fs.IntVar(&cfg.MaxURLs, "max_urls", sitemap.MaxURLs, "maximum number of URLs per sitemap shard")
+	fs.Var((*stringSliceFlag)(&cfg.Alternates), "alternate", "HREFLANG=BASE_URL of a localized mirror to link from every entry (repeatable)")
 	fs.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "usage: sitemapcompiler [options]\n")
 		fs.PrintDefaults()
@@ -160,21 +257,32 @@ func parseFlags(args []string) (cfg Config, err error) {
 	return
 }
 
-func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL string) (*URLSet, error) {
-	sitemap := &URLSet{
-		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
-		URLs:  make([]URL, 0),
+// generateSitemap returns every sitemap entry of the registry. Entries
+// without a commit of their own carry the registry commit date as lastmod.
+func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, symbols *sympb.ModuleRegistrySymbols, opts *options) ([]sitemap.URL, error) {
+	baseURL := opts.baseURL
+	b := &sitemapBuilder{
+		baseURL:        baseURL,
+		defaultLastMod: commitLastMod(registry.CommitDate),
+		alternates:     opts.alternates,
+	}
+
+	symbolsByID := make(map[string]*sympb.ModuleVersionSymbols)
+	if symbols != nil {
+		for _, mv := range symbols.ModuleVersion {
+			symbolsByID[mv.ModuleName+"@"+mv.Version] = mv
+		}
 	}
 
 	// Add homepage
-	sitemap.URLs = append(sitemap.URLs, URL{
+	b.add(sitemap.URL{
 		Loc:        baseURL,
 		ChangeFreq: "daily",
 		Priority:   1.0,
 	})
 
 	// Add modules index page
-	sitemap.URLs = append(sitemap.URLs, URL{
+	b.add(sitemap.URL{
 		Loc:        fmt.Sprintf("%s/modules", baseURL),
 		ChangeFreq: "daily",
 		Priority:   0.9,
@@ -192,7 +300,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 		"/bazel/flags/list/categories",
 		"/bazel/flags/list/tags",
 	} {
-		sitemap.URLs = append(sitemap.URLs, URL{
+		b.add(sitemap.URL{
 			Loc:        baseURL + p,
 			ChangeFreq: "weekly",
 			Priority:   0.9,
@@ -200,7 +308,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 	}
 
 	// Add registry-wide Targets landing page.
-	sitemap.URLs = append(sitemap.URLs, URL{
+	b.add(sitemap.URL{
 		Loc:        baseURL + "/targets",
 		ChangeFreq: "weekly",
 		Priority:   0.7,
@@ -215,7 +323,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 			if version.Version == "" {
 				continue
 			}
-			sitemap.URLs = append(sitemap.URLs, URL{
+			b.add(sitemap.URL{
 				Loc:        fmt.Sprintf("%s/bazel/%s", baseURL, version.Version),
 				ChangeFreq: "monthly",
 				Priority:   0.7,
@@ -234,7 +342,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 			if f.Name == "" {
 				continue
 			}
-			sitemap.URLs = append(sitemap.URLs, URL{
+			b.add(sitemap.URL{
 				Loc:        fmt.Sprintf("%s/bazel/flags/%s", baseURL, f.Name),
 				ChangeFreq: "monthly",
 				Priority:   0.6,
@@ -247,7 +355,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 					continue
 				}
 				seenTags[tag] = struct{}{}
-				sitemap.URLs = append(sitemap.URLs, URL{
+				b.add(sitemap.URL{
 					Loc:        fmt.Sprintf("%s/bazel/flags/tag/%s", baseURL, tag),
 					ChangeFreq: "monthly",
 					Priority:   0.5,
@@ -256,7 +364,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 			if f.Category != "" {
 				if _, ok := seenCats[f.Category]; !ok {
 					seenCats[f.Category] = struct{}{}
-					sitemap.URLs = append(sitemap.URLs, URL{
+					b.add(sitemap.URL{
 						Loc:        fmt.Sprintf("%s/bazel/flags/category/%s", baseURL, url.PathEscape(f.Category)),
 						ChangeFreq: "monthly",
 						Priority:   0.5,
@@ -268,7 +376,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 			if cmd == "" {
 				continue
 			}
-			sitemap.URLs = append(sitemap.URLs, URL{
+			b.add(sitemap.URL{
 				Loc:        fmt.Sprintf("%s/bazel/command/%s", baseURL, cmd),
 				ChangeFreq: "monthly",
 				Priority:   0.5,
@@ -287,10 +395,12 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 		}
 
 		// Add module page
-		sitemap.URLs = append(sitemap.URLs, URL{
+		b.add(sitemap.URL{
 			Loc:        fmt.Sprintf("%s/modules/%s", baseURL, module.Name),
 			ChangeFreq: "weekly",
 			Priority:   0.8,
+			LastMod:    moduleLastMod(module),
+			Images:     repositoryImages(module.RepositoryMetadata),
 		})
 
 		// Iterate through all module versions
@@ -304,30 +414,40 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 
 			// Bare module-version URL — Overview is the default tab there,
 			// so no separate /overview emission is needed.
-			sitemap.URLs = append(sitemap.URLs, URL{
+			b.add(sitemap.URL{
 				Loc:        versionBase,
 				ChangeFreq: "monthly",
 				Priority:   0.7,
 				LastMod:    lastMod,
+				Images:     repositoryImages(cmp.Or(version.RepositoryMetadata, module.RepositoryMetadata)),
 			})
 
 			// Documentation file + symbol URLs (no separate /docs landing —
 			// these per-file URLs cover the SPA's drill-down surface).
-			if version.Source != nil && version.Source.Documentation != nil {
-				for _, file := range version.Source.Documentation.File {
+			// Prefer the registry's embedded documentation and fall back to
+			// the symbols file.
+			docs := version.GetSource().GetDocumentation()
+			if docs == nil {
+				docs = symbolsByID[module.Name+"@"+version.Version]
+			}
+			if docs != nil {
+				for _, file := range docs.File {
 					if file.Label == nil {
 						continue
 					}
 					filePath := path.Join(file.Label.Pkg, file.Label.Name)
 					fileLoc := fmt.Sprintf("%s/docs/%s", versionBase, filePath)
-					sitemap.URLs = append(sitemap.URLs, URL{
+					b.add(sitemap.URL{
 						Loc:        fileLoc,
 						ChangeFreq: "monthly",
 						Priority:   0.6,
 						LastMod:    lastMod,
 					})
+					if !opts.symbolURLs {
+						continue
+					}
 					for _, sym := range file.Symbol {
-						sitemap.URLs = append(sitemap.URLs, URL{
+						b.add(sitemap.URL{
 							Loc:        fmt.Sprintf("%s/%s", fileLoc, sym.Name),
 							ChangeFreq: "monthly",
 							Priority:   0.5,
@@ -341,7 +461,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 			// per-rule-kind load coordinates that feed /targets/<urlKey>.
 			if version.Source != nil && version.Source.Packages != nil &&
 				len(version.Source.Packages.Package) > 0 {
-				sitemap.URLs = append(sitemap.URLs, URL{
+				b.add(sitemap.URL{
 					Loc:        fmt.Sprintf("%s/packages", versionBase),
 					ChangeFreq: "monthly",
 					Priority:   0.6,
@@ -350,7 +470,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 				for _, pkg := range version.Source.Packages.Package {
 					pkgPath := stripRepoPrefix(pkg.Name)
 					if pkgPath != "" {
-						sitemap.URLs = append(sitemap.URLs, URL{
+						b.add(sitemap.URL{
 							Loc:        fmt.Sprintf("%s/packages/%s", versionBase, safeURLPath(pkgPath)),
 							ChangeFreq: "monthly",
 							Priority:   0.5,
@@ -388,7 +508,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 
 			// Attestations tab — only when attestations.json had entries.
 			if version.Attestations != nil && len(version.Attestations.Attestations) > 0 {
-				sitemap.URLs = append(sitemap.URLs, URL{
+				b.add(sitemap.URL{
 					Loc:        fmt.Sprintf("%s/attestations", versionBase),
 					ChangeFreq: "monthly",
 					Priority:   0.6,
@@ -398,14 +518,14 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 
 			// Overlay tab + per-file drill-down (the Trie-routed file viewer).
 			if version.Source != nil && len(version.Source.Overlay) > 0 {
-				sitemap.URLs = append(sitemap.URLs, URL{
+				b.add(sitemap.URL{
 					Loc:        fmt.Sprintf("%s/overlay", versionBase),
 					ChangeFreq: "monthly",
 					Priority:   0.6,
 					LastMod:    lastMod,
 				})
 				for filename := range version.Source.Overlay {
-					sitemap.URLs = append(sitemap.URLs, URL{
+					b.add(sitemap.URL{
 						Loc:        fmt.Sprintf("%s/overlay/%s", versionBase, safeURLPath(filename)),
 						ChangeFreq: "monthly",
 						Priority:   0.5,
@@ -416,14 +536,14 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 
 			// Patches tab + per-file drill-down.
 			if version.Source != nil && len(version.Source.Patches) > 0 {
-				sitemap.URLs = append(sitemap.URLs, URL{
+				b.add(sitemap.URL{
 					Loc:        fmt.Sprintf("%s/patches", versionBase),
 					ChangeFreq: "monthly",
 					Priority:   0.6,
 					LastMod:    lastMod,
 				})
 				for filename := range version.Source.Patches {
-					sitemap.URLs = append(sitemap.URLs, URL{
+					b.add(sitemap.URL{
 						Loc:        fmt.Sprintf("%s/patches/%s", versionBase, safeURLPath(filename)),
 						ChangeFreq: "monthly",
 						Priority:   0.5,
@@ -434,7 +554,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 
 			// Testing tab — only when a presubmit configuration is attached.
 			if version.Presubmit != nil {
-				sitemap.URLs = append(sitemap.URLs, URL{
+				b.add(sitemap.URL{
 					Loc:        fmt.Sprintf("%s/testing", versionBase),
 					ChangeFreq: "monthly",
 					Priority:   0.6,
@@ -453,7 +573,7 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 		}
 		sort.Strings(keys)
 		for _, k := range keys {
-			sitemap.URLs = append(sitemap.URLs, URL{
+			b.add(sitemap.URL{
 				Loc:        fmt.Sprintf("%s/targets/%s", baseURL, safeURLPath(k)),
 				ChangeFreq: "weekly",
 				Priority:   0.5,
@@ -461,31 +581,24 @@ func generateSitemap(registry *bzpb.Registry, flagDb *bhpb.BazelFlagDb, baseURL
 		}
 	}
 
-	return sitemap, nil
+	return b.urls, nil
 }
 
-func writeSitemap(filename string, sitemap *URLSet) error {
-	file, err := os.Create(filename)
+// writeSitemapTar shards urls and writes the tar merged into the release:
+// the shards under sitemaps/ and a sitemap index at sitemap.xml, the URL
+// robots.txt advertises. It returns the number of shards.
+func writeSitemapTar(filename string, urls []sitemap.URL, baseURL string, limits sitemap.Limits) (int, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	shards, err := sitemap.WriteTar(tw, urls, baseURL, "sitemap.xml", "sitemaps", limits)
 	if err != nil {
-		return fmt.Errorf("create file: %w", err)
+		return 0, err
 	}
-	defer file.Close()
-
-	// Write XML header
-	if _, err := file.WriteString(xml.Header); err != nil {
-		return fmt.Errorf("write xml header: %w", err)
+	if err := tw.Close(); err != nil {
+		return 0, fmt.Errorf("close tar: %w", err)
 	}
-
-	// Marshal and write the sitemap
-	encoder := xml.NewEncoder(file)
-	encoder.Indent("", "  ")
-	if err := encoder.Encode(sitemap); err != nil {
-		return fmt.Errorf("encode xml: %w", err)
-	}
-
-	if _, err := file.WriteString("\n"); err != nil {
-		return fmt.Errorf("write newline: %w", err)
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return 0, fmt.Errorf("write file: %w", err)
 	}
-
-	return nil
+	return shards, nil
 }
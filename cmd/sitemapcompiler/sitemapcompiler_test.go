@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/sitemap"
+)
+
+func newTestRegistry() *bzpb.Registry {
+	return &bzpb.Registry{
+		CommitDate: "2024-03-01 12:00:00 +0000",
+		Modules: []*bzpb.Module{{
+			Name: "rules_foo",
+			RepositoryMetadata: &bzpb.RepositoryMetadata{
+				Type:         bzpb.RepositoryType_GITHUB,
+				Organization: "foo-org",
+				Name:         "rules_foo",
+			},
+			Versions: []*bzpb.ModuleVersion{
+				{Version: "2.0", Commit: &bzpb.ModuleCommit{Date: "2024-02-10T08:00:00Z"}},
+				{Version: "1.0", Commit: &bzpb.ModuleCommit{Date: "2023-05-01T08:00:00Z"}},
+			},
+		}},
+	}
+}
+
+func newTestSymbols() *sympb.ModuleRegistrySymbols {
+	return &sympb.ModuleRegistrySymbols{ModuleVersion: []*sympb.ModuleVersionSymbols{{
+		ModuleName: "rules_foo",
+		Version:    "2.0",
+		File: []*sympb.File{{
+			Label:  &slpb.Label{Pkg: "foo", Name: "defs.bzl"},
+			Symbol: []*sympb.Symbol{{Name: "foo_library"}},
+		}},
+	}}}
+}
+
+func urlsByLoc(urls []sitemap.URL) map[string]sitemap.URL {
+	result := make(map[string]sitemap.URL, len(urls))
+	for _, u := range urls {
+		result[u.Loc] = u
+	}
+	return result
+}
+
+func TestGenerateSitemapLastMod(t *testing.T) {
+	urls, err := generateSitemap(newTestRegistry(), nil, nil, &options{baseURL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range urls {
+		if u.LastMod == "" {
+			t.Errorf("%s has no lastmod", u.Loc)
+		}
+	}
+	byLoc := urlsByLoc(urls)
+	for loc, want := range map[string]string{
+		"https://example.com":                       "2024-03-01",
+		"https://example.com/modules/rules_foo":     "2024-02-10",
+		"https://example.com/modules/rules_foo/1.0": "2023-05-01",
+		"https://example.com/modules/rules_foo/2.0": "2024-02-10",
+		"https://example.com/bazel/flags/list/tags": "2024-03-01",
+	} {
+		if got := byLoc[loc].LastMod; got != want {
+			t.Errorf("%s lastmod = %q, want %q", loc, got, want)
+		}
+	}
+
+	module := byLoc["https://example.com/modules/rules_foo"]
+	if len(module.Images) != 1 || module.Images[0].Loc != "https://github.com/foo-org.png" {
+		t.Errorf("module images = %v", module.Images)
+	}
+}
+
+func TestGenerateSitemapSymbols(t *testing.T) {
+	const fileLoc = "https://example.com/modules/rules_foo/2.0/docs/foo/defs.bzl"
+
+	urls, err := generateSitemap(newTestRegistry(), nil, newTestSymbols(), &options{baseURL: "https://example.com", symbolURLs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLoc := urlsByLoc(urls)
+	if _, ok := byLoc[fileLoc]; !ok {
+		t.Errorf("missing documentation file URL from the symbols file")
+	}
+	if _, ok := byLoc[fileLoc+"/foo_library"]; !ok {
+		t.Errorf("missing symbol URL from the symbols file")
+	}
+
+	urls, err = generateSitemap(newTestRegistry(), nil, newTestSymbols(), &options{baseURL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLoc = urlsByLoc(urls)
+	if _, ok := byLoc[fileLoc]; !ok {
+		t.Errorf("documentation file URL should not depend on symbol URLs")
+	}
+	if _, ok := byLoc[fileLoc+"/foo_library"]; ok {
+		t.Errorf("symbol URL emitted with symbol URLs disabled")
+	}
+}
+
+func TestGenerateSitemapAlternates(t *testing.T) {
+	urls, err := generateSitemap(newTestRegistry(), nil, nil, &options{
+		baseURL:    "https://example.com",
+		alternates: []alternate{{hreflang: "de", baseURL: "https://de.example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := urlsByLoc(urls)["https://example.com/modules/rules_foo"]
+	if len(module.Alternates) != 1 {
+		t.Fatalf("alternates = %v", module.Alternates)
+	}
+	if alt := module.Alternates[0]; alt.Rel != "alternate" || alt.Hreflang != "de" || alt.Href != "https://de.example.com/modules/rules_foo" {
+		t.Errorf("alternate = %+v", alt)
+	}
+}
@@ -1,8 +1,8 @@
 // sitemapindexcompiler reads a JSON manifest of routes (one per SPA-visible
-// URL) and writes:
+// URL) and writes a tar of:
 //
-//   - sitemap.xml.gz — gzipped urlset XML
-//   - sitemapindex.xml — sitemap-index pointing at the single sitemap above
+//   - sitemap-N.xml.gz — gzipped urlset XML, sharded to the protocol limits
+//   - sitemapindex.xml — sitemap-index pointing at every shard above
 //
 // The manifest is produced by the module_registry Starlark rule via
 // ctx.actions.write — see rules/module_registry.bzl _compile_sitemap_index_action.
@@ -12,14 +12,16 @@
 package main
 
 import (
-	"compress/gzip"
+	"archive/tar"
+	"bytes"
 	"encoding/json"
-	"encoding/xml"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+
+	"github.com/bazel-contrib/bcr-frontend/pkg/sitemap"
 )
 
 // routeEntry mirrors the dict shape written by _route_to_dict in module_registry.bzl.
@@ -30,37 +32,11 @@ type routeEntry struct {
 	ChangeFreq string  `json:"changefreq"`
 }
 
-// URLSet is the root of a sitemap.xml.
-type URLSet struct {
-	XMLName xml.Name `xml:"urlset"`
-	Xmlns   string   `xml:"xmlns,attr"`
-	URLs    []url    `xml:"url"`
-}
-
-type url struct {
-	Loc        string  `xml:"loc"`
-	LastMod    string  `xml:"lastmod,omitempty"`
-	ChangeFreq string  `xml:"changefreq,omitempty"`
-	Priority   float64 `xml:"priority,omitempty"`
-}
-
-// SitemapIndex is the root of a sitemapindex.xml.
-type SitemapIndex struct {
-	XMLName  xml.Name      `xml:"sitemapindex"`
-	Xmlns    string        `xml:"xmlns,attr"`
-	Sitemaps []sitemapRef  `xml:"sitemap"`
-}
-
-type sitemapRef struct {
-	Loc string `xml:"loc"`
-}
-
 type config struct {
-	RoutesFile         string
-	BaseURL            string
-	SitemapOutput      string
-	SitemapIndexOutput string
-	SitemapURL         string
+	RoutesFile string
+	BaseURL    string
+	OutputFile string
+	MaxURLs    int
 }
 
 func main() {
@@ -83,14 +59,8 @@ func run(args []string) error {
 	if cfg.BaseURL == "" {
 		return fmt.Errorf("--base_url is required")
 	}
-	if cfg.SitemapOutput == "" {
-		return fmt.Errorf("--sitemap_output is required")
-	}
-	if cfg.SitemapIndexOutput == "" {
-		return fmt.Errorf("--sitemapindex_output is required")
-	}
-	if cfg.SitemapURL == "" {
-		return fmt.Errorf("--sitemap_url is required")
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("--output_file is required")
 	}
 
 	routes, err := readRoutes(cfg.RoutesFile)
@@ -98,13 +68,14 @@ func run(args []string) error {
 		return fmt.Errorf("reading routes: %w", err)
 	}
 
-	urls := make([]url, 0, len(routes))
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	urls := make([]sitemap.URL, 0, len(routes))
 	for _, r := range routes {
 		loc := r.Loc
 		if strings.HasPrefix(loc, "/") {
-			loc = strings.TrimRight(cfg.BaseURL, "/") + loc
+			loc = baseURL + loc
 		}
-		urls = append(urls, url{
+		urls = append(urls, sitemap.URL{
 			Loc:        loc,
 			LastMod:    r.LastMod,
 			ChangeFreq: r.ChangeFreq,
@@ -112,15 +83,21 @@ func run(args []string) error {
 		})
 	}
 
-	if err := writeGzippedSitemap(cfg.SitemapOutput, urls); err != nil {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	shards, err := sitemap.WriteTar(tw, urls, baseURL, "sitemapindex.xml", "", sitemap.Limits{MaxURLs: cfg.MaxURLs})
+	if err != nil {
 		return fmt.Errorf("writing sitemap: %w", err)
 	}
-	if err := writeSitemapIndex(cfg.SitemapIndexOutput, cfg.SitemapURL); err != nil {
-		return fmt.Errorf("writing sitemap index: %w", err)
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing tar: %w", err)
+	}
+	if err := os.WriteFile(cfg.OutputFile, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", cfg.OutputFile, err)
 	}
 
-	log.Printf("Wrote sitemap (%d URLs) to %s; index to %s",
-		len(urls), cfg.SitemapOutput, cfg.SitemapIndexOutput)
+	log.Printf("Wrote sitemap (%d URLs in %d shards) and index to %s",
+		len(urls), shards, cfg.OutputFile)
 	return nil
 }
 
@@ -129,9 +106,8 @@ func parseFlags(args []string) (config, error) {
 	fs := flag.NewFlagSet("sitemapindexcompiler", flag.ExitOnError)
 	fs.StringVar(&cfg.RoutesFile, "routes_file", "", "JSON file of routes (list of {loc, lastmod, priority, changefreq})")
 	fs.StringVar(&cfg.BaseURL, "base_url", "", "base URL prepended to routes whose loc begins with '/' (e.g., https://registry.bazel.build)")
-	fs.StringVar(&cfg.SitemapOutput, "sitemap_output", "", "output path for the gzipped sitemap.xml.gz")
-	fs.StringVar(&cfg.SitemapIndexOutput, "sitemapindex_output", "", "output path for sitemapindex.xml")
-	fs.StringVar(&cfg.SitemapURL, "sitemap_url", "", "public URL the sitemap is served from (used in sitemapindex.xml)")
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "output path for the tar of sitemapindex.xml and its sitemap-N.xml.gz shards")
+	fs.IntVar(&cfg.MaxURLs, "max_urls", sitemap.MaxURLs, "maximum number of URLs per sitemap shard")
 	fs.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "usage: sitemapindexcompiler [options]\n")
 		fs.PrintDefaults()
@@ -153,58 +129,3 @@ func readRoutes(path string) ([]routeEntry, error) {
 	}
 	return routes, nil
 }
-
-func writeGzippedSitemap(path string, urls []url) error {
-	urlset := &URLSet{
-		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
-		URLs:  urls,
-	}
-
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	gz := gzip.NewWriter(f)
-	defer gz.Close()
-
-	if _, err := gz.Write([]byte(xml.Header)); err != nil {
-		return err
-	}
-	enc := xml.NewEncoder(gz)
-	enc.Indent("", "  ")
-	if err := enc.Encode(urlset); err != nil {
-		return fmt.Errorf("encode urlset: %w", err)
-	}
-	if _, err := gz.Write([]byte("\n")); err != nil {
-		return err
-	}
-	return nil
-}
-
-func writeSitemapIndex(path, sitemapURL string) error {
-	idx := &SitemapIndex{
-		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
-		Sitemaps: []sitemapRef{
-			{Loc: sitemapURL},
-		},
-	}
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if _, err := f.WriteString(xml.Header); err != nil {
-		return err
-	}
-	enc := xml.NewEncoder(f)
-	enc.Indent("", "  ")
-	if err := enc.Encode(idx); err != nil {
-		return fmt.Errorf("encode sitemapindex: %w", err)
-	}
-	if _, err := f.WriteString("\n"); err != nil {
-		return err
-	}
-	return nil
-}
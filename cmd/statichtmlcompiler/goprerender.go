@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bazel-contrib/bcr-frontend/pkg/ssr"
+)
+
+// Renderers selectable with --renderer.
+const (
+	rendererChrome = "chrome"
+	rendererGo     = "go"
+	rendererDiff   = "diff"
+)
+
+// processGo renders every URL with the pure-Go renderer from the release
+// tarball, without a browser or a server. URLs the Go renderer does not
+// cover fall back to Chrome when --chrome_path is set and the URL is
+// absolute; otherwise they get the unmodified SPA shell, which boots the
+// route client-side just like a non-prerendered release.
+func processGo(cfg Config) error {
+	r, err := ssr.LoadRelease(cfg.ReleaseTar)
+	if err != nil {
+		return fmt.Errorf("load release: %w", err)
+	}
+
+	var fallback Config
+	var rendered, shells int
+	for i, targetURL := range cfg.URLs {
+		outputFile := cfg.OutputFiles[i]
+		content, err := r.Render(targetURL)
+		mode := "go"
+		if errors.Is(err, ssr.ErrUnsupported) {
+			if cfg.ChromePath != "" && isAbsoluteURL(targetURL) {
+				fallback.URLs = append(fallback.URLs, targetURL)
+				fallback.OutputFiles = append(fallback.OutputFiles, outputFile)
+				continue
+			}
+			content, err, mode = r.Shell(), nil, "shell"
+			shells++
+		} else {
+			rendered++
+		}
+		if err != nil {
+			return err
+		}
+		if err := writeRenderedHTML(outputFile, content); err != nil {
+			return fmt.Errorf("write %s: %w", outputFile, err)
+		}
+		logURL(cfg, "[%d/%d] %s -> %s (%s, %d bytes)", i+1, len(cfg.URLs), targetURL, outputFile, mode, len(content))
+	}
+
+	if cfg.FlagPagesDir != "" {
+		names := r.FlagNames()
+		for _, name := range names {
+			content, err := r.Render("/bazel/flags/" + name)
+			if err != nil {
+				return err
+			}
+			outputFile := filepath.Join(cfg.FlagPagesDir, "bazel", "flags", name, "index.html")
+			if err := writeRenderedHTML(outputFile, content); err != nil {
+				return fmt.Errorf("write %s: %w", outputFile, err)
+			}
+		}
+		rendered += len(names)
+		log.Printf("Rendered %d flag pages into %s", len(names), cfg.FlagPagesDir)
+	}
+
+	log.Printf("Go renderer: %d pages rendered, %d shell fallbacks, %d Chrome fallbacks", rendered, shells, len(fallback.URLs))
+	if len(fallback.URLs) == 0 {
+		return nil
+	}
+	chromeCfg := cfg
+	chromeCfg.URLs = fallback.URLs
+	chromeCfg.OutputFiles = fallback.OutputFiles
+	chromeCfg.UseChromedp = true
+	return renderWithChrome(chromeCfg)
+}
+
+// diffRendered compares what Chrome wrote for each URL with the Go
+// rendering of the same path. Every text node of the Go skeleton must
+// appear in the Chrome snapshot; URLs the Go renderer does not cover are
+// skipped. Mismatches and a summary line are written to --diff_report (if
+// set); any mismatch fails the run.
+func diffRendered(cfg Config, r *ssr.Renderer) error {
+	var report strings.Builder
+	var compared, mismatched int
+	for i, targetURL := range cfg.URLs {
+		rendered, err := r.Render(targetURL)
+		if errors.Is(err, ssr.ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		outputFile := strings.TrimSpace(strings.Split(cfg.OutputFiles[i], ",")[0])
+		snapshot, err := os.ReadFile(outputFile)
+		if err != nil {
+			return fmt.Errorf("read chrome output: %w", err)
+		}
+		missing, err := ssr.Missing(rendered, snapshot)
+		if err != nil {
+			return fmt.Errorf("%s: %w", targetURL, err)
+		}
+		compared++
+		if len(missing) == 0 {
+			continue
+		}
+		mismatched++
+		fmt.Fprintf(&report, "%s: %d text nodes missing from the chrome rendering\n", ssr.NormalizePath(targetURL), len(missing))
+		for _, text := range missing {
+			fmt.Fprintf(&report, "\t%q\n", text)
+		}
+	}
+
+	summary := fmt.Sprintf("compared %d pages, %d differ", compared, mismatched)
+	log.Printf("Diff: %s", summary)
+	if cfg.DiffReport != "" {
+		if err := os.WriteFile(cfg.DiffReport, []byte(report.String()+summary+"\n"), 0644); err != nil {
+			return fmt.Errorf("write diff report: %w", err)
+		}
+	}
+	if mismatched > 0 {
+		log.Print(report.String())
+		return fmt.Errorf("go and chrome renderings differ on %d of %d pages", mismatched, compared)
+	}
+	return nil
+}
+
+func isAbsoluteURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
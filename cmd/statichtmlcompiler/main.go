@@ -17,6 +17,8 @@ import (
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
+
+	"github.com/bazel-contrib/bcr-frontend/pkg/ssr"
 )
 
 type stringList []string
@@ -71,6 +73,20 @@ type Config struct {
 	// REGISTRY_DATA parse so a 16-way simultaneous parse doesn't push tabs
 	// past --timeout. 0 disables warmup.
 	WarmupConcurrency int
+	// Renderer selects how pages are produced: "chrome" snapshots them
+	// with chromedp, "go" renders them from the release tarball with
+	// pkg/ssr (no browser, no server), and "diff" snapshots with Chrome
+	// and then checks the Go rendering against each snapshot.
+	Renderer string
+	// ReleaseTar is the release tarball the go and diff renderers read
+	// index.html, registry.pb.gz and the flag database from.
+	ReleaseTar string
+	// FlagPagesDir, with --renderer=go, additionally receives a page per
+	// Bazel flag at bazel/flags/<name>/index.html.
+	FlagPagesDir string
+	// DiffReport, with --renderer=diff, receives the list of pages whose
+	// renderings differ.
+	DiffReport string
 	// Open file handle for ProgressLog (populated by run() — not a flag).
 	// Per-URL render lines go to this file unconditionally so tail -f
 	// remains useful even when --verbose is off.
@@ -164,7 +180,7 @@ func run(args []string) error {
 		log.Printf("Progress log: %s", cfg.ProgressLog)
 	}
 
-	if len(cfg.URLs) == 0 {
+	if len(cfg.URLs) == 0 && !(cfg.Renderer == rendererGo && cfg.FlagPagesDir != "") {
 		return fmt.Errorf("at least one --url is required")
 	}
 
@@ -172,6 +188,30 @@ func run(args []string) error {
 		return fmt.Errorf("number of --url and --output_file flags must match (got %d URLs and %d output files)", len(cfg.URLs), len(cfg.OutputFiles))
 	}
 
+	switch cfg.Renderer {
+	case rendererGo:
+		return processGo(cfg)
+	case rendererDiff:
+		// Load the Go side first so a bad tarball fails before Chrome
+		// spends minutes rendering.
+		r, err := ssr.LoadRelease(cfg.ReleaseTar)
+		if err != nil {
+			return fmt.Errorf("load release: %w", err)
+		}
+		if err := renderWithChrome(cfg); err != nil {
+			return err
+		}
+		return diffRendered(cfg, r)
+	case rendererChrome:
+		return renderWithChrome(cfg)
+	default:
+		return fmt.Errorf("unknown --renderer %q (want %s, %s or %s)", cfg.Renderer, rendererChrome, rendererGo, rendererDiff)
+	}
+}
+
+// renderWithChrome snapshots cfg.URLs with chromedp (or plain HTTP with
+// --chromedp=false).
+func renderWithChrome(cfg Config) error {
 	// Single URL mode
 	if len(cfg.URLs) == 1 {
 		return processSingleURL(cfg, cfg.URLs[0], cfg.OutputFiles[0])
@@ -916,6 +956,10 @@ func parseFlags(args []string) (cfg Config, err error) {
 	fs.StringVar(&cfg.ProgressLog, "progress_log", "", "mirror log output to this file path; useful with `tail -f` to watch a long shard mid-action since Bazel buffers stderr. Per-URL render lines are written to this file regardless of --verbose")
 	fs.BoolVar(&cfg.Verbose, "verbose", false, "also emit the per-URL render line to stderr (Bazel will dump it all at action-end). Default off keeps the final stderr quiet; the line still reaches --progress_log when set")
 	fs.IntVar(&cfg.Retries, "retries", 1, "retry a failed render up to N times with a fresh tab. Covers transient chromedp errors (tab/renderer crash, CDP race, per-render timeout) without masking systemic bugs")
+	fs.StringVar(&cfg.Renderer, "renderer", rendererChrome, "how to render pages: chrome (headless Chrome snapshot), go (pure-Go skeleton from --release_tar; no browser needed) or diff (chrome, then check the go rendering against it)")
+	fs.StringVar(&cfg.ReleaseTar, "release_tar", "", "release tarball to read index.html, registry.pb.gz and the flag database from (required for --renderer=go and diff)")
+	fs.StringVar(&cfg.FlagPagesDir, "flag_pages_dir", "", "with --renderer=go, also render every Bazel flag page into this directory at bazel/flags/<name>/index.html")
+	fs.StringVar(&cfg.DiffReport, "diff_report", "", "with --renderer=diff, write the pages whose renderings differ to this file")
 	fs.IntVar(&cfg.WarmupConcurrency, "warmup_concurrency", 4, "pre-warm all pool tabs against the SPA root before processing real URLs, with at most this many in-flight REGISTRY_DATA parses. Avoids the cold-start contention spike that times out the first batch under high pool sizes. 0 disables warmup")
 	fs.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "usage: statichtmlcompiler [options]\n\nExamples:\n")
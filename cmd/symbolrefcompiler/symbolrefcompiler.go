@@ -0,0 +1,188 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/paramsfile"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/symbolref"
+)
+
+const toolName = "symbolrefcompiler"
+
+type Config struct {
+	OutputFile   string
+	RegistryFile string
+	SymbolsFile  string
+}
+
+func main() {
+	log.SetPrefix(toolName + ": ")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(0) // don't print timestamps
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	parsedArgs, err := paramsfile.ReadArgsParamsFile(args)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+
+	cfg, err := parseFlags(parsedArgs)
+	if err != nil {
+		return fmt.Errorf("failed to parse args: %v", err)
+	}
+
+	if cfg.OutputFile == "" {
+		return fmt.Errorf("output_file is required")
+	}
+	if cfg.RegistryFile == "" {
+		return fmt.Errorf("registry_file is required")
+	}
+	if cfg.SymbolsFile == "" {
+		return fmt.Errorf("symbols_file is required")
+	}
+
+	var registry bzpb.Registry
+	if err := protoutil.ReadFile(cfg.RegistryFile, &registry); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.RegistryFile, err)
+	}
+	var symbols sympb.ModuleRegistrySymbols
+	if err := protoutil.ReadFile(cfg.SymbolsFile, &symbols); err != nil {
+		return fmt.Errorf("reading %s: %v", cfg.SymbolsFile, err)
+	}
+
+	index := buildSymbolReferenceIndex(&registry, &symbols)
+
+	if err := protoutil.WriteFile(cfg.OutputFile, index); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	log.Printf("Resolved loads for %d module versions (%d symbols used across modules)",
+		len(index.ModuleVersion), len(index.Usage))
+	return nil
+}
+
+func parseFlags(args []string) (cfg Config, err error) {
+	fs := flag.NewFlagSet(toolName, flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "output_file", "", "the SymbolReferenceIndex file to write")
+	fs.StringVar(&cfg.RegistryFile, "registry_file", "", "the registry protobuf file to read (for dependencies and MVS-selected versions)")
+	fs.StringVar(&cfg.SymbolsFile, "symbols_file", "", "the ModuleRegistrySymbols protobuf file to read")
+	fs.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s @PARAMS_FILE", toolName)
+		fs.PrintDefaults()
+	}
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	return
+}
+
+func compareFileRefs(a, b *sympb.FileRef) int {
+	return cmp.Or(
+		cmp.Compare(a.ModuleName, b.ModuleName),
+		cmp.Compare(a.Version, b.Version),
+		cmp.Compare(a.File.GetPkg(), b.File.GetPkg()),
+		cmp.Compare(a.File.GetName(), b.File.GetName()),
+	)
+}
+
+type usageKey struct {
+	module, version, pkg, file, name string
+}
+
+// buildSymbolReferenceIndex resolves every load statement of every module
+// version with extracted symbols, and aggregates the cross-module usages of
+// each resolved symbol.
+func buildSymbolReferenceIndex(registry *bzpb.Registry, symbols *sympb.ModuleRegistrySymbols) *sympb.SymbolReferenceIndex {
+	r := symbolref.NewResolver(registry, symbols)
+	index := &sympb.SymbolReferenceIndex{}
+	usages := make(map[usageKey]*sympb.SymbolUsage)
+
+	mvsList := slices.Clone(symbols.ModuleVersion)
+	slices.SortFunc(mvsList, func(a, b *sympb.ModuleVersionSymbols) int {
+		return cmp.Or(cmp.Compare(a.ModuleName, b.ModuleName), cmp.Compare(a.Version, b.Version))
+	})
+
+	for _, mvs := range mvsList {
+		mv := r.ModuleVersion(mvs.ModuleName, mvs.Version)
+		if mv == nil {
+			continue
+		}
+		refs := &sympb.ModuleVersionReferences{ModuleName: mvs.ModuleName, Version: mvs.Version}
+
+		files := slices.Clone(mvs.File)
+		slices.SortFunc(files, func(a, b *sympb.File) int {
+			return cmp.Or(cmp.Compare(a.Label.GetPkg(), b.Label.GetPkg()), cmp.Compare(a.Label.GetName(), b.Label.GetName()))
+		})
+		for _, file := range files {
+			if file.Label == nil {
+				continue
+			}
+			fileRefs := &sympb.FileReferences{Label: symbolref.FileLabel(file.Label)}
+			usedBy := &sympb.FileRef{ModuleName: mvs.ModuleName, Version: mvs.Version, File: symbolref.FileLabel(file.Label)}
+			for _, sym := range file.Symbol {
+				load := sym.GetLoad()
+				if load == nil {
+					continue
+				}
+				resolved := r.ResolveLoad(mvs.ModuleName, mv, file.Label.GetPkg(), load)
+				fileRefs.Load = append(fileRefs.Load, resolved)
+
+				for _, rs := range resolved.Symbol {
+					if rs.DefinedIn == nil || rs.DefinedIn.ModuleName == mvs.ModuleName {
+						continue
+					}
+					key := usageKey{rs.DefinedIn.ModuleName, rs.DefinedIn.Version, rs.DefinedIn.File.Pkg, rs.DefinedIn.File.Name, rs.Name}
+					usage, ok := usages[key]
+					if !ok {
+						usage = &sympb.SymbolUsage{
+							DefinedIn:  rs.DefinedIn,
+							Name:       rs.Name,
+							Type:       rs.Type,
+							Deprecated: rs.Deprecated,
+						}
+						usages[key] = usage
+					}
+					usage.UsedBy = append(usage.UsedBy, usedBy)
+				}
+			}
+			if len(fileRefs.Load) > 0 {
+				refs.File = append(refs.File, fileRefs)
+			}
+		}
+		if len(refs.File) > 0 {
+			index.ModuleVersion = append(index.ModuleVersion, refs)
+		}
+	}
+
+	for _, usage := range usages {
+		slices.SortFunc(usage.UsedBy, compareFileRefs)
+		usage.UsedBy = slices.CompactFunc(usage.UsedBy, func(a, b *sympb.FileRef) bool {
+			return compareFileRefs(a, b) == 0
+		})
+		for _, ref := range usage.UsedBy {
+			usage.UsedByModule = append(usage.UsedByModule, ref.ModuleName)
+		}
+		usage.UsedByModule = slices.Compact(usage.UsedByModule)
+		index.Usage = append(index.Usage, usage)
+	}
+	slices.SortFunc(index.Usage, func(a, b *sympb.SymbolUsage) int {
+		return cmp.Or(compareFileRefs(a.DefinedIn, b.DefinedIn), cmp.Compare(a.Name, b.Name))
+	})
+
+	return index
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+)
+
+func loadSymbol(repo, pkg, name string, symbols ...string) *sympb.Symbol {
+	load := &slpb.LoadStmt{Label: &slpb.Label{Repo: repo, Pkg: pkg, Name: name}}
+	for _, s := range symbols {
+		load.Symbol = append(load.Symbol, &slpb.LoadSymbol{From: s, To: s})
+	}
+	return &sympb.Symbol{Type: sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT, Info: &sympb.Symbol_Load{Load: load}}
+}
+
+func newTestData() (*bzpb.Registry, *sympb.ModuleRegistrySymbols) {
+	registry := &bzpb.Registry{
+		Modules: []*bzpb.Module{
+			{Name: "rules_cc", Versions: []*bzpb.ModuleVersion{{Version: "0.1.0"}, {Version: "0.2.0"}}},
+			{Name: "app", Versions: []*bzpb.ModuleVersion{{
+				Version: "1.0.0",
+				Deps: []*bzpb.ModuleDependency{
+					// MVS picked a newer version than the one declared.
+					{Name: "rules_cc", Version: "0.1.0", SelectedVersion: "0.2.0"},
+					{Name: "missing", Version: "1.0"},
+				},
+			}}},
+		},
+	}
+	symbols := &sympb.ModuleRegistrySymbols{
+		ModuleVersion: []*sympb.ModuleVersionSymbols{
+			{
+				ModuleName: "rules_cc",
+				Version:    "0.2.0",
+				File: []*sympb.File{
+					{
+						Label:  &slpb.Label{Repo: "rules_cc", Pkg: "cc", Name: "defs.bzl"},
+						Symbol: []*sympb.Symbol{loadSymbol("", "cc/private", "rules.bzl", "cc_library", "old_macro")},
+					},
+					{
+						Label: &slpb.Label{Repo: "rules_cc", Pkg: "cc/private", Name: "rules.bzl"},
+						Symbol: []*sympb.Symbol{
+							{Type: sympb.SymbolType_SYMBOL_TYPE_RULE, Name: "cc_library"},
+							{
+								Type: sympb.SymbolType_SYMBOL_TYPE_FUNCTION,
+								Name: "old_macro",
+								Info: &sympb.Symbol_Func{Func: &slpb.Function{Info: &sdpb.StarlarkFunctionInfo{
+									Deprecated: &sdpb.FunctionDeprecationInfo{DocString: "use cc_library"},
+								}}},
+							},
+						},
+					},
+				},
+			},
+			{
+				ModuleName: "app",
+				Version:    "1.0.0",
+				File: []*sympb.File{
+					{
+						Label: &slpb.Label{Repo: "app", Pkg: "", Name: "defs.bzl"},
+						Symbol: []*sympb.Symbol{
+							loadSymbol("rules_cc", "cc", "defs.bzl", "cc_library", "old_macro", "nope"),
+							loadSymbol("missing", "", "x.bzl", "x"),
+							loadSymbol("unknown", "", "y.bzl", "y"),
+							loadSymbol("", "", "internal.bzl", "helper"),
+						},
+					},
+					{
+						Label:  &slpb.Label{Repo: "app", Pkg: "", Name: "internal.bzl"},
+						Symbol: []*sympb.Symbol{{Type: sympb.SymbolType_SYMBOL_TYPE_FUNCTION, Name: "helper"}},
+					},
+				},
+			},
+		},
+	}
+	return registry, symbols
+}
+
+func TestBuildSymbolReferenceIndex(t *testing.T) {
+	index := buildSymbolReferenceIndex(newTestData())
+
+	if len(index.ModuleVersion) != 2 {
+		t.Fatalf("got %d module versions; want 2", len(index.ModuleVersion))
+	}
+	app := index.ModuleVersion[0]
+	if app.ModuleName != "app" || len(app.File) != 1 {
+		t.Fatalf("unexpected app references: %v", app)
+	}
+	loads := app.File[0].Load
+	if len(loads) != 4 {
+		t.Fatalf("got %d loads; want 4", len(loads))
+	}
+
+	cc := loads[0]
+	if cc.Error != "" {
+		t.Errorf("unexpected error: %s", cc.Error)
+	}
+	if cc.File.ModuleName != "rules_cc" || cc.File.Version != "0.2.0" {
+		t.Errorf("resolved to %s@%s; want MVS-selected rules_cc@0.2.0", cc.File.ModuleName, cc.File.Version)
+	}
+	lib := cc.Symbol[0]
+	if lib.DefinedIn.GetFile().GetPkg() != "cc/private" || lib.Type != sympb.SymbolType_SYMBOL_TYPE_RULE {
+		t.Errorf("cc_library not followed through re-export: %v", lib)
+	}
+	if !cc.Symbol[1].Deprecated {
+		t.Errorf("old_macro should be deprecated")
+	}
+	if cc.Symbol[2].DefinedIn != nil {
+		t.Errorf("nope should not resolve: %v", cc.Symbol[2])
+	}
+
+	if loads[1].Error != "no symbols available for missing@1.0" {
+		t.Errorf("missing error = %q", loads[1].Error)
+	}
+	if loads[2].Error != "repository @unknown is not a dependency of app@1.0.0" {
+		t.Errorf("unknown error = %q", loads[2].Error)
+	}
+	if loads[3].Error != "" || loads[3].Symbol[0].DefinedIn.GetModuleName() != "app" {
+		t.Errorf("local load = %v", loads[3])
+	}
+
+	var names []string
+	for _, u := range index.Usage {
+		names = append(names, u.Name)
+		if !slices.Equal(u.UsedByModule, []string{"app"}) {
+			t.Errorf("%s used by %v; want [app]", u.Name, u.UsedByModule)
+		}
+	}
+	// Same-module loads (helper, and rules_cc's own re-export) are not usages.
+	if want := []string{"cc_library", "old_macro"}; !slices.Equal(names, want) {
+		t.Errorf("usages = %v; want %v", names, want)
+	}
+}
@@ -84,6 +84,7 @@ type bcrExtension struct {
 	moduleIDsBySourceUrl      map[string][]moduleID                           // tracks URLs for starlark_repository
 	resourceStatusByUrl       map[string]*bzpb.ResourceStatus                 // results of reading resourceStatusSetFile, keyed by URL
 	moduleCommits             map[moduleBazelRelPath]*bzpb.ModuleCommit       // cache of all module commits (preloaded)
+	moduleCommitCacheDir      string                                          // directory for the registry's path -> creation commit index, keyed by HEAD
 	bazelReleasesByVersion    map[string]*bzpb.BazelRelease                   // cache of Bazel releases (preloaded)
 	prAuthorSetFile           string                                          // path to pr-authors.json cache file
 	prAuthorsByPR             map[int]*bzpb.PRAuthor                          // cached PR authors keyed by PR number
@@ -98,6 +99,8 @@ type bcrExtension struct {
 	bcrRepositoryURL          string                                          // remote URL of the bazel-central-registry submodule
 	fetchAttestations         bool                                            // whether to emit http_file rules for .intoto.jsonl bundles
 	attestationFetches        map[string]*attestationFetch                    // unique .intoto.jsonl fetches, keyed by URL
+	verifyIntegrity           bool                                            // whether to download source archives and verify their integrity
+	verifyIntegrityJobs       int                                             // max concurrent downloads when verifying integrity
 }
 
 // Name returns the name of the language. This should be a prefix of the kinds
@@ -125,6 +128,8 @@ func (ext *bcrExtension) RegisterFlags(fs *flag.FlagSet, cmd string, c *config.C
 		"bazel-release-set-file", "", "path to bazel-releases.json file containing cached Bazel release data (helpful for development)")
 	fs.StringVar(&ext.prAuthorSetFile,
 		"pr-author-set-file", "", "path to pr-authors.json file containing cached PR author data (helpful for development)")
+	fs.StringVar(&ext.moduleCommitCacheDir,
+		"module-commit-cache-dir", defaultModuleCommitCacheDir(), "directory to cache the registry's file creation commits in, keyed by the registry HEAD (empty disables the cache)")
 	fs.StringVar(&ext.githubToken,
 		"github-token", os.Getenv("GITHUB_TOKEN"), "GitHub API token (defaults to GITHUB_TOKEN env var)")
 	fs.StringVar(&ext.gitlabToken,
@@ -139,6 +144,10 @@ func (ext *bcrExtension) RegisterFlags(fs *flag.FlagSet, cmd string, c *config.C
 		"docs-site-repo", "", "URL of the GitHub Pages site repo to check for existing docs")
 	fs.BoolVar(&ext.fetchAttestations,
 		"fetch-attestations", true, "emit http_file rules to fetch .intoto.jsonl bundles referenced by each module-version's attestations.json")
+	fs.BoolVar(&ext.verifyIntegrity,
+		"verify-source-integrity", false, "download every source archive (and its mirror_urls) and verify the bytes still match source.json integrity")
+	fs.IntVar(&ext.verifyIntegrityJobs,
+		"verify-source-integrity-jobs", 4, "max number of concurrent archive downloads when --verify-source-integrity is set")
 }
 
 func (ext *bcrExtension) CheckFlags(fs *flag.FlagSet, c *config.Config) error {
@@ -393,7 +402,7 @@ func (ext *bcrExtension) GenerateRules(args language.GenerateArgs) language.Gene
 		var commitRule *rule.Rule
 
 		// Create module_commit rule with git metadata using preloaded cache
-		commit, err := makeModuleVersionCommitRule(args.Config, ext.registryRoot, args.Rel, ext.moduleCommits)
+		commit, err := makeModuleVersionCommitRule(ext.registryRoot, args.Rel, ext.moduleCommits)
 		if err != nil {
 			log.Printf("warning: failed to create commit rule for %s: %v", args.Rel, err)
 		} else {
@@ -408,8 +417,10 @@ func (ext *bcrExtension) GenerateRules(args language.GenerateArgs) language.Gene
 				log.Fatalf("reading %s/source.json: %v", args.Rel, err)
 			}
 			module.Source = source
+			moduleVersionDir := filepath.Join(args.Config.WorkDir, args.Rel)
+			readModuleSourcePatchStats(moduleVersionDir, source)
 
-			sourceRule = makeModuleSourceRule(module, source, "source.json")
+			sourceRule = makeModuleSourceRule(module, source, "source.json", moduleOverlayFiles(moduleVersionDir, args.Rel, source))
 			rules = append(rules, sourceRule)
 
 			// Track the rule and URLS
@@ -75,6 +75,13 @@ func (ext *bcrExtension) AfterResolvingDeps(ctx context.Context) {
 		ext.resolveSourceCommitSHAsForRankedModules(availableBzlRepositories)
 	}
 
+	// Re-verify source archive integrity (opt-in, downloads every archive).
+	// Results land in the resource status cache, so this must happen before
+	// it is written back.
+	if ext.verifyIntegrity {
+		ext.verifySourceIntegrity()
+	}
+
 	// Write the updated caches back to files - best effort, ignoring errors
 	if err := ext.writeResourceStatusCacheFile(); err != nil {
 		log.Println("writing resource status cache file: ")
@@ -2,7 +2,9 @@ package bcr
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 
 	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
@@ -33,12 +35,13 @@ func moduleCommitKinds() map[string]rule.KindInfo {
 }
 
 // makeModuleVersionCommitRule creates a module_commit rule with git commit
-// metadata modulePath should be relative to the workspace root (e.g.,
-// "data/bazel-central-registry/modules/apple_support/1.22.0") submoduleRoot
-// should be the path to the submodule root relative to workspace (e.g.,
-// "data/bazel-central-registry") commitsCache is the preloaded cache of module
-// commits (can be nil for fallback)
-func makeModuleVersionCommitRule(cfg *config.Config, registryRoot, rel string, commitsCache map[moduleBazelRelPath]*bzpb.ModuleCommit) (*rule.Rule, error) {
+// metadata. rel should be relative to the workspace root (e.g.,
+// "data/bazel-central-registry/modules/apple_support/1.22.0") and
+// registryRoot the path to the submodule root relative to the workspace
+// (e.g., "data/bazel-central-registry"). commits is the creation index of the
+// registry preloaded by readModuleCommits; a MODULE.bazel file missing from
+// it (e.g. not committed yet) has no creation commit.
+func makeModuleVersionCommitRule(registryRoot, rel string, commits map[moduleBazelRelPath]*bzpb.ModuleCommit) (*rule.Rule, error) {
 	// Strip submodule prefix from modulePath to get path relative to submodule
 	// e.g., "data/bazel-central-registry/modules/apple_support/1.22.0" ->
 	// "modules/apple_support/1.22.0"
@@ -50,23 +53,9 @@ func makeModuleVersionCommitRule(cfg *config.Config, registryRoot, rel string, c
 	// Get the MODULE.bazel file path relative to submodule
 	moduleFile := filepath.Join(relPath, "MODULE.bazel")
 
-	var commit *bzpb.ModuleCommit
-
-	// Try to get from cache first
-	if commitsCache != nil {
-		if cached, ok := commitsCache[moduleBazelRelPath(moduleFile)]; ok {
-			commit = cached
-		}
-	}
-
-	// Fallback to individual git call if not in cache
-	if commit == nil {
-		ctx := context.Background()
-		submodulePath := filepath.Join(cfg.RepoRoot, registryRoot)
-		commit, err = gitpkg.GetFileCreationCommit(ctx, submodulePath, moduleFile)
-		if err != nil {
-			return nil, err
-		}
+	commit, ok := commits[moduleBazelRelPath(filepath.ToSlash(moduleFile))]
+	if !ok {
+		return nil, fmt.Errorf("no creation commit for %s in the registry history", moduleFile)
 	}
 
 	r := rule.NewRule(moduleCommitKind, "commit")
@@ -87,11 +76,11 @@ func makeModuleVersionCommitRule(cfg *config.Config, registryRoot, rel string, c
 }
 
 func (ext *bcrExtension) readModuleCommits(c *config.Config) {
-	// Preload all module commits in one git call for performance
+	// Preload the creation commit of every registry file in one history walk
 	ctx := context.Background()
 	submodulePath := filepath.Join(c.RepoRoot, ext.registryRoot)
 	log.Printf("Preloading module commits from %s...", submodulePath)
-	commits, err := gitpkg.GetAllModuleCommits(ctx, submodulePath, "modules/*/*/MODULE.bazel")
+	commits, err := gitpkg.LoadCreationIndex(ctx, submodulePath, os.ExpandEnv(ext.moduleCommitCacheDir))
 	if err != nil {
 		log.Printf("warning: failed to preload module commits: %v", err)
 		ext.moduleCommits = make(map[moduleBazelRelPath]*bzpb.ModuleCommit)
@@ -103,3 +92,14 @@ func (ext *bcrExtension) readModuleCommits(c *config.Config) {
 		log.Printf("Preloaded %d module commits", len(commits))
 	}
 }
+
+// defaultModuleCommitCacheDir returns the directory under the user cache
+// directory that caches the registry's creation index, or "" if there is no
+// user cache directory.
+func defaultModuleCommitCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bcr-frontend", "module-commits")
+}
@@ -0,0 +1,27 @@
+package bcr
+
+import (
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+func TestMakeModuleVersionCommitRule(t *testing.T) {
+	commits := map[moduleBazelRelPath]*bzpb.ModuleCommit{
+		"modules/foo/1.0.0/MODULE.bazel": {Sha1: "abc123", Date: "2024-01-02T03:04:05Z", Message: "Add foo@1.0.0 (#42)"},
+	}
+
+	r, err := makeModuleVersionCommitRule("data/bcr", "data/bcr/modules/foo/1.0.0", commits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.AttrString("sha1"); got != "abc123" {
+		t.Errorf("sha1 = %q, want abc123", got)
+	}
+
+	// Files missing from the index (e.g. not committed yet) have no commit;
+	// there is no separate lookup that could disagree with the index.
+	if _, err := makeModuleVersionCommitRule("data/bcr", "data/bcr/modules/foo/2.0.0", commits); err == nil {
+		t.Error("want an error for a MODULE.bazel missing from the index")
+	}
+}
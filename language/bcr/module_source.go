@@ -1,10 +1,20 @@
 package bcr
 
 import (
+	"log"
+	"maps"
 	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
 	"github.com/bazel-contrib/bcr-frontend/pkg/netutil"
+	"github.com/bazel-contrib/bcr-frontend/pkg/patchfile"
+	"github.com/bazelbuild/bazel-gazelle/label"
 	"github.com/bazelbuild/bazel-gazelle/rule"
 )
 
@@ -29,7 +39,7 @@ func moduleSourceKinds() map[string]rule.KindInfo {
 	}
 }
 
-func makeModuleSourceRule(module *bzpb.ModuleVersion, source *bzpb.ModuleSource, sourceJsonFile string) *rule.Rule {
+func makeModuleSourceRule(module *bzpb.ModuleVersion, source *bzpb.ModuleSource, sourceJsonFile string, overlayFiles []string) *rule.Rule {
 	r := rule.NewRule(moduleSourceKind, "source")
 
 	r.SetPrivateAttr(moduleVersionPrivateAttr, module)
@@ -51,20 +61,93 @@ func makeModuleSourceRule(module *bzpb.ModuleVersion, source *bzpb.ModuleSource,
 	}
 	if len(source.Patches) > 0 {
 		r.SetAttr("patches", source.Patches)
+		r.SetAttr("patch_files", modulePatchFiles(source))
 	}
 	if len(source.Overlay) > 0 {
 		r.SetAttr("overlay", source.Overlay)
 	}
+	if len(overlayFiles) > 0 {
+		r.SetAttr("overlay_files", overlayFiles)
+	}
 	if sourceJsonFile != "" {
 		r.SetAttr("source_json", sourceJsonFile)
 	}
 	if source.CommitSha != "" {
 		r.SetAttr("commit_sha", source.CommitSha)
 	}
+	if len(source.PatchStats) > 0 {
+		patchStats := make([]string, 0, len(source.PatchStats))
+		for _, stats := range source.PatchStats {
+			patchStats = append(patchStats, patchfile.FormatStats(stats))
+		}
+		r.SetAttr("patch_stats", patchStats)
+	}
 
 	return r
 }
 
+// modulePatchFiles returns the patches/ labels of the patches listed in
+// source.json, relative to the module version package.
+func modulePatchFiles(source *bzpb.ModuleSource) []string {
+	var files []string
+	for _, name := range slices.Sorted(maps.Keys(source.Patches)) {
+		files = append(files, "patches/"+name)
+	}
+	return files
+}
+
+// moduleOverlayFiles returns the labels of the overlay files listed in
+// source.json. Overlays commonly ship a BUILD file, which makes overlay/ (or
+// one of its subdirectories) a package of its own; files inside such a
+// package are referenced through it, the rest relative to the module version
+// package rel.
+func moduleOverlayFiles(moduleVersionDir, rel string, source *bzpb.ModuleSource) []string {
+	var files []string
+	for _, name := range slices.Sorted(maps.Keys(source.Overlay)) {
+		file := path.Join("overlay", name)
+		// the innermost directory with a BUILD file owns the file
+		pkg := ""
+		parts := strings.Split(path.Dir(file), "/")
+		for i := range parts {
+			dir := strings.Join(parts[:i+1], "/")
+			if isPackageDir(filepath.Join(moduleVersionDir, filepath.FromSlash(dir))) {
+				pkg = dir
+			}
+		}
+		if pkg == "" {
+			files = append(files, file)
+		} else {
+			files = append(files, label.New("", path.Join(rel, pkg), strings.TrimPrefix(file, pkg+"/")).String())
+		}
+	}
+	return files
+}
+
+// isPackageDir reports whether dir contains a BUILD file.
+func isPackageDir(dir string) bool {
+	for _, name := range []string{"BUILD.bazel", "BUILD"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// readModuleSourcePatchStats parses each patch listed in source.json from the
+// module version's patches/ directory and summarizes the overlay. Patches that
+// cannot be read or parsed are logged and skipped.
+func readModuleSourcePatchStats(moduleVersionDir string, source *bzpb.ModuleSource) {
+	for _, name := range slices.Sorted(maps.Keys(source.Patches)) {
+		stats, err := patchfile.ReadFile(filepath.Join(moduleVersionDir, "patches", name), int(source.PatchStrip))
+		if err != nil {
+			log.Printf("warning: %v", err)
+			continue
+		}
+		source.PatchStats = append(source.PatchStats, stats)
+	}
+	source.OverlayStats = patchfile.MakeOverlayStats(source.Overlay)
+}
+
 func updateModuleSourceRuleDocsUrlStatus(r *rule.Rule, status netutil.URLStatus) {
 	if status.Code != 0 {
 		r.SetAttr("docs_url_status_code", status.Code)
@@ -83,6 +166,24 @@ func updateModuleSourceRuleUrlStatus(r *rule.Rule, status netutil.URLStatus) {
 	}
 }
 
+// updateModuleSourceRuleIntegrityStatus records the integrity verification
+// result of a URL (primary or mirror), replacing any earlier result for it.
+// Entries are kept sorted by URL.
+func updateModuleSourceRuleIntegrityStatus(r *rule.Rule, url string, status netutil.IntegrityStatus) {
+	values := []string{netutil.FormatIntegrityStatus(url, status)}
+	for _, value := range r.AttrStrings("integrity_status") {
+		if u, _, err := netutil.ParseIntegrityStatus(value); err == nil && u != url {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		a, _, _ := netutil.ParseIntegrityStatus(values[i])
+		b, _, _ := netutil.ParseIntegrityStatus(values[j])
+		return a < b
+	})
+	r.SetAttr("integrity_status", values)
+}
+
 func updateModuleSourceRuleSourceCommitSha(source *protoRule[*bzpb.ModuleSource], commitSHA string) {
 	source.Rule().SetAttr("commit_sha", commitSHA)
 	source.Proto().CommitSha = commitSHA
@@ -0,0 +1,87 @@
+package bcr
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// TestModuleSourceRuleGolden checks the generated module_source rule of a
+// module version with patches and an overlay that ships BUILD files of its
+// own against testdata/module_source.golden.
+func TestModuleSourceRuleGolden(t *testing.T) {
+	const rel = "modules/foo/1.0.0"
+	dir := t.TempDir()
+	for _, name := range []string{
+		"source.json",
+		"patches/a.patch",
+		"patches/b.patch",
+		"overlay/BUILD.bazel",
+		"overlay/MODULE.bazel",
+		"overlay/docs/README.md",
+		"overlay/src/BUILD",
+		"overlay/src/util/defs.bzl",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	module := &bzpb.ModuleVersion{Name: "foo", Version: "1.0.0"}
+	source := &bzpb.ModuleSource{
+		Url:        "https://example.com/foo-1.0.0.tar.gz",
+		Integrity:  "sha256-AAAA",
+		PatchStrip: 1,
+		Patches: map[string]string{
+			"b.patch": "sha256-BBBB",
+			"a.patch": "sha256-CCCC",
+		},
+		Overlay: map[string]string{
+			"BUILD.bazel":       "sha256-DDDD",
+			"MODULE.bazel":      "sha256-EEEE",
+			"docs/README.md":    "sha256-FFFF",
+			"src/BUILD":         "sha256-GGGG",
+			"src/util/defs.bzl": "sha256-HHHH",
+		},
+	}
+
+	r := makeModuleSourceRule(module, source, "source.json", moduleOverlayFiles(dir, rel, source))
+	f := rule.EmptyFile("BUILD.bazel", rel)
+	r.Insert(f)
+	got := f.Format()
+
+	golden := filepath.Join("testdata", "module_source.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("module_source rule mismatch (run with -update to accept):\n--- got\n%s\n--- want\n%s", got, want)
+	}
+}
+
+func TestModuleOverlayFilesWithoutBuildFile(t *testing.T) {
+	source := &bzpb.ModuleSource{
+		Overlay: map[string]string{"MODULE.bazel": "sha256-AAAA", "lib/defs.bzl": "sha256-BBBB"},
+	}
+	got := moduleOverlayFiles(t.TempDir(), "modules/foo/1.0.0", source)
+	want := []string{"overlay/MODULE.bazel", "overlay/lib/defs.bzl"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("moduleOverlayFiles() = %v, want %v", got, want)
+	}
+}
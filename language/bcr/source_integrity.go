@@ -0,0 +1,111 @@
+package bcr
+
+import (
+	"cmp"
+	"log"
+	"maps"
+	"slices"
+
+	"github.com/bazel-contrib/bcr-frontend/pkg/netutil"
+)
+
+// integrityCheckItem groups a URL and the integrity it is expected to serve
+// with the module IDs that reference it. The same URL may be the primary url
+// of one module version and a mirror of another; both must serve the same
+// bytes, so the pair is the unit of work.
+type integrityCheckItem struct {
+	url       string     // URL to download (primary or mirror)
+	integrity string     // expected SRI integrity from source.json
+	moduleIDs []moduleID // modules that reference this URL
+}
+
+// collectIntegrityCheckItems gathers the primary and mirror URLs of every
+// module_source that declares an integrity, skipping blacklisted URLs. Items
+// are returned in a deterministic order.
+func (ext *bcrExtension) collectIntegrityCheckItems() []*integrityCheckItem {
+	type key struct{ url, integrity string }
+	items := make(map[key]*integrityCheckItem)
+
+	for _, id := range slices.Sorted(maps.Keys(ext.moduleSourceRules)) {
+		source := ext.moduleSourceRules[id].Proto()
+		if source.Integrity == "" {
+			continue
+		}
+		for _, url := range append([]string{source.Url}, source.MirrorUrls...) {
+			if url == "" || ext.blacklistedUrls[url] {
+				continue
+			}
+			k := key{url, source.Integrity}
+			item, ok := items[k]
+			if !ok {
+				item = &integrityCheckItem{url: url, integrity: source.Integrity}
+				items[k] = item
+			}
+			item.moduleIDs = append(item.moduleIDs, id)
+		}
+	}
+
+	result := slices.Collect(maps.Values(items))
+	slices.SortFunc(result, func(a, b *integrityCheckItem) int {
+		return cmp.Or(cmp.Compare(a.url, b.url), cmp.Compare(a.integrity, b.integrity))
+	})
+	return result
+}
+
+// verifySourceIntegrity downloads every source archive (primary url and
+// mirror_urls) and verifies the bytes against the integrity declared in
+// source.json. Upstream re-tags silently change archive contents while the
+// URL keeps answering 200, which the HEAD-based URL checks cannot detect.
+//
+// Results are recorded on the referencing module_source rules and attached to
+// the URL's cached HEAD status.
+func (ext *bcrExtension) verifySourceIntegrity() {
+	items := ext.collectIntegrityCheckItems()
+	if len(items) == 0 {
+		return
+	}
+
+	var verified, mismatched, failed int
+
+	netutil.VerifyURLsParallel("Verifying source archive integrity", items, ext.verifyIntegrityJobs,
+		func(item *integrityCheckItem) (string, string) { return item.url, item.integrity },
+		func(item *integrityCheckItem, status netutil.IntegrityStatus) {
+			switch {
+			case status.Verified():
+				verified++
+			case status.Mismatch():
+				mismatched++
+			default:
+				failed++
+			}
+			ext.handleSourceIntegrityStatus(item, status)
+		})
+
+	log.Printf("Verified source archive integrity: %d ok, %d mismatched, %d failed to download", verified, mismatched, failed)
+}
+
+// handleSourceIntegrityStatus records the verification result on each
+// referencing module_source rule. Download failures are recorded too, so they
+// are not mistaken for verified URLs.
+//
+// The resource status cache holds HEAD-check results that other passes use to
+// decide whether a URL exists, so only the integrity fields of an existing
+// entry are updated; the GET status never replaces its code and message.
+func (ext *bcrExtension) handleSourceIntegrityStatus(item *integrityCheckItem, status netutil.IntegrityStatus) {
+	if cached, found := ext.resourceStatusByUrl[item.url]; found {
+		cached.Integrity = status.Expected
+		cached.ActualIntegrity = status.Actual
+		cached.IntegrityMismatch = status.Mismatch()
+		cached.DownloadFailed = status.DownloadFailed()
+	}
+
+	for _, id := range item.moduleIDs {
+		updateModuleSourceRuleIntegrityStatus(ext.moduleSourceRules[id].Rule(), item.url, status)
+	}
+
+	if status.DownloadFailed() {
+		log.Printf("warning: could not download source archive for verification: %s (status: %d %s)", item.url, status.Code, status.Message)
+	} else if status.Mismatch() {
+		log.Printf("warning: source archive integrity mismatch: %s (expected %s, got %s; referenced by %v)", item.url, status.Expected, status.Actual, item.moduleIDs)
+	}
+}
@@ -0,0 +1,46 @@
+package bcr
+
+import (
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/netutil"
+)
+
+// TestHandleSourceIntegrityStatus_KeepsHeadStatus verifies that a failed
+// verification download does not replace the cached HEAD status, which
+// prepareBzlRepositories reads to decide whether a URL exists.
+func TestHandleSourceIntegrityStatus_KeepsHeadStatus(t *testing.T) {
+	ext := newTestExtension()
+	url := "https://example.com/foo-1.0.tar.gz"
+	ext.resourceStatusByUrl[url] = &bzpb.ResourceStatus{Url: url, Code: 200, Message: "OK"}
+
+	ext.handleSourceIntegrityStatus(&integrityCheckItem{url: url, integrity: testSRI}, netutil.IntegrityStatus{
+		URLStatus: netutil.URLStatus{Code: 0, Message: "connection reset by peer"},
+		Expected:  testSRI,
+	})
+
+	got := ext.resourceStatusByUrl[url]
+	if got.Code != 200 || got.Message != "OK" {
+		t.Errorf("cached status = %d %q; want 200 \"OK\"", got.Code, got.Message)
+	}
+	if !got.DownloadFailed || got.Integrity != testSRI {
+		t.Errorf("integrity fields not recorded: download_failed=%v integrity=%q", got.DownloadFailed, got.Integrity)
+	}
+}
+
+// TestHandleSourceIntegrityStatus_NoCachedStatus verifies that verification
+// results do not create resource status entries on their own.
+func TestHandleSourceIntegrityStatus_NoCachedStatus(t *testing.T) {
+	ext := newTestExtension()
+	url := "https://example.com/bar-1.0.tar.gz"
+
+	ext.handleSourceIntegrityStatus(&integrityCheckItem{url: url, integrity: testSRI}, netutil.IntegrityStatus{
+		URLStatus: netutil.URLStatus{Code: 404, Message: "Not Found"},
+		Expected:  testSRI,
+	})
+
+	if _, found := ext.resourceStatusByUrl[url]; found {
+		t.Errorf("resource status cached for %s; want none", url)
+	}
+}
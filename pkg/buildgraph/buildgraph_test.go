@@ -0,0 +1,253 @@
+package buildgraph
+
+import (
+	"strings"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+)
+
+func str(s string) *slpb.Value {
+	return &slpb.Value{Value: &slpb.Value_String_{String_: s}}
+}
+
+func list(values ...string) *slpb.Value {
+	l := &slpb.ValueList{}
+	for _, v := range values {
+		l.Value = append(l.Value, str(v))
+	}
+	return &slpb.Value{Value: &slpb.Value_List{List: l}}
+}
+
+func target(kind, name string, attrs ...*slpb.TargetAttribute) *slpb.Target {
+	return &slpb.Target{Kind: kind, Name: name, Attribute: attrs}
+}
+
+func attr(name string, value *slpb.Value) *slpb.TargetAttribute {
+	return &slpb.TargetAttribute{Name: name, Value: value}
+}
+
+func testGraph() *Graph {
+	return New([]*sympb.ModuleVersionPackages{
+		{
+			ModuleName: "app",
+			Version:    "1.0.0",
+			Package: []*slpb.Package{
+				{
+					Name: "@@app+//cmd",
+					Target: []*slpb.Target{
+						target("go_binary", "cmd", attr("embed", list(":cmd_lib"))),
+						target("go_library", "cmd_lib",
+							attr("srcs", list("main.go")),
+							attr("deps", list("//lib", "@rules_go//go/tools:util")),
+							attr("visibility", list("//visibility:private"))),
+					},
+				},
+				{
+					Name: "@@app+//lib",
+					Target: []*slpb.Target{
+						target("go_library", "lib", attr("srcs", list("lib.go"))),
+						target("go_test", "lib_test",
+							attr("embed", list(":lib")),
+							attr("size", str("small"))),
+					},
+				},
+				{
+					// Failed extraction: no name, skipped.
+					Filename: "/broken/BUILD.bazel",
+					Error:    []string{"boom"},
+				},
+			},
+		},
+		{
+			ModuleName: "rules_go",
+			Version:    "0.50.1",
+			Package: []*slpb.Package{
+				{
+					Name: "@@rules_go+//go/tools",
+					Target: []*slpb.Target{
+						target("go_library", "util", attr("srcs", list("util.go"))),
+					},
+				},
+			},
+		},
+	})
+}
+
+func labels(nodes []*Node) string {
+	var out []string
+	for _, n := range nodes {
+		out = append(out, n.Label)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestGraphEdges(t *testing.T) {
+	g := testGraph()
+	lib := g.Node("@app//cmd:cmd_lib")
+	if lib == nil {
+		t.Fatal("cmd_lib not found")
+	}
+	var deps []string
+	for _, e := range g.Deps(lib) {
+		deps = append(deps, e.Attr+"="+e.To.Label)
+	}
+	want := "srcs=@app//cmd:main.go deps=@app//lib:lib deps=@rules_go//go/tools:util"
+	if got := strings.Join(deps, " "); got != want {
+		t.Errorf("deps:\nwant %s\ngot  %s", want, got)
+	}
+	if n := g.Node("@app//cmd:main.go"); n == nil || n.Kind != SourceFileKind {
+		t.Errorf("expected source file node, got %+v", n)
+	}
+	if n := g.Node("@rules_go//go/tools:util"); n == nil || n.Version != "0.50.1" || n.Target == nil {
+		t.Errorf("expected cross-module target, got %+v", n)
+	}
+	if n := g.Node("@app//visibility:private"); n != nil {
+		t.Errorf("visibility should not be followed, got %+v", n)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	g := testGraph()
+	for _, tc := range []struct {
+		query, module, want string
+	}{
+		{"//lib", "app", "@app//lib:lib"},
+		{"//lib:all", "app", "@app//lib:lib @app//lib:lib_test"},
+		{"//lib:*", "app", "@app//lib:lib @app//lib:lib.go @app//lib:lib_test"},
+		{"@rules_go//...", "", "@rules_go//go/tools:util"},
+		{"@rules_go//...:*", "", "@rules_go//go/tools:util @rules_go//go/tools:util.go"},
+		{"deps(//cmd)", "app",
+			"@app//cmd:cmd @app//cmd:cmd_lib @app//cmd:main.go @app//lib:lib @app//lib:lib.go @rules_go//go/tools:util @rules_go//go/tools:util.go"},
+		{"deps(//cmd, 1)", "app", "@app//cmd:cmd @app//cmd:cmd_lib"},
+		{"kind(go_library, deps(//cmd))", "app", "@app//cmd:cmd_lib @app//lib:lib @rules_go//go/tools:util"},
+		{"rdeps(//..., @rules_go//go/tools:util)", "", "@app//cmd:cmd @app//cmd:cmd_lib @rules_go//go/tools:util"},
+		{"rdeps(@app//..., //lib, 1)", "app", "@app//cmd:cmd_lib @app//lib:lib @app//lib:lib_test"},
+		{"attr(size, small, //...)", "app", "@app//lib:lib_test"},
+		{"attr(deps, 'rules_go', //...)", "app", "@app//cmd:cmd_lib"},
+		{"somepath(//cmd, @rules_go//go/tools:util)", "app", "@app//cmd:cmd @app//cmd:cmd_lib @rules_go//go/tools:util"},
+		{"kind(go_, //...) - kind(test, //...)", "app", "@app//cmd:cmd @app//cmd:cmd_lib @app//lib:lib"},
+		{"//lib:all ^ (kind(test, //...) union //cmd)", "app", "@app//lib:lib_test"},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			nodes, err := g.Query(tc.query, tc.module)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := labels(nodes); got != tc.want {
+				t.Errorf("\nwant %s\ngot  %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	g := testGraph()
+	for _, query := range []string{
+		"//nope:nope",
+		"deps(//lib",
+		"deps(//lib, x)",
+		"kind(go_library)",
+		"lib",
+		"//lib extra",
+	} {
+		if _, err := g.Query(query, "app"); err == nil {
+			t.Errorf("%s: expected error", query)
+		}
+	}
+}
+
+func utilModule(version string) *sympb.ModuleVersionPackages {
+	return &sympb.ModuleVersionPackages{
+		ModuleName: "util",
+		Version:    version,
+		Package: []*slpb.Package{
+			{Name: "@@util+//", Target: []*slpb.Target{target("cc_library", "util")}},
+		},
+	}
+}
+
+func TestGraphVersions(t *testing.T) {
+	app := func(version, dep string) *sympb.ModuleVersionPackages {
+		return &sympb.ModuleVersionPackages{
+			ModuleName: "app",
+			Version:    version,
+			Package: []*slpb.Package{
+				{Name: "@@app+//", Target: []*slpb.Target{target("cc_binary", "app", attr("deps", list(dep)))}},
+			},
+		}
+	}
+	registry := map[string]*bzpb.ModuleVersion{
+		"app@1.0": {Deps: []*bzpb.ModuleDependency{{Name: "util", Version: "1.0", RepoName: "com_example_util"}}},
+		// MVS raised the declared util 1.0 to 2.0; the selected version wins.
+		"app@2.0": {Deps: []*bzpb.ModuleDependency{{Name: "util", Version: "1.0", SelectedVersion: "2.0"}}},
+	}
+	g := NewWithOptions([]*sympb.ModuleVersionPackages{
+		app("2.0", "@util"),
+		app("1.0", "@com_example_util//:util"),
+		utilModule("2.0"),
+		utilModule("1.0"),
+	}, Options{
+		ModuleVersion: func(module, version string) *bzpb.ModuleVersion {
+			return registry[module+"@"+version]
+		},
+	})
+
+	for _, tc := range []struct{ from, want string }{
+		{"@app@1.0//:app", "@util@1.0//:util"},
+		{"@app@2.0//:app", "@util@2.0//:util"},
+	} {
+		from := g.Node(tc.from)
+		if from == nil {
+			t.Fatalf("%s not found", tc.from)
+		}
+		deps := g.Deps(from)
+		if len(deps) != 1 || deps[0].To.ID != tc.want || deps[0].To.Target == nil {
+			t.Errorf("%s deps = %v; want %s", tc.from, deps, tc.want)
+		}
+	}
+	if n := g.Node("@util//:util"); n != nil {
+		t.Errorf("Node(@util//:util) = %s; want nil for a label in two versions", n.ID)
+	}
+	if got := g.DisplayLabel(g.Node("@util@1.0//:util")); got != "@util@1.0//:util" {
+		t.Errorf("DisplayLabel = %s", got)
+	}
+
+	for _, tc := range []struct{ query, want string }{
+		{"@util//...", "@util//:util @util//:util"},
+		{"@util@1.0//...", "@util//:util"},
+		{"rdeps(//..., @util@2.0//:util)", "@app//:app @util//:util"},
+	} {
+		nodes, err := g.Query(tc.query, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := labels(nodes); got != tc.want {
+			t.Errorf("%s:\nwant %s\ngot  %s", tc.query, tc.want, got)
+		}
+	}
+	nodes, _ := g.Query("rdeps(//..., @util@2.0//:util)", "")
+	if len(nodes) != 2 || nodes[0].Version != "2.0" {
+		t.Errorf("rdeps of util 2.0 = %v; want app 2.0", nodes)
+	}
+}
+
+func TestGraphUnknownRepoUsesFirstVersion(t *testing.T) {
+	g := New([]*sympb.ModuleVersionPackages{
+		{
+			ModuleName: "app",
+			Version:    "1.0",
+			Package: []*slpb.Package{
+				{Name: "@@app+//", Target: []*slpb.Target{target("cc_binary", "app", attr("deps", list("@@util+//:util")))}},
+			},
+		},
+		utilModule("2.0"),
+		utilModule("1.0"),
+	})
+	deps := g.Deps(g.Node("@app//:app"))
+	if len(deps) != 1 || deps[0].To.ID != "@util@2.0//:util" {
+		t.Errorf("deps = %v; want @util@2.0//:util", deps)
+	}
+}
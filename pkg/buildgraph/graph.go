@@ -0,0 +1,394 @@
+// Package buildgraph builds a target dependency graph from extracted BUILD
+// files (ModuleVersionPackages) and evaluates a small subset of the Bazel
+// query language over it, without running Bazel.
+package buildgraph
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/label"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+)
+
+// SourceFileKind is the kind of nodes that are referenced by a label-valued
+// attribute but not declared as a target in the extracted packages.
+const SourceFileKind = "source file"
+
+// DefaultLabelAttributes are the target attributes whose string values are
+// interpreted as labels when building edges.
+var DefaultLabelAttributes = []string{
+	"actual",
+	"data",
+	"deps",
+	"embed",
+	"exports",
+	"hdrs",
+	"implementation_deps",
+	"plugins",
+	"runtime_deps",
+	"srcs",
+	"tools",
+}
+
+// Node is a target, or a file referenced by a target, in the graph.
+type Node struct {
+	// ID identifies the node within the graph, "@module@version//pkg:name".
+	ID string
+	// Label is the normalized label, "@module//pkg:name". Nodes of different
+	// versions of a module share a label.
+	Label string
+	// Module is the module (or external repository) that owns the node.
+	Module string
+	// Version is the module version, empty for nodes outside the graph.
+	Version string
+	// Package is the package path within the module.
+	Package string
+	// Name is the target name.
+	Name string
+	// Kind is the rule or macro kind, or SourceFileKind.
+	Kind string
+	// Target is the extracted target, nil for source files.
+	Target *slpb.Target
+}
+
+// Edge is a dependency of From on To through attribute Attr.
+type Edge struct {
+	From, To *Node
+	Attr     string
+}
+
+// Graph is a target dependency graph over one or more module versions.
+// Several versions of a module may be in the graph at once.
+type Graph struct {
+	nodes    map[string]*Node
+	byLabel  map[string][]*Node
+	versions map[string][]string
+	out      map[*Node][]*Edge
+	in       map[*Node][]*Edge
+}
+
+// Options configure how a graph is built.
+type Options struct {
+	// LabelAttributes are the attributes followed when building edges
+	// (default DefaultLabelAttributes).
+	LabelAttributes []string
+	// ModuleVersion returns the registry metadata of a module version, or
+	// nil if unknown. Its bazel_deps map the apparent repository names used
+	// in labels (the dep's repo_name, or else its module name) to the
+	// module and version they refer to.
+	ModuleVersion func(module, version string) *bzpb.ModuleVersion
+}
+
+// New builds a graph from the given module versions, following the
+// DefaultLabelAttributes. Labels in one module that name the apparent or
+// canonical repository of another module in the graph resolve to that
+// module's targets.
+func New(moduleVersions []*sympb.ModuleVersionPackages) *Graph {
+	return NewWithOptions(moduleVersions, Options{})
+}
+
+// NewWithAttributes is like New but follows the given label attributes.
+func NewWithAttributes(moduleVersions []*sympb.ModuleVersionPackages, attrs []string) *Graph {
+	return NewWithOptions(moduleVersions, Options{LabelAttributes: attrs})
+}
+
+// NewWithOptions builds a graph from the given module versions. A label in
+// module version M@V that names another module resolves to the version M@V
+// depends on, if it is in the graph, and otherwise to the first listed
+// version of that module.
+func NewWithOptions(moduleVersions []*sympb.ModuleVersionPackages, opts Options) *Graph {
+	g := &Graph{
+		nodes:    make(map[string]*Node),
+		byLabel:  make(map[string][]*Node),
+		versions: make(map[string][]string),
+		out:      make(map[*Node][]*Edge),
+		in:       make(map[*Node][]*Edge),
+	}
+	attrs := opts.LabelAttributes
+	if attrs == nil {
+		attrs = DefaultLabelAttributes
+	}
+	labelAttrs := make(map[string]bool)
+	for _, name := range attrs {
+		labelAttrs[name] = true
+	}
+	for _, mv := range moduleVersions {
+		if !slices.Contains(g.versions[mv.ModuleName], mv.Version) {
+			g.versions[mv.ModuleName] = append(g.versions[mv.ModuleName], mv.Version)
+		}
+	}
+
+	var targets []*Node
+	for _, mv := range moduleVersions {
+		for _, pkg := range mv.Package {
+			pkgPath, ok := packagePath(pkg.Name)
+			if !ok {
+				continue
+			}
+			for _, target := range pkg.Target {
+				if target.Name == "" {
+					continue
+				}
+				node := g.node(mv.ModuleName, mv.Version, pkgPath, target.Name)
+				node.Kind = target.Kind
+				node.Target = target
+				targets = append(targets, node)
+			}
+		}
+	}
+
+	repos := make(map[string]map[string]repoRef)
+	for _, from := range targets {
+		key := from.Module + "@" + from.Version
+		if _, ok := repos[key]; !ok {
+			repos[key] = g.repoMapping(from.Module, from.Version, opts.ModuleVersion)
+		}
+		for _, attr := range from.Target.Attribute {
+			if !labelAttrs[attr.Name] {
+				continue
+			}
+			for _, s := range stringValues(attr.Value) {
+				ref, pkg, name, ok := resolveLabel(repos[key], from, s)
+				if !ok {
+					continue
+				}
+				if ref.version == "" {
+					ref.version = g.defaultVersion(ref.module)
+				}
+				to := g.node(ref.module, ref.version, pkg, name)
+				g.addEdge(&Edge{From: from, To: to, Attr: attr.Name})
+			}
+		}
+	}
+
+	return g
+}
+
+// repoRef is the module version an apparent repository name refers to. An
+// empty version means the module's default version in the graph.
+type repoRef struct {
+	module, version string
+}
+
+// repoMapping returns the apparent repository names visible to a module
+// version: its own repo_name and the repo_name (or module name) of each of
+// its bazel_deps.
+func (g *Graph) repoMapping(module, version string, lookup func(string, string) *bzpb.ModuleVersion) map[string]repoRef {
+	repos := map[string]repoRef{module: {module, version}}
+	if lookup == nil {
+		return repos
+	}
+	mv := lookup(module, version)
+	if mv == nil {
+		return repos
+	}
+	if mv.RepoName != "" {
+		repos[mv.RepoName] = repoRef{module, version}
+	}
+	for _, dep := range mv.Deps {
+		ref := repoRef{module: dep.Name}
+		// The version MVS selected is what the build resolves; the
+		// declared minimum is the fallback when it is not in the graph.
+		for _, v := range []string{dep.SelectedVersion, dep.Version} {
+			if v != "" && slices.Contains(g.versions[dep.Name], v) {
+				ref.version = v
+				break
+			}
+		}
+		repoName := dep.RepoName
+		if repoName == "" {
+			repoName = dep.Name
+		}
+		repos[repoName] = ref
+	}
+	return repos
+}
+
+// defaultVersion returns the first listed version of module, or "" if the
+// module is not in the graph.
+func (g *Graph) defaultVersion(module string) string {
+	if versions := g.versions[module]; len(versions) > 0 {
+		return versions[0]
+	}
+	return ""
+}
+
+// node returns the node for the given label, creating a source file node
+// if the label does not name a declared target.
+func (g *Graph) node(module, version, pkg, name string) *Node {
+	id := formatID(module, version, pkg, name)
+	if n, ok := g.nodes[id]; ok {
+		return n
+	}
+	n := &Node{
+		ID:      id,
+		Label:   formatLabel(module, pkg, name),
+		Module:  module,
+		Version: version,
+		Package: pkg,
+		Name:    name,
+		Kind:    SourceFileKind,
+	}
+	g.nodes[id] = n
+	g.byLabel[n.Label] = append(g.byLabel[n.Label], n)
+	return n
+}
+
+func (g *Graph) addEdge(e *Edge) {
+	for _, existing := range g.out[e.From] {
+		if existing.To == e.To && existing.Attr == e.Attr {
+			return
+		}
+	}
+	g.out[e.From] = append(g.out[e.From], e)
+	g.in[e.To] = append(g.in[e.To], e)
+}
+
+// Node returns the node with the given ID, or with the given normalized
+// label if only one version of its module has it. It returns nil otherwise.
+func (g *Graph) Node(lbl string) *Node {
+	if n, ok := g.nodes[lbl]; ok {
+		return n
+	}
+	if nodes := g.byLabel[lbl]; len(nodes) == 1 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// DisplayLabel returns the label of n, or its ID when several versions of
+// its module are in the graph.
+func (g *Graph) DisplayLabel(n *Node) string {
+	if len(g.versions[n.Module]) > 1 {
+		return n.ID
+	}
+	return n.Label
+}
+
+// Nodes returns all nodes sorted by label.
+func (g *Graph) Nodes() []*Node {
+	nodes := make([]*Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+// Deps returns the outgoing edges of n.
+func (g *Graph) Deps(n *Node) []*Edge {
+	return g.out[n]
+}
+
+// RDeps returns the incoming edges of n.
+func (g *Graph) RDeps(n *Node) []*Edge {
+	return g.in[n]
+}
+
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Label != nodes[j].Label {
+			return nodes[i].Label < nodes[j].Label
+		}
+		return nodes[i].Version < nodes[j].Version
+	})
+}
+
+func formatLabel(module, pkg, name string) string {
+	return "@" + module + "//" + pkg + ":" + name
+}
+
+func formatID(module, version, pkg, name string) string {
+	return "@" + module + "@" + version + "//" + pkg + ":" + name
+}
+
+// packagePath returns the package path of a package name such as
+// "@@repo//foo/bar" or "//foo/bar".
+func packagePath(name string) (string, bool) {
+	i := strings.Index(name, "//")
+	if i < 0 {
+		return "", false
+	}
+	return strings.TrimSuffix(name[i+2:], "/"), true
+}
+
+// repoModule maps a repository name to a module name by stripping the
+// canonical name suffix ("rules_go+", "rules_go~0.50.1").
+func repoModule(repo string) string {
+	if i := strings.IndexAny(repo, "+~"); i >= 0 {
+		return repo[:i]
+	}
+	return repo
+}
+
+// resolveLabel resolves s, as written in the package of from, to the module
+// version, package and name it refers to. Apparent repository names are
+// looked up in repos; canonical names ("rules_go+") and unknown names fall
+// back to the module of the same name.
+func resolveLabel(repos map[string]repoRef, from *Node, s string) (repoRef, string, string, bool) {
+	if s == "" || strings.Contains(s, "$(") {
+		return repoRef{}, "", "", false
+	}
+	l, err := label.Parse(s)
+	if err != nil {
+		return repoRef{}, "", "", false
+	}
+	self := repoRef{from.Module, from.Version}
+	if l.Relative {
+		return self, from.Package, l.Name, true
+	}
+	if l.Repo == "" {
+		return self, l.Pkg, l.Name, true
+	}
+	if ref, ok := repos[l.Repo]; ok && !l.Canonical {
+		return ref, l.Pkg, l.Name, true
+	}
+	module := repoModule(l.Repo)
+	if module == from.Module {
+		return self, l.Pkg, l.Name, true
+	}
+	for _, name := range slices.Sorted(maps.Keys(repos)) {
+		if ref := repos[name]; ref.module == module {
+			return ref, l.Pkg, l.Name, true
+		}
+	}
+	return repoRef{module: module}, l.Pkg, l.Name, true
+}
+
+// stringValues returns the strings in v, descending into lists and the
+// branches of select() calls.
+func stringValues(v *slpb.Value) []string {
+	if v == nil {
+		return nil
+	}
+	switch x := v.Value.(type) {
+	case *slpb.Value_String_:
+		return []string{x.String_}
+	case *slpb.Value_List:
+		var values []string
+		for _, elem := range x.List.Value {
+			values = append(values, stringValues(elem)...)
+		}
+		return values
+	case *slpb.Value_Call:
+		if x.Call.FunctionName != "select" {
+			return nil
+		}
+		var values []string
+		for _, arg := range x.Call.Positional {
+			if d, ok := arg.Value.(*slpb.Value_Dict); ok {
+				for _, entry := range d.Dict.Entry {
+					values = append(values, stringValues(entry.Value)...)
+				}
+			}
+		}
+		return values
+	}
+	return nil
+}
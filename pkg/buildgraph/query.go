@@ -0,0 +1,548 @@
+package buildgraph
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+)
+
+// Query evaluates a query expression and returns the matching nodes sorted
+// by label. The supported language is a subset of Bazel query:
+//
+//	//pkg:name, //pkg:all, //pkg:*, //pkg/..., @module//...   target patterns
+//	@module@version//...                                       target patterns in one version
+//	deps(x [, depth])                                          transitive dependencies
+//	rdeps(universe, x [, depth])                               reverse dependencies within universe
+//	kind(pattern, x)                                           targets whose kind matches the regexp
+//	attr(name, pattern, x)                                     targets whose attribute value matches the regexp
+//	somepath(from, to)                                         the nodes of one path between the sets
+//	x + y, x - y, x ^ y (union, except, intersect)             set operations, left-associative
+//
+// Target patterns without a repository refer to defaultModule, or to every
+// module in the graph when defaultModule is empty. A pattern without a
+// version matches every version of its module in the graph.
+func (g *Graph) Query(query, defaultModule string) ([]*Node, error) {
+	p := &parser{tokens: tokenize(query)}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	result, err := e.eval(&evaluator{graph: g, defaultModule: defaultModule})
+	if err != nil {
+		return nil, err
+	}
+	return result.sorted(), nil
+}
+
+type nodeSet map[*Node]bool
+
+func (s nodeSet) sorted() []*Node {
+	nodes := make([]*Node, 0, len(s))
+	for n := range s {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenize splits a query into words, parentheses and commas. Words may be
+// single- or double-quoted.
+func tokenize(s string) []token {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				end = len(s) - i - 1
+			}
+			tokens = append(tokens, token{tokenWord, s[i+1 : i+1+end], i})
+			i += end + 2
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n(),", rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, s[start:i], start})
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(s)})
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if tok := p.next(); tok.kind != kind {
+		return fmt.Errorf("expected %q at offset %d, got %q", text, tok.pos, tok.text)
+	}
+	return nil
+}
+
+var setOperators = map[string]string{
+	"+":         "+",
+	"union":     "+",
+	"-":         "-",
+	"except":    "-",
+	"^":         "^",
+	"intersect": "^",
+}
+
+// functionArity is the number of required and optional arguments of each
+// function.
+var functionArity = map[string][2]int{
+	"deps":     {1, 1},
+	"rdeps":    {2, 1},
+	"kind":     {2, 0},
+	"attr":     {3, 0},
+	"somepath": {2, 0},
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		op, ok := setOperators[tok.text]
+		if tok.kind != tokenWord || !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(tokenRParen, ")")
+	case tokenWord:
+		arity, isFunc := functionArity[tok.text]
+		if !isFunc || p.peek().kind != tokenLParen {
+			return &patternExpr{pattern: tok.text}, nil
+		}
+		p.next()
+		call := &callExpr{name: tok.text}
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		if n := len(call.args); n < arity[0] || n > arity[0]+arity[1] {
+			return nil, fmt.Errorf("%s: wrong number of arguments (%d) at offset %d", call.name, n, tok.pos)
+		}
+		return call, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+}
+
+type evaluator struct {
+	graph         *Graph
+	defaultModule string
+}
+
+type expr interface {
+	eval(*evaluator) (nodeSet, error)
+}
+
+type patternExpr struct {
+	pattern string
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expr
+}
+
+type callExpr struct {
+	name string
+	args []expr
+}
+
+func (e *binaryExpr) eval(ev *evaluator) (nodeSet, error) {
+	left, err := e.left.eval(ev)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.eval(ev)
+	if err != nil {
+		return nil, err
+	}
+	result := make(nodeSet)
+	switch e.op {
+	case "+":
+		for n := range left {
+			result[n] = true
+		}
+		for n := range right {
+			result[n] = true
+		}
+	case "-":
+		for n := range left {
+			if !right[n] {
+				result[n] = true
+			}
+		}
+	case "^":
+		for n := range left {
+			if right[n] {
+				result[n] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+func (e *patternExpr) eval(ev *evaluator) (nodeSet, error) {
+	module, version, rest := ev.defaultModule, "", e.pattern
+	if strings.HasPrefix(rest, "@") {
+		i := strings.Index(rest, "//")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid target pattern %q", e.pattern)
+		}
+		repo := strings.TrimLeft(rest[:i], "@")
+		repo, version, _ = strings.Cut(repo, "@")
+		module = repoModule(repo)
+		rest = rest[i:]
+	}
+	matches := func(n *Node) bool {
+		return (module == "" || n.Module == module) && (version == "" || n.Version == version)
+	}
+	if !strings.HasPrefix(rest, "//") && !strings.HasPrefix(rest, ":") {
+		return nil, fmt.Errorf("invalid target pattern %q", e.pattern)
+	}
+	rest = strings.TrimPrefix(rest, "//")
+
+	pkg, name, hasName := strings.Cut(rest, ":")
+	recursive := pkg == "..." || strings.HasSuffix(pkg, "/...")
+	if recursive {
+		pkg = strings.TrimSuffix(strings.TrimSuffix(pkg, "..."), "/")
+		if !hasName {
+			name = "all"
+		}
+	} else if !hasName {
+		name = pkg[strings.LastIndex(pkg, "/")+1:]
+	}
+
+	result := make(nodeSet)
+	if !recursive && name != "all" && name != "*" && name != "all-targets" {
+		for _, n := range ev.graph.nodes {
+			if matches(n) && n.Package == pkg && n.Name == name {
+				result[n] = true
+			}
+		}
+		if len(result) == 0 {
+			return nil, fmt.Errorf("no such target %q", e.pattern)
+		}
+		return result, nil
+	}
+
+	for _, n := range ev.graph.nodes {
+		if !matches(n) {
+			continue
+		}
+		if recursive {
+			if pkg != "" && n.Package != pkg && !strings.HasPrefix(n.Package, pkg+"/") {
+				continue
+			}
+		} else if n.Package != pkg {
+			continue
+		}
+		// ":all" matches rule targets only; ":*" also matches files.
+		if name == "all" && n.Target == nil {
+			continue
+		}
+		result[n] = true
+	}
+	return result, nil
+}
+
+func (e *callExpr) eval(ev *evaluator) (nodeSet, error) {
+	switch e.name {
+	case "deps":
+		x, err := e.args[0].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		depth, err := e.depthArg(1)
+		if err != nil {
+			return nil, err
+		}
+		return ev.graph.reachable(x, depth, nil, ev.graph.out, func(e *Edge) *Node { return e.To }), nil
+	case "rdeps":
+		universe, err := e.args[0].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		x, err := e.args[1].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		depth, err := e.depthArg(2)
+		if err != nil {
+			return nil, err
+		}
+		return ev.graph.reachable(x, depth, universe, ev.graph.in, func(e *Edge) *Node { return e.From }), nil
+	case "kind":
+		re, err := e.regexpArg(0)
+		if err != nil {
+			return nil, err
+		}
+		x, err := e.args[1].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		result := make(nodeSet)
+		for n := range x {
+			if re.MatchString(n.Kind) {
+				result[n] = true
+			}
+		}
+		return result, nil
+	case "attr":
+		name, err := e.wordArg(0)
+		if err != nil {
+			return nil, err
+		}
+		re, err := e.regexpArg(1)
+		if err != nil {
+			return nil, err
+		}
+		x, err := e.args[2].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		result := make(nodeSet)
+		for n := range x {
+			if n.Target == nil {
+				continue
+			}
+			for _, attr := range n.Target.Attribute {
+				if attr.Name == name && re.MatchString(ValueString(attr.Value)) {
+					result[n] = true
+					break
+				}
+			}
+		}
+		return result, nil
+	case "somepath":
+		from, err := e.args[0].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		to, err := e.args[1].eval(ev)
+		if err != nil {
+			return nil, err
+		}
+		result := make(nodeSet)
+		for _, n := range ev.graph.SomePath(from.sorted(), to) {
+			result[n] = true
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unknown function %q", e.name)
+}
+
+func (e *callExpr) wordArg(i int) (string, error) {
+	p, ok := e.args[i].(*patternExpr)
+	if !ok {
+		return "", fmt.Errorf("%s: argument %d must be a word", e.name, i+1)
+	}
+	return p.pattern, nil
+}
+
+func (e *callExpr) regexpArg(i int) (*regexp.Regexp, error) {
+	word, err := e.wordArg(i)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(word)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", e.name, err)
+	}
+	return re, nil
+}
+
+// depthArg returns the optional depth argument at i, or -1 (unbounded).
+func (e *callExpr) depthArg(i int) (int, error) {
+	if i >= len(e.args) {
+		return -1, nil
+	}
+	word, err := e.wordArg(i)
+	if err != nil {
+		return 0, err
+	}
+	depth, err := strconv.Atoi(word)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("%s: invalid depth %q", e.name, word)
+	}
+	return depth, nil
+}
+
+// reachable returns the nodes reachable from start within depth steps
+// (unbounded when negative) along edges, including start itself. When
+// universe is non-nil, traversal is restricted to its nodes.
+func (g *Graph) reachable(start nodeSet, depth int, universe nodeSet, edges map[*Node][]*Edge, follow func(*Edge) *Node) nodeSet {
+	seen := make(nodeSet)
+	var frontier []*Node
+	for n := range start {
+		if universe == nil || universe[n] {
+			seen[n] = true
+			frontier = append(frontier, n)
+		}
+	}
+	for level := 0; len(frontier) > 0 && (depth < 0 || level < depth); level++ {
+		var next []*Node
+		for _, n := range frontier {
+			for _, e := range edges[n] {
+				m := follow(e)
+				if seen[m] || (universe != nil && !universe[m]) {
+					continue
+				}
+				seen[m] = true
+				next = append(next, m)
+			}
+		}
+		frontier = next
+	}
+	return seen
+}
+
+// SomePath returns the nodes of a shortest dependency path from any node in
+// from to any node in to, or nil if there is none.
+func (g *Graph) SomePath(from []*Node, to map[*Node]bool) []*Node {
+	parent := make(map[*Node]*Node)
+	seen := make(nodeSet)
+	queue := append([]*Node(nil), from...)
+	for _, n := range from {
+		seen[n] = true
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if to[n] {
+			var path []*Node
+			for ; n != nil; n = parent[n] {
+				path = append([]*Node{n}, path...)
+			}
+			return path
+		}
+		for _, e := range g.out[n] {
+			if !seen[e.To] {
+				seen[e.To] = true
+				parent[e.To] = n
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return nil
+}
+
+// ValueString formats an attribute value the way it would appear in a BUILD
+// file, for matching with attr().
+func ValueString(v *slpb.Value) string {
+	if v == nil {
+		return ""
+	}
+	switch x := v.Value.(type) {
+	case *slpb.Value_String_:
+		return x.String_
+	case *slpb.Value_Int:
+		return strconv.FormatInt(x.Int, 10)
+	case *slpb.Value_Bool:
+		if x.Bool {
+			return "True"
+		}
+		return "False"
+	case *slpb.Value_List:
+		elems := make([]string, len(x.List.Value))
+		for i, elem := range x.List.Value {
+			elems[i] = ValueString(elem)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case *slpb.Value_Call:
+		args := make([]string, 0, len(x.Call.Positional)+len(x.Call.Kwarg))
+		for _, arg := range x.Call.Positional {
+			args = append(args, ValueString(arg))
+		}
+		for _, kw := range x.Call.Kwarg {
+			args = append(args, kw.Name+" = "+ValueString(kw.Value))
+		}
+		return x.Call.FunctionName + "(" + strings.Join(args, ", ") + ")"
+	case *slpb.Value_Dict:
+		entries := make([]string, len(x.Dict.Entry))
+		for i, entry := range x.Dict.Entry {
+			entries[i] = ValueString(entry.Key) + ": " + ValueString(entry.Value)
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+	return ""
+}
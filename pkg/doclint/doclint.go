@@ -0,0 +1,241 @@
+// Package doclint analyzes the documentation quality of extracted Starlark
+// symbols: missing docstrings, undocumented attributes, parameters and
+// provider fields, `Args:` sections that disagree with the signature, and
+// broken markdown links. It also computes a per-module-version coverage
+// percentage.
+package doclint
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	"github.com/bazel-contrib/bcr-frontend/pkg/stardoc"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+)
+
+// Analyze lints every public symbol in symbols and returns the coverage
+// report. Symbols whose name begins with an underscore, load statements and
+// plain values are not considered documentable.
+func Analyze(symbols *sympb.ModuleVersionSymbols) *sympb.DocCoverage {
+	a := &analyzer{
+		cov: &sympb.DocCoverage{
+			ModuleName: symbols.ModuleName,
+			Version:    symbols.Version,
+		},
+	}
+	for _, file := range symbols.File {
+		a.file = file.Label
+		a.checkLinks("", file.Description)
+		for _, sym := range file.Symbol {
+			a.checkSymbol(sym)
+		}
+	}
+	a.cov.Percent = Percent(a.cov.Documented, a.cov.Total)
+	return a.cov
+}
+
+// Percent returns documented/total as a percentage, or 100 when total is
+// zero.
+func Percent(documented, total int32) float32 {
+	if total == 0 {
+		return 100
+	}
+	return float32(documented) * 100 / float32(total)
+}
+
+type analyzer struct {
+	cov  *sympb.DocCoverage
+	file *slpb.Label
+}
+
+func (a *analyzer) addFinding(kind sympb.DocFindingKind, symbol, item, message string) {
+	a.cov.Finding = append(a.cov.Finding, &sympb.DocFinding{
+		Kind:    kind,
+		File:    a.file,
+		Symbol:  symbol,
+		Item:    item,
+		Message: message,
+	})
+}
+
+// count records one documentable item, adding a finding if it is
+// undocumented.
+func (a *analyzer) count(documented bool, kind sympb.DocFindingKind, symbol, item, message string) {
+	a.cov.Total++
+	if documented {
+		a.cov.Documented++
+		return
+	}
+	a.addFinding(kind, symbol, item, message)
+}
+
+func isPrivate(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+func hasText(s string) bool {
+	return strings.TrimSpace(s) != ""
+}
+
+func (a *analyzer) checkSymbol(sym *sympb.Symbol) {
+	if isPrivate(sym.Name) {
+		return
+	}
+	switch sym.Type {
+	case sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT, sympb.SymbolType_SYMBOL_TYPE_VALUE:
+		return
+	}
+
+	a.count(hasText(sym.Description), sympb.DocFindingKind_DOC_FINDING_KIND_MISSING_DOCSTRING, sym.Name, "",
+		fmt.Sprintf("%s has no docstring", sym.Name))
+	a.checkLinks(sym.Name, sym.Description)
+
+	switch info := sym.Info.(type) {
+	case *sympb.Symbol_Rule:
+		a.checkAttributes(sym.Name, info.Rule.GetInfo().GetAttribute())
+	case *sympb.Symbol_Macro:
+		a.checkAttributes(sym.Name, info.Macro.GetInfo().GetAttribute())
+	case *sympb.Symbol_Aspect:
+		a.checkAttributes(sym.Name, info.Aspect.GetInfo().GetAttribute())
+	case *sympb.Symbol_RepositoryRule:
+		a.checkAttributes(sym.Name, info.RepositoryRule.GetInfo().GetAttribute())
+	case *sympb.Symbol_ModuleExtension:
+		for _, tag := range info.ModuleExtension.GetInfo().GetTagClass() {
+			name := sym.Name + "." + tag.TagName
+			a.count(hasText(tag.DocString), sympb.DocFindingKind_DOC_FINDING_KIND_MISSING_DOCSTRING, name, "",
+				fmt.Sprintf("tag class %s has no docstring", name))
+			a.checkLinks(name, tag.DocString)
+			a.checkAttributes(name, tag.Attribute)
+		}
+	case *sympb.Symbol_Provider:
+		for _, field := range info.Provider.GetInfo().GetFieldInfo() {
+			a.count(hasText(field.DocString), sympb.DocFindingKind_DOC_FINDING_KIND_UNDOCUMENTED_PROVIDER_FIELD, sym.Name, field.Name,
+				fmt.Sprintf("field %s of provider %s has no docstring", field.Name, sym.Name))
+			a.checkLinks(sym.Name, field.DocString)
+		}
+	case *sympb.Symbol_Func:
+		a.checkFunction(sym.Name, sym.Description, info.Func.GetInfo())
+	case *sympb.Symbol_RuleMacro:
+		a.checkFunction(sym.Name, sym.Description, info.RuleMacro.GetFunction().GetInfo())
+	}
+}
+
+// checkAttributes counts every attribute except the implicit `name` and
+// natively defined ones, which rule authors cannot document.
+func (a *analyzer) checkAttributes(symbol string, attrs []*sdpb.AttributeInfo) {
+	for _, attr := range attrs {
+		if attr.Name == "name" || attr.NativelyDefined || isPrivate(attr.Name) {
+			continue
+		}
+		a.count(hasText(attr.DocString), sympb.DocFindingKind_DOC_FINDING_KIND_UNDOCUMENTED_ATTRIBUTE, symbol, attr.Name,
+			fmt.Sprintf("attribute %s of %s has no docstring", attr.Name, symbol))
+		a.checkLinks(symbol, attr.DocString)
+	}
+}
+
+// checkFunction compares the signature against both inline parameter
+// docstrings and the `Args:` section of the function docstring.
+func (a *analyzer) checkFunction(symbol, docstring string, info *sdpb.StarlarkFunctionInfo) {
+	documented := ArgsSectionNames(docstring)
+	inSignature := make(map[string]bool)
+	for _, param := range info.GetParameter() {
+		inSignature[param.Name] = true
+		if isPrivate(param.Name) {
+			continue
+		}
+		ok := hasText(param.DocString) || documented[param.Name]
+		a.count(ok, sympb.DocFindingKind_DOC_FINDING_KIND_UNDOCUMENTED_PARAM, symbol, param.Name,
+			fmt.Sprintf("parameter %s of %s is not documented", param.Name, symbol))
+		a.checkLinks(symbol, param.DocString)
+	}
+	if info == nil {
+		return
+	}
+	for _, name := range argsSectionOrder(docstring) {
+		if !inSignature[name] {
+			a.addFinding(sympb.DocFindingKind_DOC_FINDING_KIND_UNKNOWN_DOCUMENTED_PARAM, symbol, name,
+				fmt.Sprintf("Args: documents %s, which is not a parameter of %s", name, symbol))
+		}
+	}
+}
+
+// ArgsSectionNames returns the parameter names documented in the Google
+// style `Args:` section(s) of docstring. Leading `*` and `**` are stripped.
+func ArgsSectionNames(docstring string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range argsSectionOrder(docstring) {
+		names[name] = true
+	}
+	return names
+}
+
+func argsSectionOrder(docstring string) []string {
+	var names []string
+	for _, arg := range stardoc.ParseDocstring(docstring).Args() {
+		names = append(names, arg.Name)
+	}
+	return names
+}
+
+var (
+	fencedCodePattern  = regexp.MustCompile("(?s)```.*?```")
+	inlineCodePattern  = regexp.MustCompile("`[^`\n]*`")
+	inlineLinkPattern  = regexp.MustCompile(`\[([^\]\n]*)\]\(([^)\n]*)\)`)
+	refLinkPattern     = regexp.MustCompile(`\[([^\]\n]+)\]\[([^\]\n]*)\]`)
+	linkDefPattern     = regexp.MustCompile(`(?m)^\s{0,3}\[([^\]\n]+)\]:\s*\S+`)
+	linkTitleSeparator = regexp.MustCompile(`\s+("[^"]*"|'[^']*')$`)
+)
+
+// BrokenLinks returns the targets of markdown links in text that cannot
+// resolve: empty or malformed inline targets, and reference-style links
+// without a matching definition. Code spans and fenced blocks are ignored.
+func BrokenLinks(text string) []string {
+	if !strings.Contains(text, "](") && !strings.Contains(text, "][") {
+		return nil
+	}
+	text = fencedCodePattern.ReplaceAllString(text, "")
+	text = inlineCodePattern.ReplaceAllString(text, "")
+
+	var broken []string
+	for _, m := range inlineLinkPattern.FindAllStringSubmatch(text, -1) {
+		target := strings.TrimSpace(m[2])
+		target = linkTitleSeparator.ReplaceAllString(target, "")
+		target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+		if target == "" || strings.ContainsAny(target, " \t") {
+			broken = append(broken, m[2])
+			continue
+		}
+		if _, err := url.Parse(target); err != nil {
+			broken = append(broken, m[2])
+		}
+	}
+
+	defined := make(map[string]bool)
+	for _, m := range linkDefPattern.FindAllStringSubmatch(text, -1) {
+		defined[strings.ToLower(m[1])] = true
+	}
+	for _, m := range refLinkPattern.FindAllStringSubmatch(text, -1) {
+		ref := m[2]
+		if ref == "" {
+			ref = m[1]
+		}
+		if !defined[strings.ToLower(ref)] {
+			broken = append(broken, "["+ref+"]")
+		}
+	}
+	return broken
+}
+
+func (a *analyzer) checkLinks(symbol, text string) {
+	for _, target := range BrokenLinks(text) {
+		msg := fmt.Sprintf("broken markdown link %q", target)
+		if symbol != "" {
+			msg += " in " + symbol
+		}
+		a.addFinding(sympb.DocFindingKind_DOC_FINDING_KIND_BROKEN_LINK, symbol, target, msg)
+	}
+}
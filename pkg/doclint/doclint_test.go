@@ -0,0 +1,118 @@
+package doclint
+
+import (
+	"slices"
+	"testing"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+)
+
+func TestArgsSectionNames(t *testing.T) {
+	doc := `Does a thing.
+
+Args:
+    name: the target name.
+    srcs (list): source files,
+        with a continuation: that looks like an entry.
+    **kwargs: passed through.
+
+Returns:
+    nothing: really.
+`
+	got := argsSectionOrder(doc)
+	if want := []string{"name", "srcs", "kwargs"}; !slices.Equal(got, want) {
+		t.Errorf("argsSectionOrder = %v; want %v", got, want)
+	}
+}
+
+func TestBrokenLinks(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want []string
+	}{
+		{"see [docs](https://example.com) and [here](#anchor)", nil},
+		{"a [titled](https://example.com \"Title\") link", nil},
+		{"an [empty]() link", []string{""}},
+		{"a [spaced](not a url) link", []string{"not a url"}},
+		{"a [ref][missing] link", []string{"[missing]"}},
+		{"a [ref][defined] link\n\n[defined]: https://example.com", nil},
+		{"code `[x]()` is ignored", nil},
+	} {
+		if got := BrokenLinks(tc.text); !slices.Equal(got, tc.want) {
+			t.Errorf("BrokenLinks(%q) = %q; want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	symbols := &sympb.ModuleVersionSymbols{
+		ModuleName: "rules_foo",
+		Version:    "1.0.0",
+		File: []*sympb.File{{
+			Label: &slpb.Label{Pkg: "foo", Name: "defs.bzl"},
+			Symbol: []*sympb.Symbol{
+				{
+					Type:        sympb.SymbolType_SYMBOL_TYPE_RULE,
+					Name:        "foo_library",
+					Description: "Builds foo. See [the guide]().",
+					Info: &sympb.Symbol_Rule{Rule: &slpb.Rule{Info: &sdpb.RuleInfo{Attribute: []*sdpb.AttributeInfo{
+						{Name: "name"},
+						{Name: "srcs", DocString: "Sources."},
+						{Name: "deps"},
+					}}}},
+				},
+				{
+					Type:        sympb.SymbolType_SYMBOL_TYPE_FUNCTION,
+					Name:        "foo_macro",
+					Description: "A macro.\n\nArgs:\n  name: the name.\n  extra: gone.\n",
+					Info: &sympb.Symbol_Func{Func: &slpb.Function{Info: &sdpb.StarlarkFunctionInfo{Parameter: []*sdpb.FunctionParamInfo{
+						{Name: "name"},
+						{Name: "visibility"},
+					}}}},
+				},
+				{
+					Type: sympb.SymbolType_SYMBOL_TYPE_PROVIDER,
+					Name: "FooInfo",
+					Info: &sympb.Symbol_Provider{Provider: &slpb.Provider{Info: &sdpb.ProviderInfo{FieldInfo: []*sdpb.ProviderFieldInfo{
+						{Name: "files", DocString: "Files."},
+					}}}},
+				},
+				{Type: sympb.SymbolType_SYMBOL_TYPE_FUNCTION, Name: "_private"},
+				{Type: sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT, Name: "//foo:private.bzl"},
+			},
+		}},
+	}
+
+	cov := Analyze(symbols)
+
+	// Items: foo_library, srcs, deps, foo_macro, name, visibility, FooInfo, files.
+	if cov.Total != 8 || cov.Documented != 5 {
+		t.Errorf("coverage = %d/%d; want 5/8", cov.Documented, cov.Total)
+	}
+	if cov.Percent != 62.5 {
+		t.Errorf("percent = %v; want 62.5", cov.Percent)
+	}
+
+	var got []string
+	for _, f := range cov.Finding {
+		got = append(got, f.Kind.String()+":"+f.Symbol+":"+f.Item)
+	}
+	want := []string{
+		"DOC_FINDING_KIND_BROKEN_LINK:foo_library:",
+		"DOC_FINDING_KIND_UNDOCUMENTED_ATTRIBUTE:foo_library:deps",
+		"DOC_FINDING_KIND_UNDOCUMENTED_PARAM:foo_macro:visibility",
+		"DOC_FINDING_KIND_UNKNOWN_DOCUMENTED_PARAM:foo_macro:extra",
+		"DOC_FINDING_KIND_MISSING_DOCSTRING:FooInfo:",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findings =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestPercentEmpty(t *testing.T) {
+	if got := Percent(0, 0); got != 100 {
+		t.Errorf("Percent(0, 0) = %v; want 100", got)
+	}
+}
@@ -0,0 +1,136 @@
+// Package extractcache is a local, content-addressed cache of Starlark
+// extraction results. Entries are keyed by the digest of the extracted file,
+// the digests of its transitive load closure and the extractor version, so
+// identical files in different module versions share one entry.
+package extractcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Cache stores serialized protobuf messages on disk under a directory. It
+// is safe for concurrent use, including by multiple processes sharing the
+// directory. A nil *Cache is valid and never hits.
+type Cache struct {
+	dir    string
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// Open returns a cache rooted at dir, creating it if necessary.
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache dir: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Key is a cache key, the hex sha256 of its parts.
+type Key string
+
+// NewKey returns the key for the given parts. Parts are length-prefixed so
+// that distinct part lists never produce the same key.
+func NewKey(parts ...string) Key {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	return Key(hex.EncodeToString(h.Sum(nil)))
+}
+
+func (c *Cache) path(key Key) string {
+	return filepath.Join(c.dir, string(key[:2]), string(key)+".pb")
+}
+
+// Get reads the entry for key into msg and reports whether it was found.
+func (c *Cache) Get(key Key, msg proto.Message) bool {
+	if c == nil {
+		return false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		c.misses.Add(1)
+		return false
+	}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		// treat a corrupt entry as a miss; it is overwritten by the next Put
+		c.errors.Add(1)
+		c.misses.Add(1)
+		return false
+	}
+	c.hits.Add(1)
+	return true
+}
+
+// Put stores msg under key. The entry is written to a temporary file and
+// renamed into place so concurrent readers never see a partial entry.
+func (c *Cache) Put(key Key, msg proto.Message) error {
+	if c == nil {
+		return nil
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	dst := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+string(key)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Stats are the hit and miss counts of a cache.
+type Stats struct {
+	Hits, Misses, Errors int64
+}
+
+// Stats returns the counts since the cache was opened.
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Errors: c.errors.Load()}
+}
+
+// Sub returns the counts accumulated since an earlier snapshot.
+func (s Stats) Sub(before Stats) Stats {
+	return Stats{Hits: s.Hits - before.Hits, Misses: s.Misses - before.Misses, Errors: s.Errors - before.Errors}
+}
+
+func (s Stats) String() string {
+	total := s.Hits + s.Misses
+	var pct float64
+	if total > 0 {
+		pct = float64(s.Hits) / float64(total) * 100.0
+	}
+	str := fmt.Sprintf("%d/%d hits %.1f%%", s.Hits, total, pct)
+	if s.Errors > 0 {
+		str += fmt.Sprintf(" (%d corrupt)", s.Errors)
+	}
+	return str
+}
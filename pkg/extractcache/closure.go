@@ -0,0 +1,195 @@
+package extractcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// ClosureHasher computes digests of files in an extraction work directory
+// (laid out as external/REPO/PKG/NAME) together with their transitive load
+// closure. Digests are memoized; a hasher is meant to live for one batch,
+// after the work directory has been populated.
+type ClosureHasher struct {
+	root string
+
+	mu      sync.Mutex
+	closure map[string]string
+}
+
+// NewClosureHasher returns a hasher for the work directory root.
+func NewClosureHasher(root string) *ClosureHasher {
+	return &ClosureHasher{
+		root:    root,
+		closure: make(map[string]string),
+	}
+}
+
+// Hash returns the closure digest of relPath, a path relative to the work
+// directory. Loaded files that are missing contribute their label, so a
+// file appearing later changes the digest.
+func (h *ClosureHasher) Hash(relPath string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.hash(filepath.ToSlash(relPath), make(map[string]bool))
+}
+
+func (h *ClosureHasher) hash(relPath string, visiting map[string]bool) (string, error) {
+	if digest, ok := h.closure[relPath]; ok {
+		return digest, nil
+	}
+	if visiting[relPath] {
+		// a load cycle is an extraction error; the cycle itself is
+		// captured by the contents of the files on it.
+		return "cycle", nil
+	}
+	visiting[relPath] = true
+	defer delete(visiting, relPath)
+
+	data, err := os.ReadFile(filepath.Join(h.root, relPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "missing", nil
+		}
+		return "", err
+	}
+
+	var deps []string
+	for _, dep := range loadPaths(relPath, data) {
+		digest, err := h.hash(dep, visiting)
+		if err != nil {
+			return "", err
+		}
+		deps = append(deps, dep+"="+digest)
+	}
+	sort.Strings(deps)
+
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%x\n", sha256.Sum256(data))
+	for _, dep := range deps {
+		fmt.Fprintln(sum, dep)
+	}
+	digest := hex.EncodeToString(sum.Sum(nil))
+	h.closure[relPath] = digest
+	return digest, nil
+}
+
+// TreeHash returns a digest of every file under relDir, for inputs such as
+// @_builtins that are not reached through load statements.
+func (h *ClosureHasher) TreeHash(relDir string) (string, error) {
+	sum := sha256.New()
+	dir := filepath.Join(h.root, relDir)
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		digest, err := FileDigest(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(sum, "%s=%s\n", filepath.ToSlash(rel), digest)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// loadPaths returns the work directory paths of the files loaded by the
+// file at relPath.
+func loadPaths(relPath string, data []byte) []string {
+	ast, err := build.ParseBzl(relPath, data)
+	if err != nil || ast == nil {
+		return nil
+	}
+	repo, pkg := repoAndPackage(relPath)
+
+	var paths []string
+	for _, stmt := range ast.Stmt {
+		load, ok := stmt.(*build.LoadStmt)
+		if !ok {
+			continue
+		}
+		l, err := label.Parse(load.Module.Value)
+		if err != nil {
+			continue
+		}
+		l = l.Abs(repo, pkg)
+		if l.Repo == "" {
+			l.Repo = repo
+		}
+		paths = append(paths, path.Join("external", l.Repo, l.Pkg, l.Name))
+	}
+	return paths
+}
+
+// repoAndPackage splits external/REPO/PKG/NAME.
+func repoAndPackage(relPath string) (string, string) {
+	rest, ok := strings.CutPrefix(relPath, "external/")
+	if !ok {
+		return "", path.Dir(relPath)
+	}
+	repo, file, _ := strings.Cut(rest, "/")
+	pkg := path.Dir(file)
+	if pkg == "." {
+		pkg = ""
+	}
+	return repo, pkg
+}
+
+var fileDigests sync.Map // path -> fileDigest
+
+type fileDigest struct {
+	size    int64
+	modTime int64
+	digest  string
+}
+
+// FileDigest returns the hex sha256 of the file at path. Results are
+// memoized by path, size and modification time, so it is cheap to call
+// repeatedly for large inputs such as the server jar.
+func FileDigest(p string) (string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return "", err
+	}
+	if v, ok := fileDigests.Load(p); ok {
+		d := v.(fileDigest)
+		if d.size == info.Size() && d.modTime == info.ModTime().UnixNano() {
+			return d.digest, nil
+		}
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	digest := hex.EncodeToString(sum.Sum(nil))
+	fileDigests.Store(p, fileDigest{size: info.Size(), modTime: info.ModTime().UnixNano(), digest: digest})
+	return digest, nil
+}
@@ -0,0 +1,107 @@
+package extractcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := NewKey("a", "b")
+	var got slpb.Label
+	if cache.Get(key, &got) {
+		t.Fatal("unexpected hit")
+	}
+	if err := cache.Put(key, &slpb.Label{Repo: "rules_go", Name: "def.bzl"}); err != nil {
+		t.Fatal(err)
+	}
+	if !cache.Get(key, &got) || got.Repo != "rules_go" || got.Name != "def.bzl" {
+		t.Fatalf("expected hit, got %v", &got)
+	}
+	if s := cache.Stats(); s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+	if NewKey("ab", "c") == NewKey("a", "bc") {
+		t.Error("keys must be length-prefixed")
+	}
+
+	var nilCache *Cache
+	if nilCache.Get(key, &got) || nilCache.Put(key, &got) != nil {
+		t.Error("nil cache should be a no-op")
+	}
+}
+
+func TestClosureHasher(t *testing.T) {
+	files := map[string]string{
+		"external/rules_x/x/defs.bzl":         `load("//x/private:impl.bzl", "impl")` + "\nx = impl\n",
+		"external/rules_x/x/private/impl.bzl": `load("@dep//:lib.bzl", "lib")` + "\nimpl = lib\n",
+		"external/dep/lib.bzl":                "lib = 1\n",
+		"external/other/other.bzl":            "other = 1\n",
+		"external/_builtins/exports.bzl":      "exported_rules = {}\n",
+		"server.jar":                          "jar",
+	}
+	hash := func(root string) (string, string) {
+		k, err := NewKeyer(root, "ModuleInfo", filepath.Join(root, "server.jar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := k.Key("external/rules_x/x/defs.bzl", "@rules_x//x:defs.bzl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := k.hasher.Hash("external/other/other.bzl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(key), other
+	}
+
+	a := t.TempDir()
+	writeFiles(t, a, files)
+	keyA, otherA := hash(a)
+
+	// Same contents in another work directory: same key.
+	b := t.TempDir()
+	writeFiles(t, b, files)
+	if keyB, _ := hash(b); keyB != keyA {
+		t.Error("identical trees should produce identical keys")
+	}
+
+	// A change in the transitive closure changes the key, but not the
+	// digest of unrelated files.
+	writeFiles(t, b, map[string]string{"external/dep/lib.bzl": "lib = 2\n"})
+	keyB, otherB := hash(b)
+	if keyB == keyA {
+		t.Error("changing a transitive load should change the key")
+	}
+	if otherB != otherA {
+		t.Error("unrelated file digest should not change")
+	}
+
+	// So does a change in @_builtins.
+	c := t.TempDir()
+	writeFiles(t, c, files)
+	writeFiles(t, c, map[string]string{"external/_builtins/exports.bzl": "exported_rules = {'a': 1}\n"})
+	if keyC, _ := hash(c); keyC == keyA {
+		t.Error("changing builtins should change the key")
+	}
+}
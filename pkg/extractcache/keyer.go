@@ -0,0 +1,44 @@
+package extractcache
+
+// formatVersion is mixed into every key. Bump it when the way results are
+// produced changes in a way the server jar digest does not capture.
+const formatVersion = "1"
+
+// builtinsDir is the work directory path of the @_builtins tree that the
+// server evaluates implicitly alongside every file.
+const builtinsDir = "external/_builtins"
+
+// Keyer derives cache keys for the files of one populated work directory.
+type Keyer struct {
+	hasher *ClosureHasher
+	base   string
+}
+
+// NewKeyer returns a keyer for the work directory root. method names the
+// extraction (e.g. "ModuleInfo") and serverJar is hashed as the extractor
+// version.
+func NewKeyer(root, method, serverJar string) (*Keyer, error) {
+	jar, err := FileDigest(serverJar)
+	if err != nil {
+		return nil, err
+	}
+	hasher := NewClosureHasher(root)
+	builtins, err := hasher.TreeHash(builtinsDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyer{
+		hasher: hasher,
+		base:   string(NewKey(formatVersion, method, jar, builtins)),
+	}, nil
+}
+
+// Key returns the key for extracting the file at relPath (relative to the
+// work directory) as targetLabel.
+func (k *Keyer) Key(relPath, targetLabel string) (Key, error) {
+	closure, err := k.hasher.Hash(relPath)
+	if err != nil {
+		return "", err
+	}
+	return NewKey(k.base, targetLabel, closure), nil
+}
@@ -0,0 +1,175 @@
+// Package extracterror classifies failures reported by the Starlark
+// extraction server so they can be recorded as structured
+// ExtractionError records and aggregated by cause.
+package extracterror
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"google.golang.org/grpc/codes"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+)
+
+var (
+	missingLoadRes = []*regexp.Regexp{
+		regexp.MustCompile(`cannot load '([^']+)'`),
+		regexp.MustCompile(`(?i)(?:unable to load|error loading|failed to load)(?: file)? '([^']+)'`),
+		regexp.MustCompile(`(?i)(?:file|label) '([^']+)' does not exist`),
+		regexp.MustCompile(`no such package '([^']+)'`),
+	}
+	undefinedNameRe   = regexp.MustCompile(`name '([A-Za-z_][A-Za-z0-9_]*)' is not defined`)
+	nativeMemberRe    = regexp.MustCompile(`'native' (?:value|module|object) has no (?:field or method|attribute) '([^']+)'`)
+	syntaxRe          = regexp.MustCompile(`(?i)syntax error|unable to parse|parse error|invalid syntax|unexpected token`)
+	timeoutRe         = regexp.MustCompile(`(?i)deadline exceeded|timed out`)
+	locationRe        = regexp.MustCompile(`((?:@@?[\w.+~-]*)?//[\w./+~-]*:[\w./+~-]*|[\w./+~-]*(?:\.bzl|\.star|\.package|BUILD|BUILD\.bazel)):(\d+)(?::(\d+))?`)
+	locationLabelRepo = regexp.MustCompile(`^@@?([\w.+~-]*)//(.*)$`)
+)
+
+// Error is an extraction error and its classification.
+type Error struct {
+	err   error
+	Proto *sympb.ExtractionError
+}
+
+// Wrap attaches the classification x to err.
+func Wrap(err error, x *sympb.ExtractionError) *Error {
+	return &Error{err: err, Proto: x}
+}
+
+func (e *Error) Error() string { return e.err.Error() }
+
+func (e *Error) Unwrap() error { return e.err }
+
+// Classify classifies the failure to extract the file lbl. code is the gRPC
+// status code of the failed call and msg its message with sandbox paths
+// removed. knownRepo reports whether files of a repository were provided to
+// the extractor; missing loads from other repositories are SHIM_MISSING. A
+// nil knownRepo treats every repository as known.
+func Classify(lbl string, code codes.Code, msg string, knownRepo func(string) bool) *sympb.ExtractionError {
+	x := &sympb.ExtractionError{
+		Label:    lbl,
+		Message:  msg,
+		Location: location(msg),
+	}
+
+	if code == codes.DeadlineExceeded || timeoutRe.MatchString(msg) {
+		x.Kind = sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SERVER_TIMEOUT
+		return x
+	}
+
+	for _, re := range missingLoadRes {
+		m := re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		target := normalizeLabel(m[1])
+		x.Kind = sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET
+		x.Cause = target
+		if l, err := label.Parse(target); err == nil && l.Repo != "" && knownRepo != nil && !knownRepo(l.Repo) {
+			x.Kind = sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SHIM_MISSING
+			x.Cause = "@" + l.Repo
+		}
+		return x
+	}
+
+	if m := nativeMemberRe.FindStringSubmatch(msg); m != nil {
+		x.Kind = sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN
+		x.Cause = "native." + m[1]
+		return x
+	}
+	if m := undefinedNameRe.FindStringSubmatch(msg); m != nil {
+		x.Kind = sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN
+		x.Cause = m[1]
+		return x
+	}
+
+	if syntaxRe.MatchString(msg) {
+		x.Kind = sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SYNTAX_ERROR
+		return x
+	}
+
+	return x
+}
+
+// RepoExists returns a knownRepo function for Classify that reports whether
+// the work directory root has an external/REPO directory.
+func RepoExists(root string) func(string) bool {
+	return func(repo string) bool {
+		info, err := os.Stat(filepath.Join(root, "external", repo))
+		return err == nil && info.IsDir()
+	}
+}
+
+// PackageLabel returns the label of the package of a BUILD file label,
+// "@repo//pkg". The extractor records it as the name of a package that
+// failed to extract, so the package can be joined with the errors labeled
+// with its BUILD file.
+func PackageLabel(fileLabel string) string {
+	l, err := label.Parse(fileLabel)
+	if err != nil || l.Relative || !strings.Contains(fileLabel, "//") {
+		return fileLabel
+	}
+	return strings.TrimSuffix(label.New(l.Repo, l.Pkg, "").String(), ":")
+}
+
+// location returns the first FILE:LINE[:COLUMN] mentioned in msg.
+func location(msg string) *sympb.ExtractionErrorLocation {
+	m := locationRe.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	loc := &sympb.ExtractionErrorLocation{File: normalizeLabel(m[1])}
+	if n, err := strconv.Atoi(m[2]); err == nil {
+		loc.Line = int32(n)
+	}
+	if n, err := strconv.Atoi(m[3]); err == nil {
+		loc.Column = int32(n)
+	}
+	return loc
+}
+
+// normalizeLabel turns a work directory path, external/REPO/PKG/NAME, into
+// the label @REPO//PKG:NAME and strips the extra @ of canonical labels.
+// Other strings are returned unchanged.
+func normalizeLabel(s string) string {
+	if m := locationLabelRepo.FindStringSubmatch(s); m != nil {
+		return "@" + m[1] + "//" + m[2]
+	}
+	rest, ok := strings.CutPrefix(s, "external/")
+	if !ok {
+		return s
+	}
+	repo, file, ok := strings.Cut(rest, "/")
+	if !ok {
+		return s
+	}
+	pkg, name := "", file
+	if i := strings.LastIndex(file, "/"); i >= 0 {
+		pkg, name = file[:i], file[i+1:]
+	}
+	return "@" + repo + "//" + pkg + ":" + name
+}
+
+// Summary formats the number of errors of each kind, e.g.
+// "MISSING_LOAD_TARGET=3 SYNTAX_ERROR=1", in kind order.
+func Summary(errs []*sympb.ExtractionError) string {
+	counts := make(map[sympb.ExtractionErrorKind]int)
+	for _, x := range errs {
+		counts[x.Kind]++
+	}
+	var parts []string
+	for i := range len(sympb.ExtractionErrorKind_name) {
+		kind := sympb.ExtractionErrorKind(i)
+		if n := counts[kind]; n > 0 {
+			name := strings.TrimPrefix(kind.String(), "EXTRACTION_ERROR_KIND_")
+			parts = append(parts, name+"="+strconv.Itoa(n))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,120 @@
+package extracterror
+
+import (
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+)
+
+func TestClassify(t *testing.T) {
+	known := func(repo string) bool { return repo == "rules_go" || repo == "bazel_skylib" }
+	for _, tc := range []struct {
+		name  string
+		code  codes.Code
+		msg   string
+		kind  sympb.ExtractionErrorKind
+		cause string
+		loc   string
+	}{
+		{
+			name:  "missing load in known repo",
+			code:  codes.Unknown,
+			msg:   "external/rules_go/go/def.bzl:3:1: cannot load '@bazel_skylib//lib:nope.bzl': no such file",
+			kind:  sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET,
+			cause: "@bazel_skylib//lib:nope.bzl",
+			loc:   "@rules_go//go:def.bzl:3:1",
+		},
+		{
+			name:  "missing load from unknown repo",
+			code:  codes.Unknown,
+			msg:   "cannot load '@local_config_cc//:toolchain.bzl': no such file",
+			kind:  sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SHIM_MISSING,
+			cause: "@local_config_cc",
+		},
+		{
+			name:  "missing load as work dir path",
+			code:  codes.Unknown,
+			msg:   "Unable to load file 'external/rules_go/private/gone.bzl'",
+			kind:  sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET,
+			cause: "@rules_go//private:gone.bzl",
+		},
+		{
+			name:  "undefined name",
+			code:  codes.Unknown,
+			msg:   "@@rules_go+//go:def.bzl:10:5: name 'cc_common' is not defined",
+			kind:  sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN,
+			cause: "cc_common",
+			loc:   "@rules_go+//go:def.bzl:10:5",
+		},
+		{
+			name:  "native member",
+			code:  codes.Unknown,
+			msg:   "'native' value has no field or method 'module_name'",
+			kind:  sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNSUPPORTED_BUILTIN,
+			cause: "native.module_name",
+		},
+		{
+			name: "syntax error",
+			code: codes.Unknown,
+			msg:  "Unable to parse external/rules_go/file.bzl:5:5",
+			kind: sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SYNTAX_ERROR,
+			loc:  "@rules_go//:file.bzl:5:5",
+		},
+		{
+			name: "timeout",
+			code: codes.DeadlineExceeded,
+			msg:  "context deadline exceeded",
+			kind: sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SERVER_TIMEOUT,
+		},
+		{
+			name: "unknown",
+			code: codes.Internal,
+			msg:  "java.lang.NullPointerException",
+			kind: sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_UNKNOWN,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			x := Classify("@rules_go//go:def.bzl", tc.code, tc.msg, known)
+			if x.Kind != tc.kind || x.Cause != tc.cause {
+				t.Errorf("got %v %q, want %v %q", x.Kind, x.Cause, tc.kind, tc.cause)
+			}
+			var loc string
+			if l := x.Location; l != nil {
+				loc = l.File + ":" + strconv.Itoa(int(l.Line)) + ":" + strconv.Itoa(int(l.Column))
+			}
+			if loc != tc.loc {
+				t.Errorf("location = %q, want %q", loc, tc.loc)
+			}
+			if x.Label != "@rules_go//go:def.bzl" || x.Message != tc.msg {
+				t.Errorf("label/message not recorded: %v", x)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	got := Summary([]*sympb.ExtractionError{
+		{Kind: sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_SYNTAX_ERROR},
+		{Kind: sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET},
+		{Kind: sympb.ExtractionErrorKind_EXTRACTION_ERROR_KIND_MISSING_LOAD_TARGET},
+	})
+	if want := "MISSING_LOAD_TARGET=2 SYNTAX_ERROR=1"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestPackageLabel(t *testing.T) {
+	for in, want := range map[string]string{
+		"@rules_a//lib:BUILD.bazel":       "@rules_a//lib",
+		"@rules_a//:BUILD.bazel":          "@rules_a//",
+		"@@rules_a+//lib/sub:BUILD":       "@rules_a+//lib/sub",
+		"rules_a/lib/BUILD.bazel.package": "rules_a/lib/BUILD.bazel.package",
+	} {
+		if got := PackageLabel(in); got != want {
+			t.Errorf("PackageLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
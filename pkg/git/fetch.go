@@ -0,0 +1,253 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Fetch downloads the commit at HEAD of the remote repository at url (a
+// smart HTTP URL such as https://github.com/org/repo.git) together with its
+// trees, like `git clone --depth=1 --filter=blob:none --no-checkout`, and
+// returns it as an in-memory Repository. Blobs are omitted when the server
+// supports filtering. It speaks git protocol version 2.
+func Fetch(ctx context.Context, client *http.Client, url string) (*Repository, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	url = strings.TrimSuffix(url, "/")
+	caps, err := advertisedCapabilities(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	fetchFeatures := strings.Fields(caps["fetch"])
+
+	head, err := lsRemoteHead(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var req bytes.Buffer
+	writePktLine(&req, "command=fetch\n")
+	writeDelim(&req)
+	writePktLine(&req, "ofs-delta\n")
+	writePktLine(&req, "no-progress\n")
+	if hasFeature(fetchFeatures, "shallow") {
+		writePktLine(&req, "deepen 1\n")
+	}
+	if hasFeature(fetchFeatures, "filter") {
+		writePktLine(&req, "filter blob:none\n")
+	}
+	writePktLine(&req, "want "+head.String()+"\n")
+	writePktLine(&req, "done\n")
+	writeFlush(&req)
+
+	resp, err := uploadPack(ctx, client, url, &req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	data, err := readPackfileSection(resp)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	p, err := indexPack(data)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	return newMemoryRepository(head, p), nil
+}
+
+// newMemoryRepository returns a Repository backed by a single in-memory
+// pack whose HEAD is head.
+func newMemoryRepository(head Hash, p *pack) *Repository {
+	return &Repository{packs: []*pack{p}, head: &head}
+}
+
+// advertisedCapabilities requests the protocol v2 capability advertisement
+// and returns it as a map from capability to value.
+func advertisedCapabilities(ctx context.Context, client *http.Client, url string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/info/refs?service=git-upload-pack", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Git-Protocol", "version=2")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s/info/refs: %s", url, resp.Status)
+	}
+
+	caps := make(map[string]string)
+	br := bufio.NewReader(resp.Body)
+	version2 := false
+	for {
+		line, err := readPktLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("GET %s/info/refs: %w", url, err)
+		}
+		if line == nil {
+			// The smart HTTP service header is followed by a flush; the
+			// advertisement itself ends with one.
+			if version2 {
+				break
+			}
+			continue
+		}
+		text := strings.TrimSuffix(string(line), "\n")
+		if text == "version 2" {
+			version2 = true
+			continue
+		}
+		key, value, _ := strings.Cut(text, "=")
+		caps[key] = value
+	}
+	if !version2 {
+		return nil, fmt.Errorf("%s does not support git protocol version 2", url)
+	}
+	return caps, nil
+}
+
+// lsRemoteHead returns the commit HEAD points to on the remote.
+func lsRemoteHead(ctx context.Context, client *http.Client, url string) (Hash, error) {
+	var req bytes.Buffer
+	writePktLine(&req, "command=ls-refs\n")
+	writeDelim(&req)
+	writePktLine(&req, "peel\n")
+	writePktLine(&req, "symrefs\n")
+	writePktLine(&req, "ref-prefix HEAD\n")
+	writeFlush(&req)
+
+	resp, err := uploadPack(ctx, client, url, &req)
+	if err != nil {
+		return Hash{}, err
+	}
+	defer resp.Close()
+	br := bufio.NewReader(resp)
+	for {
+		line, err := readPktLine(br)
+		if err != nil {
+			return Hash{}, fmt.Errorf("ls-refs %s: %w", url, err)
+		}
+		if line == nil {
+			return Hash{}, fmt.Errorf("ls-refs %s: no HEAD", url)
+		}
+		fields := strings.Fields(string(line))
+		if len(fields) >= 2 && fields[1] == "HEAD" {
+			return ParseHash(fields[0])
+		}
+	}
+}
+
+// uploadPack POSTs a protocol v2 command to the upload-pack service.
+func uploadPack(ctx context.Context, client *http.Client, url string, body io.Reader) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+"/git-upload-pack", body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
+	req.Header.Set("Accept", "application/x-git-upload-pack-result")
+	req.Header.Set("Git-Protocol", "version=2")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("POST %s/git-upload-pack: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// readPackfileSection skips to the "packfile" section of a fetch response
+// and returns the pack data multiplexed on side-band 1.
+func readPackfileSection(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := readPktLine(br)
+		if err != nil {
+			return nil, err
+		}
+		if string(line) == "packfile\n" {
+			break
+		}
+		if bytes.HasPrefix(line, []byte("ERR ")) {
+			return nil, errors.New(strings.TrimSpace(string(line[4:])))
+		}
+	}
+	var pack bytes.Buffer
+	for {
+		line, err := readPktLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			break
+		}
+		switch line[0] {
+		case 1:
+			pack.Write(line[1:])
+		case 2:
+			// progress
+		case 3:
+			return nil, fmt.Errorf("remote error: %s", strings.TrimSpace(string(line[1:])))
+		}
+	}
+	return pack.Bytes(), nil
+}
+
+func hasFeature(features []string, name string) bool {
+	for _, f := range features {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func writePktLine(w *bytes.Buffer, s string) {
+	fmt.Fprintf(w, "%04x%s", len(s)+4, s)
+}
+
+func writeDelim(w *bytes.Buffer) { w.WriteString("0001") }
+
+func writeFlush(w *bytes.Buffer) { w.WriteString("0000") }
+
+// readPktLine reads one pkt-line. It returns nil for flush, delimiter and
+// response-end packets.
+func readPktLine(r *bufio.Reader) ([]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n, err := strconv.ParseUint(string(hdr[:]), 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("malformed pkt-line length %q", hdr)
+	}
+	if n <= 2 {
+		return nil, nil
+	}
+	if n < 4 {
+		return nil, fmt.Errorf("malformed pkt-line length %q", hdr)
+	}
+	line := make([]byte, n-4)
+	if _, err := io.ReadFull(r, line); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
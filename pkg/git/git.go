@@ -23,68 +23,43 @@ func ParsePullRequestFromCommitMessage(message string) string {
 	return ""
 }
 
-// GetFileCreationCommit returns the commit information for when a file was first added (created)
-func GetFileCreationCommit(ctx context.Context, repoPath, filePath string) (*bzpb.ModuleCommit, error) {
-	// Get first commit (creation) for the file
-	// --follow: Follow file renames
-	// --diff-filter=A: Only show commits where file was Added
-	// Format: SHA|Date|AuthorEmail|Message (email before message since message can contain |)
-	output, err := exec.CommandContext(ctx, "git", "-C", repoPath,
-		"log", "--follow", "--format=%H|%cI|%aE|%s", "--diff-filter=A", "--", filePath).Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get git commit info for %s: %w", filePath, err)
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 0 || lines[0] == "" {
-		return nil, fmt.Errorf("no commit history found for %s", filePath)
-	}
-
-	// Get the first (oldest) commit - it's the last line
-	firstCommit := lines[len(lines)-1]
-	parts := strings.SplitN(firstCommit, "|", 4)
-	if len(parts) != 4 {
-		return nil, fmt.Errorf("unexpected git log output format: %s", firstCommit)
-	}
-
-	return &bzpb.ModuleCommit{
-		Sha1:        parts[0],
-		Date:        parts[1],
-		Message:     parts[3],
-		PullRequest: ParsePullRequestFromCommitMessage(parts[3]),
-	}, nil
-}
-
 // GetRegistryCommit returns the current commit SHA and date for a repository
 func GetRegistryCommit(ctx context.Context, repoPath string) (sha, date string, err error) {
-	// Get commit SHA and date from git repository
-	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "log", "-1", "--format=%H|%cI").Output()
+	repo, err := Open(repoPath)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get git commit info: %w", err)
 	}
-
-	parts := strings.Split(strings.TrimSpace(string(output)), "|")
-	if len(parts) != 2 {
-		return "", "", fmt.Errorf("unexpected git log output format: %s", output)
+	defer repo.Close()
+	head, err := repo.Head()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get git commit info: %w", err)
 	}
-
-	return parts[0], parts[1], nil
+	commit, err := repo.Commit(head)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get git commit info: %w", err)
+	}
+	mc := commit.ModuleCommit()
+	return mc.Sha1, mc.Date, nil
 }
 
 // GetRemoteURL returns the remote origin URL for a repository
 func GetRemoteURL(ctx context.Context, repoPath string) (string, error) {
-	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "config", "--get", "remote.origin.url").Output()
+	repo, err := Open(repoPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to get git remote URL: %w", err)
 	}
-
-	remoteURL := strings.TrimSuffix(strings.TrimSpace(string(output)), ".git")
-	return remoteURL, nil
+	defer repo.Close()
+	remoteURL, err := repo.RemoteURL("origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to get git remote URL: %w", err)
+	}
+	return strings.TrimSuffix(remoteURL, ".git"), nil
 }
 
 // GetAllModuleCommits returns commit information for all MODULE.bazel files in one git call
-// This is much faster than calling GetFileCreationCommit for each file individually
+// This is much faster than running git log for each file individually
 // Returns a map of file path -> commit info
+// It shells out to git; LoadCreationIndex is the in-process equivalent.
 func GetAllModuleCommits(ctx context.Context, repoPath, pattern string) (map[string]*bzpb.ModuleCommit, error) {
 	// Use git log with --name-only to get all commits that touched MODULE.bazel files
 	// Format: commit info line, blank line, file names
@@ -118,10 +93,9 @@ func GetAllModuleCommits(ctx context.Context, repoPath, pattern string) (map[str
 				}
 			}
 		} else if currentCommit != nil {
-			// This is a file name - only record if we haven't seen it yet (want first/oldest commit)
-			if _, exists := commits[line]; !exists {
-				commits[line] = currentCommit
-			}
+			// This is a file name. git log prints the newest commit first,
+			// so a later line is an older add; keep the oldest.
+			commits[line] = currentCommit
 		}
 	}
 
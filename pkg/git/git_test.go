@@ -0,0 +1,428 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixture builds git repositories with the git binary, with a fixed
+// identity and clock so object names are stable.
+type fixture struct {
+	tb   testing.TB
+	dir  string
+	time int64
+}
+
+func newFixture(tb testing.TB) *fixture {
+	tb.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		tb.Skip("git not installed")
+	}
+	f := &fixture{tb: tb, dir: tb.TempDir(), time: 1700000000}
+	f.git("init", "-q", "-b", "main")
+	f.git("remote", "add", "origin", "https://github.com/bazelbuild/bazel-central-registry.git")
+	return f
+}
+
+func (f *fixture) git(args ...string) string {
+	f.tb.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = f.dir
+	date := fmt.Sprintf("%d +0100", f.time)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+f.dir,
+		"GIT_AUTHOR_NAME=Jo Doe", "GIT_AUTHOR_EMAIL=jo@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Jo Doe", "GIT_COMMITTER_EMAIL=jo@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		f.tb.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (f *fixture) write(path, content string) {
+	f.tb.Helper()
+	p := filepath.Join(f.dir, path)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		f.tb.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		f.tb.Fatal(err)
+	}
+}
+
+func (f *fixture) commit(message string) string {
+	f.tb.Helper()
+	f.time += 3600
+	f.git("add", "-A")
+	f.git("commit", "-q", "--allow-empty", "-m", message)
+	return f.git("rev-parse", "HEAD")
+}
+
+// moduleFile returns a MODULE.bazel with enough shared content for git to
+// store later versions as deltas.
+func moduleFile(name, version string) string {
+	return fmt.Sprintf("module(name = %q, version = %q)\n%s", name, version, strings.Repeat("bazel_dep(name = \"rules_cc\", version = \"0.1.1\")\n", 50))
+}
+
+// newRegistryFixture builds a small registry history with a side branch, a
+// merge, a deleted and re-added version and a multi-line message.
+func newRegistryFixture(tb testing.TB) *fixture {
+	f := newFixture(tb)
+	f.write("README.md", "registry\n")
+	f.write("modules/rules_foo/1.0/MODULE.bazel", moduleFile("rules_foo", "1.0"))
+	f.commit("rules_foo@1.0 (#1)")
+
+	f.write("modules/rules_foo/1.1/MODULE.bazel", moduleFile("rules_foo", "1.1"))
+	f.write("modules/rules_foo/1.1/patches/fix.patch", "--- a\n+++ b\n")
+	f.commit("rules_foo@1.1\nwith a wrapped\n\nand a body (#9)")
+
+	f.git("checkout", "-q", "-b", "side")
+	f.write("modules/rules_bar/0.1/MODULE.bazel", moduleFile("rules_bar", "0.1"))
+	f.commit("rules_bar@0.1 (#2)")
+	f.git("checkout", "-q", "main")
+	f.write("modules/rules_foo/2.0/MODULE.bazel", moduleFile("rules_foo", "2.0"))
+	f.commit("rules_foo@2.0 (#3)")
+	f.time += 3600
+	f.git("merge", "-q", "--no-ff", "-m", "Merge side", "side")
+
+	f.write("modules/rules_foo/1.0/MODULE.bazel", moduleFile("rules_foo", "1.0")+"# edited\n")
+	f.commit("Edit rules_foo@1.0")
+	os.RemoveAll(filepath.Join(f.dir, "modules/rules_foo/1.1"))
+	f.commit("Remove rules_foo@1.1")
+	f.write("modules/rules_foo/1.1/MODULE.bazel", moduleFile("rules_foo", "1.1"))
+	f.commit("Restore rules_foo@1.1")
+	return f
+}
+
+func TestCreationIndex(t *testing.T) {
+	f := newRegistryFixture(t)
+	for _, storage := range []string{"loose", "packed"} {
+		t.Run(storage, func(t *testing.T) {
+			if storage == "packed" {
+				f.git("repack", "-q", "-a", "-d", "-f", "--depth=10")
+				f.git("prune-packed")
+			}
+			index, err := LoadCreationIndex(context.Background(), f.dir, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			paths := strings.Fields(f.git("log", "--format=", "--name-only", "--diff-filter=A"))
+			for _, p := range paths {
+				// Without --follow: similar MODULE.bazel files of a new
+				// version are otherwise taken for copies of an older one.
+				log := strings.Split(f.git("log", "--format=%H|%cI|%s", "--diff-filter=A", "--", p), "\n")
+				want := log[len(log)-1]
+				got := index[p]
+				if got == nil {
+					t.Errorf("%s: missing from index", p)
+					continue
+				}
+				if got := got.Sha1 + "|" + got.Date + "|" + got.Message; got != want {
+					t.Errorf("%s: got %s, want %s", p, got, want)
+				}
+			}
+			if got := index["modules/rules_foo/1.1/MODULE.bazel"]; got == nil || got.Message != "rules_foo@1.1 with a wrapped" || got.PullRequest != "" {
+				t.Errorf("re-added file: got %v, want the first add with a joined subject", got)
+			}
+			if got := index["modules/rules_bar/0.1/MODULE.bazel"]; got == nil || got.PullRequest != "2" {
+				t.Errorf("merged file: got %v, want the side branch commit", got)
+			}
+			if len(index) != 6 {
+				t.Errorf("index has %d paths, want 6: %v", len(index), index)
+			}
+		})
+	}
+}
+
+// TestGetAllModuleCommits checks that the git log based lookup agrees with
+// the creation index, so that the benchmark compares like with like.
+func TestGetAllModuleCommits(t *testing.T) {
+	f := newRegistryFixture(t)
+	ctx := context.Background()
+	index, err := LoadCreationIndex(ctx, f.dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := GetAllModuleCommits(ctx, f.dir, "modules/*/*/MODULE.bazel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 4 {
+		t.Errorf("got %d paths, want 4: %v", len(commits), commits)
+	}
+	for p, got := range commits {
+		if want := index[p]; want == nil || got.Sha1 != want.Sha1 {
+			t.Errorf("%s: got %s, want %v", p, got.Sha1, want)
+		}
+	}
+}
+
+// TestCreationIndexRenames checks renamed and re-added files against
+// `git log --follow`. The files have distinct contents so that --follow's
+// copy detection finds only the real renames.
+func TestCreationIndexRenames(t *testing.T) {
+	f := newFixture(t)
+	f.write("modules/rules_baz/1.0/MODULE.bazel", "module(name = \"rules_baz\", version = \"1.0\")\n")
+	f.write("modules/rules_baz/1.0/source.json", "{\"url\": \"https://example.com/rules_baz-1.0.tar.gz\"}\n")
+	f.commit("rules_baz@1.0 (#1)")
+
+	f.git("mv", "modules/rules_baz", "modules/rules_qux")
+	f.commit("Rename rules_baz to rules_qux (#2)")
+
+	f.write("modules/rules_qux/1.1/MODULE.bazel", "module(name = \"rules_qux\", version = \"1.1\")\nbazel_dep(name = \"platforms\", version = \"1.0.0\")\n")
+	f.commit("rules_qux@1.1 (#3)")
+
+	os.Remove(filepath.Join(f.dir, "modules/rules_qux/1.0/MODULE.bazel"))
+	f.commit("Remove rules_qux@1.0 MODULE.bazel")
+	f.write("modules/rules_qux/1.0/MODULE.bazel", "module(name = \"rules_baz\", version = \"1.0\")\n")
+	f.commit("Restore rules_qux@1.0 MODULE.bazel")
+
+	f.write("modules/rules_baz/2.0/MODULE.bazel", "module(\n    name = \"rules_baz\",\n    version = \"2.0\",\n    compatibility_level = 2,\n)\n")
+	f.commit("rules_baz@2.0 (#4)")
+
+	index, err := LoadCreationIndex(context.Background(), f.dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, wantPR := range map[string]string{
+		"modules/rules_qux/1.0/MODULE.bazel": "1",
+		"modules/rules_qux/1.0/source.json":  "1",
+		"modules/rules_qux/1.1/MODULE.bazel": "3",
+		"modules/rules_baz/2.0/MODULE.bazel": "4",
+	} {
+		log := strings.Split(f.git("log", "--follow", "--format=%H|%cI|%s", "--diff-filter=A", "--", p), "\n")
+		want := log[len(log)-1]
+		got := index[p]
+		if got == nil {
+			t.Errorf("%s: missing from index", p)
+			continue
+		}
+		if got := got.Sha1 + "|" + got.Date + "|" + got.Message; got != want {
+			t.Errorf("%s: got %s, want %s", p, got, want)
+		}
+		if got.PullRequest != wantPR {
+			t.Errorf("%s: got pull request %q, want %q", p, got.PullRequest, wantPR)
+		}
+	}
+	if got := index["modules/rules_baz/1.0/MODULE.bazel"]; got == nil || got.PullRequest != "1" {
+		t.Errorf("renamed-away path: got %v, want its own add", got)
+	}
+}
+
+func TestLoadCreationIndexCache(t *testing.T) {
+	f := newRegistryFixture(t)
+	cacheDir := t.TempDir()
+	first, err := LoadCreationIndex(context.Background(), f.dir, cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := f.git("rev-parse", "HEAD")
+	cacheFile := filepath.Join(cacheDir, creationIndexPrefix+head+".gob")
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Fatalf("cache not written: %v", err)
+	}
+
+	// A cached index for the same HEAD is used without walking history.
+	err = writeCreationIndex(cacheFile, &creationIndexFile{
+		Head:    head,
+		Commits: []creationIndexCommit{{Sha1: "cached", Message: "From the cache (#7)"}},
+		Paths:   map[string]int32{"modules/rules_foo/1.0/MODULE.bazel": 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, err := LoadCreationIndex(context.Background(), f.dir, cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cached["modules/rules_foo/1.0/MODULE.bazel"]; len(cached) != 1 || got.Sha1 != "cached" || got.PullRequest != "7" {
+		t.Errorf("cached index = %v", cached)
+	}
+
+	// Moving HEAD invalidates it.
+	f.write("modules/rules_baz/1.0/MODULE.bazel", moduleFile("rules_baz", "1.0"))
+	f.commit("rules_baz@1.0")
+	rebuilt, err := LoadCreationIndex(context.Background(), f.dir, cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rebuilt) != len(first)+1 {
+		t.Errorf("rebuilt index has %d paths, want %d", len(rebuilt), len(first)+1)
+	}
+}
+
+func TestRegistryCommitAndRemote(t *testing.T) {
+	f := newRegistryFixture(t)
+	f.git("pack-refs", "--all")
+	sha, date, err := GetRegistryCommit(context.Background(), f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := f.git("log", "-1", "--format=%H|%cI")
+	if got := sha + "|" + date; got != want {
+		t.Errorf("GetRegistryCommit = %s, want %s", got, want)
+	}
+	url, err := GetRemoteURL(context.Background(), f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/bazelbuild/bazel-central-registry" {
+		t.Errorf("GetRemoteURL = %q", url)
+	}
+}
+
+func TestOpenSubmodule(t *testing.T) {
+	f := newRegistryFixture(t)
+	work := t.TempDir()
+	if err := os.WriteFile(filepath.Join(work, ".git"), []byte("gitdir: "+filepath.Join(f.dir, ".git")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Open(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.String() != f.git("rev-parse", "HEAD") {
+		t.Errorf("head = %s", head)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	f := newRegistryFixture(t)
+	f.write("modules/rules_foo/1.0/documentationinfo.pb.gz", "docs")
+	f.commit("Add docs")
+	f.git("config", "uploadpack.allowFilter", "true")
+	backend, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip(err)
+	}
+	server := httptest.NewServer(&cgi.Handler{
+		Path: backend,
+		Args: []string{"http-backend"},
+		Env: []string{
+			"GIT_PROJECT_ROOT=" + filepath.Dir(f.dir),
+			"GIT_HTTP_EXPORT_ALL=1",
+			"GIT_CONFIG_NOSYSTEM=1",
+			"HOME=" + f.dir,
+		},
+	})
+	defer server.Close()
+
+	repo, err := Fetch(context.Background(), server.Client(), server.URL+"/"+filepath.Base(f.dir)+"/.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.String() != f.git("rev-parse", "HEAD") {
+		t.Errorf("head = %s", head)
+	}
+
+	var files []string
+	err = repo.WalkFiles(head, "modules", func(path string, e TreeEntry) error {
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "modules/rules_bar/0.1/MODULE.bazel modules/rules_foo/1.0/MODULE.bazel modules/rules_foo/1.0/documentationinfo.pb.gz modules/rules_foo/1.1/MODULE.bazel modules/rules_foo/2.0/MODULE.bazel"
+	if got := strings.Join(files, " "); got != want {
+		t.Errorf("files = %s\nwant    %s", got, want)
+	}
+
+	// Only the tip was fetched, without blobs.
+	c, err := repo.Commit(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit(c.Parents[0]); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("parent commit: got %v, want ErrObjectNotFound", err)
+	}
+	if err := repo.WalkFiles(head, "docs", nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing dir: got %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello, world")
+	// Source size 12, target size 11: copy 5 bytes from offset 0, then
+	// insert " git!!".
+	delta := []byte{12, 11, 0x90, 5, 6, ' ', 'g', 'i', 't', '!', '!'}
+	got, err := applyDelta(base, delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello git!!" {
+		t.Errorf("applyDelta = %q", got)
+	}
+	if _, err := applyDelta(base, []byte{11, 1, 1, 'x'}); err == nil {
+		t.Error("want an error for a mismatched base size")
+	}
+}
+
+// newBenchmarkFixture builds a packed registry with modules*versions
+// commits, one version per commit.
+func newBenchmarkFixture(b *testing.B, modules, versions int) *fixture {
+	f := newFixture(b)
+	for v := range versions {
+		for m := range modules {
+			name := fmt.Sprintf("module_%03d", m)
+			version := fmt.Sprintf("1.%d", v)
+			f.write(filepath.Join("modules", name, version, "MODULE.bazel"), moduleFile(name, version))
+			f.write(filepath.Join("modules", name, version, "source.json"), "{}\n")
+			f.commit(fmt.Sprintf("%s@%s (#%d)", name, version, v*modules+m))
+		}
+	}
+	f.git("gc", "-q")
+	return f
+}
+
+// BenchmarkCreationIndex compares the in-process history walk with the
+// git log based GetAllModuleCommits on the same fixture.
+func BenchmarkCreationIndex(b *testing.B) {
+	f := newBenchmarkFixture(b, 40, 10)
+	ctx := context.Background()
+	b.Run("inprocess", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := LoadCreationIndex(ctx, f.dir, ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("exec", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := GetAllModuleCommits(ctx, f.dir, "modules/*/*/MODULE.bazel"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	cacheDir := b.TempDir()
+	if _, err := LoadCreationIndex(ctx, f.dir, cacheDir); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("cached", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := LoadCreationIndex(ctx, f.dir, cacheDir); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
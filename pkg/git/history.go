@@ -0,0 +1,400 @@
+package git
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+// maxCachedTrees bounds the parsed trees kept while walking history. A
+// commit's trees are mostly its parent's, so a small cache suffices.
+const maxCachedTrees = 8192
+
+// CreationIndex walks every commit reachable from head once and returns,
+// for every path that was ever added, the commit that first added it: the
+// oldest non-merge commit whose diff against its parent adds the path, the
+// same commit `git log --follow --diff-filter=A -- PATH` lists last.
+//
+// Renames are followed when they are exact: a commit that deletes one path
+// and adds another with the same blob moves the file, and the new path
+// inherits the creation commit of the old one. Like --follow, only the
+// newest rename into a path is followed; adds of the path before it belong
+// to a different file. Edited renames are not detected and count as adds.
+// Commits at the boundary of a shallow clone are diffed against the empty
+// tree.
+func (r *Repository) CreationIndex(ctx context.Context, head Hash) (map[string]*Commit, error) {
+	w := &historyWalker{repo: r, trees: make(map[Hash][]TreeEntry)}
+	h := make(pathHistory)
+
+	c, err := r.Commit(head)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[Hash]bool{head: true}
+	stack := []*Commit{c}
+	for n := 0; len(stack) > 0; n++ {
+		if n%256 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		var parentTrees []Hash
+		for _, p := range c.Parents {
+			pc, err := r.Commit(p)
+			if errors.Is(err, ErrObjectNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			parentTrees = append(parentTrees, pc.Tree)
+			if !seen[p] {
+				seen[p] = true
+				stack = append(stack, pc)
+			}
+		}
+		if len(parentTrees) > 1 {
+			// Like git log, merges are not diffed; whatever they bring in
+			// was added by a commit on the merged branch.
+			continue
+		}
+		var base Hash
+		if len(parentTrees) == 1 {
+			base = parentTrees[0]
+		}
+		var added []TreeEntry
+		deleted := make(map[Hash][]string)
+		err = w.diff(base, c.Tree, "", func(p string, blob Hash, isAdd bool) {
+			if isAdd {
+				added = append(added, TreeEntry{Name: p, Hash: blob})
+			} else {
+				deleted[blob] = append(deleted[blob], p)
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("commit %s: %w", c.Hash, err)
+		}
+		for _, e := range added {
+			ev := h.events(e.Name)
+			if from := renameSource(e.Name, deleted[e.Hash]); from != "" {
+				ev.renames = append(ev.renames, rename{from: from, commit: c})
+			} else {
+				ev.adds = append(ev.adds, c)
+			}
+		}
+	}
+
+	created := make(map[string]*Commit, len(h))
+	for p := range h {
+		if c := h.creation(p, time.Time{}); c != nil {
+			created[p] = c
+		}
+	}
+	return created, nil
+}
+
+// renameSource picks the path an added file was renamed from among the
+// deleted paths with the same blob, preferring one with the same base name.
+// It returns "" if there is none.
+func renameSource(to string, candidates []string) string {
+	for _, from := range candidates {
+		if path.Base(from) == path.Base(to) {
+			return from
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// pathHistory records, per path, the commits that added it and the exact
+// renames that moved another path onto it.
+type pathHistory map[string]*pathEvents
+
+type pathEvents struct {
+	adds    []*Commit
+	renames []rename
+}
+
+type rename struct {
+	from   string
+	commit *Commit
+}
+
+func (h pathHistory) events(p string) *pathEvents {
+	ev, ok := h[p]
+	if !ok {
+		ev = &pathEvents{}
+		h[p] = ev
+	}
+	return ev
+}
+
+// creation returns the commit that created the file at p as it existed
+// before the given time (any time if zero). The newest rename into p before
+// then is followed to its source, which existed before the rename, so each
+// step looks further back and the recursion ends. A rename whose source
+// has no recorded add (a shallow boundary) counts as the add.
+func (h pathHistory) creation(p string, before time.Time) *Commit {
+	ev := h[p]
+	if ev == nil {
+		return nil
+	}
+	var newest *rename
+	for i, r := range ev.renames {
+		if (before.IsZero() || r.commit.Time.Before(before)) && (newest == nil || r.commit.Time.After(newest.commit.Time)) {
+			newest = &ev.renames[i]
+		}
+	}
+	var oldest *Commit
+	if newest != nil {
+		if c := h.creation(newest.from, newest.commit.Time); c != nil {
+			return c
+		}
+		oldest = newest.commit
+	}
+	for _, c := range ev.adds {
+		if !before.IsZero() && !c.Time.Before(before) {
+			continue
+		}
+		if newest != nil && c.Time.Before(newest.commit.Time) {
+			continue
+		}
+		if oldest == nil || !oldest.Time.Before(c.Time) {
+			oldest = c
+		}
+	}
+	return oldest
+}
+
+// historyWalker diffs trees with a cache of parsed trees.
+type historyWalker struct {
+	repo  *Repository
+	trees map[Hash][]TreeEntry
+}
+
+func (w *historyWalker) tree(h Hash) ([]TreeEntry, error) {
+	if h == (Hash{}) {
+		return nil, nil
+	}
+	if entries, ok := w.trees[h]; ok {
+		return entries, nil
+	}
+	entries, err := w.repo.Tree(h)
+	if err != nil {
+		return nil, err
+	}
+	if len(w.trees) >= maxCachedTrees {
+		clear(w.trees)
+	}
+	w.trees[h] = entries
+	return entries, nil
+}
+
+// diff calls fn for every non-directory path under dir that is in tree
+// newTree but not in oldTree (added) or the other way round (deleted), with
+// the blob it names. Modified files are not reported. Subtrees with equal
+// hashes are skipped, so the cost is proportional to the size of the
+// change.
+func (w *historyWalker) diff(oldTree, newTree Hash, dir string, fn func(p string, blob Hash, added bool)) error {
+	if oldTree == newTree {
+		return nil
+	}
+	oldEntries, err := w.tree(oldTree)
+	if err != nil {
+		return err
+	}
+	newEntries, err := w.tree(newTree)
+	if err != nil {
+		return err
+	}
+	// Both lists are in git's tree order, so they can be merged. A file
+	// and a directory of the same name sort apart and are treated as
+	// different entries, which reports a file replacing a directory (and
+	// vice versa) as an add and a delete.
+	report := func(e TreeEntry, added bool) error {
+		p := path.Join(dir, e.Name)
+		if !e.IsTree() {
+			fn(p, e.Hash, added)
+			return nil
+		}
+		if added {
+			return w.diff(Hash{}, e.Hash, p, fn)
+		}
+		return w.diff(e.Hash, Hash{}, p, fn)
+	}
+	i, j := 0, 0
+	for i < len(oldEntries) || j < len(newEntries) {
+		switch {
+		case j == len(newEntries) || (i < len(oldEntries) && treeEntryLess(oldEntries[i], newEntries[j])):
+			if err := report(oldEntries[i], false); err != nil {
+				return err
+			}
+			i++
+		case i == len(oldEntries) || treeEntryLess(newEntries[j], oldEntries[i]):
+			if err := report(newEntries[j], true); err != nil {
+				return err
+			}
+			j++
+		default:
+			if o, n := oldEntries[i], newEntries[j]; n.IsTree() {
+				if err := w.diff(o.Hash, n.Hash, path.Join(dir, n.Name), fn); err != nil {
+					return err
+				}
+			}
+			i++
+			j++
+		}
+	}
+	return nil
+}
+
+// treeEntryLess orders entries like git: directories sort as if their
+// name ended in a slash.
+func treeEntryLess(a, b TreeEntry) bool {
+	return treeEntryKey(a) < treeEntryKey(b)
+}
+
+func treeEntryKey(e TreeEntry) string {
+	if e.IsTree() {
+		return e.Name + "/"
+	}
+	return e.Name
+}
+
+// creationIndexPrefix names cached creation index files. The version is
+// bumped whenever the index would differ for the same HEAD, so stale caches
+// are ignored.
+const creationIndexPrefix = "creation-index-v2-"
+
+// creationIndexFile is the on-disk form of a creation index. Commits are
+// stored once and referenced by position.
+type creationIndexFile struct {
+	Head    string
+	Commits []creationIndexCommit
+	Paths   map[string]int32
+}
+
+type creationIndexCommit struct {
+	Sha1, Date, Message string
+}
+
+// LoadCreationIndex returns the creation index of HEAD of the repository at
+// repoPath, keyed by path. If cacheDir is set the index is read from, or
+// written to, a file there named after HEAD, so it is only rebuilt when
+// HEAD moves.
+func LoadCreationIndex(ctx context.Context, repoPath, cacheDir string) (map[string]*bzpb.ModuleCommit, error) {
+	repo, err := Open(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	var cacheFile string
+	if cacheDir != "" {
+		cacheFile = filepath.Join(cacheDir, creationIndexPrefix+head.String()+".gob")
+		if index, err := readCreationIndex(cacheFile, head); err == nil {
+			return index, nil
+		}
+	}
+
+	created, err := repo.CreationIndex(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	f := &creationIndexFile{Head: head.String(), Paths: make(map[string]int32, len(created))}
+	positions := make(map[*Commit]int32)
+	index := make(map[string]*bzpb.ModuleCommit, len(created))
+	commits := make(map[*Commit]*bzpb.ModuleCommit)
+	for p, c := range created {
+		mc, ok := commits[c]
+		if !ok {
+			mc = c.ModuleCommit()
+			commits[c] = mc
+			positions[c] = int32(len(f.Commits))
+			f.Commits = append(f.Commits, creationIndexCommit{Sha1: mc.Sha1, Date: mc.Date, Message: mc.Message})
+		}
+		index[p] = mc
+		f.Paths[p] = positions[c]
+	}
+
+	if cacheFile != "" {
+		if err := writeCreationIndex(cacheFile, f); err != nil {
+			return nil, fmt.Errorf("writing creation index cache: %w", err)
+		}
+	}
+	return index, nil
+}
+
+func readCreationIndex(filename string, head Hash) (map[string]*bzpb.ModuleCommit, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var f creationIndexFile
+	if err := gob.NewDecoder(file).Decode(&f); err != nil {
+		return nil, err
+	}
+	if f.Head != head.String() {
+		return nil, fmt.Errorf("%s: index is for %s", filename, f.Head)
+	}
+	commits := make([]*bzpb.ModuleCommit, len(f.Commits))
+	for i, c := range f.Commits {
+		commits[i] = &bzpb.ModuleCommit{
+			Sha1:        c.Sha1,
+			Date:        c.Date,
+			Message:     c.Message,
+			PullRequest: ParsePullRequestFromCommitMessage(c.Message),
+		}
+	}
+	index := make(map[string]*bzpb.ModuleCommit, len(f.Paths))
+	for p, i := range f.Paths {
+		if int(i) >= len(commits) {
+			return nil, fmt.Errorf("%s: corrupt", filename)
+		}
+		index[p] = commits[i]
+	}
+	return index, nil
+}
+
+// writeCreationIndex writes the index to a temporary file and renames it
+// into place so concurrent readers never see a partial file.
+func writeCreationIndex(filename string, f *creationIndexFile) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(f); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package git
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+// Hash is a SHA-1 object name.
+type Hash [20]byte
+
+// ParseHash parses a 40 character hex object name.
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	if len(s) != 40 {
+		return h, fmt.Errorf("invalid object name %q", s)
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return h, fmt.Errorf("invalid object name %q: %w", s, err)
+	}
+	return h, nil
+}
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// objectType is the type of a git object, numbered as in packfiles.
+type objectType int
+
+const (
+	objCommit   objectType = 1
+	objTree     objectType = 2
+	objBlob     objectType = 3
+	objTag      objectType = 4
+	objOfsDelta objectType = 6
+	objRefDelta objectType = 7
+)
+
+func (t objectType) String() string {
+	switch t {
+	case objCommit:
+		return "commit"
+	case objTree:
+		return "tree"
+	case objBlob:
+		return "blob"
+	case objTag:
+		return "tag"
+	case objOfsDelta:
+		return "ofs-delta"
+	case objRefDelta:
+		return "ref-delta"
+	}
+	return fmt.Sprintf("type(%d)", int(t))
+}
+
+func parseObjectType(s string) (objectType, error) {
+	switch s {
+	case "commit":
+		return objCommit, nil
+	case "tree":
+		return objTree, nil
+	case "blob":
+		return objBlob, nil
+	case "tag":
+		return objTag, nil
+	}
+	return 0, fmt.Errorf("unknown object type %q", s)
+}
+
+// Commit is the part of a commit object the registry needs.
+type Commit struct {
+	Hash    Hash
+	Tree    Hash
+	Parents []Hash
+	// Committer time, in the committer's time zone.
+	Time    time.Time
+	Message string
+}
+
+// Subject returns the first paragraph of the message joined into one line,
+// like git's %s.
+func (c *Commit) Subject() string {
+	var lines []string
+	for _, line := range strings.Split(c.Message, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
+
+// ModuleCommit converts c to the registry's commit message, with the date
+// formatted like git's %cI.
+func (c *Commit) ModuleCommit() *bzpb.ModuleCommit {
+	subject := c.Subject()
+	return &bzpb.ModuleCommit{
+		Sha1:        c.Hash.String(),
+		Date:        c.Time.Format("2006-01-02T15:04:05-07:00"),
+		Message:     subject,
+		PullRequest: ParsePullRequestFromCommitMessage(subject),
+	}
+}
+
+func parseCommit(h Hash, data []byte) (*Commit, error) {
+	c := &Commit{Hash: h}
+	header, message, _ := bytes.Cut(data, []byte("\n\n"))
+	c.Message = string(message)
+	for _, line := range strings.Split(string(header), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			tree, err := ParseHash(value)
+			if err != nil {
+				return nil, fmt.Errorf("commit %s: %w", h, err)
+			}
+			c.Tree = tree
+		case "parent":
+			parent, err := ParseHash(value)
+			if err != nil {
+				return nil, fmt.Errorf("commit %s: %w", h, err)
+			}
+			c.Parents = append(c.Parents, parent)
+		case "committer":
+			t, err := parseSignatureTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("commit %s: %w", h, err)
+			}
+			c.Time = t
+		}
+	}
+	return c, nil
+}
+
+// parseSignatureTime parses the "<unix seconds> <+hhmm>" suffix of an
+// author or committer line.
+func parseSignatureTime(sig string) (time.Time, error) {
+	i := strings.LastIndex(sig, "> ")
+	if i < 0 {
+		return time.Time{}, fmt.Errorf("malformed signature %q", sig)
+	}
+	secs, tz, _ := strings.Cut(sig[i+2:], " ")
+	unix, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed signature time %q", sig)
+	}
+	loc := time.UTC
+	if len(tz) == 5 && (tz[0] == '+' || tz[0] == '-') {
+		hh, err1 := strconv.Atoi(tz[1:3])
+		mm, err2 := strconv.Atoi(tz[3:5])
+		if err1 == nil && err2 == nil {
+			offset := hh*3600 + mm*60
+			if tz[0] == '-' {
+				offset = -offset
+			}
+			loc = time.FixedZone("", offset)
+		}
+	}
+	return time.Unix(unix, 0).In(loc), nil
+}
+
+// TreeEntry is one entry of a tree object.
+type TreeEntry struct {
+	Name string
+	Mode string
+	Hash Hash
+}
+
+// IsTree reports whether the entry is a subdirectory.
+func (e TreeEntry) IsTree() bool {
+	return e.Mode == "40000"
+}
+
+func parseTree(h Hash, data []byte) ([]TreeEntry, error) {
+	// Entries are a mode, a name and a 20 byte hash; 40 bytes is a
+	// reasonable guess for registry paths.
+	entries := make([]TreeEntry, 0, len(data)/40+1)
+	for len(data) > 0 {
+		sp := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if sp < 0 || nul < sp || nul+21 > len(data) {
+			return nil, fmt.Errorf("tree %s: malformed entry", h)
+		}
+		e := TreeEntry{Mode: string(data[:sp]), Name: string(data[sp+1 : nul])}
+		copy(e.Hash[:], data[nul+1:nul+21])
+		entries = append(entries, e)
+		data = data[nul+21:]
+	}
+	return entries, nil
+}
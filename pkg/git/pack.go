@@ -0,0 +1,467 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// maxCachedBytes bounds the cache of resolved pack objects. Trees in a
+// registry checkout are deltified against each other, so the cache saves
+// re-applying the same delta chains while walking history.
+const maxCachedBytes = 64 << 20
+
+// pack reads objects from a packfile.
+type pack struct {
+	r    io.ReaderAt
+	size int64
+	// find returns the offset of an object in this pack.
+	find func(Hash) (int64, bool)
+	// external reads REF_DELTA bases that are not in this pack.
+	external func(Hash) (objectType, []byte, error)
+
+	cache      map[int64]cachedObject
+	cacheBytes int
+	closer     io.Closer
+
+	// Reused across reads; allocating a flate window per object dominates
+	// the cost of walking history otherwise.
+	br *bufio.Reader
+	zr io.ReadCloser
+}
+
+type cachedObject struct {
+	typ  objectType
+	data []byte
+}
+
+// openPack opens PATH.pack using its PATH.idx version 2 index.
+func openPack(path string, external func(Hash) (objectType, []byte, error)) (*pack, error) {
+	idx, err := os.ReadFile(path + ".idx")
+	if err != nil {
+		return nil, err
+	}
+	find, err := parseIndex(idx)
+	if err != nil {
+		return nil, fmt.Errorf("%s.idx: %w", path, err)
+	}
+	f, err := os.Open(path + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	p := &pack{r: f, size: info.Size(), find: find, external: external, closer: f}
+	if err := p.checkHeader(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s.pack: %w", path, err)
+	}
+	return p, nil
+}
+
+func (p *pack) Close() error {
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+	return nil
+}
+
+func (p *pack) checkHeader() error {
+	var hdr [12]byte
+	if _, err := p.r.ReadAt(hdr[:], 0); err != nil {
+		return err
+	}
+	if string(hdr[:4]) != "PACK" {
+		return errors.New("not a packfile")
+	}
+	if v := binary.BigEndian.Uint32(hdr[4:8]); v != 2 && v != 3 {
+		return fmt.Errorf("unsupported pack version %d", v)
+	}
+	return nil
+}
+
+// parseIndex returns a lookup function over a version 2 pack index.
+func parseIndex(idx []byte) (func(Hash) (int64, bool), error) {
+	const header = 8 + 256*4
+	if len(idx) < header || !bytes.Equal(idx[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(idx[4:8]) != 2 {
+		return nil, errors.New("unsupported pack index (want version 2)")
+	}
+	fanout := idx[8:header]
+	n := int(binary.BigEndian.Uint32(fanout[255*4:]))
+	names := header
+	offsets := names + n*20 + n*4
+	large := offsets + n*4
+	if len(idx) < large {
+		return nil, errors.New("truncated pack index")
+	}
+	return func(h Hash) (int64, bool) {
+		lo := 0
+		if h[0] > 0 {
+			lo = int(binary.BigEndian.Uint32(fanout[(int(h[0])-1)*4:]))
+		}
+		hi := int(binary.BigEndian.Uint32(fanout[int(h[0])*4:]))
+		i := lo + sort.Search(hi-lo, func(i int) bool {
+			return bytes.Compare(idx[names+(lo+i)*20:names+(lo+i)*20+20], h[:]) >= 0
+		})
+		if i >= hi || !bytes.Equal(idx[names+i*20:names+i*20+20], h[:]) {
+			return 0, false
+		}
+		off := binary.BigEndian.Uint32(idx[offsets+i*4:])
+		if off&0x80000000 == 0 {
+			return int64(off), true
+		}
+		j := large + int(off&0x7fffffff)*8
+		if j+8 > len(idx) {
+			return 0, false
+		}
+		return int64(binary.BigEndian.Uint64(idx[j:])), true
+	}, nil
+}
+
+// has reports whether the pack contains h.
+func (p *pack) has(h Hash) bool {
+	_, ok := p.find(h)
+	return ok
+}
+
+// read returns the object h, which must be in the pack.
+func (p *pack) read(h Hash) (objectType, []byte, error) {
+	off, ok := p.find(h)
+	if !ok {
+		return 0, nil, fmt.Errorf("object %s: %w", h, ErrObjectNotFound)
+	}
+	return p.readAt(off)
+}
+
+// entryHeader is the header of a pack entry.
+type entryHeader struct {
+	typ  objectType
+	size int64
+	// base is set for OFS_DELTA entries, baseHash for REF_DELTA entries.
+	base     int64
+	baseHash Hash
+}
+
+// readHeader parses the entry header at off and returns a reader
+// positioned at the start of its compressed data.
+func (p *pack) readHeader(off int64) (entryHeader, *bufio.Reader, error) {
+	var e entryHeader
+	section := io.NewSectionReader(p.r, off, p.size-off)
+	if p.br == nil {
+		p.br = bufio.NewReader(section)
+	} else {
+		p.br.Reset(section)
+	}
+	br := p.br
+	b, err := br.ReadByte()
+	if err != nil {
+		return e, nil, err
+	}
+	e.typ = objectType(b >> 4 & 7)
+	e.size = int64(b & 0x0f)
+	for shift := 4; b&0x80 != 0; shift += 7 {
+		if b, err = br.ReadByte(); err != nil {
+			return e, nil, err
+		}
+		e.size |= int64(b&0x7f) << shift
+	}
+	switch e.typ {
+	case objOfsDelta:
+		if b, err = br.ReadByte(); err != nil {
+			return e, nil, err
+		}
+		rel := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = br.ReadByte(); err != nil {
+				return e, nil, err
+			}
+			rel = (rel+1)<<7 | int64(b&0x7f)
+		}
+		e.base = off - rel
+		if e.base <= 0 || e.base >= off {
+			return e, nil, fmt.Errorf("pack entry at %d: bad delta base offset", off)
+		}
+	case objRefDelta:
+		if _, err := io.ReadFull(br, e.baseHash[:]); err != nil {
+			return e, nil, err
+		}
+	}
+	return e, br, nil
+}
+
+// inflate decompresses exactly size bytes of zlib data from r.
+func (p *pack) inflate(r io.Reader, size int64) ([]byte, error) {
+	if p.zr == nil {
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		p.zr = zr
+	} else if err := p.zr.(zlib.Resetter).Reset(r, nil); err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(p.zr, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readAt returns the fully resolved object at off.
+func (p *pack) readAt(off int64) (objectType, []byte, error) {
+	if obj, ok := p.cache[off]; ok {
+		return obj.typ, obj.data, nil
+	}
+
+	// Follow the delta chain down to a base object, then apply the deltas
+	// from the bottom up.
+	var deltas [][]byte
+	var chain []int64
+	var typ objectType
+	var data []byte
+	for cur := off; ; {
+		if obj, ok := p.cache[cur]; ok {
+			typ, data = obj.typ, obj.data
+			break
+		}
+		e, br, err := p.readHeader(cur)
+		if err != nil {
+			return 0, nil, fmt.Errorf("pack entry at %d: %w", cur, err)
+		}
+		raw, err := p.inflate(br, e.size)
+		if err != nil {
+			return 0, nil, fmt.Errorf("pack entry at %d: %w", cur, err)
+		}
+		if e.typ != objOfsDelta && e.typ != objRefDelta {
+			typ, data = e.typ, raw
+			p.remember(cur, typ, data)
+			break
+		}
+		deltas = append(deltas, raw)
+		chain = append(chain, cur)
+		if e.typ == objOfsDelta {
+			cur = e.base
+			continue
+		}
+		if base, ok := p.find(e.baseHash); ok {
+			cur = base
+			continue
+		}
+		if p.external == nil {
+			return 0, nil, fmt.Errorf("delta base %s: %w", e.baseHash, ErrObjectNotFound)
+		}
+		if typ, data, err = p.external(e.baseHash); err != nil {
+			return 0, nil, fmt.Errorf("delta base %s: %w", e.baseHash, err)
+		}
+		break
+	}
+	for i := len(deltas) - 1; i >= 0; i-- {
+		var err error
+		if data, err = applyDelta(data, deltas[i]); err != nil {
+			return 0, nil, fmt.Errorf("pack entry at %d: %w", chain[i], err)
+		}
+		p.remember(chain[i], typ, data)
+	}
+	return typ, data, nil
+}
+
+// remember caches a resolved object. Blobs are not needed again while
+// walking history and are not cached.
+func (p *pack) remember(off int64, typ objectType, data []byte) {
+	if typ == objBlob {
+		return
+	}
+	if p.cache == nil || p.cacheBytes+len(data) > maxCachedBytes {
+		p.cache = make(map[int64]cachedObject)
+		p.cacheBytes = 0
+	}
+	p.cache[off] = cachedObject{typ, data}
+	p.cacheBytes += len(data)
+}
+
+// applyDelta applies a git delta to base.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	srcSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+	if srcSize != len(base) {
+		return nil, fmt.Errorf("delta base size %d, want %d", len(base), srcSize)
+	}
+	dstSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch {
+		case op&0x80 != 0:
+			var offset, size int
+			for i := range 4 {
+				if op&(1<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errors.New("truncated delta")
+					}
+					offset |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			for i := range 3 {
+				if op&(0x10<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errors.New("truncated delta")
+					}
+					size |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, errors.New("delta copy out of range")
+			}
+			out = append(out, base[offset:offset+size]...)
+		case op != 0:
+			if int(op) > len(delta) {
+				return nil, errors.New("truncated delta")
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errors.New("invalid delta opcode 0")
+		}
+	}
+	if len(out) != dstSize {
+		return nil, fmt.Errorf("delta produced %d bytes, want %d", len(out), dstSize)
+	}
+	return out, nil
+}
+
+func deltaSize(delta []byte) (int, []byte, error) {
+	size, shift := 0, 0
+	for i, b := range delta {
+		size |= int(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			return size, delta[i+1:], nil
+		}
+	}
+	return 0, nil, errors.New("truncated delta header")
+}
+
+// indexPack builds an in-memory pack over the raw bytes of a packfile, such
+// as one received from a fetch, by hashing every object.
+func indexPack(data []byte) (*pack, error) {
+	if len(data) < 12+20 {
+		return nil, errors.New("truncated packfile")
+	}
+	offsets := make(map[Hash]int64)
+	p := &pack{
+		r:    bytes.NewReader(data),
+		size: int64(len(data)),
+		find: func(h Hash) (int64, bool) {
+			off, ok := offsets[h]
+			return off, ok
+		},
+	}
+	if err := p.checkHeader(); err != nil {
+		return nil, err
+	}
+	n := int(binary.BigEndian.Uint32(data[8:12]))
+
+	// Find where every entry starts. The zlib reader stops exactly at the
+	// end of each entry because bytes.Reader is an io.ByteReader.
+	entries := make([]int64, 0, n)
+	r := bytes.NewReader(data)
+	pos := int64(12)
+	for range n {
+		entries = append(entries, pos)
+		e, _, err := p.readHeader(pos)
+		if err != nil {
+			return nil, fmt.Errorf("pack entry at %d: %w", pos, err)
+		}
+		hdrLen, err := entryHeaderLen(data[pos:], e.typ)
+		if err != nil {
+			return nil, err
+		}
+		r.Seek(pos+int64(hdrLen), io.SeekStart)
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("pack entry at %d: %w", pos, err)
+		}
+		if _, err := io.Copy(io.Discard, zr); err != nil {
+			return nil, fmt.Errorf("pack entry at %d: %w", pos, err)
+		}
+		zr.Close()
+		pos = int64(len(data)) - int64(r.Len())
+	}
+
+	// Hash every entry. REF_DELTA entries whose base has not been hashed
+	// yet are retried until no progress is made.
+	pending := entries
+	for len(pending) > 0 {
+		var next []int64
+		for _, off := range pending {
+			typ, content, err := p.readAt(off)
+			if errors.Is(err, ErrObjectNotFound) {
+				next = append(next, off)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			offsets[objectHash(typ, content)] = off
+		}
+		if len(next) == len(pending) {
+			return nil, fmt.Errorf("%d pack entries have missing delta bases", len(next))
+		}
+		pending = next
+	}
+	return p, nil
+}
+
+// entryHeaderLen returns the length of the entry header at the start of b.
+func entryHeaderLen(b []byte, typ objectType) (int, error) {
+	i := 0
+	for i < len(b) && b[i]&0x80 != 0 {
+		i++
+	}
+	i++
+	switch typ {
+	case objOfsDelta:
+		for i < len(b) && b[i]&0x80 != 0 {
+			i++
+		}
+		i++
+	case objRefDelta:
+		i += 20
+	}
+	if i > len(b) {
+		return 0, errors.New("truncated pack entry header")
+	}
+	return i, nil
+}
+
+// objectHash returns the name of an object.
+func objectHash(typ objectType, data []byte) Hash {
+	h := sha1.New()
+	h.Write([]byte(typ.String() + " " + strconv.Itoa(len(data)) + "\x00"))
+	h.Write(data)
+	var sum Hash
+	h.Sum(sum[:0])
+	return sum
+}
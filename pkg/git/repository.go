@@ -0,0 +1,361 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrObjectNotFound is returned for objects missing from the repository,
+// e.g. parents beyond the boundary of a shallow clone.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Repository reads objects and refs directly from a git repository,
+// without the git binary. It understands loose objects, version 2 pack
+// indexes, alternates, packed refs and the .git files of submodules and
+// worktrees. A Repository is not safe for concurrent use.
+type Repository struct {
+	// gitDir holds HEAD; commonDir holds objects, refs and config. They
+	// differ for worktrees.
+	gitDir, commonDir string
+	objectDirs        []string
+	packs             []*pack
+	// head is set for repositories that exist only in memory.
+	head *Hash
+}
+
+// Open opens the repository whose working tree (or bare git directory) is
+// at path.
+func Open(path string) (*Repository, error) {
+	gitDir, err := findGitDir(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{gitDir: gitDir, commonDir: gitDir}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		r.commonDir = resolvePath(gitDir, strings.TrimSpace(string(common)))
+	}
+	if err := r.addObjectDir(filepath.Join(r.commonDir, "objects"), 0); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close releases the open packfiles.
+func (r *Repository) Close() error {
+	var errs []error
+	for _, p := range r.packs {
+		errs = append(errs, p.Close())
+	}
+	r.packs = nil
+	return errors.Join(errs...)
+}
+
+// findGitDir returns the git directory of the repository at path: path
+// itself if bare, path/.git, or the target of a "gitdir:" file.
+func findGitDir(path string) (string, error) {
+	if isGitDir(path) {
+		return path, nil
+	}
+	dotGit := filepath.Join(path, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %s", path)
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("malformed %s", dotGit)
+	}
+	return resolvePath(path, strings.TrimSpace(target)), nil
+}
+
+func isGitDir(path string) bool {
+	_, err1 := os.Stat(filepath.Join(path, "HEAD"))
+	_, err2 := os.Stat(filepath.Join(path, "objects"))
+	return err1 == nil && err2 == nil
+}
+
+func resolvePath(base, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(base, p)
+}
+
+// addObjectDir registers an objects directory, its packs and, recursively,
+// its alternates.
+func (r *Repository) addObjectDir(dir string, depth int) error {
+	if depth > 5 {
+		return fmt.Errorf("%s: alternates nested too deeply", dir)
+	}
+	r.objectDirs = append(r.objectDirs, dir)
+	idxs, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idx := range idxs {
+		p, err := openPack(strings.TrimSuffix(idx, ".idx"), r.readObject)
+		if err != nil {
+			return err
+		}
+		r.packs = append(r.packs, p)
+	}
+	alternates, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(alternates), "\n") {
+		if line = strings.TrimSpace(line); line == "" || line[0] == '#' {
+			continue
+		}
+		if err := r.addObjectDir(resolvePath(dir, line), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readObject returns the type and content of object h.
+func (r *Repository) readObject(h Hash) (objectType, []byte, error) {
+	for _, p := range r.packs {
+		if p.has(h) {
+			return p.read(h)
+		}
+	}
+	name := h.String()
+	for _, dir := range r.objectDirs {
+		f, err := os.Open(filepath.Join(dir, name[:2], name[2:]))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		defer f.Close()
+		return readLooseObject(h, f)
+	}
+	return 0, nil, fmt.Errorf("object %s: %w", h, ErrObjectNotFound)
+}
+
+// readLooseObject decodes a zlib-compressed "<type> <size>\0<content>"
+// object file.
+func readLooseObject(h Hash, f *os.File) (objectType, []byte, error) {
+	zr, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return 0, nil, fmt.Errorf("object %s: %w", h, err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("object %s: %w", h, err)
+	}
+	header, data, ok := bytes.Cut(raw, []byte{0})
+	typeName, size, _ := strings.Cut(string(header), " ")
+	if !ok || size != strconv.Itoa(len(data)) {
+		return 0, nil, fmt.Errorf("object %s: malformed header", h)
+	}
+	typ, err := parseObjectType(typeName)
+	if err != nil {
+		return 0, nil, fmt.Errorf("object %s: %w", h, err)
+	}
+	return typ, data, nil
+}
+
+// Commit reads and parses commit h.
+func (r *Repository) Commit(h Hash) (*Commit, error) {
+	typ, data, err := r.readObject(h)
+	if err != nil {
+		return nil, err
+	}
+	if typ != objCommit {
+		return nil, fmt.Errorf("object %s is a %s, not a commit", h, typ)
+	}
+	return parseCommit(h, data)
+}
+
+// Tree reads and parses tree h.
+func (r *Repository) Tree(h Hash) ([]TreeEntry, error) {
+	typ, data, err := r.readObject(h)
+	if err != nil {
+		return nil, err
+	}
+	if typ != objTree {
+		return nil, fmt.Errorf("object %s is a %s, not a tree", h, typ)
+	}
+	return parseTree(h, data)
+}
+
+// Head resolves HEAD to a commit.
+func (r *Repository) Head() (Hash, error) {
+	if r.head != nil {
+		return *r.head, nil
+	}
+	return r.ResolveRef("HEAD")
+}
+
+// ResolveRef resolves a ref name such as HEAD or refs/heads/main, following
+// symbolic refs and peeling annotated tags.
+func (r *Repository) ResolveRef(name string) (Hash, error) {
+	for range 10 {
+		value, err := r.readRef(name)
+		if err != nil {
+			return Hash{}, err
+		}
+		if target, ok := strings.CutPrefix(value, "ref: "); ok {
+			name = strings.TrimSpace(target)
+			continue
+		}
+		h, err := ParseHash(value)
+		if err != nil {
+			return Hash{}, fmt.Errorf("ref %s: %w", name, err)
+		}
+		return r.peel(h)
+	}
+	return Hash{}, fmt.Errorf("ref %s: symbolic refs nested too deeply", name)
+}
+
+// readRef returns the raw value of a loose or packed ref.
+func (r *Repository) readRef(name string) (string, error) {
+	dir := r.commonDir
+	if name == "HEAD" {
+		dir = r.gitDir
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	packed, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	for _, line := range strings.Split(string(packed), "\n") {
+		if hash, ref, ok := strings.Cut(line, " "); ok && ref == name {
+			return hash, nil
+		}
+	}
+	return "", fmt.Errorf("ref %s not found", name)
+}
+
+// peel follows annotated tags to the object they point at.
+func (r *Repository) peel(h Hash) (Hash, error) {
+	for range 10 {
+		typ, data, err := r.readObject(h)
+		if err != nil {
+			return Hash{}, err
+		}
+		if typ != objTag {
+			return h, nil
+		}
+		object, _, _ := bytes.Cut(data, []byte("\n"))
+		target, ok := bytes.CutPrefix(object, []byte("object "))
+		if !ok {
+			return Hash{}, fmt.Errorf("tag %s: malformed", h)
+		}
+		next, err := ParseHash(string(target))
+		if err != nil {
+			return Hash{}, fmt.Errorf("tag %s: %w", h, err)
+		}
+		h = next
+	}
+	return Hash{}, fmt.Errorf("tag %s: nested too deeply", h)
+}
+
+// RemoteURL returns the url of a remote from the repository config.
+func (r *Repository) RemoteURL(remote string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "config"))
+	if err != nil {
+		return "", err
+	}
+	section := fmt.Sprintf(`[remote "%s"]`, remote)
+	in := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			in = line == section
+			continue
+		}
+		if !in {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			value = strings.TrimSpace(value)
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("remote %s has no url", remote)
+}
+
+// WalkFiles calls fn with the slash-separated path of every non-directory
+// entry under dir ("" for the root) in the tree of commit. It returns an
+// error wrapping fs.ErrNotExist if dir does not exist.
+func (r *Repository) WalkFiles(commit Hash, dir string, fn func(path string, e TreeEntry) error) error {
+	c, err := r.Commit(commit)
+	if err != nil {
+		return err
+	}
+	tree := c.Tree
+	if dir != "" {
+		for _, name := range strings.Split(dir, "/") {
+			entries, err := r.Tree(tree)
+			if err != nil {
+				return err
+			}
+			found := false
+			for _, e := range entries {
+				if e.Name == name && e.IsTree() {
+					tree, found = e.Hash, true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s: %w", dir, fs.ErrNotExist)
+			}
+		}
+	}
+	return r.walkTree(tree, dir, fn)
+}
+
+func (r *Repository) walkTree(tree Hash, dir string, fn func(string, TreeEntry) error) error {
+	entries, err := r.Tree(tree)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		p := e.Name
+		if dir != "" {
+			p = path.Join(dir, e.Name)
+		}
+		if e.IsTree() {
+			err = r.walkTree(e.Hash, p, fn)
+		} else {
+			err = fn(p, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
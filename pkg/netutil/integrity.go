@@ -0,0 +1,229 @@
+package netutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// downloadTimeout bounds a single archive download. Source archives can be
+// hundreds of MB, so this is considerably more generous than the HEAD check.
+const downloadTimeout = 10 * time.Minute
+
+// IntegrityStatus represents the outcome of downloading a URL and comparing
+// its bytes against an expected SRI integrity string.
+type IntegrityStatus struct {
+	URLStatus
+	Expected string // expected SRI integrity (e.g. "sha256-<base64>")
+	Actual   string // SRI integrity computed from the downloaded bytes (empty if not downloaded)
+}
+
+// Verified returns true if the URL was downloaded and its bytes match the
+// expected integrity.
+func (s IntegrityStatus) Verified() bool {
+	return s.Exists() && s.Actual != "" && s.Actual == s.Expected
+}
+
+// Mismatch returns true if the URL was downloaded but its bytes do not match
+// the expected integrity. Download failures are not mismatches.
+func (s IntegrityStatus) Mismatch() bool {
+	return s.Exists() && s.Actual != "" && s.Actual != s.Expected
+}
+
+// DownloadFailed returns true if the URL could not be downloaded, so its
+// integrity is unknown.
+func (s IntegrityStatus) DownloadFailed() bool {
+	return s.Actual == ""
+}
+
+// integrityStatusJSON is the encoded form of an IntegrityStatus.
+type integrityStatusJSON struct {
+	URL      string `json:"url"`
+	Code     int    `json:"code"`
+	Message  string `json:"message,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// FormatIntegrityStatus encodes the verification result of a URL as JSON,
+// suitable for a string_list rule attribute. The encoding is stable, so
+// regenerating BUILD files does not churn.
+func FormatIntegrityStatus(url string, s IntegrityStatus) string {
+	data, _ := json.Marshal(integrityStatusJSON{
+		URL:      url,
+		Code:     s.Code,
+		Message:  s.Message,
+		Expected: s.Expected,
+		Actual:   s.Actual,
+	})
+	return string(data)
+}
+
+// ParseIntegrityStatus decodes a value produced by FormatIntegrityStatus and
+// returns the URL and its verification result.
+func ParseIntegrityStatus(value string) (string, IntegrityStatus, error) {
+	var v integrityStatusJSON
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return "", IntegrityStatus{}, fmt.Errorf("malformed integrity status %q: %w", value, err)
+	}
+	if v.URL == "" {
+		return "", IntegrityStatus{}, fmt.Errorf("malformed integrity status %q: missing url", value)
+	}
+	return v.URL, IntegrityStatus{
+		URLStatus: URLStatus{Code: v.Code, Message: v.Message},
+		Expected:  v.Expected,
+		Actual:    v.Actual,
+	}, nil
+}
+
+// NewIntegrityHash parses an SRI integrity string and returns a hash for its
+// algorithm, along with the algorithm name (sha256, sha384 or sha512).
+func NewIntegrityHash(integrity string) (hash.Hash, string, error) {
+	algo, digest, ok := strings.Cut(integrity, "-")
+	if !ok || digest == "" {
+		return nil, "", fmt.Errorf("malformed integrity %q", integrity)
+	}
+	switch algo {
+	case "sha256":
+		return sha256.New(), algo, nil
+	case "sha384":
+		return sha512.New384(), algo, nil
+	case "sha512":
+		return sha512.New(), algo, nil
+	}
+	return nil, "", fmt.Errorf("unsupported integrity algorithm %q", algo)
+}
+
+// ComputeIntegrity reads r to completion and returns the SRI integrity string
+// of its content using the same algorithm as the given integrity.
+func ComputeIntegrity(integrity string, r io.Reader) (string, error) {
+	h, algo, err := NewIntegrityHash(integrity)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return algo + "-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyURL downloads the URL and compares its content against the expected
+// SRI integrity. The returned status carries the HTTP status code and message
+// of the GET request; Actual is only set when the download completed.
+func VerifyURL(url, integrity string) IntegrityStatus {
+	status := IntegrityStatus{Expected: integrity}
+
+	if _, _, err := NewIntegrityHash(integrity); err != nil {
+		status.Message = err.Error()
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := newHTTPClient(downloadTimeout).Do(req)
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	defer resp.Body.Close()
+
+	status.Code = resp.StatusCode
+	status.Message = resp.Status
+	if !status.Exists() {
+		return status
+	}
+
+	actual, err := ComputeIntegrity(integrity, resp.Body)
+	if err != nil {
+		// A truncated download says nothing about the upstream bytes; report
+		// it as a failed request rather than a mismatch.
+		status.Code = 0
+		status.Message = err.Error()
+		return status
+	}
+	status.Actual = actual
+
+	return status
+}
+
+// VerifyURLsParallel downloads a list of URLs with bounded concurrency and
+// verifies each against its expected integrity. The getURL function extracts
+// the URL and expected integrity from each item. The onResult callback is
+// called for each completed verification (optional, can be nil). Displays
+// progress with a visual progress bar.
+func VerifyURLsParallel[T any](desc string, items []T, concurrency int, getURL func(T) (string, string), onResult func(T, IntegrityStatus)) []IntegrityStatus {
+	if len(items) == 0 {
+		return nil
+	}
+
+	total := len(items)
+	results := make([]IntegrityStatus, total)
+
+	if !Interactive() {
+		log.Printf("%s (%d URLs)", desc, total)
+	}
+	bar := NewProgressBar(desc, total)
+
+	type job struct {
+		index int
+		item  T
+	}
+	type result struct {
+		index  int
+		item   T
+		status IntegrityStatus
+	}
+
+	jobs := make(chan job, total)
+	resultChan := make(chan result, total)
+
+	var wg sync.WaitGroup
+	for range max(1, min(concurrency, total)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				url, integrity := getURL(j.item)
+				resultChan <- result{index: j.index, item: j.item, status: VerifyURL(url, integrity)}
+			}
+		}()
+	}
+
+	for i, item := range items {
+		jobs <- job{index: i, item: item}
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	for r := range resultChan {
+		results[r.index] = r.status
+		if onResult != nil {
+			onResult(r.item, r.status)
+		}
+		bar.Add(1)
+	}
+
+	return results
+}
@@ -0,0 +1,166 @@
+package netutil
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testArchive = "not really a tarball"
+
+func sha256Integrity(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/archive.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testArchive))
+	})
+	mux.HandleFunc("/retagged.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testArchive + " (retagged)"))
+	})
+	mux.HandleFunc("/redirect.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/archive.tar.gz", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestComputeIntegrity(t *testing.T) {
+	sum384 := sha512.Sum384([]byte(testArchive))
+	sum512 := sha512.Sum512([]byte(testArchive))
+
+	for _, tc := range []struct {
+		name      string
+		integrity string
+		want      string
+		wantErr   bool
+	}{
+		{name: "sha256", integrity: "sha256-AAAA", want: sha256Integrity(testArchive)},
+		{name: "sha384", integrity: "sha384-AAAA", want: "sha384-" + base64.StdEncoding.EncodeToString(sum384[:])},
+		{name: "sha512", integrity: "sha512-AAAA", want: "sha512-" + base64.StdEncoding.EncodeToString(sum512[:])},
+		{name: "unsupported", integrity: "md5-AAAA", wantErr: true},
+		{name: "malformed", integrity: "sha256", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ComputeIntegrity(tc.integrity, strings.NewReader(testArchive))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ComputeIntegrity(%q) expected error, got %q", tc.integrity, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("ComputeIntegrity(%q) = %q; want %q", tc.integrity, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyURL(t *testing.T) {
+	srv := newTestServer(t)
+	integrity := sha256Integrity(testArchive)
+
+	for _, tc := range []struct {
+		name         string
+		path         string
+		wantCode     int
+		wantVerified bool
+		wantMismatch bool
+	}{
+		{name: "match", path: "/archive.tar.gz", wantCode: http.StatusOK, wantVerified: true},
+		{name: "redirect", path: "/redirect.tar.gz", wantCode: http.StatusOK, wantVerified: true},
+		{name: "mismatch", path: "/retagged.tar.gz", wantCode: http.StatusOK, wantMismatch: true},
+		{name: "not found", path: "/missing.tar.gz", wantCode: http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status := VerifyURL(srv.URL+tc.path, integrity)
+			if status.Code != tc.wantCode {
+				t.Errorf("Code = %d; want %d (%s)", status.Code, tc.wantCode, status.Message)
+			}
+			if status.Verified() != tc.wantVerified {
+				t.Errorf("Verified() = %v; want %v", status.Verified(), tc.wantVerified)
+			}
+			if status.Mismatch() != tc.wantMismatch {
+				t.Errorf("Mismatch() = %v; want %v", status.Mismatch(), tc.wantMismatch)
+			}
+			if status.Expected != integrity {
+				t.Errorf("Expected = %q; want %q", status.Expected, integrity)
+			}
+		})
+	}
+}
+
+func TestVerifyURLsParallel(t *testing.T) {
+	srv := newTestServer(t)
+	integrity := sha256Integrity(testArchive)
+
+	urls := []string{
+		srv.URL + "/archive.tar.gz",
+		srv.URL + "/retagged.tar.gz",
+		srv.URL + "/missing.tar.gz",
+	}
+
+	mismatched := make(map[string]bool)
+	results := VerifyURLsParallel("Verifying", urls, 2,
+		func(u string) (string, string) { return u, integrity },
+		func(u string, status IntegrityStatus) {
+			if status.Mismatch() {
+				mismatched[u] = true
+			}
+		})
+
+	if len(results) != len(urls) {
+		t.Fatalf("got %d results; want %d", len(results), len(urls))
+	}
+	if !results[0].Verified() {
+		t.Errorf("results[0] not verified: %+v", results[0])
+	}
+	if !results[1].Mismatch() {
+		t.Errorf("results[1] not a mismatch: %+v", results[1])
+	}
+	if results[2].Code != http.StatusNotFound {
+		t.Errorf("results[2].Code = %d; want 404", results[2].Code)
+	}
+	if len(mismatched) != 1 || !mismatched[urls[1]] {
+		t.Errorf("onResult mismatches = %v; want only %s", mismatched, urls[1])
+	}
+}
+
+func TestFormatParseIntegrityStatus(t *testing.T) {
+	for name, want := range map[string]IntegrityStatus{
+		"verified":  {URLStatus: URLStatus{Code: 200, Message: "200 OK"}, Expected: "sha256-a", Actual: "sha256-a"},
+		"mismatch":  {URLStatus: URLStatus{Code: 200, Message: "200 OK"}, Expected: "sha256-a", Actual: "sha256-b"},
+		"not found": {URLStatus: URLStatus{Code: 404, Message: "404 Not Found"}, Expected: "sha256-a"},
+		"network":   {URLStatus: URLStatus{Message: "dial tcp: connection refused"}, Expected: "sha256-a"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			url := "https://example.com/a.tar.gz?x=1,2|3"
+			encoded := FormatIntegrityStatus(url, want)
+			gotURL, got, err := ParseIntegrityStatus(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotURL != url || got != want {
+				t.Errorf("ParseIntegrityStatus(%s) = %q, %+v; want %q, %+v", encoded, gotURL, got, url, want)
+			}
+			if got.DownloadFailed() == (got.Verified() || got.Mismatch()) {
+				t.Errorf("DownloadFailed() = %v, Verified() = %v, Mismatch() = %v", got.DownloadFailed(), got.Verified(), got.Mismatch())
+			}
+		})
+	}
+	if _, _, err := ParseIntegrityStatus(`{"code":200}`); err == nil {
+		t.Error("ParseIntegrityStatus() without url: want error")
+	}
+}
@@ -10,7 +10,12 @@ import (
 	"time"
 )
 
-const connectionAvailableDuration = 250 * time.Millisecond
+const (
+	connectionAvailableDuration = 250 * time.Millisecond
+	// userAgent is sent with every request to avoid being blocked by some
+	// servers.
+	userAgent = "Bazel-Central-Registry-Gazelle/1.0"
+)
 
 // WaitForConnectionAvailable pings a tcp connection every 250 milliseconds
 // until it connects and returns true.  If it fails to connect by the timeout
@@ -70,11 +75,22 @@ func CheckURL(url string) URLStatus {
 		return URLStatus{Code: 0, Message: err.Error()}
 	}
 
-	// Add a User-Agent to avoid being blocked by some servers
-	req.Header.Set("User-Agent", "Bazel-Central-Registry-Gazelle/1.0")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := newHTTPClient(10 * time.Second).Do(req)
+	if err != nil {
+		return URLStatus{Code: 0, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	return URLStatus{Code: resp.StatusCode, Message: resp.Status}
+}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// newHTTPClient returns a client with the given timeout that follows up to 10
+// redirects.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			// Allow up to 10 redirects
 			if len(via) >= 10 {
@@ -83,14 +99,6 @@ func CheckURL(url string) URLStatus {
 			return nil
 		},
 	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return URLStatus{Code: 0, Message: err.Error()}
-	}
-	defer resp.Body.Close()
-
-	return URLStatus{Code: resp.StatusCode, Message: resp.Status}
 }
 
 // URLExists checks if a URL is accessible by performing a HEAD request
@@ -0,0 +1,273 @@
+// Package pagemeta generates the <head> metadata of registry pages: the
+// title, description, canonical URL, OpenGraph tags and schema.org JSON-LD
+// that search engines and link previews read without running the SPA.
+//
+// The metadata is derived from the Registry proto alone, so it can be
+// injected into static HTML at release assembly time. Injected elements
+// carry a data-pagemeta attribute; Inject removes them before adding new
+// ones, so a page rendered from an already-annotated template ends up with
+// exactly one set.
+package pagemeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+// SiteName is the name pages are published under.
+const SiteName = "Bazel Central Registry"
+
+// siteDescription describes the registry as a whole.
+const siteDescription = "Browse, search and read the documentation of the Bazel modules published to the Bazel Central Registry."
+
+// Page is the metadata of one page.
+type Page struct {
+	Title       string
+	Description string
+	// URL is the canonical URL. It is empty for documents served at more
+	// than one path, such as the SPA fallback index.html.
+	URL string
+	// Type is the og:type.
+	Type  string
+	Image string
+	// StructuredData is encoded as a JSON-LD script, if non-nil.
+	StructuredData map[string]any
+}
+
+// Home returns the metadata of the registry home page. The canonical URL is
+// left empty when canonical is false.
+func Home(registry *bzpb.Registry, canonical bool) *Page {
+	baseURL := strings.TrimRight(registry.RegistryUrl, "/")
+	page := &Page{
+		Title:       SiteName,
+		Description: siteDescription,
+		Type:        "website",
+		StructuredData: map[string]any{
+			"@context":    "https://schema.org",
+			"@type":       "WebSite",
+			"name":        SiteName,
+			"description": siteDescription,
+		},
+	}
+	if canonical && baseURL != "" {
+		page.URL = baseURL + "/"
+		page.StructuredData["url"] = page.URL
+	}
+	return page
+}
+
+// ModuleVersion returns the metadata of a module version page. url is its
+// canonical URL and licenses the SPDX identifiers of the version, if known.
+func ModuleVersion(module *bzpb.Module, version *bzpb.ModuleVersion, url string, licenses []string) *Page {
+	md := version.RepositoryMetadata
+	if md == nil {
+		md = module.RepositoryMetadata
+	}
+	description := md.GetDescription()
+	if description == "" {
+		description = fmt.Sprintf("%s %s is a Bazel module in the %s.", module.Name, version.Version, SiteName)
+	}
+
+	code := map[string]any{
+		"@context":    "https://schema.org",
+		"@type":       "SoftwareSourceCode",
+		"name":        module.Name,
+		"version":     version.Version,
+		"description": description,
+		"url":         url,
+	}
+	if repo := repositoryURL(md); repo != "" {
+		code["codeRepository"] = repo
+	}
+	if lang := md.GetPrimaryLanguage(); lang != "" {
+		code["programmingLanguage"] = lang
+	}
+	switch len(licenses) {
+	case 0:
+	case 1:
+		code["license"] = spdxURL(licenses[0])
+	default:
+		urls := make([]string, len(licenses))
+		for i, id := range licenses {
+			urls[i] = spdxURL(id)
+		}
+		code["license"] = urls
+	}
+	if date := version.GetCommit().GetDate(); date != "" {
+		code["dateModified"] = date
+	}
+
+	return &Page{
+		Title:          fmt.Sprintf("%s %s · %s", module.Name, version.Version, SiteName),
+		Description:    description,
+		URL:            url,
+		Type:           "website",
+		Image:          ownerAvatarURL(md),
+		StructuredData: code,
+	}
+}
+
+// ForPath returns the metadata of the document at a release tarball path,
+// or nil if the path is not a page with metadata of its own. Recognized
+// paths are index.html (the SPA shell, without a canonical URL),
+// home/index.html, modules/NAME/index.html (the latest version) and
+// modules/NAME/VERSION/index.html. licenses maps NAME@VERSION to SPDX
+// identifiers and may be nil.
+func ForPath(registry *bzpb.Registry, licenses map[string][]string, path string) *Page {
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "index.html"), "/")
+	baseURL := strings.TrimRight(registry.RegistryUrl, "/")
+
+	switch path {
+	case "":
+		return Home(registry, false)
+	case "home":
+		return Home(registry, true)
+	}
+
+	parts := strings.Split(path, "/")
+	if parts[0] != "modules" || len(parts) < 2 || len(parts) > 3 {
+		return nil
+	}
+	module := findModule(registry, parts[1])
+	if module == nil {
+		return nil
+	}
+	var version *bzpb.ModuleVersion
+	if len(parts) == 3 {
+		version = findVersion(module, parts[2])
+	} else {
+		version = latestVersion(module)
+	}
+	if version == nil {
+		return nil
+	}
+	return ModuleVersion(module, version, baseURL+"/"+path, licenses[module.Name+"@"+version.Version])
+}
+
+func findModule(registry *bzpb.Registry, name string) *bzpb.Module {
+	for _, module := range registry.Modules {
+		if module.Name == name {
+			return module
+		}
+	}
+	return nil
+}
+
+func findVersion(module *bzpb.Module, version string) *bzpb.ModuleVersion {
+	for _, mv := range module.Versions {
+		if mv.Version == version {
+			return mv
+		}
+	}
+	return nil
+}
+
+// latestVersion returns the version flagged as latest, falling back to the
+// first (newest) version.
+func latestVersion(module *bzpb.Module) *bzpb.ModuleVersion {
+	for _, mv := range module.Versions {
+		if mv.IsLatestVersion {
+			return mv
+		}
+	}
+	if len(module.Versions) > 0 {
+		return module.Versions[0]
+	}
+	return nil
+}
+
+func repositoryURL(md *bzpb.RepositoryMetadata) string {
+	if md == nil || md.Organization == "" || md.Name == "" {
+		return ""
+	}
+	switch md.Type {
+	case bzpb.RepositoryType_GITHUB:
+		return fmt.Sprintf("https://github.com/%s/%s", md.Organization, md.Name)
+	case bzpb.RepositoryType_GITLAB:
+		return fmt.Sprintf("https://gitlab.com/%s/%s", md.Organization, md.Name)
+	}
+	return ""
+}
+
+// ownerAvatarURL returns the avatar of a GitHub repository owner, the same
+// image module pages show.
+func ownerAvatarURL(md *bzpb.RepositoryMetadata) string {
+	if md == nil || md.Type != bzpb.RepositoryType_GITHUB || md.Organization == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://github.com/%s.png", md.Organization)
+}
+
+func spdxURL(id string) string {
+	return "https://spdx.org/licenses/" + id + ".html"
+}
+
+// HeadHTML renders the page's <head> elements, excluding <title>.
+func (p *Page) HeadHTML() ([]byte, error) {
+	var b bytes.Buffer
+	meta := func(attr, key, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "<meta %s=\"%s\" content=\"%s\" data-pagemeta>\n", attr, key, html.EscapeString(value))
+		}
+	}
+	meta("name", "description", p.Description)
+	if p.URL != "" {
+		fmt.Fprintf(&b, "<link rel=\"canonical\" href=\"%s\" data-pagemeta>\n", html.EscapeString(p.URL))
+	}
+	meta("property", "og:site_name", SiteName)
+	meta("property", "og:type", p.Type)
+	meta("property", "og:title", p.Title)
+	meta("property", "og:description", p.Description)
+	meta("property", "og:url", p.URL)
+	meta("property", "og:image", p.Image)
+	meta("name", "twitter:card", "summary")
+	if p.StructuredData != nil {
+		// json.Marshal escapes <, > and &, so the data cannot close the
+		// script element early.
+		data, err := json.Marshal(p.StructuredData)
+		if err != nil {
+			return nil, fmt.Errorf("encode JSON-LD: %w", err)
+		}
+		fmt.Fprintf(&b, "<script type=\"application/ld+json\" data-pagemeta>%s</script>\n", data)
+	}
+	return b.Bytes(), nil
+}
+
+var (
+	titleRE    = regexp.MustCompile(`(?is)<title>.*?</title>`)
+	injectedRE = regexp.MustCompile(`(?is)<script\b[^>]*\sdata-pagemeta\b[^>]*>.*?</script>\s*|<(?:meta|link)\b[^>]*\sdata-pagemeta\b[^>]*>\s*`)
+	headEndRE  = regexp.MustCompile(`(?i)</head>`)
+)
+
+// Inject sets the document title and replaces any previously injected
+// metadata with the page's. Documents without a </head> are returned
+// unchanged.
+func Inject(doc []byte, p *Page) ([]byte, error) {
+	loc := headEndRE.FindIndex(doc)
+	if loc == nil {
+		return doc, nil
+	}
+	meta, err := p.HeadHTML()
+	if err != nil {
+		return nil, err
+	}
+
+	head := injectedRE.ReplaceAll(doc[:loc[0]], nil)
+	var out bytes.Buffer
+	if m := titleRE.FindIndex(head); m != nil && p.Title != "" {
+		out.Write(head[:m[0]])
+		out.WriteString("<title>" + html.EscapeString(p.Title) + "</title>")
+		out.Write(head[m[1]:])
+	} else {
+		out.Write(head)
+	}
+	out.Write(meta)
+	out.Write(doc[loc[0]:])
+	return out.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+package pagemeta
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+func newTestRegistry() *bzpb.Registry {
+	return &bzpb.Registry{
+		RegistryUrl: "https://registry.example.com/",
+		Modules: []*bzpb.Module{{
+			Name: "rules_foo",
+			RepositoryMetadata: &bzpb.RepositoryMetadata{
+				Type:            bzpb.RepositoryType_GITHUB,
+				Organization:    "foo-org",
+				Name:            "rules_foo",
+				Description:     `Rules for "foo" <fast>`,
+				PrimaryLanguage: "Starlark",
+			},
+			Versions: []*bzpb.ModuleVersion{
+				{Version: "2.0", Commit: &bzpb.ModuleCommit{Date: "2024-02-10T08:00:00Z"}},
+				{Version: "1.0", IsLatestVersion: true},
+			},
+		}},
+	}
+}
+
+func jsonLD(t *testing.T, doc string) map[string]any {
+	t.Helper()
+	m := regexp.MustCompile(`<script type="application/ld\+json" data-pagemeta>(.*?)</script>`).FindStringSubmatch(doc)
+	if m == nil {
+		t.Fatalf("no JSON-LD in\n%s", doc)
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(m[1]), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestForPath(t *testing.T) {
+	registry := newTestRegistry()
+	licenses := map[string][]string{"rules_foo@2.0": {"Apache-2.0"}}
+
+	page := ForPath(registry, licenses, "modules/rules_foo/2.0/index.html")
+	if page == nil {
+		t.Fatal("no metadata for a module version page")
+	}
+	if page.URL != "https://registry.example.com/modules/rules_foo/2.0" {
+		t.Errorf("url = %q", page.URL)
+	}
+	if page.Image != "https://github.com/foo-org.png" {
+		t.Errorf("image = %q", page.Image)
+	}
+	code := page.StructuredData
+	if code["@type"] != "SoftwareSourceCode" || code["version"] != "2.0" ||
+		code["codeRepository"] != "https://github.com/foo-org/rules_foo" ||
+		code["license"] != "https://spdx.org/licenses/Apache-2.0.html" {
+		t.Errorf("structured data = %v", code)
+	}
+
+	if page := ForPath(registry, nil, "modules/rules_foo/index.html"); page == nil || page.StructuredData["version"] != "1.0" {
+		t.Errorf("module page should describe the latest version, got %+v", page)
+	}
+	if page := ForPath(registry, nil, "index.html"); page == nil || page.URL != "" {
+		t.Errorf("SPA shell must not carry a canonical URL, got %+v", page)
+	}
+	if page := ForPath(registry, nil, "home/index.html"); page == nil || page.URL != "https://registry.example.com/" {
+		t.Errorf("home page = %+v", page)
+	}
+	for _, path := range []string{"modules/missing/index.html", "modules/rules_foo/9.9/index.html", "bazel/index.html"} {
+		if page := ForPath(registry, nil, path); page != nil {
+			t.Errorf("%s: unexpected metadata %+v", path, page)
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	registry := newTestRegistry()
+	doc := []byte("<html><head><title>Bazel Central Registry</title><meta charset=\"utf-8\"></head><body></body></html>")
+
+	shell, err := Inject(doc, ForPath(registry, nil, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A snapshot of the shell keeps its metadata (Chrome serializes boolean
+	// attributes with an empty value) and is then annotated again.
+	snapshot := strings.ReplaceAll(string(shell), "data-pagemeta>", `data-pagemeta="">`)
+	got, err := Inject([]byte(snapshot), ForPath(registry, nil, "modules/rules_foo/2.0/index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(got)
+
+	if n := strings.Count(out, `name="description"`); n != 1 {
+		t.Errorf("got %d descriptions, want 1:\n%s", n, out)
+	}
+	if n := strings.Count(out, "application/ld+json"); n != 1 {
+		t.Errorf("got %d JSON-LD scripts, want 1:\n%s", n, out)
+	}
+	for _, want := range []string{
+		"<title>rules_foo 2.0 · Bazel Central Registry</title>",
+		`<meta name="description" content="Rules for &#34;foo&#34; &lt;fast&gt;" data-pagemeta>`,
+		`<link rel="canonical" href="https://registry.example.com/modules/rules_foo/2.0" data-pagemeta>`,
+		`<meta property="og:image" content="https://github.com/foo-org.png" data-pagemeta>`,
+		`<meta charset="utf-8">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<fast>") {
+		t.Errorf("unescaped description in\n%s", out)
+	}
+	if data := jsonLD(t, out); data["description"] != `Rules for "foo" <fast>` {
+		t.Errorf("JSON-LD description = %v", data["description"])
+	}
+}
@@ -0,0 +1,227 @@
+// Package patchfile parses the unified diffs listed in the `patches` section
+// of a source.json file and summarizes what they change upstream.
+package patchfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+const devNull = "/dev/null"
+
+// ReadFile reads and parses a patch file into a PatchStats protobuf. The
+// strip argument is the source.json patch_strip value.
+func ReadFile(filename string, strip int) (*bzpb.PatchStats, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading patch file: %v", err)
+	}
+	stats, err := Parse(data, strip)
+	if err != nil {
+		return nil, fmt.Errorf("parsing patch file %s: %w", filename, err)
+	}
+	stats.Filename = filepath.Base(filename)
+	return stats, nil
+}
+
+// Parse parses a unified diff (plain or git-style) and returns the set of
+// files it touches along with the total number of lines added and removed.
+func Parse(data []byte, strip int) (*bzpb.PatchStats, error) {
+	stats := &bzpb.PatchStats{}
+	files := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		lineNo    int
+		oldPath   string
+		gitPath   string // b/ path of the current "diff --git" header, until a ---/+++ pair is seen
+		oldRemain int    // lines of the current hunk remaining on the old side
+		newRemain int    // lines of the current hunk remaining on the new side
+	)
+
+	flushGitPath := func() {
+		if gitPath != "" {
+			files[stripPath(gitPath, strip)] = true
+			gitPath = ""
+		}
+	}
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		// Inside a hunk, every line is content until both sides are consumed.
+		if oldRemain > 0 || newRemain > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				stats.LinesAdded++
+				newRemain--
+			case strings.HasPrefix(line, "-"):
+				stats.LinesRemoved++
+				oldRemain--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				// context line (a leading space, or an empty line from
+				// editors that strip trailing whitespace)
+				oldRemain--
+				newRemain--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushGitPath()
+			if _, b, ok := strings.Cut(line[len("diff --git "):], " b/"); ok {
+				gitPath = "b/" + b
+			}
+		case strings.HasPrefix(line, "--- "):
+			oldPath = diffPath(line[len("--- "):])
+		case strings.HasPrefix(line, "+++ "):
+			newPath := diffPath(line[len("+++ "):])
+			name := newPath
+			if name == devNull {
+				name = oldPath
+			}
+			if name == "" || name == devNull {
+				return nil, fmt.Errorf("line %d: file header without a path", lineNo)
+			}
+			files[stripPath(name, strip)] = true
+			gitPath = ""
+		case strings.HasPrefix(line, "@@ "):
+			var err error
+			oldRemain, newRemain, err = parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// A trailing git header without ---/+++ (rename, mode change, binary).
+	flushGitPath()
+
+	stats.Files = slices.Sorted(maps.Keys(files))
+	return stats, nil
+}
+
+// diffPath extracts the filename from a ---/+++ header value, dropping the
+// optional tab-separated timestamp.
+func diffPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	return s
+}
+
+// stripPath removes the given number of leading path components, like
+// `patch -pN`. Paths with fewer components are returned unchanged.
+func stripPath(p string, strip int) string {
+	parts := strings.Split(p, "/")
+	if strip > 0 && strip < len(parts) {
+		parts = parts[strip:]
+	}
+	return path.Clean(strings.Join(parts, "/"))
+}
+
+// parseHunkHeader parses "@@ -l,s +l,s @@" and returns the old and new line
+// counts. An omitted count defaults to 1.
+func parseHunkHeader(line string) (oldCount, newCount int, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, fmt.Errorf("malformed hunk header %q", line)
+	}
+	if oldCount, err = hunkRangeCount(fields[1][1:]); err != nil {
+		return 0, 0, fmt.Errorf("malformed hunk header %q: %w", line, err)
+	}
+	if newCount, err = hunkRangeCount(fields[2][1:]); err != nil {
+		return 0, 0, fmt.Errorf("malformed hunk header %q: %w", line, err)
+	}
+	return oldCount, newCount, nil
+}
+
+func hunkRangeCount(r string) (int, error) {
+	_, count, ok := strings.Cut(r, ",")
+	if !ok {
+		return 1, nil
+	}
+	return strconv.Atoi(count)
+}
+
+// MakeOverlayStats summarizes the overlay map of a source.json file (overlay
+// filename → integrity).
+func MakeOverlayStats(overlay map[string]string) *bzpb.OverlayStats {
+	if len(overlay) == 0 {
+		return nil
+	}
+	stats := &bzpb.OverlayStats{}
+	for name := range overlay {
+		switch path.Base(name) {
+		case "MODULE.bazel":
+			if name == "MODULE.bazel" {
+				stats.ReplacesModuleBazel = true
+			}
+		case "BUILD", "BUILD.bazel":
+			stats.BuildFiles = append(stats.BuildFiles, name)
+		}
+	}
+	slices.Sort(stats.BuildFiles)
+	return stats
+}
+
+// statsJSON is the encoded form of a PatchStats.
+type statsJSON struct {
+	Filename     string   `json:"filename"`
+	LinesAdded   int32    `json:"lines_added"`
+	LinesRemoved int32    `json:"lines_removed"`
+	Files        []string `json:"files,omitempty"`
+}
+
+// FormatStats encodes a PatchStats as JSON, suitable for a string_list rule
+// attribute. Filenames are quoted, so any character may appear in them, and
+// the encoding is stable, so regenerating BUILD files does not churn.
+func FormatStats(stats *bzpb.PatchStats) string {
+	data, _ := json.Marshal(statsJSON{
+		Filename:     stats.Filename,
+		LinesAdded:   stats.LinesAdded,
+		LinesRemoved: stats.LinesRemoved,
+		Files:        stats.Files,
+	})
+	return string(data)
+}
+
+// ParseStats decodes a value produced by FormatStats.
+func ParseStats(value string) (*bzpb.PatchStats, error) {
+	var v statsJSON
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return nil, fmt.Errorf("malformed patch stats %q: %w", value, err)
+	}
+	if v.Filename == "" {
+		return nil, fmt.Errorf("malformed patch stats %q: missing filename", value)
+	}
+	return &bzpb.PatchStats{
+		Filename:     v.Filename,
+		Files:        v.Files,
+		LinesAdded:   v.LinesAdded,
+		LinesRemoved: v.LinesRemoved,
+	}, nil
+}
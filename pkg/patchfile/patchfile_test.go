@@ -0,0 +1,163 @@
+package patchfile
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const gitPatch = `diff --git a/MODULE.bazel b/MODULE.bazel
+index 1111111..2222222 100644
+--- a/MODULE.bazel
++++ b/MODULE.bazel
+@@ -1,3 +1,4 @@
+ module(name = "foo")
+-bazel_dep(name = "rules_cc", version = "0.0.1")
++bazel_dep(name = "rules_cc", version = "0.0.9")
++bazel_dep(name = "platforms", version = "0.0.10")
+ 
+diff --git a/src/BUILD.bazel b/src/BUILD.bazel
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/src/BUILD.bazel
+@@ -0,0 +1,2 @@
++cc_library(name = "foo")
++-- a line that starts with two dashes
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+\ No newline at end of file
+diff --git a/bin/tool b/bin/tool
+old mode 100644
+new mode 100755
+`
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		patch string
+		strip int
+		want  *bzpb.PatchStats
+	}{
+		{
+			name:  "git style with strip 1",
+			patch: gitPatch,
+			strip: 1,
+			want: &bzpb.PatchStats{
+				Files:        []string{"MODULE.bazel", "bin/tool", "old.txt", "src/BUILD.bazel"},
+				LinesAdded:   4,
+				LinesRemoved: 2,
+			},
+		},
+		{
+			name:  "git style without strip",
+			patch: gitPatch,
+			want: &bzpb.PatchStats{
+				Files:        []string{"a/old.txt", "b/MODULE.bazel", "b/bin/tool", "b/src/BUILD.bazel"},
+				LinesAdded:   4,
+				LinesRemoved: 2,
+			},
+		},
+		{
+			name: "plain diff -u with timestamps",
+			patch: "--- foo.c\t2024-01-01 00:00:00\n" +
+				"+++ foo.c\t2024-01-02 00:00:00\n" +
+				"@@ -10,2 +10,2 @@\n" +
+				" int x;\n" +
+				"-int y;\n" +
+				"+long y;\n",
+			want: &bzpb.PatchStats{
+				Files:        []string{"foo.c"},
+				LinesAdded:   1,
+				LinesRemoved: 1,
+			},
+		},
+		{
+			name:  "empty",
+			patch: "",
+			want:  &bzpb.PatchStats{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse([]byte(tc.patch), tc.strip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, tc.want) {
+				t.Errorf("Parse() =\n  %v\nwant\n  %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse_MalformedHunk(t *testing.T) {
+	if _, err := Parse([]byte("--- a/x\n+++ b/x\n@@ -1,z +1 @@\n"), 1); err == nil {
+		t.Fatal("expected error for malformed hunk header")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "fix_module.patch")
+	if err := os.WriteFile(filename, []byte(gitPatch), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(filename, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Filename != "fix_module.patch" {
+		t.Errorf("Filename = %q; want fix_module.patch", got.Filename)
+	}
+}
+
+func TestFormatParseStats(t *testing.T) {
+	want := &bzpb.PatchStats{
+		Filename:     "fix|a,b.patch",
+		Files:        []string{"BUILD", "src/a,b.c", "src/c|d.h"},
+		LinesAdded:   12,
+		LinesRemoved: 3,
+	}
+	encoded := FormatStats(want)
+	if want := `{"filename":"fix|a,b.patch","lines_added":12,"lines_removed":3,"files":["BUILD","src/a,b.c","src/c|d.h"]}`; encoded != want {
+		t.Errorf("FormatStats() = %s; want %s", encoded, want)
+	}
+	got, err := ParseStats(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("ParseStats() = %v; want %v", got, want)
+	}
+	for _, value := range []string{"12|3|BUILD", `{"lines_added":1}`} {
+		if _, err := ParseStats(value); err == nil {
+			t.Errorf("ParseStats(%q): expected error", value)
+		}
+	}
+}
+
+func TestMakeOverlayStats(t *testing.T) {
+	if got := MakeOverlayStats(nil); got != nil {
+		t.Errorf("MakeOverlayStats(nil) = %v; want nil", got)
+	}
+	got := MakeOverlayStats(map[string]string{
+		"MODULE.bazel":        "sha256-a",
+		"BUILD.bazel":         "sha256-b",
+		"src/BUILD":           "sha256-c",
+		"third_party/foo.bzl": "sha256-d",
+		"sub/MODULE.bazel":    "sha256-e",
+	})
+	if !got.ReplacesModuleBazel {
+		t.Error("ReplacesModuleBazel = false; want true")
+	}
+	if want := []string{"BUILD.bazel", "src/BUILD"}; !slices.Equal(got.BuildFiles, want) {
+		t.Errorf("BuildFiles = %v; want %v", got.BuildFiles, want)
+	}
+}
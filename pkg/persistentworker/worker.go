@@ -0,0 +1,160 @@
+// Package persistentworker implements the Bazel persistent worker protocol
+// (proto framing), including multiplex workers and cancellation.
+//
+// Singleplex requests (request_id 0) are handled one at a time, in order.
+// Multiplex requests are handled concurrently and their responses written
+// as they complete. A cancel request cancels the context of the matching
+// in-flight request. Once the handler has returned, so that it no longer
+// touches the request's outputs or work directory, the request is answered
+// with was_cancelled instead of its normal response.
+package persistentworker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"runtime/debug"
+	"sync"
+
+	wppb "github.com/bazel-contrib/bcr-frontend/blaze/worker"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+// Handler performs the work of one request. The returned error is reported
+// as the response output with a non-zero exit code; the worker keeps
+// running. ctx is cancelled if Bazel cancels the request.
+type Handler func(ctx context.Context, req *wppb.WorkRequest) error
+
+type syncer interface {
+	Sync() error
+}
+
+// Serve reads work requests from in until EOF and writes responses to out.
+// It returns nil on EOF, after all in-flight requests have completed.
+func Serve(in io.Reader, out io.Writer, logger *log.Logger, handler Handler) error {
+	s := &server{
+		out:      out,
+		logger:   logger,
+		handler:  handler,
+		inflight: make(map[int32]*request),
+	}
+	defer s.wg.Wait()
+
+	for {
+		var req wppb.WorkRequest
+		if err := protoutil.ReadDelimitedFrom(&req, in); err != nil {
+			if err == io.EOF {
+				// this is the signal to terminate the program
+				return nil
+			}
+			return fmt.Errorf("reading work request: %v", err)
+		}
+
+		if req.Cancel {
+			s.cancel(req.RequestId)
+			continue
+		}
+
+		if req.RequestId == 0 {
+			if err := s.respond(&wppb.WorkResponse{}, s.handle(context.Background(), &req)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		r := &request{cancel: cancel}
+		s.mu.Lock()
+		s.inflight[req.RequestId] = r
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func(req *wppb.WorkRequest) {
+			defer s.wg.Done()
+			defer cancel()
+			err := s.handle(ctx, req)
+
+			s.mu.Lock()
+			delete(s.inflight, req.RequestId)
+			cancelled := r.cancelled
+			s.mu.Unlock()
+			if cancelled {
+				// Bazel may reuse the outputs as soon as it sees the
+				// acknowledgement, so it is only sent now that the handler
+				// has stopped.
+				err = s.write(&wppb.WorkResponse{RequestId: req.RequestId, WasCancelled: true})
+			} else {
+				err = s.respond(&wppb.WorkResponse{RequestId: req.RequestId}, err)
+			}
+			if err != nil {
+				s.logger.Printf("writing work response %d: %v", req.RequestId, err)
+			}
+		}(&req)
+	}
+}
+
+type request struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+type server struct {
+	out     io.Writer
+	logger  *log.Logger
+	handler Handler
+	wg      sync.WaitGroup
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	inflight map[int32]*request
+}
+
+// handle calls the handler, turning a panic into an error so that one bad
+// request does not take down the worker.
+func (s *server) handle(ctx context.Context, req *wppb.WorkRequest) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Printf("panic in work request %d: %v\n%s", req.RequestId, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.handler(ctx, req)
+}
+
+// cancel cancels the in-flight request with the given id. The cancellation
+// is acknowledged when the handler returns. Requests that have already
+// completed, or are already cancelled, are ignored.
+func (s *server) cancel(id int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.inflight[id]; ok && !r.cancelled {
+		r.cancelled = true
+		r.cancel()
+	}
+}
+
+func (s *server) respond(resp *wppb.WorkResponse, err error) error {
+	if err != nil {
+		// Don't kill the worker on work errors - report error and continue
+		errMsg := fmt.Sprintf("performing work: %v", err)
+		s.logger.Println("ERROR:", errMsg)
+		resp.Output = errMsg
+		resp.ExitCode = 1
+	}
+	return s.write(resp)
+}
+
+func (s *server) write(resp *wppb.WorkResponse) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := protoutil.WriteDelimitedTo(resp, s.out); err != nil {
+		return fmt.Errorf("writing work response: %v", err)
+	}
+	// Flush stdout to ensure Bazel receives the response immediately
+	if f, ok := s.out.(syncer); ok {
+		f.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package persistentworker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	wppb "github.com/bazel-contrib/bcr-frontend/blaze/worker"
+	"github.com/bazel-contrib/bcr-frontend/pkg/protoutil"
+)
+
+func requests(t *testing.T, reqs ...*wppb.WorkRequest) io.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, req := range reqs {
+		if err := protoutil.WriteDelimitedTo(req, &buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &buf
+}
+
+func responses(t *testing.T, r io.Reader) []*wppb.WorkResponse {
+	t.Helper()
+	var out []*wppb.WorkResponse
+	for {
+		var resp wppb.WorkResponse
+		if err := protoutil.ReadDelimitedFrom(&resp, r); err != nil {
+			if err == io.EOF {
+				return out
+			}
+			t.Fatal(err)
+		}
+		out = append(out, &resp)
+	}
+}
+
+func TestServeSingleplex(t *testing.T) {
+	in := requests(t,
+		&wppb.WorkRequest{Arguments: []string{"ok"}},
+		&wppb.WorkRequest{Arguments: []string{"fail"}},
+		&wppb.WorkRequest{Arguments: []string{"panic"}},
+	)
+	var out bytes.Buffer
+	err := Serve(in, &out, log.New(io.Discard, "", 0), func(ctx context.Context, req *wppb.WorkRequest) error {
+		switch req.Arguments[0] {
+		case "fail":
+			return errors.New("boom")
+		case "panic":
+			panic("oops")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := responses(t, &out)
+	if len(got) != 3 {
+		t.Fatalf("got %d responses, want 3", len(got))
+	}
+	if got[0].ExitCode != 0 {
+		t.Errorf("ok: exit code %d", got[0].ExitCode)
+	}
+	if got[1].ExitCode != 1 || got[1].Output != "performing work: boom" {
+		t.Errorf("fail: %v", got[1])
+	}
+	if got[2].ExitCode != 1 || got[2].Output != "performing work: panic: oops" {
+		t.Errorf("panic: %v", got[2])
+	}
+}
+
+func TestServeMultiplex(t *testing.T) {
+	// Request 1 blocks until request 2 has completed, so responses must be
+	// written out of order. Request 3 blocks until it is cancelled.
+	done2 := make(chan struct{})
+	started3 := make(chan struct{})
+	pr, pw := io.Pipe()
+	go func() {
+		protoutil.WriteDelimitedTo(&wppb.WorkRequest{RequestId: 1, Arguments: []string{"1"}}, pw)
+		protoutil.WriteDelimitedTo(&wppb.WorkRequest{RequestId: 2, Arguments: []string{"2"}}, pw)
+		protoutil.WriteDelimitedTo(&wppb.WorkRequest{RequestId: 3, Arguments: []string{"3"}}, pw)
+		<-started3
+		protoutil.WriteDelimitedTo(&wppb.WorkRequest{RequestId: 3, Cancel: true}, pw)
+		// cancelling an unknown request is ignored
+		protoutil.WriteDelimitedTo(&wppb.WorkRequest{RequestId: 4, Cancel: true}, pw)
+		pw.Close()
+	}()
+
+	var out bytes.Buffer
+	err := Serve(pr, &out, log.New(io.Discard, "", 0), func(ctx context.Context, req *wppb.WorkRequest) error {
+		switch req.Arguments[0] {
+		case "1":
+			<-done2
+		case "2":
+			close(done2)
+		case "3":
+			close(started3)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := responses(t, &out)
+	if len(got) != 3 {
+		t.Fatalf("got %d responses, want 3: %v", len(got), got)
+	}
+	var order []int32
+	for _, resp := range got {
+		order = append(order, resp.RequestId)
+		if resp.RequestId == 3 {
+			if !resp.WasCancelled {
+				t.Errorf("request 3 should be cancelled: %v", resp)
+			}
+		} else if resp.ExitCode != 0 || resp.WasCancelled {
+			t.Errorf("request %d: %v", resp.RequestId, resp)
+		}
+	}
+	pos := make(map[int32]int)
+	for i, id := range order {
+		pos[id] = i
+	}
+	if pos[2] > pos[1] {
+		t.Errorf("request 2 should complete before request 1: %v", order)
+	}
+}
+
+func TestServeCancelWaitsForHandler(t *testing.T) {
+	// The handler ignores cancellation for a while; the acknowledgement must
+	// not be written before it returns, and no other response is sent.
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	pr, pw := io.Pipe()
+	go func() {
+		protoutil.WriteDelimitedTo(&wppb.WorkRequest{RequestId: 1}, pw)
+		<-started
+		protoutil.WriteDelimitedTo(&wppb.WorkRequest{RequestId: 1, Cancel: true}, pw)
+		pw.Close()
+	}()
+
+	var out lockedBuffer
+	err := Serve(pr, &out, log.New(io.Discard, "", 0), func(ctx context.Context, req *wppb.WorkRequest) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		// give an early acknowledgement time to show up
+		time.Sleep(20 * time.Millisecond)
+		if n := out.Len(); n != 0 {
+			t.Errorf("%d bytes written before the handler returned", n)
+		}
+		return errors.New("interrupted")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-cancelled
+
+	got := responses(t, &out.buf)
+	if len(got) != 1 || !got[0].WasCancelled || got[0].ExitCode != 0 || got[0].Output != "" {
+		t.Errorf("responses = %v; want a single was_cancelled response", got)
+	}
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
@@ -0,0 +1,101 @@
+// Package releasedelta computes and applies module-level deltas between the
+// Registry and ModuleRegistrySymbols protos of two releases.
+//
+// A delta carries every added or changed entry in full plus the ordered list
+// of entry keys in the new proto, so applying it to the old proto reproduces
+// the new one exactly (proto.Equal).
+package releasedelta
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+)
+
+// DiffRegistry returns the delta that turns from into to.
+func DiffRegistry(from, to *bzpb.Registry) *bzpb.RegistryDelta {
+	previous := make(map[string]*bzpb.Module, len(from.Modules))
+	for _, m := range from.Modules {
+		previous[m.Name] = m
+	}
+
+	header := proto.Clone(to).(*bzpb.Registry)
+	header.Modules = nil
+	delta := &bzpb.RegistryDelta{Header: header}
+	for _, m := range to.Modules {
+		delta.ModuleName = append(delta.ModuleName, m.Name)
+		if old, ok := previous[m.Name]; !ok || !proto.Equal(old, m) {
+			delta.Upsert = append(delta.Upsert, m)
+		}
+	}
+	return delta
+}
+
+// ApplyRegistry returns from updated by delta. It fails if delta references
+// a module that neither from nor the delta provides, which means delta was
+// computed against a different base.
+func ApplyRegistry(from *bzpb.Registry, delta *bzpb.RegistryDelta) (*bzpb.Registry, error) {
+	modules := make(map[string]*bzpb.Module, len(from.Modules)+len(delta.Upsert))
+	for _, m := range from.Modules {
+		modules[m.Name] = m
+	}
+	for _, m := range delta.Upsert {
+		modules[m.Name] = m
+	}
+
+	to := proto.Clone(delta.Header).(*bzpb.Registry)
+	for _, name := range delta.ModuleName {
+		m, ok := modules[name]
+		if !ok {
+			return nil, fmt.Errorf("registry delta references unknown module %q", name)
+		}
+		to.Modules = append(to.Modules, m)
+	}
+	return to, nil
+}
+
+// DiffSymbols returns the delta that turns from into to.
+func DiffSymbols(from, to *sympb.ModuleRegistrySymbols) *bzpb.SymbolsDelta {
+	previous := make(map[string]*sympb.ModuleVersionSymbols, len(from.ModuleVersion))
+	for _, mvs := range from.ModuleVersion {
+		previous[symbolsID(mvs)] = mvs
+	}
+
+	delta := &bzpb.SymbolsDelta{}
+	for _, mvs := range to.ModuleVersion {
+		id := symbolsID(mvs)
+		delta.ModuleVersion = append(delta.ModuleVersion, id)
+		if old, ok := previous[id]; !ok || !proto.Equal(old, mvs) {
+			delta.Upsert = append(delta.Upsert, mvs)
+		}
+	}
+	return delta
+}
+
+// ApplySymbols returns from updated by delta.
+func ApplySymbols(from *sympb.ModuleRegistrySymbols, delta *bzpb.SymbolsDelta) (*sympb.ModuleRegistrySymbols, error) {
+	entries := make(map[string]*sympb.ModuleVersionSymbols, len(from.ModuleVersion)+len(delta.Upsert))
+	for _, mvs := range from.ModuleVersion {
+		entries[symbolsID(mvs)] = mvs
+	}
+	for _, mvs := range delta.Upsert {
+		entries[symbolsID(mvs)] = mvs
+	}
+
+	to := &sympb.ModuleRegistrySymbols{}
+	for _, id := range delta.ModuleVersion {
+		mvs, ok := entries[id]
+		if !ok {
+			return nil, fmt.Errorf("symbols delta references unknown module version %q", id)
+		}
+		to.ModuleVersion = append(to.ModuleVersion, mvs)
+	}
+	return to, nil
+}
+
+func symbolsID(mvs *sympb.ModuleVersionSymbols) string {
+	return mvs.ModuleName + "@" + mvs.Version
+}
@@ -0,0 +1,81 @@
+package releasedelta
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+)
+
+func module(name string, versions ...string) *bzpb.Module {
+	m := &bzpb.Module{Name: name}
+	for _, v := range versions {
+		m.Versions = append(m.Versions, &bzpb.ModuleVersion{Name: name, Version: v})
+	}
+	return m
+}
+
+func TestRegistryRoundTrip(t *testing.T) {
+	from := &bzpb.Registry{
+		CommitSha: "old",
+		Modules:   []*bzpb.Module{module("a", "1"), module("b", "1"), module("c", "1")},
+	}
+	to := &bzpb.Registry{
+		CommitSha:  "new",
+		CommitDate: "2025-01-02",
+		Modules:    []*bzpb.Module{module("a", "1"), module("c", "2", "1"), module("d", "1")},
+	}
+
+	delta := DiffRegistry(from, to)
+	if got := len(delta.Upsert); got != 2 {
+		t.Errorf("upsert %d modules, want 2 (c, d)", got)
+	}
+	if delta.Header.CommitSha != "new" || len(delta.Header.Modules) != 0 {
+		t.Errorf("header = %v", delta.Header)
+	}
+	if len(to.Modules) != 3 {
+		t.Fatal("DiffRegistry must not modify its input")
+	}
+
+	got, err := ApplyRegistry(from, delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, to) {
+		t.Errorf("ApplyRegistry = %v, want %v", got, to)
+	}
+}
+
+func TestApplyRegistryWrongBase(t *testing.T) {
+	delta := DiffRegistry(&bzpb.Registry{Modules: []*bzpb.Module{module("a", "1")}},
+		&bzpb.Registry{Modules: []*bzpb.Module{module("a", "1")}})
+	if _, err := ApplyRegistry(&bzpb.Registry{}, delta); err == nil {
+		t.Error("expected an error applying a delta to the wrong base")
+	}
+}
+
+func TestSymbolsRoundTrip(t *testing.T) {
+	mvs := func(name, version, file string) *sympb.ModuleVersionSymbols {
+		return &sympb.ModuleVersionSymbols{ModuleName: name, Version: version, File: []*sympb.File{{Description: file}}}
+	}
+	from := &sympb.ModuleRegistrySymbols{ModuleVersion: []*sympb.ModuleVersionSymbols{
+		mvs("a", "1", "x"), mvs("b", "1", "y"),
+	}}
+	to := &sympb.ModuleRegistrySymbols{ModuleVersion: []*sympb.ModuleVersionSymbols{
+		mvs("a", "1", "x"), mvs("a", "2", "x"),
+	}}
+
+	delta := DiffSymbols(from, to)
+	if len(delta.Upsert) != 1 || delta.Upsert[0].Version != "2" {
+		t.Errorf("upsert = %v", delta.Upsert)
+	}
+	got, err := ApplySymbols(from, delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, to) {
+		t.Errorf("ApplySymbols = %v, want %v", got, to)
+	}
+}
@@ -0,0 +1,232 @@
+// Package sitemap encodes sitemaps.org urlset and sitemapindex documents.
+//
+// A single sitemap file may list at most 50,000 URLs and be at most 50MB
+// uncompressed. Large URL lists are therefore split into shards, each
+// written as a gzipped urlset, and published through a sitemap index that
+// references every shard.
+package sitemap
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// Xmlns is the sitemaps.org namespace for urlset and sitemapindex.
+	Xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	// ImageXmlns is the namespace of the image:image extension.
+	ImageXmlns = "http://www.google.com/schemas/sitemap-image/1.1"
+	// XhtmlXmlns is the namespace of the xhtml:link alternate extension.
+	XhtmlXmlns = "http://www.w3.org/1999/xhtml"
+
+	// MaxURLs is the protocol limit on entries per sitemap file.
+	MaxURLs = 50000
+	// MaxBytes is the protocol limit on the uncompressed size of a sitemap
+	// file.
+	MaxBytes = 50 * 1024 * 1024
+)
+
+// URL is a single <url> entry of a urlset.
+type URL struct {
+	XMLName    xml.Name    `xml:"url"`
+	Loc        string      `xml:"loc"`
+	LastMod    string      `xml:"lastmod,omitempty"`
+	ChangeFreq string      `xml:"changefreq,omitempty"`
+	Priority   float64     `xml:"priority,omitempty"`
+	Images     []Image     `xml:"image:image,omitempty"`
+	Alternates []Alternate `xml:"xhtml:link,omitempty"`
+}
+
+// Image is an <image:image> extension entry.
+type Image struct {
+	Loc string `xml:"image:loc"`
+}
+
+// Alternate is an <xhtml:link rel="alternate"> extension entry pointing at
+// a localized or otherwise equivalent version of the page.
+type Alternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// Index is the root of a sitemapindex document.
+type Index struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []IndexEntry `xml:"sitemap"`
+}
+
+// IndexEntry references one sitemap file from an index.
+type IndexEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Limits bounds the size of each shard. Zero fields take the protocol
+// maximum.
+type Limits struct {
+	MaxURLs  int
+	MaxBytes int
+}
+
+// Shard is one encoded, uncompressed urlset document.
+type Shard struct {
+	Data []byte
+	// URLs is the number of entries in the shard.
+	URLs int
+	// LastMod is the newest lastmod among the shard's entries, or "".
+	LastMod string
+}
+
+// Encode encodes urls as one or more urlset documents, starting a new shard
+// whenever the next entry would exceed either limit. Entries keep their
+// order. An empty input still yields a single empty urlset.
+func Encode(urls []URL, limits Limits) ([]*Shard, error) {
+	maxURLs, maxBytes := limits.MaxURLs, limits.MaxBytes
+	if maxURLs <= 0 || maxURLs > MaxURLs {
+		maxURLs = MaxURLs
+	}
+	if maxBytes <= 0 || maxBytes > MaxBytes {
+		maxBytes = MaxBytes
+	}
+
+	header := urlsetHeader(urls)
+	const footer = "</urlset>\n"
+	overhead := len(header) + len(footer)
+
+	var shards []*Shard
+	var current *Shard
+	var buf bytes.Buffer
+	flush := func() {
+		if current == nil {
+			return
+		}
+		buf.WriteString(footer)
+		current.Data = bytes.Clone(buf.Bytes())
+		shards = append(shards, current)
+		current = nil
+	}
+
+	for i := range urls {
+		entry, err := xml.MarshalIndent(&urls[i], "  ", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", urls[i].Loc, err)
+		}
+		entry = append(entry, '\n')
+		if overhead+len(entry) > maxBytes {
+			return nil, fmt.Errorf("%s: entry of %d bytes exceeds the %d byte sitemap limit", urls[i].Loc, len(entry), maxBytes)
+		}
+		if current != nil && (current.URLs == maxURLs || buf.Len()+len(entry)+len(footer) > maxBytes) {
+			flush()
+		}
+		if current == nil {
+			current = &Shard{}
+			buf.Reset()
+			buf.WriteString(header)
+		}
+		buf.Write(entry)
+		current.URLs++
+		if urls[i].LastMod > current.LastMod {
+			current.LastMod = urls[i].LastMod
+		}
+	}
+	if len(shards) == 0 && current == nil {
+		current = &Shard{}
+		buf.WriteString(header)
+	}
+	flush()
+	return shards, nil
+}
+
+// urlsetHeader returns the XML declaration and <urlset> start tag,
+// declaring the extension namespaces only when some entry uses them.
+func urlsetHeader(urls []URL) string {
+	var images, alternates bool
+	for i := range urls {
+		images = images || len(urls[i].Images) > 0
+		alternates = alternates || len(urls[i].Alternates) > 0
+	}
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, `<urlset xmlns="%s"`, Xmlns)
+	if images {
+		fmt.Fprintf(&b, ` xmlns:image="%s"`, ImageXmlns)
+	}
+	if alternates {
+		fmt.Fprintf(&b, ` xmlns:xhtml="%s"`, XhtmlXmlns)
+	}
+	b.WriteString(">\n")
+	return b.String()
+}
+
+// EncodeIndex encodes a sitemapindex document.
+func EncodeIndex(entries []IndexEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(&Index{Xmlns: Xmlns, Sitemaps: entries}); err != nil {
+		return nil, fmt.Errorf("encode sitemapindex: %w", err)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// WriteTar shards urls and writes each shard gzipped to tw at
+// shardDir/sitemap-N.xml.gz (N counting from 1), followed by a sitemap index
+// at indexName referencing the shards under baseURL. It returns the number
+// of shards written.
+func WriteTar(tw *tar.Writer, urls []URL, baseURL, indexName, shardDir string, limits Limits) (int, error) {
+	shards, err := Encode(urls, limits)
+	if err != nil {
+		return 0, err
+	}
+	entries := make([]IndexEntry, 0, len(shards))
+	for i, shard := range shards {
+		name := path.Join(shardDir, fmt.Sprintf("sitemap-%d.xml.gz", i+1))
+		if err := addFileToTar(tw, name, gzipBytes(shard.Data)); err != nil {
+			return 0, fmt.Errorf("adding %s: %w", name, err)
+		}
+		entries = append(entries, IndexEntry{
+			Loc:     strings.TrimRight(baseURL, "/") + "/" + name,
+			LastMod: shard.LastMod,
+		})
+	}
+	index, err := EncodeIndex(entries)
+	if err != nil {
+		return 0, err
+	}
+	if err := addFileToTar(tw, indexName, index); err != nil {
+		return 0, fmt.Errorf("adding %s: %w", indexName, err)
+	}
+	return len(shards), nil
+}
+
+// gzipBytes compresses data. The gzip header carries no timestamp, so equal
+// input yields byte-identical output across builds.
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	gw.Write(data) // writes to a bytes.Buffer cannot fail
+	gw.Close()
+	return buf.Bytes()
+}
+
+func addFileToTar(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(content)),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
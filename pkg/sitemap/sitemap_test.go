@@ -0,0 +1,180 @@
+package sitemap
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testURLs(n int) []URL {
+	urls := make([]URL, n)
+	for i := range urls {
+		urls[i] = URL{
+			Loc:     fmt.Sprintf("https://example.com/page/%d", i),
+			LastMod: fmt.Sprintf("2024-01-%02d", i%28+1),
+		}
+	}
+	return urls
+}
+
+// decodedURLSet is a namespace-agnostic view of an encoded urlset.
+type decodedURLSet struct {
+	URLs []struct {
+		Loc    string `xml:"loc"`
+		Images []struct {
+			Loc string `xml:"loc"`
+		} `xml:"image"`
+		Links []struct {
+			Hreflang string `xml:"hreflang,attr"`
+			Href     string `xml:"href,attr"`
+		} `xml:"link"`
+	} `xml:"url"`
+}
+
+func TestEncodeShardsByCount(t *testing.T) {
+	shards, err := Encode(testURLs(5), Limits{MaxURLs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) != 3 {
+		t.Fatalf("got %d shards, want 3", len(shards))
+	}
+	var locs []string
+	for _, shard := range shards {
+		var set decodedURLSet
+		if err := xml.Unmarshal(shard.Data, &set); err != nil {
+			t.Fatalf("shard does not parse: %v\n%s", err, shard.Data)
+		}
+		if len(set.URLs) != shard.URLs {
+			t.Errorf("shard reports %d URLs, has %d", shard.URLs, len(set.URLs))
+		}
+		for _, u := range set.URLs {
+			locs = append(locs, u.Loc)
+		}
+	}
+	if len(locs) != 5 || locs[0] != "https://example.com/page/0" || locs[4] != "https://example.com/page/4" {
+		t.Errorf("entries lost or reordered: %v", locs)
+	}
+	if shards[0].LastMod != "2024-01-02" {
+		t.Errorf("shard lastmod = %q, want newest entry date", shards[0].LastMod)
+	}
+}
+
+func TestEncodeShardsByBytes(t *testing.T) {
+	urls := testURLs(10)
+	single, err := Encode(urls[:1], Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Room for the document overhead plus roughly three entries.
+	limit := len(single[0].Data) * 2
+	shards, err := Encode(urls, Limits{MaxBytes: limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) < 3 {
+		t.Fatalf("got %d shards, expected the byte limit to split the input", len(shards))
+	}
+	total := 0
+	for _, shard := range shards {
+		if len(shard.Data) > limit {
+			t.Errorf("shard of %d bytes exceeds limit %d", len(shard.Data), limit)
+		}
+		total += shard.URLs
+	}
+	if total != len(urls) {
+		t.Errorf("shards hold %d URLs, want %d", total, len(urls))
+	}
+
+	if _, err := Encode(urls, Limits{MaxBytes: 100}); err == nil {
+		t.Error("expected an error for an entry larger than the limit")
+	}
+}
+
+func TestEncodeExtensions(t *testing.T) {
+	shards, err := Encode([]URL{{
+		Loc:        "https://example.com/modules/foo",
+		Images:     []Image{{Loc: "https://github.com/foo.png"}},
+		Alternates: []Alternate{{Rel: "alternate", Hreflang: "de", Href: "https://de.example.com/modules/foo"}},
+	}}, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := string(shards[0].Data)
+	for _, want := range []string{`xmlns:image="` + ImageXmlns + `"`, `xmlns:xhtml="` + XhtmlXmlns + `"`, "<image:loc>", "<xhtml:link "} {
+		if !strings.Contains(data, want) {
+			t.Errorf("missing %q in\n%s", want, data)
+		}
+	}
+	var set decodedURLSet
+	if err := xml.Unmarshal(shards[0].Data, &set); err != nil {
+		t.Fatal(err)
+	}
+	if u := set.URLs[0]; len(u.Images) != 1 || u.Images[0].Loc != "https://github.com/foo.png" || len(u.Links) != 1 || u.Links[0].Hreflang != "de" {
+		t.Errorf("extensions = %+v", u)
+	}
+
+	plain, err := Encode(testURLs(1), Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(plain[0].Data), "xmlns:image") {
+		t.Errorf("unused extension namespaces should not be declared:\n%s", plain[0].Data)
+	}
+}
+
+func TestWriteTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	n, err := WriteTar(tw, testURLs(3), "https://example.com/", "sitemap.xml", "sitemaps", Limits{MaxURLs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("wrote %d shards, want 2", n)
+	}
+
+	files := make(map[string][]byte)
+	var names []string
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[hdr.Name] = data
+		names = append(names, hdr.Name)
+	}
+	if want := "sitemaps/sitemap-1.xml.gz sitemaps/sitemap-2.xml.gz sitemap.xml"; strings.Join(names, " ") != want {
+		t.Errorf("entries = %v, want %s", names, want)
+	}
+
+	var index Index
+	if err := xml.Unmarshal(files["sitemap.xml"], &index); err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Sitemaps) != 2 || index.Sitemaps[1].Loc != "https://example.com/sitemaps/sitemap-2.xml.gz" || index.Sitemaps[0].LastMod != "2024-01-02" {
+		t.Errorf("index = %+v", index.Sitemaps)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(files["sitemaps/sitemap-2.xml.gz"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if !strings.Contains(string(data), "https://example.com/page/2") {
+		t.Errorf("second shard = %s", data)
+	}
+}
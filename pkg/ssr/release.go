@@ -0,0 +1,86 @@
+package ssr
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	hpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/help/v1"
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+// LoadRelease returns a Renderer for a release tarball as produced by
+// releasecompiler: the shell is its index.html, the registry its
+// registry.pb.gz and the flag database its content-hashed
+// bazelflagdb.<hash>.pb.gz, if any.
+func LoadRelease(tarPath string) (*Renderer, error) {
+	f, err := os.Open(tarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var shell, registryData, flagDbData []byte
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", tarPath, err)
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		var dst *[]byte
+		switch {
+		case name == "index.html":
+			dst = &shell
+		case name == "registry.pb.gz":
+			dst = &registryData
+		case strings.HasPrefix(name, "bazelflagdb.") && strings.HasSuffix(name, ".pb.gz"):
+			dst = &flagDbData
+		default:
+			continue
+		}
+		if *dst, err = io.ReadAll(tr); err != nil {
+			return nil, fmt.Errorf("read %s from %s: %w", name, tarPath, err)
+		}
+	}
+
+	if shell == nil {
+		return nil, fmt.Errorf("%s: no index.html", tarPath)
+	}
+	if registryData == nil {
+		return nil, fmt.Errorf("%s: no registry.pb.gz", tarPath)
+	}
+	registry := &bzpb.Registry{}
+	if err := unmarshalGzip(registryData, registry); err != nil {
+		return nil, fmt.Errorf("registry.pb.gz: %w", err)
+	}
+	var flagDb *hpb.BazelFlagDb
+	if flagDbData != nil {
+		flagDb = &hpb.BazelFlagDb{}
+		if err := unmarshalGzip(flagDbData, flagDb); err != nil {
+			return nil, fmt.Errorf("bazelflagdb: %w", err)
+		}
+	}
+	return New(shell, registry, flagDb), nil
+}
+
+func unmarshalGzip(data []byte, msg proto.Message) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(content, msg)
+}
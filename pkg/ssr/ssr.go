@@ -0,0 +1,390 @@
+// Package ssr renders the static HTML skeleton of registry pages in Go,
+// without a browser. It covers the pages that are deterministic views of
+// the Registry and BazelFlagDb protos: the home page, module pages (the
+// latest version), module version pages and Bazel flag pages.
+//
+// The skeleton mirrors the markup of the soy templates using only primer
+// classes (closure-renamed classes cannot be reproduced outside the JS
+// build). It is inserted into the release's index.html shell, so the page
+// boots the SPA exactly like a Chrome snapshot does: main.js discards the
+// prerendered body and renders the route client-side.
+package ssr
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	hpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/help/v1"
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+// ErrUnsupported is returned by Render for paths that have no Go renderer.
+// Callers fall back to the SPA shell or to Chrome.
+var ErrUnsupported = errors.New("no server-side renderer for path")
+
+// PrerenderedPathMeta is the <meta> name that records the path a snapshot
+// was rendered for. The early script in index.html compares it to the
+// location to decide whether the body is stale.
+const PrerenderedPathMeta = "bcr:prerendered-path"
+
+// Renderer renders pages into an index.html shell.
+type Renderer struct {
+	shell    []byte
+	registry *bzpb.Registry
+	flagDb   *hpb.BazelFlagDb
+	modules  map[string]*bzpb.Module
+	flags    map[string]*hpb.BazelFlag
+}
+
+// New returns a Renderer for the given shell document. flagDb may be nil, in
+// which case flag pages are unsupported.
+func New(shell []byte, registry *bzpb.Registry, flagDb *hpb.BazelFlagDb) *Renderer {
+	r := &Renderer{
+		shell:    shell,
+		registry: registry,
+		flagDb:   flagDb,
+		modules:  make(map[string]*bzpb.Module, len(registry.Modules)),
+		flags:    make(map[string]*hpb.BazelFlag, len(flagDb.GetFlag())),
+	}
+	for _, module := range registry.Modules {
+		r.modules[module.Name] = module
+	}
+	for _, flag := range flagDb.GetFlag() {
+		r.flags[flag.Name] = flag
+	}
+	return r
+}
+
+// Shell returns the index.html shell pages are rendered into.
+func (r *Renderer) Shell() []byte {
+	return r.shell
+}
+
+// FlagNames returns the names of the flags that have a page of their own,
+// in database order.
+func (r *Renderer) FlagNames() []string {
+	var names []string
+	for _, flag := range r.flagDb.GetFlag() {
+		if !reservedFlagSegments[flag.Name] && !strings.ContainsAny(flag.Name, "/?#") {
+			names = append(names, flag.Name)
+		}
+	}
+	return names
+}
+
+// reservedFlagSegments are the /bazel/flags/ sub-routes that are not flag
+// names.
+var reservedFlagSegments = map[string]bool{
+	"":         true,
+	"list":     true,
+	"tag":      true,
+	"category": true,
+}
+
+// Render returns the document for a URL path such as /, /modules/rules_go or
+// /bazel/flags/config. It returns an error wrapping ErrUnsupported if the
+// path is not a page this package renders, or names a module, version or
+// flag that is not in the release, so that the caller can fall back for that
+// page alone.
+func (r *Renderer) Render(path string) ([]byte, error) {
+	path = NormalizePath(path)
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	var body bytes.Buffer
+	switch {
+	case path == "/" || path == "/home":
+		if err := templates.ExecuteTemplate(&body, "home", newHomePage(r.registry)); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case parts[0] == "modules" && (len(parts) == 2 || len(parts) == 3):
+		module := r.modules[parts[1]]
+		if module == nil {
+			return nil, fmt.Errorf("%s: module %q not found: %w", path, parts[1], ErrUnsupported)
+		}
+		var version *bzpb.ModuleVersion
+		if len(parts) == 3 {
+			version = findVersion(module, parts[2])
+		} else {
+			version = latestVersion(module)
+		}
+		if version == nil {
+			return nil, fmt.Errorf("%s: no such version of %s: %w", path, module.Name, ErrUnsupported)
+		}
+		if err := templates.ExecuteTemplate(&body, "moduleVersion", newModuleVersionPage(module, version)); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case len(parts) == 3 && parts[0] == "bazel" && parts[1] == "flags" && !reservedFlagSegments[parts[2]] && r.flagDb != nil:
+		flag := r.flags[parts[2]]
+		if flag == nil {
+			return nil, fmt.Errorf("%s: flag %q not found: %w", path, parts[2], ErrUnsupported)
+		}
+		if err := templates.ExecuteTemplate(&body, "bazelFlag", newFlagPage(r.flagDb, flag)); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: %w", path, ErrUnsupported)
+	}
+
+	var page bytes.Buffer
+	if err := templates.ExecuteTemplate(&page, "body", template.HTML(body.String())); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return Assemble(r.shell, path, page.Bytes())
+}
+
+// NormalizePath returns the path component of a URL path or absolute URL
+// with trailing slashes removed; the root stays "/". It matches the
+// normalization of the early script in index.html.
+func NormalizePath(path string) string {
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+		if j := strings.IndexByte(path, '/'); j >= 0 {
+			path = path[j:]
+		} else {
+			path = "/"
+		}
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+var (
+	prerenderedMetaRE = regexp.MustCompile(`(?is)<meta\b[^>]*name="` + regexp.QuoteMeta(PrerenderedPathMeta) + `"[^>]*>\s*`)
+	headEndRE         = regexp.MustCompile(`(?i)</head>`)
+	bodyStartRE       = regexp.MustCompile(`(?is)<body\b[^>]*>`)
+)
+
+// Assemble inserts a body skeleton into the shell, directly after the
+// <body> tag, and records path in the prerendered-path meta, replacing any
+// existing one.
+func Assemble(shell []byte, path string, body []byte) ([]byte, error) {
+	doc := prerenderedMetaRE.ReplaceAll(shell, nil)
+	head := headEndRE.FindIndex(doc)
+	if head == nil {
+		return nil, fmt.Errorf("shell has no </head>")
+	}
+	start := bodyStartRE.FindIndex(doc[head[1]:])
+	if start == nil {
+		return nil, fmt.Errorf("shell has no <body>")
+	}
+	bodyAt := head[1] + start[1]
+
+	var out bytes.Buffer
+	out.Write(doc[:head[0]])
+	fmt.Fprintf(&out, "<meta name=\"%s\" content=\"%s\">", PrerenderedPathMeta, html.EscapeString(path))
+	out.Write(doc[head[0]:bodyAt])
+	out.Write(body)
+	out.Write(doc[bodyAt:])
+	return out.Bytes(), nil
+}
+
+func findVersion(module *bzpb.Module, version string) *bzpb.ModuleVersion {
+	for _, mv := range module.Versions {
+		if mv.Version == version {
+			return mv
+		}
+	}
+	return nil
+}
+
+// latestVersion returns the version flagged as latest, falling back to the
+// first (newest) version.
+func latestVersion(module *bzpb.Module) *bzpb.ModuleVersion {
+	for _, mv := range module.Versions {
+		if mv.IsLatestVersion {
+			return mv
+		}
+	}
+	if len(module.Versions) > 0 {
+		return module.Versions[0]
+	}
+	return nil
+}
+
+// commitTime parses the commit dates found in the registry. Unparseable
+// dates yield the zero time.
+func commitTime(date string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// truncate shortens s to max characters including a trailing ellipsis, like
+// the soy |truncate print directive.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-3]) + "..."
+}
+
+type versionRow struct {
+	Version string
+	Current bool
+	Yanked  bool
+}
+
+type moduleVersionPage struct {
+	Module      *bzpb.Module
+	Version     *bzpb.ModuleVersion
+	Repository  *bzpb.RepositoryMetadata
+	RepoURL     string
+	IsLatest    bool
+	Yanked      bool
+	YankMessage string
+	Deprecated  string
+	Deps        []*bzpb.ModuleDependency
+	DevDeps     []*bzpb.ModuleDependency
+	Maintainers []*bzpb.Maintainer
+	Versions    []versionRow
+}
+
+func newModuleVersionPage(module *bzpb.Module, version *bzpb.ModuleVersion) *moduleVersionPage {
+	md := version.RepositoryMetadata
+	if md == nil {
+		md = module.RepositoryMetadata
+	}
+	page := &moduleVersionPage{
+		Module:      module,
+		Version:     version,
+		Repository:  md,
+		Deprecated:  module.GetMetadata().GetDeprecated(),
+		Maintainers: module.GetMetadata().GetMaintainers(),
+	}
+	if md.GetType() == bzpb.RepositoryType_GITHUB && md.Organization != "" && md.Name != "" {
+		page.RepoURL = fmt.Sprintf("https://github.com/%s/%s", md.Organization, md.Name)
+	}
+	yanked := module.GetMetadata().GetYankedVersions()
+	page.YankMessage, page.Yanked = yanked[version.Version]
+	for _, dep := range version.Deps {
+		if dep.Dev {
+			page.DevDeps = append(page.DevDeps, dep)
+		} else {
+			page.Deps = append(page.Deps, dep)
+		}
+	}
+
+	// Newest first by commit date, as the versions table of the SPA.
+	versions := slices.Clone(module.Versions)
+	slices.SortStableFunc(versions, func(a, b *bzpb.ModuleVersion) int {
+		return commitTime(b.GetCommit().GetDate()).Compare(commitTime(a.GetCommit().GetDate()))
+	})
+	for _, mv := range versions {
+		_, isYanked := yanked[mv.Version]
+		page.Versions = append(page.Versions, versionRow{
+			Version: mv.Version,
+			Current: mv.Version == version.Version,
+			Yanked:  isYanked,
+		})
+	}
+	// The latest marker comes from the registry, not from the table order: a
+	// backport release can be committed after a newer version.
+	latest := latestVersion(module)
+	page.IsLatest = latest != nil && latest.Version == version.Version
+	return page
+}
+
+// recentLimit is the length of the home page's recently updated timeline.
+const recentLimit = 15
+
+type recentItem struct {
+	Name        string
+	Version     string
+	PullRequest string
+	GithubUser  string
+	GithubName  string
+}
+
+type homePage struct {
+	TotalModules        int
+	TotalModuleVersions int
+	TotalBazelVersions  int
+	RecentlyUpdated     []recentItem
+}
+
+// newHomePage computes the side pane totals and the recently updated
+// timeline of the home page, as home.js does. Counts that the SPA derives
+// from data outside registry.pb (symbols, people) are left out.
+func newHomePage(registry *bzpb.Registry) *homePage {
+	page := &homePage{TotalModules: len(registry.Modules)}
+	var versions []*bzpb.ModuleVersion
+	for _, module := range registry.Modules {
+		page.TotalModuleVersions += len(module.Versions)
+		if module.Name == "bazel_tools" {
+			page.TotalBazelVersions = len(module.Versions)
+		}
+		for _, mv := range module.Versions {
+			if mv.GetCommit().GetDate() != "" {
+				versions = append(versions, mv)
+			}
+		}
+	}
+	slices.SortStableFunc(versions, func(a, b *bzpb.ModuleVersion) int {
+		return commitTime(b.Commit.Date).Compare(commitTime(a.Commit.Date))
+	})
+	for _, mv := range versions[:min(len(versions), recentLimit)] {
+		page.RecentlyUpdated = append(page.RecentlyUpdated, recentItem{
+			Name:        mv.Name,
+			Version:     mv.Version,
+			PullRequest: mv.Commit.PullRequest,
+			GithubUser:  mv.Commit.GithubUser,
+			GithubName:  cmp.Or(mv.Commit.GithubName, mv.Commit.GithubUser),
+		})
+	}
+	return page
+}
+
+type flagLabel struct {
+	Name   string
+	Href   string
+	Active bool
+}
+
+type flagPage struct {
+	Flag     *hpb.BazelFlag
+	Commands []flagLabel
+	Versions []flagLabel
+}
+
+func newFlagPage(db *hpb.BazelFlagDb, flag *hpb.BazelFlag) *flagPage {
+	page := &flagPage{Flag: flag}
+	commands := make(map[int32]bool, len(flag.CommandIndex))
+	for _, i := range flag.CommandIndex {
+		commands[i] = true
+	}
+	for i, name := range db.Commands {
+		page.Commands = append(page.Commands, flagLabel{Name: name, Href: "/bazel/command/" + name, Active: commands[int32(i)]})
+	}
+	versions := make(map[int32]bool, len(flag.VersionIndex))
+	for _, i := range flag.VersionIndex {
+		versions[i] = true
+	}
+	for i, name := range db.BazelVersions {
+		page.Versions = append(page.Versions, flagLabel{Name: name, Href: "/bazel/" + name, Active: versions[int32(i)]})
+	}
+	return page
+}
@@ -0,0 +1,197 @@
+package ssr
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	hpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/help/v1"
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+const testShell = `<!DOCTYPE html>
+<html><head><title>Bazel Central Registry</title><meta name="bcr:prerendered-path" content="/"></head>
+<body style="margin: 0 auto"><script type="module">bcr.main(REGISTRY_DATA);</script></body></html>`
+
+func newTestRenderer() *Renderer {
+	registry := &bzpb.Registry{
+		Modules: []*bzpb.Module{{
+			Name: "rules_foo",
+			Metadata: &bzpb.ModuleMetadata{
+				Homepage:       "https://foo.example.com",
+				Maintainers:    []*bzpb.Maintainer{{Name: "Jo Doe", Github: "jodoe"}},
+				YankedVersions: map[string]string{"1.0": "broken <release>"},
+			},
+			RepositoryMetadata: &bzpb.RepositoryMetadata{
+				Type:         bzpb.RepositoryType_GITHUB,
+				Organization: "foo-org",
+				Name:         "rules_foo",
+				Description:  "Rules for foo",
+			},
+			Versions: []*bzpb.ModuleVersion{
+				{
+					Name:            "rules_foo",
+					Version:         "2.0",
+					IsLatestVersion: true,
+					Commit:          &bzpb.ModuleCommit{Date: "2024-02-10T08:00:00Z"},
+					Deps: []*bzpb.ModuleDependency{
+						{Name: "bazel_skylib", Version: "1.7.1"},
+						{Name: "rules_testing", Version: "0.6.0", Dev: true},
+					},
+				},
+				{Name: "rules_foo", Version: "1.0", Commit: &bzpb.ModuleCommit{Date: "2023-05-01 08:00:00 +0000", PullRequest: "1234", GithubUser: "jodoe"}},
+			},
+		}},
+	}
+	flagDb := &hpb.BazelFlagDb{
+		BazelVersions: []string{"7.0.0", "8.0.0"},
+		Commands:      []string{"build", "test"},
+		Flag: []*hpb.BazelFlag{{
+			Name:         "config",
+			Type:         "string",
+			Description:  []string{"Selects `--config` sections."},
+			Tag:          []string{"no_op"},
+			VersionIndex: []int32{1},
+			CommandIndex: []int32{0, 1},
+		}},
+	}
+	return New([]byte(testShell), registry, flagDb)
+}
+
+func TestRenderModuleVersion(t *testing.T) {
+	r := newTestRenderer()
+	doc, err := r.Render("/modules/rules_foo/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(doc)
+
+	if n := strings.Count(out, `name="bcr:prerendered-path"`); n != 1 {
+		t.Errorf("got %d prerendered-path metas, want 1", n)
+	}
+	if !strings.Contains(out, `<meta name="bcr:prerendered-path" content="/modules/rules_foo">`) {
+		t.Errorf("missing prerendered-path meta in\n%s", out)
+	}
+	if strings.Index(out, "data-ssr") > strings.Index(out, "bcr.main") {
+		t.Errorf("skeleton must precede the boot script")
+	}
+
+	texts, err := Text(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"rules_foo", "2.0", "Rules for foo", "Homepage", "bazel_skylib", "1.7.1", "Dev Dependencies", "rules_testing", "Maintainers", "1"} {
+		if !slices.Contains(texts, want) {
+			t.Errorf("missing text %q in %q", want, texts)
+		}
+	}
+	if !strings.Contains(out, "bcr.main(REGISTRY_DATA)") {
+		t.Errorf("boot script dropped")
+	}
+
+	yanked, err := r.Render("http://localhost:8080/modules/rules_foo/1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(yanked), "broken &lt;release&gt;") {
+		t.Errorf("missing escaped yank message in\n%s", yanked)
+	}
+}
+
+func TestRenderFlag(t *testing.T) {
+	doc, err := newTestRenderer().Render("/bazel/flags/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts, err := Text(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"--config", "string", "no_op", "Applies to", "build", "8.0.0"} {
+		if !slices.Contains(texts, want) {
+			t.Errorf("missing text %q in %q", want, texts)
+		}
+	}
+	if slices.Contains(texts, "Selects `--config` sections.") {
+		t.Errorf("markdown source should be skipped by Text")
+	}
+	if !strings.Contains(string(doc), `href="/bazel/command/test"`) {
+		t.Errorf("active command should link to its page")
+	}
+}
+
+func TestRenderHome(t *testing.T) {
+	r := newTestRenderer()
+	for _, path := range []string{"/", "/home/"} {
+		doc, err := r.Render(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		texts, err := Text(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		joined := strings.Join(texts, " ")
+		for _, want := range []string{
+			"Modules (Versions) 1 (2)",
+			"Bazel Versions 0",
+			"Recently Updated",
+			"rules_foo 2.0 rules_foo 1.0 #1234 by jodoe",
+		} {
+			if !strings.Contains(joined, want) {
+				t.Errorf("%s: text missing %q in %q", path, want, joined)
+			}
+		}
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	r := newTestRenderer()
+	for _, path := range []string{"/bazel/flags/list", "/modules/rules_foo/2.0/docs"} {
+		if _, err := r.Render(path); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: got %v, want ErrUnsupported", path, err)
+		}
+	}
+	// Pages for things missing from the release fall back rather than fail
+	// the whole run.
+	for _, path := range []string{"/modules/missing", "/modules/rules_foo/9.9", "/bazel/flags/nope"} {
+		if _, err := r.Render(path); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: got %v, want ErrUnsupported", path, err)
+		}
+	}
+}
+
+func TestModuleVersionPageIsLatest(t *testing.T) {
+	// 1.9.1 is a backport committed after 2.0, which is still the latest.
+	latest := &bzpb.ModuleVersion{Version: "2.0", IsLatestVersion: true, Commit: &bzpb.ModuleCommit{Date: "2024-02-10T08:00:00Z"}}
+	backport := &bzpb.ModuleVersion{Version: "1.9.1", Commit: &bzpb.ModuleCommit{Date: "2024-03-01T08:00:00Z"}}
+	module := &bzpb.Module{Name: "rules_foo", Versions: []*bzpb.ModuleVersion{latest, backport}}
+
+	if !newModuleVersionPage(module, latest).IsLatest {
+		t.Errorf("2.0: IsLatest = false, want true")
+	}
+	if newModuleVersionPage(module, backport).IsLatest {
+		t.Errorf("1.9.1: IsLatest = true, want false")
+	}
+}
+
+func TestMissing(t *testing.T) {
+	rendered := []byte(`<html><body><span>rules_foo</span> <span>2.0</span><div data-ssr-skip>*raw*</div></body></html>`)
+	browser := []byte(`<html><body><h1>rules_foo <b>2.0</b></h1><p>raw</p></body></html>`)
+	missing, err := Missing(rendered, browser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing = %q, want none", missing)
+	}
+
+	missing, err = Missing(rendered, []byte(`<html><body><h1>rules_foo</h1><script>"2.0"</script></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(missing, []string{"2.0"}) {
+		t.Errorf("missing = %q, want [2.0]", missing)
+	}
+}
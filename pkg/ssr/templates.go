@@ -0,0 +1,230 @@
+package ssr
+
+import (
+	"html/template"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+)
+
+// dependencyBox is the data of a moduleDependencies box.
+type dependencyBox struct {
+	Title string
+	Deps  []*bzpb.ModuleDependency
+}
+
+// sidePaneCount is the data of a sidePaneCount link of the home page.
+type sidePaneCount struct {
+	Href  string
+	Title string
+	Count any
+}
+
+// templates mirror app.soy and registry.soy: bodySelect, homeOverviewSelectNav
+// (with bcrSidePane and homeRecentTimeline), moduleVersionHeader,
+// moduleVersionComponent (with the dependency boxes the SPA fills in) and
+// bazelFlagDetail. Text that the SPA formats in the browser (relative dates,
+// markdown) is left out or marked data-ssr-skip so diff mode ignores it.
+var templates = template.Must(template.New("ssr").Funcs(template.FuncMap{
+	"truncate": truncate,
+	"deps": func(title string, deps []*bzpb.ModuleDependency) dependencyBox {
+		return dependencyBox{Title: title, Deps: deps}
+	},
+	"count": func(href, title string, count any) sidePaneCount {
+		return sidePaneCount{Href: href, Title: title, Count: count}
+	},
+}).Parse(`
+{{define "body"}}<div class="container-xl" data-ssr>
+<div class="PageLayout px-3">
+<div class="Header" style="background-color: unset">
+<div class="Header-item" style="max-width: 54px; position: relative;"><a href="/home" aria-label="Bazel Central Registry"></a></div>
+<div class="Header-item Header-item--full"></div>
+</div>
+<div class="PageLayout-content">{{.}}</div>
+<div class="PageLayout-footer mt-5"></div>
+</div>
+</div>
+{{end}}
+
+{{define "sectionHeader"}}<h3 class="h3 d-flex flex-items-center mb-2"><span>{{.}}</span></h3>{{end}}
+
+{{define "sectionDivider"}}<div class="border-top my-3"></div>{{end}}
+
+{{define "sidePaneCount"}}<a class="Link--primary d-flex flex-items-center mb-2" href="{{.Href}}"><span class="color-fg-muted">{{.Title}}</span> <span class="dotted-leader"></span><span class="text-bold">{{.Count}}</span></a>{{end}}
+
+{{define "home"}}<div class="PageLayout PageLayout--panePos-start PageLayout--hasPaneDivider PageLayout--responsive-stackRegions PageLayout--responsive-panePos-start mt-3">
+<div class="PageLayout-columns">
+<div class="PageLayout-pane">
+<div class="px-2">
+<h2 class="h2 d-flex flex-items-center mb-2"><span>Bazel Central Registry</span></h2>
+<p class="f4 mb-4 color-fg-muted">The central registry of Bazel modules for the Bzlmod external dependency system.</p>
+{{template "sidePaneCount" (count "/modules" "Modules (Versions)" (printf "%d (%d)" .TotalModules .TotalModuleVersions))}}
+{{template "sidePaneCount" (count "/bazel/versions" "Bazel Versions" .TotalBazelVersions)}}
+</div>
+</div>
+<div class="PageLayout-content">
+<nav class="UnderlineNav"><div class="UnderlineNav-body"><span class="UnderlineNav-item">Recently Updated</span> <span class="UnderlineNav-item">Recently Added</span></div></nav>
+<div class="mt-3 px-3">
+{{- range .RecentlyUpdated}}
+<div class="TimelineItem">
+<div class="TimelineItem-body">
+<div class="d-flex flex-items-center"><div style="flex-shrink: 0"><a class="Link--primary text-bold" href="/modules/{{.Name}}/{{.Version}}">{{.Name}}</a> <span class="color-fg-muted">{{.Version}}</span></div><span class="dotted-leader"></span></div>
+{{- if .PullRequest}}
+<div class="f6 color-fg-muted mt-1"><a class="Link--muted" href="https://github.com/bazelbuild/bazel-central-registry/pull/{{.PullRequest}}">#{{.PullRequest}}</a>
+{{- if .GithubUser}} by <a class="Link--muted text-bold" href="/maintainers/{{.GithubUser}}">{{.GithubName}}</a>{{end -}}
+</div>
+{{- end}}
+</div>
+</div>
+{{- end}}
+</div>
+</div>
+</div>
+</div>
+{{end}}
+
+{{define "moduleDependencies"}}<div class="Box Box--condensed mt-3">
+<div class="Box-header d-flex flex-items-center flex-justify-between"><span class="Box-title">{{.Title}} <label class="Counter ml-1">{{len .Deps}}</label></span></div>
+<div class="Box-body width-full">
+{{- range .Deps}}
+<div class="mx-1 my-2"><a class="Box-row-link" href="/modules/{{.Name}}/{{.Version}}">{{truncate .Name 32}}
+<span class="ml-1 text-light text-small">
+{{- if .Override}}({{if .Version}}<span class="mr-1" style="text-decoration: line-through;">{{truncate .Version 32}}</span>{{end}}<span class="text-bold">override</span>)
+{{- else if .Unresolved}}(<span class="mr-1" style="text-decoration: line-through;">{{truncate .Version 32}}</span><span class="text-bold">unresolved</span>)
+{{- else}}{{truncate .Version 32}}{{end -}}
+</span>
+{{- if .RepoName}} <span class="ml-1 text-light text-small">as <span class="text-semibold">{{.RepoName}}</span></span>{{end -}}
+</a></div>
+{{- end}}
+</div>
+</div>
+{{end}}
+
+{{define "moduleVersion"}}<div>
+<div class="f2-light d-flex flex-justify-between flex-items-center">
+<div><span class="f1 text-semibold">{{.Version.Name}}</span> <span class="f2 d-inline-block">{{.Version.Version}}</span></div>
+{{- if .RepoURL}}
+<a class="btn btn-sm btn-invisible" href="{{.RepoURL}}" target="_blank" rel="noopener" title="View on GitHub" aria-label="View on GitHub"></a>
+{{- end}}
+</div>
+{{- if .Yanked}}
+<div class="Subhead-description"><span class="text-bold mx-1">Yanked Version:</span> <span class="text-italic Label--danger">{{or .YankMessage "No message provided."}}</span></div>
+{{- end}}
+{{- if .Deprecated}}
+<div class="Subhead-description"><span class="text-bold mx-1">Deprecation Notice:</span> <span class="text-italic Label--attention">{{.Deprecated}}</span></div>
+{{- end}}
+<nav class="UnderlineNav"><div class="UnderlineNav-body"></div></nav>
+<div class="mt-3">
+<div class="PageLayout PageLayout--panePos-end PageLayout--hasPaneDivider PageLayout--responsive-stackRegions">
+<div class="PageLayout-columns">
+<div class="PageLayout-content">
+{{- if .Deps}}{{template "moduleDependencies" (deps "Dependencies" .Deps)}}{{end}}
+{{- if .DevDeps}}{{template "moduleDependencies" (deps "Dev Dependencies" .DevDeps)}}{{end}}
+</div>
+<div class="PageLayout-pane">
+<div>
+{{template "sectionHeader" "About"}}
+<p class="f4 mb-3 color-fg-muted">{{or .Repository.GetDescription "No description provided."}}</p>
+<div class="mt-3">
+{{- with .Module.GetMetadata.GetHomepage}}
+<a class="Link--muted d-flex flex-items-center mb-2" href="{{.}}"><span>Homepage</span></a>
+{{- end}}
+{{- with .Version.GetSource.GetDocsUrl}}
+<a class="Link--muted d-flex flex-items-center mb-2" href="{{.}}"><span>Documentation</span></a>
+{{- end}}
+{{- with .Repository.GetStargazers}}
+<div class="d-flex flex-items-center mb-2 color-fg-muted"><span class="mr-1 text-bold">{{.}}</span> <span>star{{if gt . 1}}s{{end}}</span></div>
+{{- end}}
+</div>
+</div>
+{{template "sectionDivider"}}
+<div>
+<h3 class="h3 d-flex flex-items-center mb-2"><span>Maintainers</span> <span class="ml-1" style="margin-top: -4px"><label class="Counter ml-1">{{len .Maintainers}}</label></span></h3>
+<div class="d-flex flex-wrap">
+{{- range .Maintainers}}
+<div class="m-1"><a class="d-inline-block" href="/maintainers/{{or .Github .Email .Name}}" title="{{if .Name}}{{.Name}}{{else}}@{{.Github}}{{end}}"><img class="avatar circle" loading="lazy" decoding="async" width="48" height="48" src="https://github.com/{{or .Github "bazelbuild"}}.png" alt="@{{.Github}}"></a></div>
+{{- end}}
+</div>
+</div>
+{{template "sectionDivider"}}
+<div>
+<h3 class="h3 d-flex flex-items-center mb-2"><span>Versions</span> <span class="ml-1" style="margin-top: -4px"><label class="Counter ml-1">{{len .Versions}}</label></span></h3>
+<table class="width-full">
+{{- $name := .Module.Name}}
+{{- range .Versions}}
+<tr><td colspan="2" class="py-1">
+{{- if .Current}}<span class="text-bold f4">{{truncate .Version 18}}</span>
+{{- else}}<a class="Box-row-link f5" href="/modules/{{$name}}/{{.Version}}"{{if .Yanked}} style="text-decoration: line-through; color: var(--color-fg-muted);"{{end}}>{{truncate .Version 18}}</a>
+{{- end -}}
+</td></tr>
+{{- end}}
+</table>
+</div>
+</div>
+</div>
+</div>
+</div>
+</div>
+{{end}}
+
+{{define "flagLabels"}}<div class="mt-2 d-flex flex-wrap" style="gap: 6px">
+{{- range .}}
+{{- if .Active}}<a class="Label Label--success" href="{{.Href}}" style="text-decoration: none;">{{.Name}}</a>
+{{- else}}<span class="Label Label--secondary color-fg-subtle" style="opacity: 0.4">{{.Name}}</span>
+{{- end}}
+{{- end}}
+</div>{{end}}
+
+{{define "bazelFlag"}}<div class="PageLayout PageLayout--panePos-start PageLayout--hasPaneDivider PageLayout--responsive-stackRegions PageLayout--responsive-panePos-start mt-3">
+<div class="PageLayout-columns">
+<div class="PageLayout-pane"></div>
+<div class="PageLayout-content px-3">
+<div class="mb-2"><a class="Link--muted f6" href="/bazel/flags/list">Back to all flags</a></div>
+{{- with .Flag}}
+<h2 class="h2 d-flex flex-items-center flex-wrap" style="row-gap: 4px;"><span class="text-mono flex-shrink-0" style="white-space: nowrap;">--{{.Name}}</span>
+{{- if .Short}} <span class="color-fg-muted text-mono ml-3 f4 flex-shrink-0" style="white-space: nowrap;">-{{.Short}}</span>{{end -}}
+</h2>
+{{- if .Toggle}}
+<h3 class="h3 d-flex flex-items-center ml-1 color-fg-muted flex-wrap" style="row-gap: 4px;"><span class="text-mono flex-shrink-0" style="white-space: nowrap;">--no{{.Name}}</span></h3>
+{{- end}}
+<div class="d-flex flex-wrap mt-2">
+{{- if .Type}}<label class="Label mr-2 mb-1"><span class="text-mono text-bold">{{.Type}}</span></label>{{end}}
+{{- if .Default}}<label class="Label Label--secondary mr-2 mb-1">default: <span class="text-mono text-bold">{{.Default}}</span></label>{{end -}}
+</div>
+{{- if .Description}}
+<div class="markdown-body color-fg-default mt-3" data-ssr-skip>
+{{- range .Description}}{{.}}
+{{end -}}
+</div>
+{{- end}}
+{{- if .Category}}
+{{template "sectionDivider"}}
+<div class="mb-3">
+{{template "sectionHeader" "Category"}}
+<div class="mt-2 d-flex flex-wrap" style="gap: 6px"><a class="Label Label--secondary" href="/bazel/flags/category/{{.Category}}" style="text-decoration: none;">{{.Category}}</a></div>
+</div>
+{{- end}}
+{{- if .Tag}}
+{{template "sectionDivider"}}
+<div class="mb-3">
+{{template "sectionHeader" "Tags"}}
+<div class="mt-2 d-flex flex-wrap" style="gap: 6px">
+{{- range .Tag}}<a class="Label Label--secondary" href="/bazel/flags/tag/{{.}}" style="text-decoration: none;">{{.}}</a>{{end -}}
+</div>
+</div>
+{{- end}}
+{{- end}}
+{{template "sectionDivider"}}
+<div class="mb-3">
+{{template "sectionHeader" "Applies to"}}
+{{if .Commands}}{{template "flagLabels" .Commands}}{{else}}<div class="color-fg-muted">No subcommands recorded.</div>{{end}}
+</div>
+{{template "sectionDivider"}}
+<div class="mb-3">
+{{template "sectionHeader" "Available in"}}
+{{if .Versions}}{{template "flagLabels" .Versions}}{{else}}<div class="color-fg-muted">No bazel versions recorded for this flag.</div>{{end}}
+</div>
+</div>
+</div>
+</div>
+{{end}}
+`))
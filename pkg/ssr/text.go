@@ -0,0 +1,73 @@
+package ssr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// SkipAttr marks elements whose text the browser renders differently from
+// their source (markdown, for example). Text skips their subtrees.
+const SkipAttr = "data-ssr-skip"
+
+// Text returns the visible text nodes of a document's body in document
+// order, with whitespace collapsed. Scripts, styles and elements carrying
+// SkipAttr are left out.
+func Text(doc []byte) ([]string, error) {
+	root, err := html.Parse(bytes.NewReader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+	var texts []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if text := strings.Join(strings.Fields(n.Data), " "); text != "" {
+				texts = append(texts, text)
+			}
+			return
+		case html.ElementNode:
+			switch n.DataAtom {
+			case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template:
+				return
+			}
+			for _, attr := range n.Attr {
+				if attr.Key == SkipAttr {
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return texts, nil
+}
+
+// Missing compares a Go-rendered document with a browser-rendered one and
+// returns the text of the former that does not appear in the latter. The Go
+// skeleton is a subset of the full page, so an empty result means the two
+// agree on everything the skeleton shows.
+func Missing(rendered, browser []byte) ([]string, error) {
+	want, err := Text(rendered)
+	if err != nil {
+		return nil, err
+	}
+	got, err := Text(browser)
+	if err != nil {
+		return nil, err
+	}
+	haystack := strings.Join(got, " ")
+	var missing []string
+	for _, text := range want {
+		if !strings.Contains(haystack, text) {
+			missing = append(missing, text)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,193 @@
+package stardoc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DocstringSectionKind identifies a Google-style docstring section.
+type DocstringSectionKind int
+
+const (
+	// TextSection is free-form text outside any recognized section.
+	TextSection DocstringSectionKind = iota
+	// ArgsSection is an `Args:` (or `Arguments:`, `Keyword Args:`) block.
+	ArgsSection
+	// ReturnsSection is a `Returns:` block.
+	ReturnsSection
+	// DeprecatedSection is a `Deprecated:` block.
+	DeprecatedSection
+)
+
+// DocstringArg is a single entry of an Args section.
+type DocstringArg struct {
+	// Name is the parameter name with any leading `*` or `**` removed.
+	Name string
+	// Doc is the dedented description, including continuation lines.
+	Doc string
+}
+
+// DocstringSection is a contiguous run of docstring lines.
+type DocstringSection struct {
+	Kind DocstringSectionKind
+	// Lines are the raw lines of the section, including its header.
+	Lines []string
+	// Body is the dedented section text without the header (empty for
+	// TextSection).
+	Body string
+	// Args are the parsed entries of an ArgsSection.
+	Args []DocstringArg
+}
+
+// Docstring is a docstring split into Google-style sections.
+type Docstring struct {
+	Sections []*DocstringSection
+}
+
+var (
+	sectionHeaderPattern = regexp.MustCompile(`^(\s*)(Args|Arguments|Keyword Args|Keyword Arguments|Returns|Return|Deprecated):\s*$`)
+	argEntryPattern      = regexp.MustCompile(`^\*{0,2}([A-Za-z_][A-Za-z0-9_]*)\s*(?:\([^)]*\))?\s*:(?:\s+(.*))?$`)
+)
+
+func sectionKind(header string) DocstringSectionKind {
+	switch header {
+	case "Args", "Arguments", "Keyword Args", "Keyword Arguments":
+		return ArgsSection
+	case "Returns", "Return":
+		return ReturnsSection
+	case "Deprecated":
+		return DeprecatedSection
+	}
+	return TextSection
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// ParseDocstring splits doc into text and Google-style Args, Returns and
+// Deprecated sections. A section runs from its header line until the next
+// non-blank line indented no deeper than the header.
+func ParseDocstring(doc string) *Docstring {
+	d := &Docstring{}
+	lines := strings.Split(doc, "\n")
+	var text *DocstringSection
+
+	for i := 0; i < len(lines); i++ {
+		m := sectionHeaderPattern.FindStringSubmatch(lines[i])
+		if m == nil {
+			if text == nil {
+				text = &DocstringSection{Kind: TextSection}
+				d.Sections = append(d.Sections, text)
+			}
+			text.Lines = append(text.Lines, lines[i])
+			continue
+		}
+		text = nil
+
+		headerIndent := len(m[1])
+		section := &DocstringSection{Kind: sectionKind(m[2]), Lines: []string{lines[i]}}
+		var body []string
+		for i+1 < len(lines) {
+			next := lines[i+1]
+			if strings.TrimSpace(next) != "" && indentOf(next) <= headerIndent {
+				break
+			}
+			i++
+			section.Lines = append(section.Lines, next)
+			body = append(body, next)
+		}
+		// Trailing blank lines belong to the text that follows.
+		for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+			body = body[:len(body)-1]
+			section.Lines = section.Lines[:len(section.Lines)-1]
+			i--
+		}
+		section.Body = strings.TrimSpace(Dedent(strings.Join(body, "\n")))
+		if section.Kind == ArgsSection {
+			section.Args = parseArgs(body)
+		}
+		d.Sections = append(d.Sections, section)
+	}
+	return d
+}
+
+// parseArgs parses the body lines of an Args section. Entries start at the
+// indentation of the first non-blank line; deeper lines continue the
+// previous entry.
+func parseArgs(body []string) []DocstringArg {
+	var args []DocstringArg
+	var current []string
+	entryIndent := -1
+
+	flush := func() {
+		if len(args) > 0 && current != nil {
+			args[len(args)-1].Doc = strings.TrimSpace(Dedent(strings.Join(current, "\n")))
+		}
+		current = nil
+	}
+
+	for _, line := range body {
+		if strings.TrimSpace(line) == "" {
+			if current != nil {
+				current = append(current, "")
+			}
+			continue
+		}
+		indent := indentOf(line)
+		if entryIndent < 0 {
+			entryIndent = indent
+		}
+		if indent == entryIndent {
+			if m := argEntryPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				flush()
+				args = append(args, DocstringArg{Name: m[1]})
+				current = []string{m[2]}
+				continue
+			}
+		}
+		if current != nil {
+			current = append(current, line)
+		}
+	}
+	flush()
+	return args
+}
+
+// Args returns the entries of all Args sections, in order.
+func (d *Docstring) Args() []DocstringArg {
+	var args []DocstringArg
+	for _, s := range d.Sections {
+		args = append(args, s.Args...)
+	}
+	return args
+}
+
+// Section returns the body of the first section of the given kind, or "".
+func (d *Docstring) Section(kind DocstringSectionKind) string {
+	for _, s := range d.Sections {
+		if s.Kind == kind {
+			return s.Body
+		}
+	}
+	return ""
+}
+
+// String reassembles the docstring, omitting sections for which drop
+// returns true. Blank lines left adjacent by a dropped section are collapsed.
+func (d *Docstring) String(drop func(*DocstringSection) bool) string {
+	var lines []string
+	for _, s := range d.Sections {
+		if drop != nil && drop(s) {
+			continue
+		}
+		for _, line := range s.Lines {
+			blank := strings.TrimSpace(line) == ""
+			if blank && len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,126 @@
+package stardoc
+
+import (
+	"testing"
+
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+	sdpb "github.com/bazel-contrib/bcr-frontend/stardoc_output"
+)
+
+const googleDocstring = `Copies a file.
+
+More details here.
+
+Args:
+    src: the source file.
+    dst (str): the destination,
+        possibly in another package.
+    **kwargs: passed to the rule.
+
+Returns:
+    The output file.
+
+Deprecated:
+    Use copy_to_directory instead.
+
+Example follows.`
+
+func TestParseDocstring(t *testing.T) {
+	doc := ParseDocstring(googleDocstring)
+
+	args := doc.Args()
+	want := []DocstringArg{
+		{Name: "src", Doc: "the source file."},
+		{Name: "dst", Doc: "the destination,\npossibly in another package."},
+		{Name: "kwargs", Doc: "passed to the rule."},
+	}
+	if len(args) != len(want) {
+		t.Fatalf("args: got %d, want %d: %+v", len(args), len(want), args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("args[%d]:"+errorMsg, i, want[i], args[i])
+		}
+	}
+	if got := doc.Section(ReturnsSection); got != "The output file." {
+		t.Errorf("returns:"+errorMsg, "The output file.", got)
+	}
+	if got := doc.Section(DeprecatedSection); got != "Use copy_to_directory instead." {
+		t.Errorf("deprecated:"+errorMsg, "Use copy_to_directory instead.", got)
+	}
+	if got := doc.String(nil); got != googleDocstring {
+		t.Errorf("round trip:"+errorMsg, googleDocstring, got)
+	}
+	stripped := doc.String(func(s *DocstringSection) bool { return s.Kind != TextSection })
+	if expect := "Copies a file.\n\nMore details here.\n\nExample follows."; stripped != expect {
+		t.Errorf("stripped:"+errorMsg, expect, stripped)
+	}
+}
+
+func TestParseDocstringNoSections(t *testing.T) {
+	doc := ParseDocstring("Just text.\nArgs are mentioned: inline.")
+	if len(doc.Args()) != 0 {
+		t.Errorf("unexpected args: %+v", doc.Args())
+	}
+	if len(doc.Sections) != 1 || doc.Sections[0].Kind != TextSection {
+		t.Errorf("expected a single text section, got %+v", doc.Sections)
+	}
+}
+
+func TestProcessFunctionDocstringSections(t *testing.T) {
+	info := &sdpb.StarlarkFunctionInfo{
+		FunctionName: "copy_file",
+		DocString:    googleDocstring,
+		Parameter: []*sdpb.FunctionParamInfo{
+			{Name: "src"},
+			{Name: "dst", DocString: "inline doc wins"},
+			{Name: "kwargs"},
+		},
+	}
+	sym := makeFunctionSymbol(info, nil)
+
+	fn := sym.GetFunc()
+	if got := fn.Info.Parameter[0].DocString; got != "the source file." {
+		t.Errorf("src:"+errorMsg, "the source file.", got)
+	}
+	if got := fn.Info.Parameter[1].DocString; got != "inline doc wins" {
+		t.Errorf("dst:"+errorMsg, "inline doc wins", got)
+	}
+	if got := fn.Param[2].Info.DocString; got != "passed to the rule." {
+		t.Errorf("kwargs:"+errorMsg, "passed to the rule.", got)
+	}
+	if got := fn.Info.GetReturn().GetDocString(); got != "The output file." {
+		t.Errorf("return:"+errorMsg, "The output file.", got)
+	}
+	if got := fn.Info.GetDeprecated().GetDocString(); got != "Use copy_to_directory instead." {
+		t.Errorf("deprecated:"+errorMsg, "Use copy_to_directory instead.", got)
+	}
+	expect := "Copies a file.\n\nMore details here.\n\nExample follows."
+	if sym.Description != expect {
+		t.Errorf("description:"+errorMsg, expect, sym.Description)
+	}
+}
+
+func TestProcessFunctionKeepsUnmatchedArgs(t *testing.T) {
+	doc := "Does things.\n\nArgs:\n    a: known.\n    b: not a parameter."
+	function := &slpb.Function{
+		Info: &sdpb.StarlarkFunctionInfo{
+			DocString: doc,
+			Parameter: []*sdpb.FunctionParamInfo{{Name: "a"}},
+			Return:    &sdpb.FunctionReturnInfo{DocString: "already set"},
+		},
+	}
+	processFunction(function)
+	if got := function.Info.Parameter[0].DocString; got != "known." {
+		t.Errorf("a:"+errorMsg, "known.", got)
+	}
+	if function.Info.DocString != doc {
+		t.Errorf("docstring:"+errorMsg, doc, function.Info.DocString)
+	}
+	if got := function.Info.Return.DocString; got != "already set" {
+		t.Errorf("return:"+errorMsg, "already set", got)
+	}
+	if function.Info.Deprecated != nil {
+		t.Errorf("unexpected deprecation: %v", function.Info.Deprecated)
+	}
+}
@@ -51,9 +51,69 @@ func processFunction(function *slpb.Function) {
 	for _, param := range function.Param {
 		processFunctionParam(param)
 	}
+	applyDocstringSections(function)
 	processSymbolLocation(function.Location)
 }
 
+// applyDocstringSections fills parameter, return and deprecation docs that
+// the extractor left empty from the Google-style sections of the function
+// docstring. A section is removed from the docstring only once all of its
+// content has been moved into the structured fields.
+func applyDocstringSections(function *slpb.Function) {
+	info := function.Info
+	doc := ParseDocstring(info.DocString)
+
+	params := make(map[string][]*string)
+	for _, param := range info.Parameter {
+		params[param.Name] = append(params[param.Name], &param.DocString)
+	}
+	for _, param := range function.Param {
+		if param.Info != nil {
+			params[param.Info.Name] = append(params[param.Info.Name], &param.Info.DocString)
+		}
+	}
+
+	consumed := make(map[*DocstringSection]bool)
+	for _, section := range doc.Sections {
+		switch section.Kind {
+		case ArgsSection:
+			all := len(section.Args) > 0
+			for _, arg := range section.Args {
+				docs, ok := params[arg.Name]
+				if !ok {
+					all = false
+					continue
+				}
+				for _, d := range docs {
+					if *d == "" {
+						*d = arg.Doc
+					}
+				}
+			}
+			consumed[section] = all
+		case ReturnsSection:
+			if info.Return == nil {
+				info.Return = &sdpb.FunctionReturnInfo{}
+			}
+			if info.Return.DocString == "" {
+				info.Return.DocString = section.Body
+				consumed[section] = true
+			}
+		case DeprecatedSection:
+			if info.Deprecated == nil {
+				info.Deprecated = &sdpb.FunctionDeprecationInfo{}
+			}
+			if info.Deprecated.DocString == "" {
+				info.Deprecated.DocString = section.Body
+				consumed[section] = true
+			}
+		}
+	}
+	if len(consumed) > 0 {
+		info.DocString = doc.String(func(s *DocstringSection) bool { return consumed[s] })
+	}
+}
+
 func processProviderField(field *slpb.ProviderField) {
 	field.Info.DocString = processDocString(field.Info.DocString)
 	processSymbolLocation(field.Location)
@@ -304,13 +364,13 @@ func makeMacroSymbol(info *sdpb.MacroInfo, macro *slpb.Macro) *sympb.Symbol {
 }
 
 func makeRuleMacroSymbol(ruleMacro *slpb.RuleMacro) *sympb.Symbol {
+	processRule(ruleMacro.Rule)
+	processFunction(ruleMacro.Function)
 	// Get description from rule or function
 	description := ruleMacro.Function.Info.DocString
 	if description == "" {
 		description = ruleMacro.Rule.Info.DocString
 	}
-	processRule(ruleMacro.Rule)
-	processFunction(ruleMacro.Function)
 	return &sympb.Symbol{
 		Type:        sympb.SymbolType_SYMBOL_TYPE_RULE_MACRO,
 		Name:        ruleMacro.Function.Info.FunctionName,
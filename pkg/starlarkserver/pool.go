@@ -0,0 +1,121 @@
+package starlarkserver
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool is a bounded pool of servers. Servers are started lazily, up to the
+// pool size, as concurrent callers need them.
+type Pool struct {
+	start func() (*Server, error)
+
+	mu      sync.Mutex
+	idle    []*Server
+	closed  bool
+	permits chan struct{}
+}
+
+// NewPool returns a pool of at most size servers created by start.
+func NewPool(size int, start func() (*Server, error)) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	return &Pool{
+		start:   start,
+		permits: make(chan struct{}, size),
+	}
+}
+
+// Acquire returns an idle server, starting one if fewer than the pool size
+// exist, or blocks until one is released or ctx is done. The server must be
+// returned with Release.
+func (p *Pool) Acquire(ctx context.Context) (*Server, error) {
+	select {
+	case p.permits <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	if n := len(p.idle); n > 0 {
+		s := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return s, nil
+	}
+	p.mu.Unlock()
+
+	s, err := p.start()
+	if err != nil {
+		<-p.permits
+		return nil, err
+	}
+	return s, nil
+}
+
+// Release returns a server acquired with Acquire to the pool. Pass broken
+// as true if the server is no longer usable; it is closed and a new one
+// will be started when needed.
+func (p *Pool) Release(s *Server, broken bool) {
+	p.mu.Lock()
+	if broken || p.closed {
+		p.mu.Unlock()
+		s.Close()
+	} else {
+		p.idle = append(p.idle, s)
+		p.mu.Unlock()
+	}
+	<-p.permits
+}
+
+// Close closes every server. Servers still in use are closed on release.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for _, s := range p.idle {
+		s.Close()
+	}
+	p.idle = nil
+}
+
+// Pools keeps one Pool per distinct server configuration, so that requests
+// of a persistent worker that pass different flags get servers started with
+// their own options rather than those of the first request.
+type Pools struct {
+	mu    sync.Mutex
+	pools map[poolKey]*Pool
+}
+
+type poolKey struct {
+	opts Options
+	size int
+}
+
+// Get returns the pool for the given options and size, creating it with
+// start on first use.
+func (ps *Pools) Get(opts Options, size int, start func() (*Server, error)) *Pool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	key := poolKey{opts, size}
+	if p, ok := ps.pools[key]; ok {
+		return p
+	}
+	if ps.pools == nil {
+		ps.pools = make(map[poolKey]*Pool)
+	}
+	p := NewPool(size, start)
+	ps.pools[key] = p
+	return p
+}
+
+// Close closes every pool.
+func (ps *Pools) Close() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, p := range ps.pools {
+		p.Close()
+	}
+	ps.pools = nil
+}
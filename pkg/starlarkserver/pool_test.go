@@ -0,0 +1,105 @@
+package starlarkserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	var started int
+	pool := NewPool(2, func() (*Server, error) {
+		started++
+		return &Server{Port: started, logger: log.New(io.Discard, "", 0)}, nil
+	})
+	ctx := context.Background()
+
+	a, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b || started != 2 {
+		t.Fatalf("expected two distinct servers, started %d", started)
+	}
+
+	// The pool is exhausted: Acquire blocks until the context is done.
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// A released server is reused.
+	pool.Release(a, false)
+	c, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != a || started != 2 {
+		t.Errorf("expected idle server to be reused, started %d", started)
+	}
+
+	// A broken server is replaced.
+	pool.Release(c, true)
+	d, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d == a || started != 3 {
+		t.Errorf("expected a new server, started %d", started)
+	}
+
+	pool.Release(b, false)
+	pool.Release(d, false)
+	pool.Close()
+}
+
+func TestPoolStartError(t *testing.T) {
+	pool := NewPool(1, func() (*Server, error) {
+		return nil, errors.New("no java")
+	})
+	for i := 0; i < 2; i++ {
+		// a failed start must not leak the permit
+		if _, err := pool.Acquire(context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+func TestPools(t *testing.T) {
+	start := func() (*Server, error) { return nil, errors.New("unused") }
+	var pools Pools
+	defer pools.Close()
+
+	a := pools.Get(Options{ServerJar: "a.jar"}, 2, start)
+	if got := pools.Get(Options{ServerJar: "a.jar"}, 2, start); got != a {
+		t.Error("expected the same options to share a pool")
+	}
+	if got := pools.Get(Options{ServerJar: "b.jar"}, 2, start); got == a {
+		t.Error("expected different options to get a separate pool")
+	}
+	if got := pools.Get(Options{ServerJar: "a.jar"}, 1, start); got == a {
+		t.Error("expected a different size to get a separate pool")
+	}
+}
+
+func TestGetFreePortUnique(t *testing.T) {
+	seen := make(map[int]bool)
+	for range 20 {
+		port, err := getFreePort()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[port] {
+			t.Fatalf("port %d handed out twice", port)
+		}
+		seen[port] = true
+	}
+}
@@ -0,0 +1,254 @@
+// Package starlarkserver manages the Java Starlark (constellate) server
+// processes that the extraction compilers talk to over gRPC.
+package starlarkserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Options configure how a server is started.
+type Options struct {
+	// JavaInterpreter is the path to the java binary.
+	JavaInterpreter string
+	// ServerJar is the executable jar of the server.
+	ServerJar string
+	// Port, if non-zero, is the port of an externally running server (e.g.
+	// in development); no process is started.
+	Port int
+	// LogFilePrefix names the file that receives the stdout and stderr of the
+	// server process, LogFilePrefix + ".server.log". If empty, ToolName is
+	// used as the prefix.
+	LogFilePrefix string
+	// ToolName is the name of the calling tool.
+	ToolName string
+	// Logger receives lifecycle messages.
+	Logger *log.Logger
+}
+
+// Server is a running (or external) server and a gRPC connection to it.
+type Server struct {
+	Port    int
+	Client  slpb.StarlarkClient
+	cmd     *exec.Cmd
+	exited  chan struct{} // closed when the process exits; nil if external
+	logFile *os.File
+	conn    *grpc.ClientConn
+	logger  *log.Logger
+}
+
+// startAttempts bounds how often Start picks a new port when the server
+// process exits before it is ready, typically because another process bound
+// the port between getFreePort and the server's listen.
+const startAttempts = 3
+
+// errServerExited is returned by waitForServer when the process exits.
+var errServerExited = errors.New("server process exited")
+
+// Start starts the server process unless opts.Port is set, connects to it,
+// and waits for it to be ready.
+func Start(opts Options) (*Server, error) {
+	if opts.Port != 0 {
+		// assume it is running externally (e.g., in development)
+		return connect(&Server{Port: opts.Port, logger: opts.Logger})
+	}
+
+	var err error
+	for attempt := 1; attempt <= startAttempts; attempt++ {
+		s := &Server{logger: opts.Logger}
+		port, perr := getFreePort()
+		if perr != nil {
+			return nil, fmt.Errorf("unable to determine free port: %w", perr)
+		}
+		s.Port = port
+		if err := s.startProcess(opts.JavaInterpreter, opts.ServerJar, opts.logPath()); err != nil {
+			return nil, fmt.Errorf("failed to start server process: %w", err)
+		}
+		s, err = connect(s)
+		if !errors.Is(err, errServerExited) {
+			return s, err
+		}
+		opts.Logger.Printf("Server process exited before it was ready (attempt %d of %d), retrying on a new port", attempt, startAttempts)
+	}
+	return nil, err
+}
+
+// connect connects to the server and waits for it to be ready. The server
+// is closed if it does not become ready.
+func connect(s *Server) (*Server, error) {
+	conn, err := grpc.NewClient(
+		fmt.Sprintf("localhost:%d", s.Port),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	s.conn = conn
+	s.Client = slpb.NewStarlarkClient(conn)
+
+	if err := waitForServer(s.Client, 30*time.Second, s.logger, s.exited); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("server failed to start: %w", err)
+	}
+
+	return s, nil
+}
+
+func (opts Options) logPath() string {
+	prefix := opts.LogFilePrefix
+	if prefix == "" {
+		prefix = opts.ToolName
+	}
+	return prefix + ".server.log"
+}
+
+// Close kills the server process, if we started one, and closes the
+// connection.
+func (s *Server) Close() {
+	if s.cmd != nil && s.cmd.Process != nil {
+		s.logger.Println("Shutting down server process...")
+		if err := s.cmd.Process.Kill(); err != nil {
+			s.logger.Printf("Error killing server process: %v", err)
+		}
+	}
+	if s.logFile != nil {
+		s.logFile.Close()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+func (s *Server) startProcess(javaInterpreter, serverJar, logPath string) error {
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to create server log file: %v", err)
+	}
+
+	cmd := exec.Command(
+		javaInterpreter,
+		"-jar",
+		serverJar,
+		fmt.Sprintf("--listen_port=%d", s.Port),
+	)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	s.logger.Printf("Starting server: %s -jar %s --listen_port=%d", javaInterpreter, serverJar, s.Port)
+	s.logger.Printf("Server logs: %s", logPath)
+
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return fmt.Errorf("failed to start server: %v", err)
+	}
+
+	// Monitor server process in background
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		if err := cmd.Wait(); err != nil {
+			s.logger.Printf("Server process exited with error: %v", err)
+			s.logger.Printf("Check server logs at: %s", logPath)
+		} else {
+			s.logger.Printf("Server process exited cleanly")
+		}
+	}()
+
+	s.cmd = cmd
+	s.exited = exited
+	s.logFile = logFile
+	return nil
+}
+
+// IsConnectionError reports whether err indicates that the server is not
+// reachable.
+func IsConnectionError(err error) bool {
+	s := err.Error()
+	return strings.Contains(s, "connection refused") ||
+		strings.Contains(s, "connection reset") ||
+		strings.Contains(s, "connect: no route to host") ||
+		(strings.Contains(s, "dial tcp") && strings.Contains(s, "connect:"))
+}
+
+// waitForServer pings the server until it responds. It returns
+// errServerExited if exited is closed first.
+func waitForServer(client slpb.StarlarkClient, timeout time.Duration, logger *log.Logger, exited <-chan struct{}) error {
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	logger.Println("Waiting for server to be ready...")
+	attempts := 0
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ticker.C:
+		case <-exited:
+			return errServerExited
+		}
+		attempts++
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err := client.Ping(ctx, &slpb.PingRequest{})
+		cancel()
+
+		// If we get any response (even an error), server is up
+		if err == nil {
+			logger.Printf("Server ready after %d attempts", attempts)
+			return nil
+		}
+		if !IsConnectionError(err) {
+			logger.Printf("Server responding after %d attempts (with error, but that's ok)", attempts)
+			return nil
+		}
+
+		if attempts%10 == 0 {
+			logger.Printf("Still waiting for server... (attempt %d)", attempts)
+		}
+	}
+
+	return fmt.Errorf("server did not start within %v after %d attempts", timeout, attempts)
+}
+
+var (
+	portsMu   sync.Mutex
+	portsUsed = make(map[int]bool)
+)
+
+// getFreePort asks the OS for a free port. Ports are never handed out twice
+// within the process, so concurrent Starts cannot pick the same one; another
+// process can still take it before the server listens, which Start handles
+// by retrying.
+func getFreePort() (int, error) {
+	portsMu.Lock()
+	defer portsMu.Unlock()
+	for {
+		addr, err := net.ResolveTCPAddr("tcp", "localhost:0")
+		if err != nil {
+			return 0, err
+		}
+		l, err := net.ListenTCP("tcp", addr)
+		if err != nil {
+			return 0, err
+		}
+		port := l.Addr().(*net.TCPAddr).Port
+		l.Close()
+		if !portsUsed[port] {
+			portsUsed[port] = true
+			return port, nil
+		}
+	}
+}
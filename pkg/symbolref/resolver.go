@@ -0,0 +1,230 @@
+// Package symbolref resolves load statements of extracted Starlark files
+// to the module versions selected by MVS and to the files and symbols they
+// refer to.
+package symbolref
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	bzpb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/registry/v1"
+	sympb "github.com/bazel-contrib/bcr-frontend/build/stack/bazel/symbol/v1"
+	slpb "github.com/bazel-contrib/bcr-frontend/build/stack/starlark/v1beta1"
+)
+
+// maxReexportDepth bounds how many re-export hops are followed when
+// resolving a loaded symbol to its definition.
+const maxReexportDepth = 8
+
+type fileKey struct {
+	pkg, name string
+}
+
+// ModuleVersionKey returns NAME@VERSION.
+func ModuleVersionKey(name, version string) string {
+	return name + "@" + version
+}
+
+// Resolver maps load statements in one module version to files and symbols
+// in the module versions selected by MVS.
+type Resolver struct {
+	versions map[string]*bzpb.ModuleVersion
+	files    map[string]map[fileKey]*sympb.File
+}
+
+// NewResolver indexes the module versions of registry and the files of
+// symbols.
+func NewResolver(registry *bzpb.Registry, symbols *sympb.ModuleRegistrySymbols) *Resolver {
+	r := &Resolver{
+		versions: make(map[string]*bzpb.ModuleVersion),
+		files:    make(map[string]map[fileKey]*sympb.File),
+	}
+	for _, module := range registry.Modules {
+		for _, mv := range module.Versions {
+			r.versions[ModuleVersionKey(module.Name, mv.Version)] = mv
+		}
+	}
+	for _, mvs := range symbols.ModuleVersion {
+		files := make(map[fileKey]*sympb.File)
+		for _, file := range mvs.File {
+			if file.Label != nil {
+				files[fileKey{file.Label.Pkg, file.Label.Name}] = file
+			}
+		}
+		r.files[ModuleVersionKey(mvs.ModuleName, mvs.Version)] = files
+	}
+	return r
+}
+
+// CanonicalRepoName reduces a repository name as written in a load label
+// (apparent, or canonical such as "rules_cc+" or "rules_cc~1.0") to the
+// name used for matching against module dependencies.
+func CanonicalRepoName(repo string) (name string, canonical bool) {
+	repo = strings.TrimLeft(repo, "@")
+	if i := strings.IndexAny(repo, "+~"); i >= 0 {
+		return repo[:i], true
+	}
+	return repo, false
+}
+
+// ModuleForRepo maps the repository of a load label to the module name and
+// version it refers to from the perspective of mv.
+func (r *Resolver) ModuleForRepo(moduleName string, mv *bzpb.ModuleVersion, repo string) (string, string, error) {
+	name, canonical := CanonicalRepoName(repo)
+	if name == "" || name == moduleName || (!canonical && name == mv.RepoName) {
+		return moduleName, mv.Version, nil
+	}
+	for _, dep := range mv.Deps {
+		apparent := cmp.Or(dep.RepoName, dep.Name)
+		if apparent != name && !(canonical && dep.Name == name) {
+			continue
+		}
+		version := cmp.Or(dep.SelectedVersion, dep.Version)
+		if version == "" {
+			return dep.Name, "", fmt.Errorf("no version known for dependency %s", dep.Name)
+		}
+		return dep.Name, version, nil
+	}
+	return "", "", fmt.Errorf("repository @%s is not a dependency of %s", name, ModuleVersionKey(moduleName, mv.Version))
+}
+
+// FindFile looks up the loaded file, falling back to the loading file's
+// package for labels that were recorded relative to it.
+func (r *Resolver) FindFile(moduleName, version string, lbl *slpb.Label, fromPkg string) *sympb.File {
+	files := r.files[ModuleVersionKey(moduleName, version)]
+	if file, ok := files[fileKey{lbl.Pkg, lbl.Name}]; ok {
+		return file
+	}
+	if lbl.Repo == "" && lbl.Pkg == "" {
+		return files[fileKey{fromPkg, lbl.Name}]
+	}
+	return nil
+}
+
+// ResolveLoad resolves a load statement written in package fromPkg of
+// moduleName at mv to the loaded file and the definitions of its symbols.
+func (r *Resolver) ResolveLoad(moduleName string, mv *bzpb.ModuleVersion, fromPkg string, load *slpb.LoadStmt) *sympb.ResolvedLoad {
+	resolved := &sympb.ResolvedLoad{Label: load.Label}
+	for _, sym := range load.Symbol {
+		resolved.Symbol = append(resolved.Symbol, &sympb.ResolvedLoadSymbol{From: sym.From, To: sym.To})
+	}
+	if load.Label == nil {
+		resolved.Error = "load statement has no label"
+		return resolved
+	}
+
+	depName, depVersion, err := r.ModuleForRepo(moduleName, mv, load.Label.Repo)
+	if err != nil {
+		resolved.Error = err.Error()
+		return resolved
+	}
+	if _, ok := r.files[ModuleVersionKey(depName, depVersion)]; !ok {
+		resolved.File = &sympb.FileRef{ModuleName: depName, Version: depVersion, File: FileLabel(load.Label)}
+		resolved.Error = fmt.Sprintf("no symbols available for %s", ModuleVersionKey(depName, depVersion))
+		return resolved
+	}
+	file := r.FindFile(depName, depVersion, load.Label, fromPkg)
+	if file == nil {
+		resolved.File = &sympb.FileRef{ModuleName: depName, Version: depVersion, File: FileLabel(load.Label)}
+		resolved.Error = fmt.Sprintf("file %s not found in %s", fileLabelString(load.Label), ModuleVersionKey(depName, depVersion))
+		return resolved
+	}
+	resolved.File = &sympb.FileRef{ModuleName: depName, Version: depVersion, File: FileLabel(file.Label)}
+
+	for _, sym := range resolved.Symbol {
+		definedIn, symbol := r.ResolveSymbol(depName, depVersion, file, sym.From)
+		if symbol == nil {
+			continue
+		}
+		sym.DefinedIn = definedIn
+		sym.Name = symbol.Name
+		sym.Type = symbol.Type
+		sym.Deprecated = IsDeprecated(symbol)
+	}
+	return resolved
+}
+
+// ResolveSymbol finds the definition of name in file, following re-exports
+// (a load of the name into file) across files and modules.
+func (r *Resolver) ResolveSymbol(moduleName, version string, file *sympb.File, name string) (*sympb.FileRef, *sympb.Symbol) {
+	return r.resolveSymbol(moduleName, version, file, name, 0)
+}
+
+func (r *Resolver) resolveSymbol(moduleName, version string, file *sympb.File, name string, depth int) (*sympb.FileRef, *sympb.Symbol) {
+	for _, sym := range file.Symbol {
+		if sym.Name == name && sym.Type != sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT {
+			return &sympb.FileRef{ModuleName: moduleName, Version: version, File: FileLabel(file.Label)}, sym
+		}
+	}
+	if depth >= maxReexportDepth {
+		return nil, nil
+	}
+	mv := r.versions[ModuleVersionKey(moduleName, version)]
+	if mv == nil {
+		return nil, nil
+	}
+	for _, sym := range file.Symbol {
+		load := sym.GetLoad()
+		if load == nil || load.Label == nil {
+			continue
+		}
+		for _, ls := range load.Symbol {
+			if ls.To != name {
+				continue
+			}
+			depName, depVersion, err := r.ModuleForRepo(moduleName, mv, load.Label.Repo)
+			if err != nil {
+				return nil, nil
+			}
+			next := r.FindFile(depName, depVersion, load.Label, file.Label.GetPkg())
+			if next == nil {
+				return nil, nil
+			}
+			return r.resolveSymbol(depName, depVersion, next, ls.From, depth+1)
+		}
+	}
+	return nil, nil
+}
+
+// IsDeprecated reports whether a function or rule macro symbol carries a
+// deprecation notice.
+func IsDeprecated(sym *sympb.Symbol) bool {
+	switch info := sym.Info.(type) {
+	case *sympb.Symbol_Func:
+		return info.Func.GetInfo().GetDeprecated() != nil
+	case *sympb.Symbol_RuleMacro:
+		return info.RuleMacro.GetFunction().GetInfo().GetDeprecated() != nil
+	}
+	return false
+}
+
+// FileLabel strips the repository from a file label; the module is carried
+// by the enclosing FileRef.
+func FileLabel(lbl *slpb.Label) *slpb.Label {
+	return &slpb.Label{Pkg: lbl.GetPkg(), Name: lbl.GetName()}
+}
+
+func fileLabelString(lbl *slpb.Label) string {
+	return "//" + lbl.Pkg + ":" + lbl.Name
+}
+
+// ModuleVersion returns the registry entry for name@version, or nil.
+func (r *Resolver) ModuleVersion(name, version string) *bzpb.ModuleVersion {
+	return r.versions[ModuleVersionKey(name, version)]
+}
+
+// Symbol returns the symbol named name in the file referenced by ref, or
+// nil.
+func (r *Resolver) Symbol(ref *sympb.FileRef, name string) *sympb.Symbol {
+	if ref == nil || ref.File == nil {
+		return nil
+	}
+	file := r.files[ModuleVersionKey(ref.ModuleName, ref.Version)][fileKey{ref.File.Pkg, ref.File.Name}]
+	for _, sym := range file.GetSymbol() {
+		if sym.Name == name && sym.Type != sympb.SymbolType_SYMBOL_TYPE_LOAD_STMT {
+			return sym
+		}
+	}
+	return nil
+}
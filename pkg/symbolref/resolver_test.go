@@ -0,0 +1,21 @@
+package symbolref
+
+import "testing"
+
+func TestCanonicalRepoName(t *testing.T) {
+	for _, tc := range []struct {
+		in        string
+		want      string
+		canonical bool
+	}{
+		{"rules_cc", "rules_cc", false},
+		{"@rules_cc+", "rules_cc", true},
+		{"rules_cc~0.1.0", "rules_cc", true},
+		{"", "", false},
+	} {
+		got, canonical := CanonicalRepoName(tc.in)
+		if got != tc.want || canonical != tc.canonical {
+			t.Errorf("CanonicalRepoName(%q) = %q, %v; want %q, %v", tc.in, got, canonical, tc.want, tc.canonical)
+		}
+	}
+}
@@ -1,5 +1,6 @@
 "provides the module_registry rule"
 
+load("@bazel_skylib//rules:common_settings.bzl", "BuildSettingInfo")
 load("@build_stack_rules_proto//rules:starlark_module_library.bzl", "StarlarkModuleLibraryInfo")
 load(
     "//rules:providers.bzl",
@@ -232,6 +233,11 @@ def _add_args_for_starlark_packages(args, module_name, starlark_packages, deps):
         for dep in deps:
             args.add("--module_dep=%s:%s=%s" % (module_name, dep.name, dep.repo_name))
 
+def _add_extraction_cache_dir_arg(ctx, args):
+    cache_dir = ctx.attr.extraction_cache_dir[BuildSettingInfo].value
+    if cache_dir:
+        args.add("--cache_dir", cache_dir)
+
 def _status_code_exists(code):
     return code >= 200 and code < 300
 
@@ -317,6 +323,11 @@ def _compile_bzl_for_module_version(ctx, mv, all_mv_by_id):
     # args.add("--error_limit=0")
     args.add("--log_file", "/tmp/bzlcompiler.log")
 
+    # content-addressed cache of extraction results, shared between module
+    # versions, runs and both extraction tools (opt-in, see
+    # //app/bcr:extraction_cache_dir).
+    _add_extraction_cache_dir_arg(ctx, args)
+
     # Add bzl_files and module_deps without flattening depsets
 
     # 1. Bazel tools and @_builtins
@@ -350,6 +361,8 @@ def _compile_bzl_for_module_version(ctx, mv, all_mv_by_id):
         progress_message = "Extracting docs for %s@%s (%d files)" % (mv.name, mv.version, len(mv.bzl_src.srcs)),
         execution_requirements = {
             "supports-workers": "1",
+            "supports-multiplex-workers": "1",
+            "supports-worker-cancellation": "1",
             "requires-worker-protocol": "proto",
         },
         executable = ctx.executable._bzlcompiler,
@@ -417,6 +430,11 @@ def _compile_packages_for_module_version(ctx, mv, all_mv_by_id):
     args.add("--server_jar_file", ctx.file._starlarkserverjar)
     args.add("--log_file", "/tmp/packagecompiler.log")
 
+    # content-addressed cache of extraction results, shared between module
+    # versions, runs and both extraction tools (opt-in, see
+    # //app/bcr:extraction_cache_dir).
+    _add_extraction_cache_dir_arg(ctx, args)
+
     # bazel run //src/main/java/build/stack/devtools/build/constellate:server -- --listen_port=3535 2>&1 | tee starlarkserver.log
     # args.add("--port", 3524)  # e.g. java -jar ./cmd/bzlcompiler/constellate.jar --listen_port=3535
 
@@ -455,6 +473,8 @@ def _compile_packages_for_module_version(ctx, mv, all_mv_by_id):
         progress_message = "Extracting packages for %s@%s (%d files)" % (mv.name, mv.version, len(mv.pkg_src.srcs)),
         execution_requirements = {
             "supports-workers": "1",
+            "supports-multiplex-workers": "1",
+            "supports-worker-cancellation": "1",
             "requires-worker-protocol": "proto",
         },
         executable = ctx.executable._packagecompiler,
@@ -594,8 +614,10 @@ def _compile_colors_action(ctx, colors_json, languages_json):
 
     return output
 
-def _compile_sitemap_action(ctx, registry_pb, bazel_flag_db_pb):
-    output = ctx.actions.declare_file("sitemap.xml")
+def _compile_sitemap_action(ctx, registry_pb, symbols_pb, bazel_flag_db_pb):
+    """Produces sitemap.tar: a sitemap index at sitemap.xml plus its gzipped
+    shards under sitemaps/, merged into the release tarball verbatim."""
+    output = ctx.actions.declare_file("sitemap.tar")
 
     # Build arguments for the compiler
     args = ctx.actions.args()
@@ -603,6 +625,8 @@ def _compile_sitemap_action(ctx, registry_pb, bazel_flag_db_pb):
     args.add(output)
     args.add("--registry_file")
     args.add(registry_pb)
+    args.add("--symbols_file")
+    args.add(symbols_pb)
     args.add("--base_url")
     args.add(ctx.attr.registry_url)
     args.add("--bazel_flag_db_file")
@@ -611,7 +635,7 @@ def _compile_sitemap_action(ctx, registry_pb, bazel_flag_db_pb):
     ctx.actions.run(
         executable = ctx.executable._sitemapcompiler,
         arguments = [args],
-        inputs = [registry_pb, bazel_flag_db_pb],
+        inputs = [registry_pb, symbols_pb, bazel_flag_db_pb],
         outputs = [output],
         mnemonic = "CompileSitemap",
         progress_message = "Compiling sitemap",
@@ -647,12 +671,13 @@ def _route_to_dict(r):
     }
 
 def _compile_sitemap_index_action(ctx):
-    """Produces sitemap.xml.gz + sitemapindex.xml from aggregated RouteInfo.
+    """Produces routes_sitemap.tar from aggregated RouteInfo.
 
     Aggregates RouteInfo across all module dependencies, writes the merged
-    routes manifest, and runs cmd/sitemapindexcompiler. Lands alongside the
-    existing sitemap.xml from _compile_sitemap_action — the two pipelines
-    coexist during the migration.
+    routes manifest, and runs cmd/sitemapindexcompiler, which shards the
+    routes into sitemap-N.xml.gz files indexed by sitemapindex.xml. Lands
+    alongside the sitemap.tar from _compile_sitemap_action — the two
+    pipelines coexist during the migration.
     """
     transitive_routes = [dep[RouteInfo].routes for dep in ctx.attr.deps if RouteInfo in dep]
     all_routes = depset(
@@ -666,25 +691,199 @@ def _compile_sitemap_index_action(ctx):
         json.encode([_route_to_dict(r) for r in all_routes.to_list()]),
     )
 
-    sitemap_gz = ctx.actions.declare_file("sitemap.xml.gz")
-    sitemap_index = ctx.actions.declare_file("sitemapindex.xml")
+    output = ctx.actions.declare_file("routes_sitemap.tar")
 
     args = ctx.actions.args()
     args.add("--routes_file", routes_json)
     args.add("--base_url", ctx.attr.registry_url)
-    args.add("--sitemap_output", sitemap_gz)
-    args.add("--sitemapindex_output", sitemap_index)
-    args.add("--sitemap_url", ctx.attr.registry_url + "/sitemap.xml.gz")
+    args.add("--output_file", output)
 
     ctx.actions.run(
         executable = ctx.executable._sitemapindexcompiler,
         arguments = [args],
         inputs = [routes_json],
-        outputs = [sitemap_gz, sitemap_index],
+        outputs = [output],
         mnemonic = "CompileSitemapIndex",
         progress_message = "Compiling sitemap index",
     )
-    return sitemap_gz, sitemap_index, routes_json
+    return output, routes_json
+
+def _compile_maintainer_index_action(ctx, registry_pb):
+    output = ctx.actions.declare_file("maintainers.pb")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--registry_file", registry_pb)
+
+    ctx.actions.run(
+        executable = ctx.executable._maintainerindexcompiler,
+        arguments = [args],
+        inputs = [registry_pb],
+        outputs = [output],
+        mnemonic = "CompileMaintainerIndex",
+        progress_message = "Compiling maintainer index",
+    )
+
+    return output
+
+def _compile_symbol_refs_action(ctx, registry_pb, symbols_pb):
+    output = ctx.actions.declare_file("symbolrefs.pb")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--registry_file", registry_pb)
+    args.add("--symbols_file", symbols_pb)
+
+    ctx.actions.run(
+        executable = ctx.executable._symbolrefcompiler,
+        arguments = [args],
+        inputs = [registry_pb, symbols_pb],
+        outputs = [output],
+        mnemonic = "CompileSymbolRefs",
+        progress_message = "Resolving cross-module symbol references",
+    )
+
+    return output
+
+def _compile_doc_coverage_action(ctx, symbols_pb):
+    output = ctx.actions.declare_file("doccoverage.pb")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--symbols_file", symbols_pb)
+
+    ctx.actions.run(
+        executable = ctx.executable._doccoveragecompiler,
+        arguments = [args],
+        inputs = [symbols_pb],
+        outputs = [output],
+        mnemonic = "CompileDocCoverage",
+        progress_message = "Compiling documentation coverage",
+    )
+
+    return output
+
+def _compile_rule_usage_action(ctx, registry_pb, packages_pb, symbols_pb):
+    output = ctx.actions.declare_file("ruleusage.pb")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--registry_file", registry_pb)
+    args.add("--packages_file", packages_pb)
+    args.add("--symbols_file", symbols_pb)
+
+    ctx.actions.run(
+        executable = ctx.executable._ruleusagecompiler,
+        arguments = [args],
+        inputs = [registry_pb, packages_pb, symbols_pb],
+        outputs = [output],
+        mnemonic = "CompileRuleUsage",
+        progress_message = "Aggregating rule usage across BUILD files",
+    )
+
+    return output
+
+def _compile_licenses_action(ctx, packages_pb):
+    output = ctx.actions.declare_file("licenses.pb")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--packages_file", packages_pb)
+
+    ctx.actions.run(
+        executable = ctx.executable._licensecompiler,
+        arguments = [args],
+        inputs = [packages_pb],
+        outputs = [output],
+        mnemonic = "CompileLicenses",
+        progress_message = "Extracting license inventory",
+    )
+
+    return output
+
+def _compile_extraction_errors_action(ctx, symbols_pb, packages_pb):
+    output = ctx.actions.declare_file("extractionerrors.pb")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--symbols_file", symbols_pb)
+    args.add("--packages_file", packages_pb)
+
+    ctx.actions.run(
+        executable = ctx.executable._extractionerrorcompiler,
+        arguments = [args],
+        inputs = [symbols_pb, packages_pb],
+        outputs = [output],
+        mnemonic = "CompileExtractionErrors",
+        progress_message = "Aggregating extraction errors by cause",
+    )
+
+    return output
+
+def _compile_feeds_action(ctx, registry_pb):
+    output = ctx.actions.declare_file("feeds.tar")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--registry_file", registry_pb)
+    args.add("--base_url", ctx.attr.registry_url)
+
+    ctx.actions.run(
+        executable = ctx.executable._feedcompiler,
+        arguments = [args],
+        inputs = [registry_pb],
+        outputs = [output],
+        mnemonic = "CompileFeeds",
+        progress_message = "Compiling change feeds",
+    )
+
+    return output
+
+def _registry_srcs(deps):
+    """Collects the raw registry files of every module and module version.
+
+    These are the files Bazel fetches from an index registry (metadata.json,
+    MODULE.bazel, source.json, patches/..., overlay/...), plus the
+    attestations and presubmit files that accompany them. releasecompiler
+    lays them out under registry/modules/... so the release doubles as a
+    registry mirror.
+    """
+    files = []
+    for d in deps:
+        if d.metadata_json:
+            files.append(d.metadata_json)
+        for mv in d.deps:
+            if mv.module_bazel:
+                files.append(mv.module_bazel)
+            if mv.source:
+                files.append(mv.source.source_json)
+                files.extend(mv.source.patch_files)
+                files.extend(mv.source.overlay_files)
+            if mv.attestations and mv.attestations.attestations_json:
+                files.append(mv.attestations.attestations_json)
+            if mv.presubmit and mv.presubmit.presubmit_yml:
+                files.append(mv.presubmit.presubmit_yml)
+    return depset(files)
+
+def _compile_api_action(ctx, registry_pb, symbols_pb, bazel_flag_db):
+    output = ctx.actions.declare_file("api.tar")
+
+    args = ctx.actions.args()
+    args.add("--output_file", output)
+    args.add("--registry_file", registry_pb)
+    args.add("--symbols_file", symbols_pb)
+    args.add("--bazel_flag_db_file", bazel_flag_db)
+
+    ctx.actions.run(
+        executable = ctx.executable._apicompiler,
+        arguments = [args],
+        inputs = [registry_pb, symbols_pb, bazel_flag_db],
+        outputs = [output],
+        mnemonic = "CompileApi",
+        progress_message = "Compiling static API documents",
+    )
+
+    return output
 
 def _write_robots_txt_action(ctx):
     output = ctx.actions.declare_file("robots.txt")
@@ -756,11 +955,19 @@ def _module_registry_impl(ctx):
     packages_pb = _compile_module_registry_packages(ctx, pkg_results)
     registry_pb = _compile_registry_action(ctx, "registry.pb", modules, symbols_pb)
     registrylite_pb = _compile_registry_action(ctx, "registrylite.pb", modules)
+    maintainers_pb = _compile_maintainer_index_action(ctx, registrylite_pb)
+    feeds_tar = _compile_feeds_action(ctx, registrylite_pb)
+    symbolrefs_pb = _compile_symbol_refs_action(ctx, registrylite_pb, symbols_pb)
+    doccoverage_pb = _compile_doc_coverage_action(ctx, symbols_pb)
+    ruleusage_pb = _compile_rule_usage_action(ctx, registrylite_pb, packages_pb, symbols_pb)
+    licenses_pb = _compile_licenses_action(ctx, packages_pb)
+    extractionerrors_pb = _compile_extraction_errors_action(ctx, symbols_pb, packages_pb)
 
     bazel_help = _compile_bazel_help_registry_action(ctx, bazel_versions)
     bazel_flag_db = _compile_bazel_flag_db_action(ctx, bazel_help)
-    sitemap_xml = _compile_sitemap_action(ctx, registry_pb, bazel_flag_db)
-    sitemap_gz, sitemap_index, routes_json = _compile_sitemap_index_action(ctx)
+    api_tar = _compile_api_action(ctx, registrylite_pb, symbols_pb, bazel_flag_db)
+    sitemap_tar = _compile_sitemap_action(ctx, registrylite_pb, symbols_pb, bazel_flag_db)
+    routes_sitemap_tar, routes_json = _compile_sitemap_index_action(ctx)
     prerender_urls = _write_prerender_urls_action(ctx, deps)
 
     # Per-module-version output groups.
@@ -781,14 +988,17 @@ def _module_registry_impl(ctx):
             repos_json = [repos_json],
             languages_json = [languages_json],
             colors_css = [colors_css],
-            sitemap_xml = [sitemap_xml],
-            sitemap_gz = [sitemap_gz],
-            sitemapindex_xml = [sitemap_index],
+            sitemap_tar = [sitemap_tar],
+            routes_sitemap_tar = [routes_sitemap_tar],
             routes_json = [routes_json],
             prerender_urls = [prerender_urls],
             robots_txt = [robots_txt],
             registry_pb = [registry_pb],
             registrylite_pb = [registrylite_pb],
+            maintainers_pb = [maintainers_pb],
+            feeds_tar = [feeds_tar],
+            api_tar = [api_tar],
+            registry_srcs = _registry_srcs(deps),
             codesearch_index = [codesearch_index],
             # The @_builtins output is a single shared file (not per-MV),
             # is already aggregated into symbols.pb, and lives at a non-
@@ -797,6 +1007,11 @@ def _module_registry_impl(ctx):
             doc_results = depset([d.output for d in doc_results if d.output != None and d.mv.name != "_builtins"]),
             docs = depset([r.output for r in doc_results if r.output != None and r.mv.name != "_builtins"]),
             symbols_pb = depset([symbols_pb]),
+            symbolrefs_pb = depset([symbolrefs_pb]),
+            doccoverage_pb = depset([doccoverage_pb]),
+            ruleusage_pb = depset([ruleusage_pb]),
+            licenses_pb = depset([licenses_pb]),
+            extractionerrors_pb = depset([extractionerrors_pb]),
             packages_pb = depset([packages_pb]),
             pkg_results = depset([r.output for r in pkg_results if r.output != None]),
             bazel_help = depset([bazel_help]),
@@ -829,6 +1044,12 @@ module_registry = rule(
         "branch": attr.string(doc = "Branch name of the repository data (e.g. 'main')"),
         "commit": attr.string(doc = "Commit sha1 of the repository data"),
         "commit_date": attr.string(doc = "Timestamp of the commit date (same format as: git log --format='%ci')"),
+        "extraction_cache_dir": attr.label(
+            default = "//app/bcr:extraction_cache_dir",
+            providers = [BuildSettingInfo],
+            doc = "Label of a string_flag naming a directory to cache Starlark " +
+                  "extraction results in. Empty (the default) disables the cache.",
+        ),
         "_colors_json": attr.label(
             default = "@com_github_ozh_github_colors//:colors_json",
             allow_single_file = True,
@@ -853,6 +1074,46 @@ module_registry = rule(
             executable = True,
             cfg = "exec",
         ),
+        "_maintainerindexcompiler": attr.label(
+            default = "//cmd/maintainerindexcompiler",
+            executable = True,
+            cfg = "exec",
+        ),
+        "_symbolrefcompiler": attr.label(
+            default = "//cmd/symbolrefcompiler",
+            executable = True,
+            cfg = "exec",
+        ),
+        "_doccoveragecompiler": attr.label(
+            default = "//cmd/doccoveragecompiler",
+            executable = True,
+            cfg = "exec",
+        ),
+        "_ruleusagecompiler": attr.label(
+            default = "//cmd/ruleusagecompiler",
+            executable = True,
+            cfg = "exec",
+        ),
+        "_licensecompiler": attr.label(
+            default = "//cmd/licensecompiler",
+            executable = True,
+            cfg = "exec",
+        ),
+        "_extractionerrorcompiler": attr.label(
+            default = "//cmd/extractionerrorcompiler",
+            executable = True,
+            cfg = "exec",
+        ),
+        "_feedcompiler": attr.label(
+            default = "//cmd/feedcompiler",
+            executable = True,
+            cfg = "exec",
+        ),
+        "_apicompiler": attr.label(
+            default = "//cmd/apicompiler",
+            executable = True,
+            cfg = "exec",
+        ),
         "_codesearchcompiler": attr.label(
             default = "//cmd/codesearchcompiler",
             executable = True,
@@ -9,10 +9,14 @@ def _module_source_impl(ctx):
             url_status_code = ctx.attr.url_status_code,
             url_status_message = ctx.attr.url_status_message,
             integrity = ctx.attr.integrity,
+            integrity_status = ctx.attr.integrity_status,
             strip_prefix = ctx.attr.strip_prefix,
             patch_strip = ctx.attr.patch_strip,
             patches = ctx.attr.patches,
             overlay = ctx.attr.overlay,
+            overlay_files = ctx.files.overlay_files,
+            patch_stats = ctx.attr.patch_stats,
+            patch_files = ctx.files.patch_files,
             source_json = ctx.file.source_json,
             docs_url = ctx.attr.docs_url,
             docs_url_status_code = ctx.attr.docs_url_status_code,
@@ -37,6 +41,9 @@ module_source = rule(
         "integrity": attr.string(
             doc = "str: Source integrity hash (e.g., sha256-...)",
         ),
+        "integrity_status": attr.string_list(
+            doc = "list[str]: JSON-encoded integrity verification result of each downloaded source and mirror URL",
+        ),
         "strip_prefix": attr.string(
             doc = "str: Directory prefix to strip from the archive",
         ),
@@ -47,9 +54,20 @@ module_source = rule(
         "patches": attr.string_dict(
             doc = "dict[str, str]: Mapping of patch filename to integrity hash",
         ),
+        "patch_stats": attr.string_list(
+            doc = "list[str]: JSON-encoded content stats of each patch in `patches`, parsed from the patch content",
+        ),
+        "patch_files": attr.label_list(
+            doc = "list[File]: The patch files listed in `patches`, from the patches/ directory",
+            allow_files = True,
+        ),
         "overlay": attr.string_dict(
             doc = "dict[str, str]: Mapping of overlay filename to integrity hash",
         ),
+        "overlay_files": attr.label_list(
+            doc = "list[File]: The overlay files listed in `overlay`, from the overlay/ directory",
+            allow_files = True,
+        ),
         "docs_url": attr.string(
             doc = "str: Documentation archive URL (empty string if not set)",
         ),
@@ -35,6 +35,14 @@ def _compile_action(ctx, source, deps, attestations, presubmit, commit):
         args.add("--unresolved_deps")
         args.add(",".join(unresolved_deps))
 
+    # The versions selected by MVS (computed by gazelle) let downstream
+    # compilers resolve load() statements against the exact dependency
+    # version.
+    for name, version in ctx.attr.mvs.items():
+        args.add("--mvs=%s=%s" % (name, version))
+    for name, version in ctx.attr.mvs_dev.items():
+        args.add("--mvs_dev=%s=%s" % (name, version))
+
     # Collect all input files
     inputs = [ctx.file.module_bazel]
 
@@ -48,6 +56,10 @@ def _compile_action(ctx, source, deps, attestations, presubmit, commit):
         args.add("--url_status_message=" + source.url_status_message)
         args.add("--docs_url_status_code=" + str(source.docs_url_status_code))
         args.add("--docs_url_status_message=" + source.docs_url_status_message)
+        for status in source.integrity_status:
+            args.add("--integrity_status=" + status)
+        for stats in source.patch_stats:
+            args.add("--patch_stats=" + stats)
 
         if source.commit_sha:
             args.add("--source_commit_sha")
@@ -4,12 +4,19 @@
 `prerender_pages` captures every URL listed in a text file (one path per line,
 e.g. `/modules/rules_buf`) and emits a tar containing entries at
 `modules/<name>/index.html` so the release pipeline can drop them into the
-final release archive verbatim.
+final release archive verbatim. A validation action (`prerendercheck`)
+rejects blank or error pages and, given a baseline, large text changes.
 
 Both rules boot `releaseserver` on a free port and drive
 chrome-headless-shell via `statichtmlcompiler`. The browser binary, server,
 and compiler tool defaults are baked in as private attrs; callers usually
 just need to supply `tarball` and (for prerender_pages) `url_list`.
+
+The `renderer` flag switches both rules to the pure-Go renderer ("go"),
+which reads the tarball directly and needs neither the server nor Chrome
+and its system libraries, or to "diff", which renders with Chrome and fails
+if the Go rendering of any page disagrees with the snapshot. In diff mode the
+comparison report is available from the `diff_report` output group.
 """
 
 load("@bazel_skylib//rules:common_settings.bzl", "BuildSettingInfo")
@@ -37,15 +44,29 @@ _TOOL_ATTRS = {
     ),
 }
 
-_PRERENDER_CMD = """\
-set -e
+_RENDERER_ATTRS = {
+    "renderer": attr.label(
+        default = "//app/bcr:prerender_renderer",
+        providers = [BuildSettingInfo],
+        doc = "Label of a string_flag selecting the renderer: \"chrome\" " +
+              "(headless Chrome snapshots), \"go\" (pure-Go skeleton rendered " +
+              "from the tarball's protos; no browser, no server, no Chrome " +
+              "system libraries) or \"diff\" (Chrome snapshots, checked " +
+              "against the Go rendering).",
+    ),
+}
+
+# Boots releaseserver for the Chrome-based renderers and sets BASE_URL. The
+# go renderer reads the tarball directly, so BASE_URL stays empty and the
+# compiler gets bare paths.
+_SERVER_CMD = """\
 PORT_FILE=$(mktemp -t bcr_prerender.XXXXXX)
 SERVER_PID=""
-cleanup() {{
+cleanup_server() {{
   if [ -n "$SERVER_PID" ]; then kill "$SERVER_PID" 2>/dev/null || true; fi
   rm -f "$PORT_FILE"
 }}
-trap cleanup EXIT
+trap cleanup_server EXIT
 
 {server} --port=0 --port_file="$PORT_FILE" {tarball} >/dev/null 2>&1 &
 SERVER_PID=$!
@@ -59,34 +80,81 @@ if [ ! -s "$PORT_FILE" ]; then
   echo "releaseserver did not write a port file" >&2
   exit 1
 fi
-PORT=$(cat "$PORT_FILE")
+BASE_URL="http://localhost:$(cat "$PORT_FILE")"
+"""
+
+_NO_SERVER_CMD = """\
+cleanup_server() {{ :; }}
+BASE_URL=""
+"""
 
+_PRERENDER_CMD = """\
+set -e
+{server_cmd}
 {compiler} \\
+  --renderer={renderer} \\
+  --release_tar={tarball} \\
   --chromedp=true \\
   --chrome_path={chrome} \\
-  --url="http://localhost:$PORT{path}" \\
+  --url="$BASE_URL{path}" \\
   --output_file={output} \\
+  --diff_report={diff_report} \\
   --wait_ready=body \\
   --timeout=60
 """
 
+def _renderer(ctx):
+    """Returns the selected renderer and whether it needs Chrome."""
+    renderer = ctx.attr.renderer[BuildSettingInfo].value
+    if renderer not in ("chrome", "go", "diff"):
+        fail("unknown prerender renderer %r (want chrome, go or diff)" % renderer)
+    return renderer, renderer != "go"
+
+def _server_cmd(ctx, needs_chrome):
+    if not needs_chrome:
+        return _NO_SERVER_CMD.format()
+    return _SERVER_CMD.format(
+        server = ctx.executable._releaseserver.path,
+        tarball = ctx.file.tarball.path,
+    )
+
+def _diff_report(ctx, renderer, name):
+    """Declares the diff report output in diff mode.
+
+    Returns the list of declared outputs and the --diff_report value.
+    """
+    if renderer != "diff":
+        return [], '""'
+    report = ctx.actions.declare_file(name)
+    return [report], report.path
+
+def _chrome(ctx, needs_chrome):
+    """Returns the chrome-headless-shell path and runfiles, if needed."""
+    if not needs_chrome:
+        return "", depset()
+    chrome_bin = ctx.attr._chromium[NamedFilesInfo].value["CHROME-HEADLESS-SHELL"]
+    return chrome_bin.path, ctx.attr._chromium[DefaultInfo].default_runfiles.files
+
 def _prerender_home_impl(ctx):
     output = ctx.actions.declare_file(ctx.label.name + ".html")
 
-    chrome_bin = ctx.attr._chromium[NamedFilesInfo].value["CHROME-HEADLESS-SHELL"]
-    chromium_runfiles = ctx.attr._chromium[DefaultInfo].default_runfiles.files
+    renderer, needs_chrome = _renderer(ctx)
+    chrome, chromium_runfiles = _chrome(ctx, needs_chrome)
+    diff_reports, diff_report = _diff_report(ctx, renderer, ctx.label.name + ".diff.txt")
 
     cmd = _PRERENDER_CMD.format(
-        server = ctx.executable._releaseserver.path,
+        server_cmd = _server_cmd(ctx, needs_chrome),
         compiler = ctx.executable._statichtmlcompiler.path,
-        chrome = chrome_bin.path,
+        renderer = renderer,
+        chrome = chrome,
         tarball = ctx.file.tarball.path,
         output = output.path,
+        diff_report = diff_report,
         path = ctx.attr.path,
     )
 
     ctx.actions.run_shell(
-        outputs = [output],
+        outputs = [output] + diff_reports,
         inputs = depset(
             direct = [ctx.file.tarball],
             transitive = [chromium_runfiles],
@@ -97,10 +165,13 @@ def _prerender_home_impl(ctx):
         ],
         command = cmd,
         mnemonic = "PrerenderHome",
-        progress_message = "Prerendering %s with chrome-headless-shell" % ctx.attr.path,
+        progress_message = "Prerendering %s (%s renderer)" % (ctx.attr.path, renderer),
     )
 
-    return [DefaultInfo(files = depset([output]))]
+    return [
+        DefaultInfo(files = depset([output])),
+        OutputGroupInfo(diff_report = depset(diff_reports)),
+    ]
 
 prerender_home = rule(
     implementation = _prerender_home_impl,
@@ -114,35 +185,22 @@ prerender_home = rule(
             default = "/",
             doc = "URL path to prerender (default: '/').",
         ),
-    }, **_TOOL_ATTRS),
+    }, **dict(_RENDERER_ATTRS, **_TOOL_ATTRS)),
 )
 
 _PRERENDER_SHARD_CMD = """\
 set -e
 
-PORT_FILE=$(mktemp -t bcr_prerender_pages.XXXXXX)
+{server_cmd}
 WORKDIR=$(mktemp -d -t bcr_prerender_pages_workdir.XXXXXX)
-SERVER_PID=""
 cleanup() {{
-  if [ -n "$SERVER_PID" ]; then kill "$SERVER_PID" 2>/dev/null || true; fi
-  rm -f "$PORT_FILE"
+  cleanup_server
   rm -rf "$WORKDIR"
 }}
 trap cleanup EXIT
 
-{server} --port=0 --port_file="$PORT_FILE" {tarball} >/dev/null 2>&1 &
-SERVER_PID=$!
-
-for i in $(seq 1 60); do
-  if [ -s "$PORT_FILE" ]; then break; fi
-  sleep 0.25
-done
-if [ ! -s "$PORT_FILE" ]; then
-  echo "releaseserver did not write a port file" >&2
-  exit 1
-fi
-PORT=$(cat "$PORT_FILE")
-BASE_URL="http://localhost:$PORT"
+# With the go renderer, one shard also renders a page per Bazel flag.
+FLAG_PAGES_DIR={flag_pages_dir}
 
 # Take 1/N of the URL list — every line whose 0-based index modulo
 # {shard_total} equals {shard_index}. This evenly distributes work
@@ -179,9 +237,10 @@ while IFS=' ' read -r url paths || [ -n "$url" ]; do
 done < "$SHARD_LIST"
 
 # Empty shard is fine (e.g., URL list shorter than shard_total) — emit
-# an empty tar so the merge step still works.
-if [ "$URL_COUNT" -eq 0 ]; then
+# an empty tar (and diff report) so the merge step still works.
+if [ "$URL_COUNT" -eq 0 ] && [ -z "$FLAG_PAGES_DIR" ]; then
   tar -cf {output} -C "$WORKDIR" .
+  if [ -n {diff_report} ]; then : > {diff_report}; fi
   exit 0
 fi
 
@@ -193,6 +252,9 @@ PROGRESS_LOG=/tmp/bcr_prerender_shard{shard_index}.log
 echo "Mirroring prerender progress to $PROGRESS_LOG (tail -f to watch)" >&2
 
 {compiler} \\
+  --renderer={renderer} \\
+  --release_tar={tarball} \\
+  --flag_pages_dir="$FLAG_PAGES_DIR" \\
   --chromedp=true \\
   --chrome_path={chrome} \\
   --chromedp_pool={pool} \\
@@ -204,6 +266,7 @@ echo "Mirroring prerender progress to $PROGRESS_LOG (tail -f to watch)" >&2
   --timeout={timeout} \\
   --settle_ms={settle_ms} \\
   --warmup_concurrency={warmup_concurrency} \\
+  --diff_report={diff_report} \\
   $URL_ARGS
 
 tar -cf {output} -C "$WORKDIR" .
@@ -218,25 +281,35 @@ tar -cf {output} -C "$WORKDIR" .
 """
 
 def _prerender_pages_impl(ctx):
-    chrome_bin = ctx.attr._chromium[NamedFilesInfo].value["CHROME-HEADLESS-SHELL"]
-    chromium_runfiles = ctx.attr._chromium[DefaultInfo].default_runfiles.files
+    renderer, needs_chrome = _renderer(ctx)
+    chrome, chromium_runfiles = _chrome(ctx, needs_chrome)
 
     n = ctx.attr.shards
     if n < 1:
         fail("shards must be >= 1, got %d" % n)
 
     shard_outputs = []
+    diff_reports = []
     for i in range(n):
         shard_out = ctx.actions.declare_file(
             "{}.shard{}.tar".format(ctx.label.name, i),
         )
+        shard_diff_reports, diff_report = _diff_report(
+            ctx,
+            renderer,
+            "{}.shard{}.diff.txt".format(ctx.label.name, i),
+        )
+        diff_reports.extend(shard_diff_reports)
         cmd = _PRERENDER_SHARD_CMD.format(
-            server = ctx.executable._releaseserver.path,
+            server_cmd = _server_cmd(ctx, needs_chrome),
             compiler = ctx.executable._statichtmlcompiler.path,
-            chrome = chrome_bin.path,
+            renderer = renderer,
+            flag_pages_dir = '"$WORKDIR"' if renderer == "go" and ctx.attr.flag_pages and i == 0 else '""',
+            chrome = chrome,
             tarball = ctx.file.tarball.path,
             url_list = ctx.file.url_list.path,
             output = shard_out.path,
+            diff_report = diff_report,
             shard_index = i,
             shard_total = n,
             timeout = ctx.attr.timeout_seconds,
@@ -247,7 +320,7 @@ def _prerender_pages_impl(ctx):
             warmup_concurrency = ctx.attr.warmup_concurrency[BuildSettingInfo].value,
         )
         ctx.actions.run_shell(
-            outputs = [shard_out],
+            outputs = [shard_out] + shard_diff_reports,
             inputs = depset(
                 direct = [ctx.file.tarball, ctx.file.url_list],
                 transitive = [chromium_runfiles],
@@ -258,7 +331,7 @@ def _prerender_pages_impl(ctx):
             ],
             command = cmd,
             mnemonic = "PrerenderPagesShard",
-            progress_message = "Prerendering pages shard {} of {}".format(i + 1, n),
+            progress_message = "Prerendering pages shard {} of {} ({} renderer)".format(i + 1, n, renderer),
         )
         shard_outputs.append(shard_out)
 
@@ -281,7 +354,49 @@ def _prerender_pages_impl(ctx):
         progress_message = "Merging {} prerender shards".format(n),
     )
 
-    return [DefaultInfo(files = depset([final_output]))]
+    report = _validate_pages_action(ctx, final_output)
+
+    return [
+        DefaultInfo(files = depset([final_output])),
+        OutputGroupInfo(
+            _validation = depset([report]),
+            prerender_report = depset([report]),
+            diff_report = depset(diff_reports),
+        ),
+    ]
+
+def _validate_pages_action(ctx, pages_tar):
+    """Check every prerendered page; a failing check fails the build.
+
+    Runs as a validation action, so it gates any build that depends on
+    pages_tar. The summary report is available from the prerender_report
+    output group; when the check fails it is printed to stderr instead.
+    """
+    report = ctx.actions.declare_file(ctx.label.name + ".report.txt")
+    args = ctx.actions.args()
+    args.add("--prerender_tar", pages_tar)
+    args.add("--report_file", report)
+    args.add("--min_bytes", ctx.attr.min_bytes)
+    args.add("--min_text_chars", ctx.attr.min_text_chars)
+    args.add("--max_text_change", ctx.attr.max_text_change)
+    args.add("--max_failures", ctx.attr.max_failures)
+    inputs = [pages_tar]
+    baseline = ctx.files.baseline
+    if len(baseline) > 1:
+        fail("baseline must provide at most one tarball, got %d files" % len(baseline))
+    if baseline:
+        args.add("--baseline_tar", baseline[0])
+        inputs.append(baseline[0])
+
+    ctx.actions.run(
+        executable = ctx.executable._prerendercheck,
+        arguments = [args],
+        inputs = inputs,
+        outputs = [report],
+        mnemonic = "PrerenderCheck",
+        progress_message = "Validating prerendered pages of %{label}",
+    )
+    return report
 
 prerender_pages = rule(
     implementation = _prerender_pages_impl,
@@ -355,5 +470,43 @@ prerender_pages = rule(
                   "Serializing the parse to 4-way concurrency lets each tab pay close to " +
                   "the ~1.5s solo cost. Set the flag to 0 to disable warmup.",
         ),
-    }, **_TOOL_ATTRS),
+        "baseline": attr.label(
+            default = "//app/bcr:prerender_baseline",
+            allow_files = [".tar"],
+            doc = "Previous release (or its prerender tarball) to compare page " +
+                  "text against. Pages whose text changed by more than " +
+                  "max_text_change are reported as regressed. An empty target " +
+                  "disables the comparison.",
+        ),
+        "min_bytes": attr.int(
+            default = 2048,
+            doc = "Minimum size of a prerendered document in bytes.",
+        ),
+        "min_text_chars": attr.int(
+            default = 40,
+            doc = "Minimum length of a prerendered page's visible text; blank " +
+                  "snapshots fall well below it.",
+        ),
+        "max_text_change": attr.string(
+            default = "0.5",
+            doc = "Largest tolerated fraction (0-1) of words that changed relative " +
+                  "to the baseline page.",
+        ),
+        "max_failures": attr.int(
+            default = 0,
+            doc = "Number of regressed pages tolerated before the validation " +
+                  "fails the build.",
+        ),
+        "_prerendercheck": attr.label(
+            default = "//cmd/prerendercheck",
+            executable = True,
+            cfg = "exec",
+        ),
+        "flag_pages": attr.bool(
+            default = True,
+            doc = "With the go renderer, also emit a page per Bazel flag at " +
+                  "bazel/flags/<name>/index.html. Chrome renders only the URLs " +
+                  "in url_list.",
+        ),
+    }, **dict(_RENDERER_ATTRS, **_TOOL_ATTRS)),
 )
@@ -51,10 +51,14 @@ ModuleSourceInfo = provider(
         "url_status_code": "int: HTTP status code of the source URL",
         "url_status_message": "str: HTTP status message of the source URL",
         "integrity": "str: Source integrity hash (e.g., sha256-...)",
+        "integrity_status": "list[str]: JSON-encoded integrity verification result of each downloaded source and mirror URL",
         "strip_prefix": "str: Directory prefix to strip from the archive",
         "patch_strip": "int: Number of leading path components to strip from patches",
         "patches": "dict[str, str]: Mapping of patch filename to integrity hash",
+        "patch_stats": "list[str]: JSON-encoded content stats of each patch in `patches`, parsed from the patch content",
+        "patch_files": "list[File]: The patch files listed in `patches`",
         "overlay": "dict[str, str]: Mapping of overlay filename to integrity hash",
+        "overlay_files": "list[File]: The overlay files listed in `overlay`",
         "source_json": "File: The source.json file",
         "docs_url": "str: Documentation archive URL (empty string if not set)",
         "docs_url_status_code": "int: HTTP status code of the docs URL",
@@ -64,7 +68,7 @@ ModuleSourceInfo = provider(
 )
 
 RouteInfo = provider(
-    doc = "Sitemap-relevant route data emitted by every rule that owns one or more SPA URLs. Used by module_registry to build sitemap.xml.gz + sitemapindex.xml.",
+    doc = "Sitemap-relevant route data emitted by every rule that owns one or more SPA URLs. Used by module_registry to build the sharded sitemap.xml.gz files and their sitemapindex.xml.",
     fields = {
         "routes": "depset[struct]: each element is struct(loc, lastmod, priority, changefreq) with loc a fully-qualified URL (or a path beginning with '/'; resolved against the base URL by the compiler), lastmod a YYYY-MM-DD string or '', priority a float in [0,1] or 0.0, changefreq one of 'daily'/'weekly'/'monthly'/'' (empty = omit)",
     },
@@ -32,9 +32,21 @@ def _compile_release_action(ctx):
     if ctx.file.bazel_flag_db_file:
         args.add("--bazel_flag_db_file")
         args.add(ctx.file.bazel_flag_db_file)
+    if ctx.file.licenses_file:
+        args.add("--licenses_file")
+        args.add(ctx.file.licenses_file)
     if ctx.file.prerendered_pages_tar:
         args.add("--prerendered_pages_tar")
         args.add(ctx.file.prerendered_pages_tar)
+    if ctx.file.feeds_tar:
+        args.add("--feeds_tar")
+        args.add(ctx.file.feeds_tar)
+    if ctx.file.api_tar:
+        args.add("--api_tar")
+        args.add(ctx.file.api_tar)
+    if ctx.file.sitemap_tar:
+        args.add("--sitemap_tar")
+        args.add(ctx.file.sitemap_tar)
 
     # Collect files to exclude from hashing
     exclude_from_hash = [src.basename for src in ctx.files.srcs]
@@ -47,13 +59,21 @@ def _compile_release_action(ctx):
     for f in ctx.files.modules_srcs:
         args.add("--modules_src", f)
 
+    # The registry mirror is tens of thousands of files; keep them off the
+    # command line.
+    args.add_all(ctx.files.registry_srcs, before_each = "--registry_src")
+    args.add_all(ctx.attr.registry_mirrors, before_each = "--registry_mirror")
+    args.add_all(ctx.files.previous_releases, before_each = "--previous_release")
+    args.add("--max_previous_releases", ctx.attr.max_previous_releases)
+    args.use_param_file("@%s", use_always = False)
+
     # Positional args (asset files) must come last, after all flags
     args.add_all(ctx.files.srcs)
     args.add_all(ctx.files.hashed_srcs)
     args.add_all(ctx.files.worker_modules)
 
     # Build inputs list
-    inputs = ctx.files.srcs + ctx.files.hashed_srcs + ctx.files.worker_modules + ctx.files.modules_srcs + [
+    inputs = ctx.files.srcs + ctx.files.hashed_srcs + ctx.files.worker_modules + ctx.files.modules_srcs + ctx.files.registry_srcs + ctx.files.previous_releases + [
         ctx.file.index_html,
         ctx.file.registry_file,
     ] + (
@@ -62,8 +82,16 @@ def _compile_release_action(ctx):
         [ctx.file.module_registry_packages_file] if ctx.file.module_registry_packages_file else []
     ) + (
         [ctx.file.bazel_flag_db_file] if ctx.file.bazel_flag_db_file else []
+    ) + (
+        [ctx.file.licenses_file] if ctx.file.licenses_file else []
     ) + (
         [ctx.file.prerendered_pages_tar] if ctx.file.prerendered_pages_tar else []
+    ) + (
+        [ctx.file.feeds_tar] if ctx.file.feeds_tar else []
+    ) + (
+        [ctx.file.api_tar] if ctx.file.api_tar else []
+    ) + (
+        [ctx.file.sitemap_tar] if ctx.file.sitemap_tar else []
     )
 
     ctx.actions.run(
@@ -96,6 +124,22 @@ release_archive = rule(
             allow_files = True,
             doc = "Files to include under modules/ in the tarball, preserving subdirectory structure",
         ),
+        "previous_releases": attr.label_list(
+            allow_files = [".tar"],
+            doc = "Prior release tarballs, newest first. The manifest references registry and symbols deltas from each so the refresh poller can apply small updates.",
+        ),
+        "max_previous_releases": attr.int(
+            default = 0,
+            doc = "The number of previous_releases to compute deltas from. " +
+                  "Off by default: no client applies the deltas yet.",
+        ),
+        "registry_srcs": attr.label_list(
+            allow_files = True,
+            doc = "Raw registry files (metadata.json, MODULE.bazel, source.json, patches/...) laid out under registry/modules/ so the release also serves as a Bazel index registry (--registry=<url>/registry)",
+        ),
+        "registry_mirrors": attr.string_list(
+            doc = "Mirror URLs listed in registry/bazel_registry.json",
+        ),
         "srcs": attr.label_list(allow_files = True),
         "worker_modules": attr.label_list(
             allow_files = [".wasm", ".js", ".mjs"],
@@ -112,10 +156,26 @@ release_archive = rule(
             allow_single_file = True,
             doc = "Optional BazelFlagDb proto. Gzipped into the tarball as bazelflagdb.pb.gz; the frontend lazy-loads it on first navigation to /bazel/flags.",
         ),
+        "licenses_file": attr.label(
+            allow_single_file = True,
+            doc = "Optional ModuleRegistryLicenses proto. Supplies the license of each module version in the JSON-LD injected into prerendered pages.",
+        ),
         "prerendered_pages_tar": attr.label(
             allow_single_file = [".tar"],
             doc = "Optional tar of prerendered HTML pages whose entries are merged into the release tarball verbatim.",
         ),
+        "feeds_tar": attr.label(
+            allow_single_file = [".tar"],
+            doc = "Optional tar of Atom and JSON Feed documents (feeds/...) merged into the release tarball verbatim.",
+        ),
+        "api_tar": attr.label(
+            allow_single_file = [".tar"],
+            doc = "Optional tar of static JSON and protobuf API documents (api/...) merged into the release tarball verbatim.",
+        ),
+        "sitemap_tar": attr.label(
+            allow_single_file = [".tar"],
+            doc = "Optional tar of the sitemap index (sitemap.xml) and its gzipped shards (sitemaps/...) merged into the release tarball verbatim.",
+        ),
         "_releasecompiler": attr.label(
             default = "//cmd/releasecompiler",
             executable = True,